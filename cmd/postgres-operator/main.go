@@ -18,18 +18,34 @@ limitations under the License.
 import (
 	"context"
 	"os"
+	"strconv"
 	"strings"
 
+	// Embed the time zone database so that uptime schedules work in images
+	// that do not include one.
+	_ "time/tzdata"
+
+	"github.com/pkg/errors"
 	"go.opentelemetry.io/otel"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/rest"
 	cruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"github.com/crunchydata/postgres-operator/internal/controller/postgrescluster"
 	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
 	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/notify"
 	"github.com/crunchydata/postgres-operator/internal/upgradecheck"
+	"github.com/crunchydata/postgres-operator/internal/vault"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 var versionString string
@@ -77,10 +93,20 @@ func main() {
 	mgr, err := runtime.CreateRuntimeManager(os.Getenv("PGO_TARGET_NAMESPACE"), cfg, false)
 	assertNoError(err)
 
+	// Discover OpenShift once rather than for every component that needs it.
+	openshift := isOpenshift(ctx, mgr.GetConfig())
+
 	// add all PostgreSQL Operator controllers to the runtime manager
-	err = addControllersToManager(ctx, mgr)
+	err = addControllersToManager(ctx, mgr, openshift,
+		cronJobTimeZones(ctx, mgr.GetClient(), os.Getenv("PGO_TARGET_NAMESPACE")))
 	assertNoError(err)
 
+	// serve the optional validating webhook when there is a certificate for it
+	if certDir := os.Getenv("PGO_WEBHOOK_CERT_DIR"); certDir != "" {
+		log.Info("validating webhook enabled")
+		addWebhooksToManager(mgr, certDir)
+	}
+
 	log.Info("starting controller runtime manager and will wait for signal to exit")
 
 	// Enable upgrade checking
@@ -90,7 +116,7 @@ func main() {
 		// get the URL for the check for upgrades endpoint if set in the env
 		upgradeCheckURL := os.Getenv("CHECK_FOR_UPGRADES_URL")
 		go upgradecheck.CheckForUpgradesScheduler(ctx, versionString, upgradeCheckURL,
-			mgr.GetClient(), mgr.GetConfig(), isOpenshift(ctx, mgr.GetConfig()),
+			mgr.GetClient(), mgr.GetConfig(), openshift,
 			mgr.GetCache(),
 		)
 	} else {
@@ -103,17 +129,99 @@ func main() {
 
 // addControllersToManager adds all PostgreSQL Operator controllers to the provided controller
 // runtime manager.
-func addControllersToManager(ctx context.Context, mgr manager.Manager) error {
+func addControllersToManager(
+	ctx context.Context, mgr manager.Manager, openshift, timeZones bool,
+) error {
 	r := &postgrescluster.Reconciler{
-		Client:      mgr.GetClient(),
-		Owner:       postgrescluster.ControllerName,
-		Recorder:    mgr.GetEventRecorderFor(postgrescluster.ControllerName),
-		Tracer:      otel.Tracer(postgrescluster.ControllerName),
-		IsOpenShift: isOpenshift(ctx, mgr.GetConfig()),
+		Client:           mgr.GetClient(),
+		Owner:            postgrescluster.ControllerName,
+		Recorder:         mgr.GetEventRecorderFor(postgrescluster.ControllerName),
+		Tracer:           otel.Tracer(postgrescluster.ControllerName),
+		IsOpenShift:      openshift,
+		CronJobTimeZones: timeZones,
+	}
+
+	// When watching all namespaces, optionally reconcile only the PostgresClusters in
+	// namespaces that have matching labels.
+	if s := os.Getenv("PGO_NAMESPACE_SELECTOR"); s != "" {
+		if os.Getenv("PGO_TARGET_NAMESPACE") != "" {
+			logging.FromContext(ctx).Info(
+				"PGO_NAMESPACE_SELECTOR is ignored when PGO_TARGET_NAMESPACE is set")
+		} else {
+			selector, err := labels.Parse(s)
+			if err != nil {
+				return err
+			}
+			r.NamespaceSelector = selector
+		}
+	}
+
+	// Optionally limit how many manual backups run at the same time, e.g. when every
+	// PostgresCluster is annotated for a backup at once.
+	if s := os.Getenv("PGO_MAX_CONCURRENT_BACKUPS"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit < 1 {
+			return errors.Errorf(
+				"PGO_MAX_CONCURRENT_BACKUPS must be a positive number, got %q", s)
+		}
+		r.MaxConcurrentBackups = limit
+	}
+
+	// Optionally read the passwords of PostgreSQL users from HashiCorp Vault. PGO
+	// sends the token of its service account to this server, so the address and
+	// role come from the environment of PGO rather than from any PostgresCluster.
+	if address := os.Getenv("VAULT_ADDR"); address != "" {
+		role := os.Getenv("PGO_VAULT_ROLE")
+		if role == "" {
+			return errors.New("PGO_VAULT_ROLE is required when VAULT_ADDR is set")
+		}
+		r.Vault = vault.NewSession(address, os.Getenv("PGO_VAULT_AUTH_PATH"), role)
+	}
+
+	// Optionally notify a webhook about lifecycle events of every PostgresCluster.
+	// Clusters can name their own webhooks in their spec.
+	var webhooks []notify.Webhook
+	if url := os.Getenv("PGO_NOTIFICATION_URL"); url != "" {
+		format := os.Getenv("PGO_NOTIFICATION_FORMAT")
+		if format != "" && format != notify.FormatGeneric && format != notify.FormatSlack {
+			return errors.Errorf(
+				"PGO_NOTIFICATION_FORMAT must be %q or %q, got %q",
+				notify.FormatGeneric, notify.FormatSlack, format)
+		}
+		webhooks = append(webhooks, notify.Webhook{URL: url, Format: format})
 	}
+	recorder := notify.NewRecorder(r.Recorder, mgr.GetClient(), webhooks...)
+
+	// Optionally email failed backups and failovers through an SMTP server.
+	if address := os.Getenv("PGO_SMTP_ADDRESS"); address != "" {
+		mailer, err := notify.NewMailer(address,
+			os.Getenv("PGO_SMTP_FROM"), os.Getenv("PGO_SMTP_TO"),
+			os.Getenv("PGO_SMTP_USERNAME"), os.Getenv("PGO_SMTP_PASSWORD"))
+		if err != nil {
+			return err
+		}
+		recorder.Mail = mailer
+
+		// PGO can read namespaces only when it watches all of them.
+		if os.Getenv("PGO_TARGET_NAMESPACE") == "" {
+			recorder.Namespaces = mgr.GetClient()
+		}
+	}
+	r.Recorder = recorder
+
 	return r.SetupWithManager(mgr)
 }
 
+// addWebhooksToManager registers the PostgresCluster validating webhook with the provided
+// controller runtime manager. The webhook server uses the "tls.crt" and "tls.key" files in
+// certDir.
+func addWebhooksToManager(mgr manager.Manager, certDir string) {
+	server := mgr.GetWebhookServer()
+	server.CertDir = certDir
+	server.Register("/validate-postgres-operator-crunchydata-com-v1beta1-postgrescluster",
+		admission.ValidatingWebhookFor(&v1beta1.PostgresCluster{}))
+}
+
 func isOpenshift(ctx context.Context, cfg *rest.Config) bool {
 	log := logging.FromContext(ctx)
 
@@ -146,3 +254,50 @@ func isOpenshift(ctx context.Context, cfg *rest.Config) bool {
 
 	return false
 }
+
+// cronJobTimeZones reports whether the Kubernetes API keeps the time zone of
+// a CronJob. Older servers, and servers without the CronJobTimeZone feature,
+// quietly drop the field, so this creates a CronJob with dry-run to find out.
+// - https://docs.k8s.io/concepts/workloads/controllers/cron-jobs/#time-zones
+func cronJobTimeZones(ctx context.Context, cc client.Client, namespace string) bool {
+	log := logging.FromContext(ctx)
+
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+
+	probe := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"generateName": "pgo-time-zone-",
+			"namespace":    namespace,
+		},
+		"spec": map[string]interface{}{
+			"schedule": "@daily",
+			"timeZone": "Etc/UTC",
+			"jobTemplate": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"template": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"restartPolicy": string(corev1.RestartPolicyNever),
+							"containers": []interface{}{map[string]interface{}{
+								"name": "probe", "image": "probe",
+							}},
+						},
+					},
+				},
+			},
+		},
+	}}
+	probe.SetGroupVersionKind(batchv1beta1.SchemeGroupVersion.WithKind("CronJob"))
+
+	if err := cc.Create(ctx, probe, client.DryRunAll); err != nil {
+		log.Error(err, "unable to detect CronJob time zones")
+		return false
+	}
+	if zone, _, _ := unstructured.NestedString(probe.Object, "spec", "timeZone"); zone == "" {
+		return false
+	}
+
+	log.Info("detected CronJob time zones")
+	return true
+}
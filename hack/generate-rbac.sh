@@ -67,6 +67,10 @@ operator["rules"] = rules.sort_by { |rule| rule.to_a }
 operator["metadata"] = { "name" => "postgres-operator" }
 IO.write(File.join(directory, "cluster", "role.yaml"), YAML.dump(operator))
 
+# A Role cannot grant access to cluster-scoped resources, so leave them out.
 operator["kind"] = "Role"
+operator["rules"] = operator["rules"].map do |rule|
+	rule.merge("resources" => rule["resources"] - ["namespaces"])
+end.reject { |rule| rule["resources"].empty? }
 IO.write(File.join(directory, "namespace", "role.yaml"), YAML.dump(operator))
 ' -- "${directory}"
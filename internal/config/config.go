@@ -45,6 +45,18 @@ func PGBackRestContainerImage(cluster *v1beta1.PostgresCluster) string {
 	return defaultFromEnv(image, "RELATED_IMAGE_PGBACKREST")
 }
 
+// FluentBitContainerImage returns the container image to use for shipping
+// pgAudit logs.
+func FluentBitContainerImage(cluster *v1beta1.PostgresCluster) string {
+	var image string
+	if cluster.Spec.Audit != nil &&
+		cluster.Spec.Audit.LogShipping != nil {
+		image = cluster.Spec.Audit.LogShipping.Image
+	}
+
+	return defaultFromEnv(image, "RELATED_IMAGE_FLUENTBIT")
+}
+
 // PGAdminContainerImage returns the container image to use for pgAdmin.
 func PGAdminContainerImage(cluster *v1beta1.PostgresCluster) string {
 	var image string
@@ -88,6 +100,9 @@ func PostgresContainerImage(cluster *v1beta1.PostgresCluster) string {
 	if version := cluster.Spec.PostGISVersion; version != "" {
 		key += "_GIS_" + version
 	}
+	if version := cluster.Spec.TimescaleDBVersion; version != "" {
+		key += "_TIMESCALEDB_" + version
+	}
 
 	return defaultFromEnv(image, key)
 }
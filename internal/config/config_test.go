@@ -49,6 +49,24 @@ func unsetEnv(t testing.TB, key string) {
 	assert.NilError(t, os.Unsetenv(key))
 }
 
+func TestFluentBitContainerImage(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+
+	unsetEnv(t, "RELATED_IMAGE_FLUENTBIT")
+	assert.Equal(t, FluentBitContainerImage(cluster), "")
+
+	setEnv(t, "RELATED_IMAGE_FLUENTBIT", "")
+	assert.Equal(t, FluentBitContainerImage(cluster), "")
+
+	setEnv(t, "RELATED_IMAGE_FLUENTBIT", "env-var-fluentbit")
+	assert.Equal(t, FluentBitContainerImage(cluster), "env-var-fluentbit")
+
+	assert.NilError(t, yaml.Unmarshal([]byte(`{
+		audit: { logShipping: { image: spec-image } },
+	}`), &cluster.Spec))
+	assert.Equal(t, FluentBitContainerImage(cluster), "spec-image")
+}
+
 func TestPGAdminContainerImage(t *testing.T) {
 	cluster := &v1beta1.PostgresCluster{}
 
@@ -144,4 +162,13 @@ func TestPostgresContainerImage(t *testing.T) {
 
 	cluster.Spec.Image = "spec-image"
 	assert.Equal(t, PostgresContainerImage(cluster), "spec-image")
+
+	cluster.Spec.Image = ""
+	cluster.Spec.PostGISVersion = ""
+	cluster.Spec.TimescaleDBVersion = "2.7"
+	setEnv(t, "RELATED_IMAGE_POSTGRES_12_TIMESCALEDB_2.7", "env-var-timescaledb")
+	assert.Equal(t, PostgresContainerImage(cluster), "env-var-timescaledb")
+
+	cluster.Spec.Image = "spec-image"
+	assert.Equal(t, PostgresContainerImage(cluster), "spec-image")
 }
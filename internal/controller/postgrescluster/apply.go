@@ -16,10 +16,13 @@
 package postgrescluster
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 
 	jsonpatch "github.com/evanphx/json-patch/v5"
 	"github.com/pkg/errors"
@@ -31,8 +34,11 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
 
 	"github.com/crunchydata/postgres-operator/internal/kubeapi"
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 // apply sends an apply patch to object's endpoint in the Kubernetes API and
@@ -50,10 +56,19 @@ func (r *Reconciler) apply(ctx context.Context, object client.Object) error {
 	intent := object.DeepCopyObject()
 	patch := kubeapi.NewJSONPatch()
 
+	// Remember the fields that were changed by others, e.g. "kubectl edit".
+	var updated map[string]metav1.FieldsV1
+	if err == nil {
+		updated = r.updatedFields(ctx, object)
+	}
+
 	// Send the apply-patch with force=true.
 	if err == nil {
 		err = r.patch(ctx, object, apply, client.ForceOwnership)
 	}
+	if err == nil {
+		r.reportDrift(ctx, object, updated)
+	}
 
 	// Some fields cannot be server-side applied correctly. When their outcome
 	// does not match the intent, send a json-patch to get really specific.
@@ -84,6 +99,96 @@ func (r *Reconciler) apply(ctx context.Context, object client.Object) error {
 	return err
 }
 
+// updatedFields returns the fields of the existing object that were last
+// written by an Update rather than an Apply of r.Owner, indexed by manager.
+// It returns nil when the object does not exist or is not owned by this
+// controller, so that only objects in the cache are read.
+func (r *Reconciler) updatedFields(
+	ctx context.Context, object client.Object,
+) map[string]metav1.FieldsV1 {
+	switch object.(type) {
+	case *appsv1.Deployment, *appsv1.StatefulSet,
+		*corev1.ConfigMap, *corev1.Secret, *corev1.Service:
+	default:
+		return nil
+	}
+
+	existing := reflect.New(reflect.TypeOf(object).Elem()).Interface().(client.Object)
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(object), existing); err != nil {
+		return nil
+	}
+
+	var updated map[string]metav1.FieldsV1
+	for _, entry := range existing.GetManagedFields() {
+		if entry.Operation == metav1.ManagedFieldsOperationUpdate &&
+			entry.Manager != string(r.Owner) && entry.FieldsV1 != nil {
+			if updated == nil {
+				updated = make(map[string]metav1.FieldsV1)
+			}
+			updated[entry.Manager] = *entry.FieldsV1
+		}
+	}
+	return updated
+}
+
+// reportDrift records an event on the PostgresCluster that controls object
+// when applying object took fields away from the managers in updated. That
+// happens when someone changes a field that PGO sets, and PGO reverts it.
+func (r *Reconciler) reportDrift(
+	ctx context.Context, object client.Object, updated map[string]metav1.FieldsV1,
+) {
+	if len(updated) == 0 {
+		return
+	}
+
+	after := make(map[string]metav1.FieldsV1, len(updated))
+	for _, entry := range object.GetManagedFields() {
+		if entry.Operation == metav1.ManagedFieldsOperationUpdate && entry.FieldsV1 != nil {
+			after[entry.Manager] = *entry.FieldsV1
+		}
+	}
+
+	// Other managers, like the status updates of Kubernetes controllers, may
+	// gain fields in the meantime. Only fields they lost count as reverted.
+	var managers []string
+	for manager, fields := range updated {
+		before, current := &fieldpath.Set{}, &fieldpath.Set{}
+		if err := before.FromJSON(bytes.NewReader(fields.Raw)); err != nil {
+			continue
+		}
+		if raw := after[manager].Raw; len(raw) > 0 {
+			if err := current.FromJSON(bytes.NewReader(raw)); err != nil {
+				continue
+			}
+		}
+		if !before.Difference(current).Empty() {
+			managers = append(managers, manager)
+		}
+	}
+	if len(managers) == 0 {
+		return
+	}
+	sort.Strings(managers)
+
+	kind := reflect.TypeOf(object).Elem().Name()
+	logging.FromContext(ctx).Info("reverted changes made outside of the operator",
+		"kind", kind, "name", object.GetName(), "managers", managers)
+
+	if owner := metav1.GetControllerOf(object); owner != nil && owner.Kind == "PostgresCluster" {
+		configurationDrift.WithLabelValues(object.GetNamespace(), owner.Name, kind).Inc()
+
+		if r.Recorder != nil {
+			cluster := &v1beta1.PostgresCluster{}
+			cluster.Namespace, cluster.Name, cluster.UID =
+				object.GetNamespace(), owner.Name, owner.UID
+
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventConfigurationDrift,
+				"Reverted changes to %s %q made by %s", kind, object.GetName(),
+				strings.Join(managers, ", "))
+		}
+	}
+}
+
 // handleServiceError inspects err for expected Kubernetes API responses to
 // writing a Service. It returns err when it cannot resolve the issue, otherwise
 // it returns nil.
@@ -27,6 +27,7 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"gotest.tools/v3/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -35,12 +36,85 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/version"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/testing/require"
 )
 
+func TestReportDrift(t *testing.T) {
+	ctx := context.Background()
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	fields := func(raw string) *metav1.FieldsV1 { return &metav1.FieldsV1{Raw: []byte(raw)} }
+
+	existing := &corev1.ConfigMap{}
+	existing.Namespace, existing.Name = "ns1", "hippo-config"
+	existing.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: "postgres-operator.crunchydata.com/v1beta1", Kind: "PostgresCluster",
+		Name: "hippo", UID: "uid", Controller: initialize.Bool(true),
+	}}
+	existing.ManagedFields = []metav1.ManagedFieldsEntry{
+		{Manager: "pgo", Operation: metav1.ManagedFieldsOperationApply,
+			FieldsV1: fields(`{"f:data":{"f:other":{}}}`)},
+		{Manager: "kubectl-edit", Operation: metav1.ManagedFieldsOperationUpdate,
+			FieldsV1: fields(`{"f:data":{"f:key":{}}}`)},
+		{Manager: "vigilant", Operation: metav1.ManagedFieldsOperationUpdate,
+			FieldsV1: fields(`{"f:metadata":{"f:labels":{"f:a":{}}}}`)},
+	}
+
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).Build(),
+		Owner:    "pgo",
+		Recorder: recorder,
+	}
+
+	intent := &corev1.ConfigMap{}
+	intent.Namespace, intent.Name = "ns1", "hippo-config"
+
+	updated := reconciler.updatedFields(ctx, intent)
+	assert.Equal(t, len(updated), 2)
+
+	t.Run("Unchanged", func(t *testing.T) {
+		object := existing.DeepCopy()
+		reconciler.reportDrift(ctx, object, updated)
+		assert.Equal(t, len(recorder.Events), 0)
+	})
+
+	t.Run("Gained", func(t *testing.T) {
+		object := existing.DeepCopy()
+		object.ManagedFields[2].FieldsV1 = fields(`{"f:metadata":{"f:labels":{"f:a":{},"f:b":{}}}}`)
+		reconciler.reportDrift(ctx, object, updated)
+		assert.Equal(t, len(recorder.Events), 0)
+	})
+
+	t.Run("Reverted", func(t *testing.T) {
+		object := existing.DeepCopy()
+		object.ManagedFields = object.ManagedFields[:1]
+		object.ManagedFields[0].FieldsV1 = fields(`{"f:data":{"f:key":{},"f:other":{}}}`)
+		object.ManagedFields = append(object.ManagedFields, existing.ManagedFields[2])
+
+		drift := configurationDrift.WithLabelValues("ns1", "hippo", "ConfigMap")
+		before := testutil.ToFloat64(drift)
+
+		reconciler.reportDrift(ctx, object, updated)
+		assert.Equal(t, testutil.ToFloat64(drift), before+1)
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Equal(t, <-recorder.Events,
+			`Warning ConfigurationDrift Reverted changes to ConfigMap "hippo-config" made by kubectl-edit`)
+	})
+
+	t.Run("Unowned", func(t *testing.T) {
+		assert.Assert(t, reconciler.updatedFields(ctx, &corev1.Pod{}) == nil)
+	})
+}
+
 func TestServerSideApply(t *testing.T) {
 	ctx := context.Background()
 	env, cc := setupKubernetes(t)
@@ -0,0 +1,152 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// generateCertManagerCertificate returns a cert-manager Certificate that
+// stores a certificate for commonName and dnsNames in a Secret of the same
+// name. The dnsNames may be empty, such as for a client certificate.
+// - https://cert-manager.io/docs/reference/api-docs/#cert-manager.io/v1.Certificate
+func generateCertManagerCertificate(
+	cluster *v1beta1.PostgresCluster, meta metav1.ObjectMeta,
+	commonName string, dnsNames []string, usages ...string,
+) *unstructured.Unstructured {
+	spec := cluster.Spec.CertManager
+
+	uses := make([]interface{}, len(usages))
+	for i := range usages {
+		uses[i] = usages[i]
+	}
+
+	certificate := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"secretName": meta.Name,
+			"commonName": commonName,
+			"usages":     uses,
+			"issuerRef": map[string]interface{}{
+				"name":  spec.IssuerRef.Name,
+				"kind":  spec.IssuerRef.Kind,
+				"group": spec.IssuerRef.Group,
+			},
+			// Match the keys generated by PGO, and replace them at every renewal.
+			"privateKey": map[string]interface{}{
+				"algorithm":      "ECDSA",
+				"size":           int64(256),
+				"rotationPolicy": "Always",
+			},
+		},
+	}}
+	if len(dnsNames) > 0 {
+		names := make([]interface{}, len(dnsNames))
+		for i := range dnsNames {
+			names[i] = dnsNames[i]
+		}
+		certificate.Object["spec"].(map[string]interface{})["dnsNames"] = names
+	}
+
+	certificate.SetAPIVersion("cert-manager.io/v1")
+	certificate.SetKind("Certificate")
+	certificate.SetNamespace(meta.Namespace)
+	certificate.SetName(meta.Name)
+
+	if spec.Duration != nil {
+		_ = unstructured.SetNestedField(certificate.Object,
+			spec.Duration.Duration.String(), "spec", "duration")
+	}
+	if spec.RenewBefore != nil {
+		_ = unstructured.SetNestedField(certificate.Object,
+			spec.RenewBefore.Duration.String(), "spec", "renewBefore")
+	}
+
+	if annotations := cluster.Spec.Metadata.GetAnnotationsOrNil(); len(annotations) > 0 {
+		certificate.SetAnnotations(naming.Merge(annotations))
+	}
+	certificate.SetLabels(naming.Merge(
+		cluster.Spec.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			naming.LabelCluster: cluster.Name,
+		}))
+
+	return certificate
+}
+
+// +kubebuilder:rbac:groups="cert-manager.io",resources="certificates",verbs={create,patch}
+
+// reconcileCertManagerCertificates asks cert-manager to issue and renew the
+// PostgreSQL server and replication client certificates of cluster. When it
+// does, the custom TLS fields of cluster refer to the Secrets that
+// cert-manager populates so that the rest of reconciliation uses them.
+func (r *Reconciler) reconcileCertManagerCertificates(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+) error {
+	if cluster.Spec.CertManager == nil {
+		return nil
+	}
+
+	// The webhook rejects this, but it is optional.
+	if cluster.Spec.CustomTLSSecret != nil || cluster.Spec.CustomReplicationClientTLSSecret != nil {
+		r.Recorder.Event(cluster, corev1.EventTypeWarning, EventInvalidCertManager,
+			"certManager cannot be used with customTLSSecret or customReplicationTLSSecret")
+		return nil
+	}
+
+	primary := &corev1.Service{ObjectMeta: naming.ClusterPrimaryService(cluster)}
+	dnsNames := append(naming.ServiceDNSNames(ctx, primary), externalDNSNames(cluster)...)
+
+	server := generateCertManagerCertificate(cluster,
+		naming.PostgresCertManagerCertificate(cluster), dnsNames[0], dnsNames,
+		"digital signature", "key encipherment", "server auth")
+
+	// The replication user authenticates using the common name of its
+	// certificate. That name is not a DNS name, so the certificate has none.
+	replication := generateCertManagerCertificate(cluster,
+		naming.ReplicationCertManagerCertificate(cluster), postgres.ReplicationUser, nil,
+		"digital signature", "key encipherment", "client auth")
+
+	err := errors.WithStack(r.setControllerReference(cluster, server))
+	if err == nil {
+		err = errors.WithStack(r.setControllerReference(cluster, replication))
+	}
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, server))
+	}
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, replication))
+	}
+
+	if err == nil {
+		cluster.Spec.CustomTLSSecret = &corev1.SecretProjection{
+			LocalObjectReference: corev1.LocalObjectReference{Name: server.GetName()},
+		}
+		cluster.Spec.CustomReplicationClientTLSSecret = &corev1.SecretProjection{
+			LocalObjectReference: corev1.LocalObjectReference{Name: replication.GetName()},
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,86 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestGenerateCertManagerCertificate(t *testing.T) {
+	cluster := testCluster()
+	cluster.Namespace = "ns1"
+	cluster.Spec.CertManager = &v1beta1.CertManagerSpec{
+		IssuerRef: v1beta1.CertManagerIssuerReference{
+			Name: "some-issuer", Kind: "ClusterIssuer", Group: "cert-manager.io",
+		},
+	}
+
+	certificate := generateCertManagerCertificate(cluster,
+		naming.PostgresCertManagerCertificate(cluster),
+		"first", []string{"first", "second"}, "server auth")
+
+	assert.Assert(t, marshalMatches(certificate, `
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  labels:
+    postgres-operator.crunchydata.com/cluster: hippo
+  name: hippo-cluster-cert-manager
+  namespace: ns1
+spec:
+  commonName: first
+  dnsNames:
+  - first
+  - second
+  issuerRef:
+    group: cert-manager.io
+    kind: ClusterIssuer
+    name: some-issuer
+  privateKey:
+    algorithm: ECDSA
+    rotationPolicy: Always
+    size: 256
+  secretName: hippo-cluster-cert-manager
+  usages:
+  - server auth
+	`))
+
+	t.Run("Durations", func(t *testing.T) {
+		cluster.Spec.CertManager.Duration = &metav1.Duration{Duration: 720 * time.Hour}
+		cluster.Spec.CertManager.RenewBefore = &metav1.Duration{Duration: 24 * time.Hour}
+
+		certificate := generateCertManagerCertificate(cluster,
+			naming.ReplicationCertManagerCertificate(cluster),
+			"_crunchyrepl", nil, "client auth")
+
+		spec := certificate.Object["spec"].(map[string]interface{})
+		assert.Equal(t, spec["duration"], "720h0m0s")
+		assert.Equal(t, spec["renewBefore"], "24h0m0s")
+		assert.Equal(t, spec["secretName"], "hippo-replication-cert-manager")
+		assert.Equal(t, spec["commonName"], "_crunchyrepl")
+
+		// A user name is not a DNS name.
+		_, ok := spec["dnsNames"]
+		assert.Assert(t, !ok, "expected no dnsNames, got %v", spec["dnsNames"])
+	})
+}
@@ -208,15 +208,19 @@ func (r *Reconciler) generateClusterReplicaService(
 			naming.LabelRole:    naming.RoleReplica,
 		})
 
-	// Allocate an IP address and let Kubernetes manage the Endpoints by
-	// selecting Pods with the Patroni replica role.
+	// Allocate an IP address and/or node port and let Kubernetes manage the
+	// Endpoints by selecting Pods with the Patroni replica role.
 	// - https://docs.k8s.io/concepts/services-networking/service/#defining-a-service
-	service.Spec.Type = corev1.ServiceTypeClusterIP
 	service.Spec.Selector = map[string]string{
 		naming.LabelCluster: cluster.Name,
 		naming.LabelRole:    naming.RolePatroniReplica,
 	}
 
+	// Leave out replicas that are too far behind. See Reconciler.reconcileReplicaLag.
+	if cluster.Spec.ReplicaServiceMaxLag != nil {
+		service.Spec.Selector[naming.LabelReplicaLagging] = "false"
+	}
+
 	// The TargetPort must be the name (not the number) of the PostgreSQL
 	// ContainerPort. This name allows the port number to differ between Pods,
 	// which can happen during a rolling update.
@@ -226,6 +230,7 @@ func (r *Reconciler) generateClusterReplicaService(
 		Protocol:   corev1.ProtocolTCP,
 		TargetPort: intstr.FromString(naming.PortPostgreSQL),
 	}}
+	setServiceSpec(service, cluster.Spec.ReplicaService)
 
 	err := errors.WithStack(r.setControllerReference(cluster, service))
 
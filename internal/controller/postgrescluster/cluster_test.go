@@ -791,4 +791,37 @@ postgres-operator.crunchydata.com/cluster: pg2
 postgres-operator.crunchydata.com/role: replica
 		`))
 	})
+
+	t.Run("ReplicaService", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.ReplicaService = &v1beta1.ServiceSpec{
+			Metadata: &v1beta1.Metadata{
+				Annotations: map[string]string{"lb": "internal"},
+			},
+			NodePort:                 initialize.Int32(32000),
+			LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+			Type:                     "LoadBalancer",
+		}
+
+		service, err := reconciler.generateClusterReplicaService(cluster)
+		assert.NilError(t, err)
+
+		assert.Assert(t, marshalMatches(service.ObjectMeta.Annotations, `
+lb: internal
+		`))
+		assert.Assert(t, marshalMatches(service.Spec, `
+loadBalancerSourceRanges:
+- 10.0.0.0/8
+ports:
+- name: postgres
+  nodePort: 32000
+  port: 9876
+  protocol: TCP
+  targetPort: postgres
+selector:
+  postgres-operator.crunchydata.com/cluster: pg2
+  postgres-operator.crunchydata.com/role: replica
+type: LoadBalancer
+		`))
+	})
 }
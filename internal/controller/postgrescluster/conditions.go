@@ -0,0 +1,164 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const (
+	// ConditionReady is the type used in a condition to indicate whether or not every instance
+	// of a PostgresCluster is ready and one of them is a writable primary
+	ConditionReady = "Ready"
+
+	// ConditionProgressing is the type used in a condition to indicate whether or not a
+	// PostgresCluster is still moving toward its specification, e.g. creating or updating
+	// instances
+	ConditionProgressing = "Progressing"
+
+	// ConditionDegraded is the type used in a condition to indicate whether or not the last
+	// reconcile of a PostgresCluster failed
+	ConditionDegraded = "Degraded"
+)
+
+// setClusterConditions sets the Ready, Progressing, and Degraded conditions of
+// cluster using the instance set statuses of cluster, the observed instances,
+// and the error, if any, of the current reconcile. These conditions summarize
+// the overall health of cluster for tools like "kubectl wait".
+func setClusterConditions(
+	cluster *v1beta1.PostgresCluster, instances *observedInstances, reconcileErr error,
+) {
+	ready := metav1.Condition{
+		ObservedGeneration: cluster.GetGeneration(),
+		Type:               ConditionReady,
+	}
+	progressing := metav1.Condition{
+		ObservedGeneration: cluster.GetGeneration(),
+		Type:               ConditionProgressing,
+	}
+	degraded := metav1.Condition{
+		ObservedGeneration: cluster.GetGeneration(),
+		Type:               ConditionDegraded,
+		Status:             metav1.ConditionFalse,
+		Reason:             "ReconcileSucceeded",
+		Message:            "The cluster was reconciled",
+	}
+
+	if reconcileErr != nil {
+		degraded.Status = metav1.ConditionTrue
+		degraded.Reason = "ReconcileFailed"
+		degraded.Message = reconcileErr.Error()
+
+		// Report the reason of a rejected Kubernetes API request, e.g. "Forbidden"
+		// or "Invalid", so automation can tell these failures apart.
+		if reason := apierrors.ReasonForError(reconcileErr); reason != metav1.StatusReasonUnknown {
+			degraded.Reason = string(reason)
+		}
+	}
+
+	// Count the desired, ready, and updated replicas of every instance set.
+	var desired, readyReplicas, updated int32
+	for i := range cluster.Spec.InstanceSets {
+		set := &cluster.Spec.InstanceSets[i]
+		if set.Replicas != nil {
+			desired += *set.Replicas
+		} else {
+			desired++
+		}
+		for _, status := range cluster.Status.InstanceSets {
+			if status.Name == set.Name {
+				readyReplicas += status.ReadyReplicas
+				updated += status.UpdatedReplicas
+			}
+		}
+	}
+
+	var primary bool
+	var crashLooping []string
+	if instances != nil {
+		for _, instance := range instances.forCluster {
+			writable, knownWritable := instance.IsWritable()
+			isReady, knownReady := instance.IsReady()
+			if writable && knownWritable && isReady && knownReady {
+				primary = true
+			}
+			if looping, known := instance.IsCrashLooping(); looping && known {
+				crashLooping = append(crashLooping, instance.Name)
+			}
+		}
+	}
+	sort.Strings(crashLooping)
+
+	switch {
+	case cluster.Spec.Shutdown != nil && *cluster.Spec.Shutdown:
+		ready.Status = metav1.ConditionFalse
+		ready.Reason = "Shutdown"
+		ready.Message = "The cluster is shut down"
+	case !primary:
+		ready.Status = metav1.ConditionFalse
+		ready.Reason = "NoPrimary"
+		ready.Message = "No PostgreSQL primary is ready"
+	case len(crashLooping) > 0:
+		// Containers in CrashLoopBackOff do not recover by waiting. Name
+		// their instances so someone can look into them.
+		ready.Status = metav1.ConditionFalse
+		ready.Reason = "InstancesCrashLooping"
+		ready.Message = fmt.Sprintf("%d of %d instances are ready; crash looping: %s",
+			readyReplicas, desired, strings.Join(crashLooping, ", "))
+	case readyReplicas < desired:
+		ready.Status = metav1.ConditionFalse
+		ready.Reason = "InstancesNotReady"
+		ready.Message = fmt.Sprintf("%d of %d instances are ready", readyReplicas, desired)
+	default:
+		ready.Status = metav1.ConditionTrue
+		ready.Reason = "AllInstancesReady"
+		ready.Message = fmt.Sprintf("%d of %d instances are ready", readyReplicas, desired)
+	}
+
+	switch {
+	case cluster.Spec.Shutdown != nil && *cluster.Spec.Shutdown:
+		progressing.Status = metav1.ConditionFalse
+		progressing.Reason = "Shutdown"
+		progressing.Message = "The cluster is shut down"
+	case instances == nil:
+		// Reconcile returned before observing instances, e.g. while moving
+		// directories or restoring a data source.
+		progressing.Status = metav1.ConditionTrue
+		progressing.Reason = "Initializing"
+		progressing.Message = "The cluster is preparing its data"
+	case updated < desired || readyReplicas < desired:
+		progressing.Status = metav1.ConditionTrue
+		progressing.Reason = "InstancesUpdating"
+		progressing.Message = fmt.Sprintf(
+			"%d of %d instances are updated and %d are ready", updated, desired, readyReplicas)
+	default:
+		progressing.Status = metav1.ConditionFalse
+		progressing.Reason = "InstancesUpdated"
+		progressing.Message = fmt.Sprintf("%d of %d instances are updated", updated, desired)
+	}
+
+	meta.SetStatusCondition(&cluster.Status.Conditions, ready)
+	meta.SetStatusCondition(&cluster.Status.Conditions, progressing)
+	meta.SetStatusCondition(&cluster.Status.Conditions, degraded)
+}
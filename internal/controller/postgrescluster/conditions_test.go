@@ -0,0 +1,163 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestSetClusterConditions(t *testing.T) {
+	primary := &Instance{Pods: []*corev1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{"status": `{"role":"master"}`},
+		},
+		Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{
+			Type: corev1.PodReady, Status: corev1.ConditionTrue,
+		}}},
+	}}}
+
+	newCluster := func() *v1beta1.PostgresCluster {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Generation = 3
+		cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{
+			{Name: "00", Replicas: initialize.Int32(2)},
+		}
+		cluster.Status.InstanceSets = []v1beta1.PostgresInstanceSetStatus{
+			{Name: "00", Replicas: 2, ReadyReplicas: 2, UpdatedReplicas: 2},
+		}
+		return cluster
+	}
+
+	t.Run("Ready", func(t *testing.T) {
+		cluster := newCluster()
+		setClusterConditions(cluster,
+			&observedInstances{forCluster: []*Instance{primary}}, nil)
+
+		ready := meta.FindStatusCondition(cluster.Status.Conditions, ConditionReady)
+		assert.Assert(t, ready != nil)
+		assert.Equal(t, ready.Status, metav1.ConditionTrue)
+		assert.Equal(t, ready.ObservedGeneration, int64(3))
+		assert.Assert(t, meta.IsStatusConditionFalse(cluster.Status.Conditions, ConditionProgressing))
+		assert.Assert(t, meta.IsStatusConditionFalse(cluster.Status.Conditions, ConditionDegraded))
+	})
+
+	t.Run("Updating", func(t *testing.T) {
+		cluster := newCluster()
+		cluster.Status.InstanceSets[0].ReadyReplicas = 1
+		cluster.Status.InstanceSets[0].UpdatedReplicas = 1
+		setClusterConditions(cluster,
+			&observedInstances{forCluster: []*Instance{primary}}, nil)
+
+		ready := meta.FindStatusCondition(cluster.Status.Conditions, ConditionReady)
+		assert.Assert(t, ready != nil)
+		assert.Equal(t, ready.Status, metav1.ConditionFalse)
+		assert.Equal(t, ready.Reason, "InstancesNotReady")
+		assert.Equal(t, ready.Message, "1 of 2 instances are ready")
+		assert.Assert(t, meta.IsStatusConditionTrue(cluster.Status.Conditions, ConditionProgressing))
+	})
+
+	t.Run("CrashLooping", func(t *testing.T) {
+		replica := &Instance{Name: "00-abcd", Pods: []*corev1.Pod{{
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "database",
+				State: corev1.ContainerState{
+					Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+				},
+			}}},
+		}}}
+
+		cluster := newCluster()
+		cluster.Status.InstanceSets[0].ReadyReplicas = 1
+		setClusterConditions(cluster,
+			&observedInstances{forCluster: []*Instance{primary, replica}}, nil)
+
+		ready := meta.FindStatusCondition(cluster.Status.Conditions, ConditionReady)
+		assert.Assert(t, ready != nil)
+		assert.Equal(t, ready.Status, metav1.ConditionFalse)
+		assert.Equal(t, ready.Reason, "InstancesCrashLooping")
+		assert.Equal(t, ready.Message, "1 of 2 instances are ready; crash looping: 00-abcd")
+	})
+
+	t.Run("NoPrimary", func(t *testing.T) {
+		cluster := newCluster()
+		setClusterConditions(cluster, &observedInstances{}, nil)
+
+		ready := meta.FindStatusCondition(cluster.Status.Conditions, ConditionReady)
+		assert.Assert(t, ready != nil)
+		assert.Equal(t, ready.Status, metav1.ConditionFalse)
+		assert.Equal(t, ready.Reason, "NoPrimary")
+	})
+
+	t.Run("Initializing", func(t *testing.T) {
+		cluster := newCluster()
+		setClusterConditions(cluster, nil, nil)
+
+		progressing := meta.FindStatusCondition(cluster.Status.Conditions, ConditionProgressing)
+		assert.Assert(t, progressing != nil)
+		assert.Equal(t, progressing.Status, metav1.ConditionTrue)
+		assert.Equal(t, progressing.Reason, "Initializing")
+	})
+
+	t.Run("Degraded", func(t *testing.T) {
+		cluster := newCluster()
+		setClusterConditions(cluster,
+			&observedInstances{forCluster: []*Instance{primary}}, errors.New("boom"))
+
+		degraded := meta.FindStatusCondition(cluster.Status.Conditions, ConditionDegraded)
+		assert.Assert(t, degraded != nil)
+		assert.Equal(t, degraded.Status, metav1.ConditionTrue)
+		assert.Equal(t, degraded.Reason, "ReconcileFailed")
+		assert.Equal(t, degraded.Message, "boom")
+	})
+
+	t.Run("DegradedByAPI", func(t *testing.T) {
+		cluster := newCluster()
+		setClusterConditions(cluster,
+			&observedInstances{forCluster: []*Instance{primary}},
+			fmt.Errorf("wrapped: %w", apierrors.NewForbidden(
+				schema.GroupResource{Resource: "services"}, "hippo-primary", errors.New("nope"))))
+
+		degraded := meta.FindStatusCondition(cluster.Status.Conditions, ConditionDegraded)
+		assert.Assert(t, degraded != nil)
+		assert.Equal(t, degraded.Status, metav1.ConditionTrue)
+		assert.Equal(t, degraded.Reason, "Forbidden")
+		assert.Assert(t, strings.Contains(degraded.Message, "nope"))
+	})
+
+	t.Run("Shutdown", func(t *testing.T) {
+		cluster := newCluster()
+		cluster.Spec.Shutdown = initialize.Bool(true)
+		setClusterConditions(cluster, nil, nil)
+
+		ready := meta.FindStatusCondition(cluster.Status.Conditions, ConditionReady)
+		assert.Assert(t, ready != nil)
+		assert.Equal(t, ready.Reason, "Shutdown")
+		assert.Assert(t, meta.IsStatusConditionFalse(cluster.Status.Conditions, ConditionProgressing))
+	})
+}
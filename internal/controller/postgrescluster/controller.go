@@ -21,6 +21,7 @@ import (
 	"io"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/pkg/errors"
 	"go.opentelemetry.io/otel/trace"
@@ -32,6 +33,7 @@ import (
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -41,6 +43,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
+	"github.com/crunchydata/postgres-operator/internal/config"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/logging"
 	"github.com/crunchydata/postgres-operator/internal/pgaudit"
 	"github.com/crunchydata/postgres-operator/internal/pgbackrest"
@@ -48,6 +52,8 @@ import (
 	"github.com/crunchydata/postgres-operator/internal/pgmonitor"
 	"github.com/crunchydata/postgres-operator/internal/pki"
 	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/internal/timescaledb"
+	"github.com/crunchydata/postgres-operator/internal/vault"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
@@ -64,6 +70,24 @@ type Reconciler struct {
 	Tracer      trace.Tracer
 	IsOpenShift bool
 
+	// CronJobTimeZones is true when the Kubernetes API keeps the time zone of
+	// CronJobs. When false, backups with a time zone are not scheduled.
+	CronJobTimeZones bool
+
+	// NamespaceSelector limits reconciliation to PostgresClusters in Namespaces
+	// with matching labels. When nil, PostgresClusters in every Namespace are
+	// reconciled.
+	NamespaceSelector labels.Selector
+
+	// MaxConcurrentBackups limits the number of manual backup Jobs that run at
+	// the same time across all PostgresClusters. When zero, there is no limit.
+	MaxConcurrentBackups int
+	manualBackups        manualBackupJobs
+
+	// Vault reads the passwords of PostgreSQL users from HashiCorp Vault. When
+	// nil, users cannot have passwords in Vault.
+	Vault *vault.Session
+
 	PodExec func(
 		namespace, pod, container string,
 		stdin io.Reader, stdout, stderr io.Writer, command ...string,
@@ -141,6 +165,64 @@ func (r *Reconciler) Reconcile(
 		return *result, nil
 	}
 
+	// Leave cluster alone when its namespace is not selected. Deletion is
+	// handled above so that finalizers are always removed.
+	if selected, err := r.namespaceSelected(ctx, cluster.Namespace); err != nil || !selected {
+		if err != nil {
+			span.RecordError(err)
+			log.Error(err, "unable to fetch Namespace")
+		} else {
+			log.V(1).Info("namespace not selected")
+		}
+		return result, err
+	}
+
+	// Stop the cluster outside of its uptime schedule as though it were shutdown,
+	// and reconcile again when the schedule says otherwise.
+	if outside, next, err := outsideUptimeSchedule(cluster, time.Now()); err != nil {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventInvalidUptimeSchedule,
+			"Unable to evaluate spec.uptimeSchedule: %v", err)
+	} else {
+		if outside {
+			cluster.Spec.Shutdown = initialize.Bool(true)
+		}
+		result.RequeueAfter = next
+	}
+
+	// Hold disruptive changes outside of the maintenance window. When changes
+	// wait, reconcile again when the window opens.
+	window, windowErr := newMaintenance(cluster, time.Now())
+	if windowErr != nil {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventInvalidMaintenanceWindow,
+			"Unable to evaluate spec.maintenanceWindow: %v", windowErr)
+	}
+
+	// No image has both PostGIS and TimescaleDB installed. Wait for the spec
+	// to change rather than pick one.
+	if cluster.Spec.PostGISVersion != "" && cluster.Spec.TimescaleDBVersion != "" {
+		r.Recorder.Event(cluster, corev1.EventTypeWarning, EventInvalidImage,
+			"Unable to run PostGIS and TimescaleDB in the same cluster; unset spec.postGISVersion or spec.timescaleDBVersion")
+		return result, nil
+	}
+
+	// A TimescaleDB cluster needs an image with TimescaleDB installed. Without
+	// one, there is nothing to run, so wait for the spec or environment to change.
+	if cluster.Spec.TimescaleDBVersion != "" && config.PostgresContainerImage(cluster) == "" {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventInvalidImage,
+			"No PostgreSQL image for TimescaleDB %s; set spec.image or RELATED_IMAGE_POSTGRES_%d_TIMESCALEDB_%[1]s",
+			cluster.Spec.TimescaleDBVersion, cluster.Spec.PostgresVersion)
+		return result, nil
+	}
+
+	// Shipping pgAudit logs needs an image that can run Fluent Bit. Without one,
+	// instance Pods cannot start, so wait for the spec or environment to change.
+	if cluster.Spec.Audit != nil && cluster.Spec.Audit.LogShipping != nil &&
+		config.FluentBitContainerImage(cluster) == "" {
+		r.Recorder.Event(cluster, corev1.EventTypeWarning, EventInvalidImage,
+			"No Fluent Bit image for pgAudit log shipping; set spec.audit.logShipping.image or RELATED_IMAGE_FLUENTBIT")
+		return result, nil
+	}
+
 	var (
 		clusterConfigMap         *corev1.ConfigMap
 		clusterReplicationSecret *corev1.Secret
@@ -160,6 +242,9 @@ func (r *Reconciler) Reconcile(
 	// occurs while attempting to patch the status, while otherwise simply returning the
 	// Result and error variables that are populated while reconciling the PostgresCluster.
 	patchClusterStatus := func() (reconcile.Result, error) {
+		setClusterConditions(cluster, instances, err)
+		recordClusterMetrics(cluster)
+
 		if !equality.Semantic.DeepEqual(before.Status, cluster.Status) {
 			// NOTE(cbandy): Kubernetes prior to v1.16.10 and v1.17.6 does not track
 			// managed fields on the status subresource: https://issue.k8s.io/88901
@@ -176,11 +261,33 @@ func (r *Reconciler) Reconcile(
 	pgHBAs := postgres.NewHBAs()
 	pgmonitor.PostgreSQLHBAs(cluster, &pgHBAs)
 	pgbouncer.PostgreSQL(cluster, &pgHBAs)
+	postgres.SpecHBAs(cluster, &pgHBAs)
 
 	pgParameters := postgres.NewParameters()
-	pgaudit.PostgreSQLParameters(&pgParameters)
+	postgres.TuningParameters(cluster, &pgParameters)
+	postgres.HugePagesParameters(cluster, &pgParameters)
+	postgres.AuthenticationParameters(cluster, &pgParameters)
+	pgaudit.PostgreSQLParameters(cluster, &pgParameters)
 	pgbackrest.PostgreSQL(cluster, &pgParameters)
 	pgmonitor.PostgreSQLParameters(cluster, &pgParameters)
+	if cluster.Spec.TimescaleDBVersion != "" {
+		timescaledb.PostgreSQLParameters(&pgParameters)
+	}
+	postgres.ExtensionParameters(cluster.Spec.Extensions, &pgParameters)
+
+	// Put temporary files on the temp volume. See
+	// [Reconciler.reconcilePostgresTempTablespace].
+	if cluster.Spec.TempVolume != nil {
+		pgParameters.Default.Add("temp_tablespaces", postgres.TempTablespace)
+	}
+
+	postgres.SpecParameters(cluster, &pgParameters)
+
+	// Refuse writes while a volume of the primary is nearly full. See
+	// [Reconciler.reconcileDiskUsage].
+	if diskUsageReadOnly(cluster) {
+		pgParameters.Mandatory.Add("default_transaction_read_only", "on")
+	}
 
 	if err == nil {
 		rootCA, err = r.reconcileRootCertificate(ctx, cluster)
@@ -208,6 +315,9 @@ func (r *Reconciler) Reconcile(
 	if err == nil {
 		instances, err = r.observeInstances(ctx, cluster)
 	}
+	if err == nil {
+		r.reportUnavailableHugePages(cluster, instances)
+	}
 	if err == nil {
 		err = updateResult(r.reconcilePatroniStatus(ctx, cluster, instances))
 	}
@@ -247,6 +357,9 @@ func (r *Reconciler) Reconcile(
 	if err == nil {
 		clusterConfigMap, err = r.reconcileClusterConfigMap(ctx, cluster, pgHBAs, pgParameters)
 	}
+	if err == nil {
+		err = r.reconcileCertManagerCertificates(ctx, cluster)
+	}
 	if err == nil {
 		clusterReplicationSecret, err = r.reconcileReplicationSecret(ctx, cluster, rootCA)
 	}
@@ -256,12 +369,18 @@ func (r *Reconciler) Reconcile(
 	if err == nil {
 		primaryService, err = r.reconcileClusterPrimaryService(ctx, cluster, patroniLeaderService)
 	}
+	if err == nil {
+		err = updateResult(r.reconcileReplicaLag(ctx, cluster, instances))
+	}
 	if err == nil {
 		err = r.reconcileClusterReplicaService(ctx, cluster)
 	}
 	if err == nil {
 		primaryCertificate, err = r.reconcileClusterCertificate(ctx, rootCA, cluster, primaryService)
 	}
+	if err == nil {
+		err = r.reportExpiringCertificates(ctx, cluster, time.Now())
+	}
 	if err == nil {
 		err = r.reconcilePatroniDistributedConfiguration(ctx, cluster)
 	}
@@ -275,14 +394,30 @@ func (r *Reconciler) Reconcile(
 		err = r.reconcileInstanceSets(
 			ctx, cluster, clusterConfigMap, clusterReplicationSecret,
 			rootCA, clusterPodService, instanceServiceAccount, instances,
-			patroniLeaderService, primaryCertificate, clusterVolumes)
+			patroniLeaderService, primaryCertificate, clusterVolumes, window)
 	}
-
 	if err == nil {
+		err = updateResult(r.reconcileDiskUsage(ctx, cluster, instances, clusterVolumes))
+	}
+
+	// PostgreSQL refuses writes while a volume of the primary is nearly full.
+	// Leave the objects inside it alone until then so that backups continue.
+	writable := !diskUsageReadOnly(cluster)
+
+	if err == nil && writable {
 		err = r.reconcilePostgresDatabases(ctx, cluster, instances)
 	}
 	if err == nil {
-		err = r.reconcilePostgresUsers(ctx, cluster, instances)
+		err = updateResult(r.reconcilePostgresUsers(ctx, cluster, instances))
+	}
+	if err == nil && writable {
+		err = r.reconcilePostgresLogicalReplication(ctx, cluster, instances)
+	}
+	if err == nil && writable {
+		err = r.reconcilePostgresTempTablespace(ctx, cluster, instances)
+	}
+	if err == nil {
+		err = r.reconcileQueryStatisticsReset(ctx, cluster, instances)
 	}
 
 	if err == nil {
@@ -295,15 +430,32 @@ func (r *Reconciler) Reconcile(
 		err = r.reconcilePGMonitor(ctx, cluster, instances, monitoringSecret)
 	}
 	if err == nil {
+		err = r.reconcileExporterServiceMonitor(ctx, cluster)
+	}
+	if err == nil && writable {
 		err = r.reconcileDatabaseInitSQL(ctx, cluster, instances)
 	}
+	if err == nil {
+		err = r.reconcileSQLPolicies(ctx, cluster, instances, window)
+	}
 	if err == nil {
 		err = r.reconcilePGAdmin(ctx, cluster)
 	}
+	if err == nil {
+		err = r.reconcilePGBench(ctx, cluster)
+	}
 	if err == nil {
 		// This is after [Reconciler.rolloutInstances] to ensure that recreating
 		// Pods takes precedence.
-		err = r.handlePatroniRestarts(ctx, cluster, instances)
+		err = r.handlePatroniRestarts(ctx, cluster, instances, window)
+	}
+	if err == nil {
+		err = updateResult(r.reconcileReplicaRemediation(ctx, cluster, instances))
+	}
+	if err == nil {
+		setMaintenanceCondition(cluster, window)
+		result = updateReconcileResult(result,
+			reconcile.Result{RequeueAfter: window.requeueAfter()})
 	}
 
 	// at this point everything reconciled successfully, and we can update the
@@ -398,14 +550,21 @@ func (r *Reconciler) SetupWithManager(mgr manager.Manager) error {
 		if i, err := strconv.Atoi(s); err == nil && i > 0 {
 			opts.MaxConcurrentReconciles = i
 		} else {
-			mgr.GetLogger().Error(err, "PGO_WORKERS must be a positive number")
+			mgr.GetLogger().Error(err, "PGO_WORKERS must be a positive number", "value", s)
 		}
 	}
 	if opts.MaxConcurrentReconciles == 0 {
 		opts.MaxConcurrentReconciles = 2
 	}
 
-	return builder.ControllerManagedBy(mgr).
+	b := builder.ControllerManagedBy(mgr)
+
+	// Reconcile PostgresClusters when their Namespace starts or stops matching.
+	if r.NamespaceSelector != nil {
+		b = b.Watches(&source.Kind{Type: &corev1.Namespace{}}, r.watchNamespaces())
+	}
+
+	return b.
 		For(&v1beta1.PostgresCluster{}).
 		WithOptions(opts).
 		Owns(&corev1.ConfigMap{}).
@@ -422,6 +581,8 @@ func (r *Reconciler) SetupWithManager(mgr manager.Manager) error {
 		Owns(&batchv1beta1.CronJob{}).
 		Owns(&policyv1beta1.PodDisruptionBudget{}).
 		Watches(&source.Kind{Type: &corev1.Pod{}}, r.watchPods()).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, r.watchSQLPolicies()).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, r.watchPasswordSecrets()).
 		Watches(&source.Kind{Type: &appsv1.StatefulSet{}},
 			r.controllerRefHandlerFuncs()). // watch all StatefulSets
 		Complete(r)
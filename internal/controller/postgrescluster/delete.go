@@ -17,8 +17,10 @@ package postgrescluster
 
 import (
 	"context"
+	"time"
 
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -82,8 +84,22 @@ func (r *Reconciler) handleDelete(
 		return &reconcile.Result{}, nil
 	}
 
-	// The cluster is being deleted and our finalizer is still set; run our
-	// finalizer logic.
+	// The cluster is being deleted and our finalizer is still set. Leave
+	// everything in place while the cluster is protected from deletion. The
+	// validating webhook refuses such deletes, but it might not be installed.
+	if cluster.Spec.DeletionProtection != nil && *cluster.Spec.DeletionProtection {
+		r.Recorder.Event(cluster, corev1.EventTypeWarning, EventDeletionProtected,
+			"Set spec.deletionProtection to false to finish deleting this cluster")
+		return &reconcile.Result{}, nil
+	}
+
+	// Run our finalizer logic. Take the final backup, if any, while instances
+	// are still running.
+	if result, err := r.reconcileFinalBackup(ctx, cluster); err != nil {
+		return nil, err
+	} else if result != nil {
+		return result, nil
+	}
 
 	if result, err := r.deleteInstances(ctx, cluster); err != nil {
 		return nil, err
@@ -96,6 +112,17 @@ func (r *Reconciler) handleDelete(
 		return nil, err
 	}
 
+	// Volumes are owned by cluster, so hold our finalizer until the end of the
+	// retention period to keep them.
+	if days := cluster.Spec.DeletionRetentionDays; days != nil {
+		expires := cluster.DeletionTimestamp.Add(time.Duration(*days) * 24 * time.Hour)
+		if remaining := time.Until(expires); remaining > 0 {
+			r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventRetainingVolumes,
+				"Volumes are kept until %s", expires.UTC().Format(time.RFC3339))
+			return &reconcile.Result{RequeueAfter: remaining}, nil
+		}
+	}
+
 	// Our finalizer logic is finished; remove our finalizer.
 	// The Finalizers field is shared by multiple controllers, but the
 	// server-side merge strategy does not work on our custom resource due to a
@@ -109,6 +136,9 @@ func (r *Reconciler) handleDelete(
 	intent.Finalizers = finalizers.Delete(naming.Finalizer).List()
 	err := errors.WithStack(r.patch(ctx, intent,
 		client.MergeFromWithOptions(before, client.MergeFromWithOptimisticLock{})))
+	if err == nil {
+		forgetClusterMetrics(cluster)
+	}
 
 	// The caller should wait for further events or requeue upon error.
 	return &reconcile.Result{}, err
@@ -0,0 +1,104 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestHandleDeleteProtectionAndRetention(t *testing.T) {
+	ctx := context.Background()
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	deleting := func(deleted time.Time) *v1beta1.PostgresCluster {
+		cluster := &v1beta1.PostgresCluster{}
+		cluster.Namespace, cluster.Name = "ns1", "hippo"
+		cluster.Finalizers = []string{naming.Finalizer}
+		cluster.DeletionTimestamp = &metav1.Time{Time: deleted}
+		return cluster
+	}
+
+	reconcilerFor := func(cluster *v1beta1.PostgresCluster) (*Reconciler, *record.FakeRecorder) {
+		recorder := record.NewFakeRecorder(10)
+		return &Reconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(cluster).Build(),
+			Recorder: recorder,
+		}, recorder
+	}
+
+	finalizers := func(t *testing.T, r *Reconciler, cluster *v1beta1.PostgresCluster) []string {
+		t.Helper()
+		stored := &v1beta1.PostgresCluster{}
+		assert.NilError(t, r.Client.Get(ctx, client.ObjectKeyFromObject(cluster), stored))
+		return stored.Finalizers
+	}
+
+	t.Run("Protected", func(t *testing.T) {
+		cluster := deleting(time.Now())
+		cluster.Spec.DeletionProtection = initialize.Bool(true)
+		r, recorder := reconcilerFor(cluster)
+
+		result, err := r.handleDelete(ctx, cluster)
+		assert.NilError(t, err)
+		assert.Assert(t, result != nil)
+		assert.DeepEqual(t, finalizers(t, r, cluster), []string{naming.Finalizer})
+		assert.Assert(t, len(recorder.Events) == 1)
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "DeletionProtected"))
+	})
+
+	t.Run("Retained", func(t *testing.T) {
+		cluster := deleting(time.Now().Add(-time.Hour))
+		cluster.Spec.DeletionRetentionDays = initialize.Int32(1)
+		r, recorder := reconcilerFor(cluster)
+
+		result, err := r.handleDelete(ctx, cluster)
+		assert.NilError(t, err)
+		assert.Assert(t, result != nil)
+		assert.Assert(t, result.RequeueAfter > 22*time.Hour)
+		assert.Assert(t, result.RequeueAfter <= 23*time.Hour)
+		assert.DeepEqual(t, finalizers(t, r, cluster), []string{naming.Finalizer})
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "RetainingVolumes"))
+	})
+
+	t.Run("RetentionExpired", func(t *testing.T) {
+		cluster := deleting(time.Now().Add(-25 * time.Hour))
+		cluster.Spec.DeletionRetentionDays = initialize.Int32(1)
+		r, recorder := reconcilerFor(cluster)
+
+		result, err := r.handleDelete(ctx, cluster)
+		assert.NilError(t, err)
+		assert.Assert(t, result != nil)
+		assert.Equal(t, result.RequeueAfter, time.Duration(0))
+		assert.Assert(t, len(finalizers(t, r, cluster)) == 0)
+		assert.Assert(t, len(recorder.Events) == 0)
+	})
+}
@@ -0,0 +1,322 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crunchydata/postgres-operator/internal/kubeapi"
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const (
+	// ConditionDiskReadOnly is the type used in a condition to indicate whether or not PGO
+	// made PostgreSQL read-only because a volume of the primary is nearly full
+	ConditionDiskReadOnly = "DiskReadOnly"
+
+	// diskUsageInterval is how often PGO checks volumes when spec.diskUsage is set.
+	diskUsageInterval = 5 * time.Minute
+)
+
+// diskUsage is the size and used space, in bytes, of a file system.
+type diskUsage struct{ size, used int64 }
+
+// percent returns the percentage of u in use, rounded up.
+func (u diskUsage) percent() int32 {
+	if u.size <= 0 {
+		return 0
+	}
+	return int32(math.Ceil(100 * float64(u.used) / float64(u.size)))
+}
+
+// parseDiskUsage reads the output of `df --block-size=1 --output=size,used`.
+func parseDiskUsage(output string) (diskUsage, error) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(lines) < 2 || len(fields) != 2 {
+		return diskUsage{}, errors.Errorf("unexpected output from df: %q", output)
+	}
+
+	size, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return diskUsage{}, errors.WithStack(err)
+	}
+	used, err := strconv.ParseInt(fields[1], 10, 64)
+	return diskUsage{size: size, used: used}, errors.WithStack(err)
+}
+
+// diskUsageReadOnly returns whether or not PostgreSQL in cluster should refuse
+// writes because a volume of its primary is nearly full.
+func diskUsageReadOnly(cluster *v1beta1.PostgresCluster) bool {
+	return cluster.Spec.DiskUsage != nil &&
+		cluster.Spec.DiskUsage.ReadOnlyPercent != nil &&
+		meta.IsStatusConditionTrue(cluster.Status.Conditions, ConditionDiskReadOnly)
+}
+
+// diskVolume is a PostgreSQL volume that PGO measures, identified by the role
+// label on its PersistentVolumeClaim.
+type diskVolume struct {
+	description, mountPath, role string
+}
+
+// instanceVolumes returns the volumes of instance that PGO measures. The WAL
+// volume is included only once it is mounted in the instance Pod.
+func instanceVolumes(instance *Instance) []diskVolume {
+	volumes := []diskVolume{{
+		description: "data",
+		mountPath:   postgres.DataMountPath(),
+		role:        naming.RolePostgresData,
+	}}
+
+	wal := postgres.WALVolumeMount()
+	for _, volume := range instance.Pods[0].Spec.Volumes {
+		if volume.Name == wal.Name {
+			volumes = append(volumes, diskVolume{
+				description: "WAL",
+				mountPath:   wal.MountPath,
+				role:        naming.RolePostgresWAL,
+			})
+		}
+	}
+	return volumes
+}
+
+// keepExpandedVolumeSize raises the storage request of spec to that of the
+// existing PersistentVolumeClaim named existing when PGO grew it. See
+// [Reconciler.expandVolume].
+func keepExpandedVolumeSize(
+	cluster *v1beta1.PostgresCluster, spec *corev1.PersistentVolumeClaimSpec,
+	existing string, clusterVolumes []corev1.PersistentVolumeClaim,
+) {
+	if cluster.Spec.DiskUsage == nil || cluster.Spec.DiskUsage.Expansion == nil {
+		return
+	}
+	for i := range clusterVolumes {
+		if clusterVolumes[i].Name != existing {
+			continue
+		}
+		grown := clusterVolumes[i].Spec.Resources.Requests[corev1.ResourceStorage]
+		if grown.Cmp(spec.Resources.Requests[corev1.ResourceStorage]) > 0 {
+			if spec.Resources.Requests == nil {
+				spec.Resources.Requests = corev1.ResourceList{}
+			}
+			spec.Resources.Requests[corev1.ResourceStorage] = grown
+		}
+	}
+}
+
+// +kubebuilder:rbac:groups="",resources="pods/exec",verbs={create}
+// +kubebuilder:rbac:groups="",resources="persistentvolumeclaims",verbs={patch}
+
+// reconcileDiskUsage measures the data and WAL volumes of every running
+// instance of cluster. Past spec.diskUsage.warningPercent, it records an event
+// and grows the volume when expansion is enabled. Past readOnlyPercent on the
+// primary, it sets the DiskReadOnly condition so that PostgreSQL refuses writes.
+func (r *Reconciler) reconcileDiskUsage(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+	instances *observedInstances, clusterVolumes []corev1.PersistentVolumeClaim,
+) (reconcile.Result, error) {
+	const container = naming.ContainerDatabase
+	log := logging.FromContext(ctx)
+	result := reconcile.Result{}
+
+	spec := cluster.Spec.DiskUsage
+	if spec == nil {
+		// TODO: remove guard with move to controller-runtime 0.9.0 https://issue.k8s.io/99714
+		if len(cluster.Status.Conditions) > 0 {
+			meta.RemoveStatusCondition(&cluster.Status.Conditions, ConditionDiskReadOnly)
+		}
+		return result, nil
+	}
+
+	warning := int32(80)
+	if spec.WarningPercent != nil {
+		warning = *spec.WarningPercent
+	}
+
+	var primaryMeasured bool
+	var primaryPercent int32
+	var primaryVolume string
+	for _, instance := range instances.forCluster {
+		// Skip instances that have no set in cluster spec. They are being
+		// removed and have no status to report.
+		if instance.Spec == nil {
+			continue
+		}
+		if running, known := instance.IsRunning(container); !running || !known {
+			continue
+		}
+		primary, _ := instance.IsPrimary()
+
+		pod := instance.Pods[0]
+		for _, volume := range instanceVolumes(instance) {
+			var stdout, stderr bytes.Buffer
+			err := r.PodExec(pod.Namespace, pod.Name, container, nil, &stdout, &stderr,
+				"df", "--block-size=1", "--output=size,used", volume.mountPath)
+
+			var usage diskUsage
+			if err == nil {
+				usage, err = parseDiskUsage(stdout.String())
+			}
+			if err != nil {
+				log.Error(err, "unable to measure volume", "volume", volume.description,
+					"instance", instance.Name, "stderr", stderr.String())
+				continue
+			}
+
+			percent := usage.percent()
+			for i := range cluster.Status.InstanceSets {
+				status := &cluster.Status.InstanceSets[i]
+				if status.Name != instance.Spec.Name {
+					continue
+				}
+				field := &status.DataVolumeUsedPercent
+				if volume.role == naming.RolePostgresWAL {
+					field = &status.WALVolumeUsedPercent
+				}
+				if *field == nil || **field < percent {
+					value := percent
+					*field = &value
+				}
+			}
+			if primary && (!primaryMeasured || primaryPercent < percent) {
+				primaryMeasured, primaryPercent, primaryVolume = true, percent, volume.description
+			}
+
+			if percent < warning {
+				continue
+			}
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventDiskUsageHigh,
+				"The %s volume of instance %q is %d%% full", volume.description, instance.Name, percent)
+
+			if spec.Expansion != nil {
+				if err := r.expandVolume(ctx, cluster, instance, volume, clusterVolumes,
+					spec.Expansion.Limit); err != nil {
+					return result, err
+				}
+			}
+		}
+	}
+
+	// Decide about read-only mode only when the primary could be measured.
+	if spec.ReadOnlyPercent == nil {
+		// TODO: remove guard with move to controller-runtime 0.9.0 https://issue.k8s.io/99714
+		if len(cluster.Status.Conditions) > 0 {
+			meta.RemoveStatusCondition(&cluster.Status.Conditions, ConditionDiskReadOnly)
+		}
+	} else if primaryMeasured {
+		condition := metav1.Condition{
+			ObservedGeneration: cluster.GetGeneration(),
+			Type:               ConditionDiskReadOnly,
+			Status:             metav1.ConditionFalse,
+			Reason:             "VolumeAvailable",
+			Message: fmt.Sprintf("The fullest volume of the primary, its %s volume, is %d%% full",
+				primaryVolume, primaryPercent),
+		}
+		if primaryPercent >= *spec.ReadOnlyPercent {
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = "VolumeFull"
+
+			if !meta.IsStatusConditionTrue(cluster.Status.Conditions, ConditionDiskReadOnly) {
+				r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventReadOnly,
+					"Making PostgreSQL read-only because the %s volume of the primary is %d%% full",
+					primaryVolume, primaryPercent)
+			}
+		}
+		meta.SetStatusCondition(&cluster.Status.Conditions, condition)
+	}
+
+	result.RequeueAfter = diskUsageInterval
+	return result, nil
+}
+
+// expandVolume grows volume of instance by half of its current size, up to
+// limit.
+func (r *Reconciler) expandVolume(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instance *Instance,
+	volume diskVolume, clusterVolumes []corev1.PersistentVolumeClaim, limit resource.Quantity,
+) error {
+	var pvc *corev1.PersistentVolumeClaim
+	for i := range clusterVolumes {
+		labels := clusterVolumes[i].Labels
+		if labels[naming.LabelInstance] == instance.Name &&
+			labels[naming.LabelRole] == volume.role {
+			pvc = &clusterVolumes[i]
+		}
+	}
+	if pvc == nil {
+		return nil
+	}
+	current := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok && capacity.Cmp(current) > 0 {
+		current = capacity
+	}
+
+	// Wait for an earlier expansion to finish.
+	for _, condition := range pvc.Status.Conditions {
+		if condition.Type == corev1.PersistentVolumeClaimResizing ||
+			condition.Type == corev1.PersistentVolumeClaimFileSystemResizePending {
+			return nil
+		}
+	}
+	if requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; requested.Cmp(current) > 0 {
+		return nil
+	}
+
+	if current.Cmp(limit) >= 0 {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventVolumeLimitReached,
+			"Volume %q is already %s, the limit of spec.diskUsage.expansion",
+			pvc.Name, current.String())
+		return nil
+	}
+
+	// Add half, rounded up to the next mebibyte, and stay within the limit.
+	const mebibyte = 1 << 20
+	value := current.Value() + current.Value()/2
+	value = (value + mebibyte - 1) / mebibyte * mebibyte
+	size := resource.NewQuantity(value, resource.BinarySI)
+	if size.Cmp(limit) > 0 {
+		size = &limit
+	}
+
+	patch, err := kubeapi.NewMergePatch().
+		Add("spec", "resources", "requests", "storage")(size.String()).Bytes()
+	if err == nil {
+		err = r.patch(ctx, pvc, client.RawPatch(client.Merge.Type(), patch))
+	}
+	if err == nil {
+		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventVolumeExpanding,
+			"Growing volume %q from %s to %s", pvc.Name, current.String(), size.String())
+	}
+	return r.handlePersistentVolumeClaimError(cluster, errors.WithStack(err))
+}
@@ -0,0 +1,247 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestParseDiskUsage(t *testing.T) {
+	usage, err := parseDiskUsage("     1B-blocks       Used\n 1073741824  536870912\n")
+	assert.NilError(t, err)
+	assert.Equal(t, usage, diskUsage{size: 1073741824, used: 536870912})
+	assert.Equal(t, usage.percent(), int32(50))
+
+	// Percentages round up so that thresholds are reached early.
+	assert.Equal(t, diskUsage{size: 1000, used: 801}.percent(), int32(81))
+	assert.Equal(t, diskUsage{}.percent(), int32(0))
+
+	for _, output := range []string{"", "1B-blocks Used", "size used\nmany 12\n"} {
+		_, err := parseDiskUsage(output)
+		assert.Assert(t, err != nil, "expected error for %q", output)
+	}
+}
+
+func TestReconcileDiskUsage(t *testing.T) {
+	ctx := context.Background()
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace, cluster.Name = "ns1", "hippo"
+	cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{{Name: "00"}}
+	cluster.Spec.DiskUsage = &v1beta1.DiskUsageSpec{
+		WarningPercent:  initialize.Int32(80),
+		ReadOnlyPercent: initialize.Int32(95),
+		Expansion:       &v1beta1.DiskExpansionSpec{Limit: resource.MustParse("2Gi")},
+	}
+
+	pod := &corev1.Pod{}
+	pod.Namespace, pod.Name = "ns1", "hippo-00-abcd-0"
+	pod.Labels = map[string]string{naming.LabelRole: naming.RolePatroniLeader}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  naming.ContainerDatabase,
+		State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+	}}
+	observed := &observedInstances{forCluster: []*Instance{{
+		Name: "hippo-00-abcd", Spec: &cluster.Spec.InstanceSets[0],
+		Pods: []*corev1.Pod{pod},
+	}}}
+
+	pvc := corev1.PersistentVolumeClaim{}
+	pvc.Namespace, pvc.Name = "ns1", "hippo-00-abcd-pgdata"
+	pvc.Labels = map[string]string{
+		naming.LabelInstance: "hippo-00-abcd",
+		naming.LabelRole:     naming.RolePostgresData,
+	}
+	pvc.Spec.Resources.Requests = corev1.ResourceList{
+		corev1.ResourceStorage: resource.MustParse("1Gi"),
+	}
+
+	var output, walOutput string
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(pvc.DeepCopy()).Build(),
+		Recorder: recorder,
+		PodExec: func(
+			namespace, pod, container string,
+			stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Equal(t, container, naming.ContainerDatabase)
+			assert.Equal(t, strings.Join(command[:3], " "),
+				"df --block-size=1 --output=size,used")
+
+			switch command[3] {
+			case "/pgdata":
+				_, err := stdout.Write([]byte(output))
+				return err
+			case "/pgwal":
+				_, err := stdout.Write([]byte(walOutput))
+				return err
+			}
+			t.Fatalf("unexpected path: %q", command[3])
+			return nil
+		},
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.DiskUsage = nil
+
+		result, err := reconciler.reconcileDiskUsage(ctx, cluster, observed, nil)
+		assert.NilError(t, err)
+		assert.Equal(t, result, reconcile.Result{})
+	})
+
+	t.Run("Warning", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Status.InstanceSets = []v1beta1.PostgresInstanceSetStatus{{Name: "00"}}
+		output = "1B-blocks Used\n1073741824 966367641\n"
+
+		result, err := reconciler.reconcileDiskUsage(ctx, cluster, observed,
+			[]corev1.PersistentVolumeClaim{pvc})
+		assert.NilError(t, err)
+		assert.Equal(t, result.RequeueAfter, diskUsageInterval)
+		assert.DeepEqual(t, cluster.Status.InstanceSets[0].DataVolumeUsedPercent,
+			initialize.Int32(90))
+
+		assert.Equal(t, len(recorder.Events), 2)
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "DiskUsageHigh"))
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "from 1Gi to 1536Mi"))
+
+		var grown corev1.PersistentVolumeClaim
+		assert.NilError(t, reconciler.Client.Get(ctx, client.ObjectKeyFromObject(&pvc), &grown))
+		assert.Equal(t, grown.Spec.Resources.Requests.Storage().String(), "1536Mi")
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, ConditionDiskReadOnly)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Reason, "VolumeAvailable")
+		assert.Assert(t, !diskUsageReadOnly(cluster))
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		output = "1B-blocks Used\n2147483648 1932735283\n"
+
+		full := pvc.DeepCopy()
+		full.Spec.Resources.Requests[corev1.ResourceStorage] = resource.MustParse("2Gi")
+
+		_, err := reconciler.reconcileDiskUsage(ctx, cluster, observed,
+			[]corev1.PersistentVolumeClaim{*full})
+		assert.NilError(t, err)
+
+		assert.Equal(t, len(recorder.Events), 2)
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "DiskUsageHigh"))
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "VolumeLimitReached"))
+	})
+
+	t.Run("ReadOnly", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.DiskUsage.Expansion = nil
+		output = "1B-blocks Used\n1073741824 1063004406\n"
+
+		_, err := reconciler.reconcileDiskUsage(ctx, cluster, observed, nil)
+		assert.NilError(t, err)
+		assert.Assert(t, diskUsageReadOnly(cluster))
+
+		assert.Equal(t, len(recorder.Events), 2)
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "DiskUsageHigh"))
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "Making PostgreSQL read-only"))
+
+		// Writes are allowed again once there is space.
+		output = "1B-blocks Used\n1073741824 536870912\n"
+
+		_, err = reconciler.reconcileDiskUsage(ctx, cluster, observed, nil)
+		assert.NilError(t, err)
+		assert.Assert(t, !diskUsageReadOnly(cluster))
+		assert.Equal(t, len(recorder.Events), 0)
+	})
+	t.Run("WALVolume", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Status.InstanceSets = []v1beta1.PostgresInstanceSetStatus{{Name: "00"}}
+		output = "1B-blocks Used\n1073741824 536870912\n"
+		walOutput = "1B-blocks Used\n1073741824 1063004405\n"
+
+		pod := pod.DeepCopy()
+		pod.Spec.Volumes = []corev1.Volume{{Name: postgres.WALVolumeMount().Name}}
+		observed := &observedInstances{forCluster: []*Instance{{
+			Name: "hippo-00-abcd", Spec: &cluster.Spec.InstanceSets[0],
+			Pods: []*corev1.Pod{pod},
+		}}}
+
+		wal := pvc.DeepCopy()
+		wal.Name = "hippo-00-abcd-pgwal"
+		wal.Labels[naming.LabelRole] = naming.RolePostgresWAL
+		wal.Spec.Resources.Requests = corev1.ResourceList{
+			corev1.ResourceStorage: resource.MustParse("1Gi"),
+		}
+		assert.NilError(t, reconciler.Client.Create(ctx, wal))
+
+		_, err := reconciler.reconcileDiskUsage(ctx, cluster, observed,
+			[]corev1.PersistentVolumeClaim{pvc, *wal})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, cluster.Status.InstanceSets[0].DataVolumeUsedPercent,
+			initialize.Int32(50))
+		assert.DeepEqual(t, cluster.Status.InstanceSets[0].WALVolumeUsedPercent,
+			initialize.Int32(99))
+
+		assert.Equal(t, len(recorder.Events), 3)
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "WAL volume of instance"))
+		assert.Assert(t, cmp.Contains(<-recorder.Events, `"hippo-00-abcd-pgwal" from 1Gi to 1536Mi`))
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "WAL volume of the primary is 99% full"))
+		assert.Assert(t, diskUsageReadOnly(cluster))
+	})
+
+	t.Run("OrphanedInstance", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Status.InstanceSets = []v1beta1.PostgresInstanceSetStatus{{Name: "00"}}
+		observed := &observedInstances{forCluster: []*Instance{{
+			Name: "hippo-01-efgh", Pods: []*corev1.Pod{pod},
+		}}}
+		reconciler := &Reconciler{
+			Client: reconciler.Client, Recorder: recorder,
+			PodExec: func(string, string, string,
+				io.Reader, io.Writer, io.Writer, ...string) error {
+				t.Fatal("expected no exec")
+				return nil
+			},
+		}
+
+		_, err := reconciler.reconcileDiskUsage(ctx, cluster, observed, nil)
+		assert.NilError(t, err)
+		assert.Assert(t, cluster.Status.InstanceSets[0].DataVolumeUsedPercent == nil)
+		assert.Equal(t, len(recorder.Events), 0)
+	})
+}
@@ -0,0 +1,142 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+// These are the reasons of the events that PGO records on a PostgresCluster.
+// Tools alert on them, so they are documented in the events reference and do
+// not change between releases. New reasons are named in UpperCamelCase, like
+// the reasons of Kubernetes itself.
+// The reasons of pgBackRest events are with its conditions in pgbackrest.go.
+const (
+	// EventBootstrapped is recorded once PostgreSQL is initialized.
+	EventBootstrapped = "Bootstrapped"
+
+	// EventPrimaryChanged is recorded when a different instance becomes the
+	// primary, by either switchover or failover.
+	EventPrimaryChanged = "PrimaryChanged"
+
+	// EventReplicaReinitialized is recorded when PGO rebuilds a replica that
+	// failed to start.
+	EventReplicaReinitialized = "ReplicaReinitialized"
+
+	// EventUserCreated is recorded when PGO creates the Secret of a user.
+	EventUserCreated = "UserCreated"
+
+	// EventConfigurationDrift is recorded when PGO reverts a change to one of
+	// its objects that was made outside of the PostgresCluster spec.
+	EventConfigurationDrift = "ConfigurationDrift"
+
+	// EventCertExpiring is recorded when a custom TLS certificate is about to
+	// expire.
+	EventCertExpiring = "CertExpiring"
+
+	// EventDiskUsageHigh is recorded while a PostgreSQL volume is fuller than
+	// its warning threshold.
+	EventDiskUsageHigh = "DiskUsageHigh"
+
+	// EventReadOnly is recorded when PGO makes PostgreSQL read-only because a
+	// volume of the primary is nearly full.
+	EventReadOnly = "ReadOnly"
+
+	// EventVolumeExpanding is recorded when PGO requests a larger volume.
+	EventVolumeExpanding = "VolumeExpanding"
+
+	// EventVolumeLimitReached is recorded when a volume cannot grow any more.
+	EventVolumeLimitReached = "VolumeLimitReached"
+
+	// EventPersistentVolumeError is recorded when a new volume could not be
+	// provisioned or bound.
+	EventPersistentVolumeError = "PersistentVolumeError"
+
+	// EventNoCheckpoint and EventSlowCheckpoint are recorded when the primary
+	// shuts down without a quick checkpoint first.
+	EventNoCheckpoint   = "NoCheckpoint"
+	EventSlowCheckpoint = "SlowCheckpoint"
+
+	// EventHugePagesUnavailable is recorded when no node has the huge pages
+	// that an instance requests.
+	EventHugePagesUnavailable = "HugePagesUnavailable"
+
+	// EventQoSNotGuaranteed and EventCPUsNotDedicated are recorded when the
+	// resources of an instance are not enough for what the spec asks of it.
+	EventQoSNotGuaranteed = "QoSNotGuaranteed"
+	EventCPUsNotDedicated = "CPUsNotDedicated"
+
+	// EventManualBackupQueued is recorded when a manual backup waits for others
+	// to finish.
+	EventManualBackupQueued = "ManualBackupQueued"
+
+	// EventFinalBackupStarted, EventFinalBackupSkipped, and EventFinalBackupFailed
+	// are recorded about the backup taken before a cluster is deleted.
+	EventFinalBackupStarted = "FinalBackupStarted"
+	EventFinalBackupSkipped = "FinalBackupSkipped"
+	EventFinalBackupFailed  = "FinalBackupFailed"
+
+	// EventQueryStatisticsReset is recorded when PGO resets the statistics of
+	// pg_stat_statements for the reset-query-statistics annotation.
+	EventQueryStatisticsReset = "QueryStatisticsReset"
+
+	// EventBenchmarkFailed is recorded when a pgbench Job fails.
+	EventBenchmarkFailed = "BenchmarkFailed"
+
+	// EventDeletionProtected is recorded when a cluster cannot be deleted
+	// because of spec.deletionProtection.
+	EventDeletionProtected = "DeletionProtected"
+
+	// EventRetainingVolumes is recorded while the volumes of a deleted cluster
+	// are kept.
+	EventRetainingVolumes = "RetainingVolumes"
+
+	// EventTempTablespaceNotDropped is recorded when PGO cannot drop the
+	// temporary tablespace after spec.tempVolume is removed.
+	EventTempTablespaceNotDropped = "TempTablespaceNotDropped"
+
+	// EventExtensionsDisabled and the events that follow it are recorded when
+	// PGO cannot install an extension that the spec asks for. The reason of
+	// EventPGAuditDisabled predates the others; it keeps its spelling so that
+	// existing alerts and queries continue to match it.
+	EventExtensionsDisabled  = "ExtensionsDisabled"
+	EventPGAuditDisabled     = "pgAuditDisabled"
+	EventPostGISDisabled     = "PostGISDisabled"
+	EventTimescaleDBDisabled = "TimescaleDBDisabled"
+
+	// EventPasswordSecretUnavailable and EventVaultUnavailable are recorded when
+	// PGO cannot read the password of a user.
+	EventPasswordSecretUnavailable = "PasswordSecretUnavailable"
+	EventVaultUnavailable          = "VaultUnavailable"
+
+	// EventPasswordPolicyViolation is recorded when the password of a user
+	// breaks spec.passwordPolicy and is not used.
+	EventPasswordPolicyViolation = "PasswordPolicyViolation"
+
+	// The events that follow are recorded when a part of the spec is invalid,
+	// so PGO ignores it until it changes.
+	EventInvalidBackupRepo         = "InvalidBackupRepo"
+	EventInvalidBenchmark          = "InvalidBenchmark"
+	EventInvalidCertManager        = "InvalidCertManager"
+	EventInvalidDatabase           = "InvalidDatabase"
+	EventInvalidDataSource         = "InvalidDataSource"
+	EventInvalidFinalBackup        = "InvalidFinalBackup"
+	EventInvalidImage              = "InvalidImage"
+	EventInvalidMaintenanceWindow  = "InvalidMaintenanceWindow"
+	EventInvalidManualBackup       = "InvalidManualBackup"
+	EventInvalidPodTemplateOverlay = "InvalidPodTemplateOverlay"
+	EventInvalidSQLPolicy          = "InvalidSQLPolicy"
+	EventInvalidSubscription       = "InvalidSubscription"
+	EventInvalidUptimeSchedule     = "InvalidUptimeSchedule"
+	EventInvalidUser               = "InvalidUser"
+	EventStanzaNotCreated          = "StanzaNotCreated"
+)
@@ -0,0 +1,50 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// reportUnavailableHugePages records an event for every instance Pod that
+// requests huge pages but cannot be scheduled because no node has enough of
+// them. Kubernetes schedules such Pods only onto nodes with enough huge pages;
+// until then, PostgreSQL cannot start.
+func (r *Reconciler) reportUnavailableHugePages(
+	cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) {
+	for _, instance := range instances.forCluster {
+		if instance.Spec == nil || !postgres.HugePagesRequested(instance.Spec.Resources) {
+			continue
+		}
+		for _, pod := range instance.Pods {
+			for _, condition := range pod.Status.Conditions {
+				if condition.Type == corev1.PodScheduled &&
+					condition.Status == corev1.ConditionFalse &&
+					condition.Reason == corev1.PodReasonUnschedulable &&
+					strings.Contains(condition.Message, corev1.ResourceHugePagesPrefix) {
+					r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventHugePagesUnavailable,
+						"Pod %q cannot be scheduled: %s", pod.Name, condition.Message)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestReportUnavailableHugePages(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{Recorder: recorder}
+	cluster := new(v1beta1.PostgresCluster)
+
+	pending := func(name, message string) *corev1.Pod {
+		pod := new(corev1.Pod)
+		pod.Name = name
+		pod.Status.Conditions = []corev1.PodCondition{{
+			Type:    corev1.PodScheduled,
+			Status:  corev1.ConditionFalse,
+			Reason:  corev1.PodReasonUnschedulable,
+			Message: message,
+		}}
+		return pod
+	}
+
+	withHugePages := &v1beta1.PostgresInstanceSetSpec{Name: "huge"}
+	withHugePages.Resources.Limits = corev1.ResourceList{
+		"hugepages-2Mi": resource.MustParse("1Gi"),
+	}
+
+	reconciler.reportUnavailableHugePages(cluster, &observedInstances{forCluster: []*Instance{
+		{Spec: withHugePages, Pods: []*corev1.Pod{
+			pending("huge-0", "0/3 nodes are available: 3 Insufficient hugepages-2Mi."),
+		}},
+		{Spec: withHugePages, Pods: []*corev1.Pod{
+			pending("huge-1", "0/3 nodes are available: 3 Insufficient cpu."),
+		}},
+		{Spec: &v1beta1.PostgresInstanceSetSpec{Name: "small"}, Pods: []*corev1.Pod{
+			pending("small-0", "0/3 nodes are available: 3 Insufficient hugepages-2Mi."),
+		}},
+		{Spec: withHugePages, Pods: []*corev1.Pod{new(corev1.Pod)}},
+	}})
+
+	assert.Equal(t, len(recorder.Events), 1)
+	event := <-recorder.Events
+	assert.Assert(t, cmp.Contains(event, "HugePagesUnavailable"))
+	assert.Assert(t, cmp.Contains(event, `Pod "huge-0" cannot be scheduled`))
+}
@@ -92,6 +92,33 @@ func (i Instance) IsReady() (ready bool, known bool) {
 	return false, false
 }
 
+// IsCrashLooping returns whether or not any container of this instance is
+// waiting to restart after exiting again and again.
+// - https://docs.k8s.io/concepts/workloads/pods/pod-lifecycle/#container-restarts
+func (i Instance) IsCrashLooping() (crashLooping bool, known bool) {
+	if len(i.Pods) != 1 {
+		return false, false
+	}
+
+	return podCrashLooping(i.Pods[0]), true
+}
+
+// podCrashLooping returns whether or not any container of pod is waiting in
+// CrashLoopBackOff.
+func podCrashLooping(pod *corev1.Pod) bool {
+	for _, statuses := range [][]corev1.ContainerStatus{
+		pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses,
+	} {
+		for _, status := range statuses {
+			if waiting := status.State.Waiting; waiting != nil &&
+				waiting.Reason == "CrashLoopBackOff" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // IsRunning returns whether or not container is running.
 func (i Instance) IsRunning(container string) (running bool, known bool) {
 	if len(i.Pods) == 1 {
@@ -334,6 +361,9 @@ func (r *Reconciler) observeInstances(
 			if matches, known := instance.PodMatchesPodTemplate(); known && matches {
 				status.UpdatedReplicas++
 			}
+			if len(instance.Pods) > 0 && patroni.PodRequiresRestart(instance.Pods[0]) {
+				status.PendingRestartReplicas++
+			}
 		}
 
 		cluster.Status.InstanceSets = append(cluster.Status.InstanceSets, status)
@@ -502,6 +532,7 @@ func (r *Reconciler) reconcileInstanceSets(
 	patroniLeaderService *corev1.Service,
 	primaryCertificate *corev1.SecretProjection,
 	clusterVolumes []corev1.PersistentVolumeClaim,
+	window *maintenance,
 ) error {
 
 	// Go through the observed instances and check if a primary has been determined.
@@ -562,9 +593,13 @@ func (r *Reconciler) reconcileInstanceSets(
 		return err
 	}
 
-	// Rollout changes to instances by calling rolloutInstance.
+	// Rollout changes to instances by calling rolloutInstance. Outside of the
+	// maintenance window, leave instances as they are.
 	err = r.rolloutInstances(ctx, cluster, instances,
 		func(ctx context.Context, instance *Instance) error {
+			if !window.allow("recreate instance Pods") {
+				return nil
+			}
 			return r.rolloutInstance(ctx, cluster, instances, instance)
 		})
 
@@ -767,10 +802,10 @@ func (r *Reconciler) rolloutInstance(
 
 		// Communicate the lack or slowness of CHECKPOINT and shutdown anyway.
 		if err != nil {
-			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "NoCheckpoint",
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventNoCheckpoint,
 				"Unable to checkpoint primary before shutdown: %v", err)
 		} else if duration > threshold {
-			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "SlowCheckpoint",
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventSlowCheckpoint,
 				"Shutting down primary despite checkpoint taking over %v", duration)
 		}
 	}
@@ -1067,6 +1102,7 @@ func (r *Reconciler) reconcileInstance(
 		instanceCertificates *corev1.Secret
 		postgresDataVolume   *corev1.PersistentVolumeClaim
 		postgresWALVolume    *corev1.PersistentVolumeClaim
+		postgresTempVolume   *corev1.PersistentVolumeClaim
 	)
 
 	if err == nil {
@@ -1082,6 +1118,9 @@ func (r *Reconciler) reconcileInstance(
 	if err == nil {
 		postgresWALVolume, err = r.reconcilePostgresWALVolume(ctx, cluster, spec, instance, observed, clusterVolumes)
 	}
+	if err == nil {
+		postgresTempVolume, err = r.reconcilePostgresTempVolume(ctx, cluster, spec, instance, clusterVolumes)
+	}
 	if err == nil {
 		postgres.InstancePod(
 			ctx, cluster, spec,
@@ -1089,9 +1128,14 @@ func (r *Reconciler) reconcileInstance(
 			postgresDataVolume, postgresWALVolume,
 			&instance.Spec.Template.Spec)
 
+		postgres.AddTempVolumeToPod(cluster, postgresTempVolume,
+			&instance.Spec.Template.Spec, naming.ContainerDatabase, naming.ContainerPostgresStartup)
+
 		addPGBackRestToInstancePodSpec(
 			cluster, instanceCertificates, &instance.Spec.Template.Spec)
 
+		addPGAuditToInstancePodSpec(cluster, &instance.Spec.Template.Spec)
+
 		err = patroni.InstancePod(
 			ctx, cluster, clusterConfigMap, clusterPodService, patroniLeaderService,
 			spec, instanceCertificates, instanceConfigMap, &instance.Spec.Template)
@@ -1122,10 +1166,38 @@ func (r *Reconciler) reconcileInstance(
 		addDevSHM(&instance.Spec.Template)
 	}
 
+	// add user-defined containers and volumes after those PGO manages
 	if err == nil {
+		addCustomContainersToInstancePodSpec(spec, &instance.Spec.Template.Spec)
+	}
+
+	// apply the user's overlay after everything generated above. Guaranteed QoS
+	// and dedicated CPUs are applied after it so they see any limits it sets;
+	// the overlay cannot change the resource requests those set. Leave the
+	// StatefulSet as it is until an invalid overlay changes.
+	overlaid := err == nil && r.overlayPodTemplate(cluster,
+		fmt.Sprintf("instance set %q", spec.Name), &instance.Spec.Template, spec.PodTemplateOverlay)
+
+	// set requests to limits when the instance set asks for Guaranteed QoS or
+	// the cluster asks for dedicated CPUs
+	dedicated := cluster.Spec.DedicatedCPUs != nil && *cluster.Spec.DedicatedCPUs
+	if overlaid && (dedicated || (spec.GuaranteedQoS != nil && *spec.GuaranteedQoS)) {
+		if missing := addGuaranteedQoS(&instance.Spec.Template); len(missing) > 0 {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventQoSNotGuaranteed,
+				"Instance %q has containers without CPU or memory limits: %v",
+				instance.Name, missing)
+		}
+		if dedicated && !hasDedicatedCPUs(&instance.Spec.Template, naming.ContainerDatabase) {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventCPUsNotDedicated,
+				"Instance %q does not request a whole number of CPUs for PostgreSQL",
+				instance.Name)
+		}
+	}
+
+	if overlaid {
 		err = errors.WithStack(r.apply(ctx, instance))
 	}
-	if err == nil {
+	if overlaid && err == nil {
 		log.V(1).Info("reconciled instance", "instance", instance.Name)
 	}
 
@@ -1190,6 +1262,7 @@ func generateInstanceStatefulSetIntent(_ context.Context,
 
 	// Use scheduling constraints from the cluster spec.
 	sts.Spec.Template.Spec.Affinity = spec.Affinity
+	sts.Spec.Template.Spec.NodeSelector = spec.NodeSelector
 	sts.Spec.Template.Spec.Tolerations = spec.Tolerations
 	sts.Spec.Template.Spec.TopologySpreadConstraints = spec.TopologySpreadConstraints
 	if spec.PriorityClassName != nil {
@@ -1274,6 +1347,16 @@ func addPGBackRestToInstancePodSpec(cluster *v1beta1.PostgresCluster,
 	pgbackrest.AddConfigToInstancePod(cluster, instancePod)
 }
 
+// addCustomContainersToInstancePodSpec appends the init containers, sidecars,
+// and volumes defined in the instance set spec to the Pod spec.
+func addCustomContainersToInstancePodSpec(
+	spec *v1beta1.PostgresInstanceSetSpec, pod *corev1.PodSpec,
+) {
+	pod.InitContainers = append(pod.InitContainers, spec.InitContainers...)
+	pod.Containers = append(pod.Containers, spec.Containers...)
+	pod.Volumes = append(pod.Volumes, spec.Volumes...)
+}
+
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=create;patch
 
 // reconcileInstanceConfigMap writes the ConfigMap that contains generated
@@ -652,3 +652,35 @@ func TestReconcilerRolloutInstances(t *testing.T) {
 			}))
 	})
 }
+
+func TestReconcilerObserveInstancesPendingRestart(t *testing.T) {
+	ctx := context.Background()
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Namespace, cluster.Name = "ns1", "hippo"
+	cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{{Name: "00"}}
+
+	pod := func(name, status string) client.Object {
+		pod := &corev1.Pod{}
+		pod.Namespace, pod.Name = "ns1", name+"-0"
+		pod.Labels = map[string]string{
+			"postgres-operator.crunchydata.com/cluster":      "hippo",
+			"postgres-operator.crunchydata.com/instance":     name,
+			"postgres-operator.crunchydata.com/instance-set": "00",
+		}
+		pod.Annotations = map[string]string{"status": status}
+		return pod
+	}
+
+	reconciler := &Reconciler{}
+	reconciler.Client = fake.NewClientBuilder().WithObjects(
+		pod("hippo-00-aaaa", `{"role":"master"}`),
+		pod("hippo-00-bbbb", `{"role":"replica","pending_restart":true}`),
+		pod("hippo-00-cccc", `{"role":"replica","pending_restart":true}`),
+	).Build()
+
+	_, err := reconciler.observeInstances(ctx, cluster)
+	assert.NilError(t, err)
+	assert.Equal(t, len(cluster.Status.InstanceSets), 1)
+	assert.Equal(t, cluster.Status.InstanceSets[0].Replicas, int32(3))
+	assert.Equal(t, cluster.Status.InstanceSets[0].PendingRestartReplicas, int32(2))
+}
@@ -103,6 +103,51 @@ func TestInstanceIsRunning(t *testing.T) {
 	assert.Assert(t, running)
 }
 
+func TestInstanceIsCrashLooping(t *testing.T) {
+	var instance Instance
+	var known, looping bool
+
+	// No pods
+	looping, known = instance.IsCrashLooping()
+	assert.Assert(t, !known)
+	assert.Assert(t, !looping)
+
+	// No statuses
+	instance.Pods = []*corev1.Pod{{}}
+	looping, known = instance.IsCrashLooping()
+	assert.Assert(t, known)
+	assert.Assert(t, !looping)
+
+	// Waiting for another reason
+	instance.Pods[0].Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name: "c1",
+		State: corev1.ContainerState{
+			Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"},
+		},
+	}}
+	looping, known = instance.IsCrashLooping()
+	assert.Assert(t, known)
+	assert.Assert(t, !looping)
+
+	// Crash looping
+	instance.Pods[0].Status.ContainerStatuses[0].State.Waiting.Reason = "CrashLoopBackOff"
+	looping, known = instance.IsCrashLooping()
+	assert.Assert(t, known)
+	assert.Assert(t, looping)
+
+	// Init containers
+	instance.Pods[0].Status.ContainerStatuses = nil
+	instance.Pods[0].Status.InitContainerStatuses = []corev1.ContainerStatus{{
+		Name: "i1",
+		State: corev1.ContainerState{
+			Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+		},
+	}}
+	looping, known = instance.IsCrashLooping()
+	assert.Assert(t, known)
+	assert.Assert(t, looping)
+}
+
 func TestInstanceIsWritable(t *testing.T) {
 	var instance Instance
 	var known, writable bool
@@ -733,6 +778,42 @@ func TestAddPGBackRestToInstancePodSpec(t *testing.T) {
 
 }
 
+func TestAddCustomContainersToInstancePodSpec(t *testing.T) {
+	pod := corev1.PodSpec{
+		InitContainers: []corev1.Container{{Name: "postgres-startup"}},
+		Containers:     []corev1.Container{{Name: "database"}},
+		Volumes:        []corev1.Volume{{Name: "postgres-data"}},
+	}
+
+	t.Run("Empty", func(t *testing.T) {
+		pod := *pod.DeepCopy()
+		addCustomContainersToInstancePodSpec(&v1beta1.PostgresInstanceSetSpec{}, &pod)
+		assert.Equal(t, len(pod.Containers), 1)
+		assert.Equal(t, len(pod.InitContainers), 1)
+		assert.Equal(t, len(pod.Volumes), 1)
+	})
+
+	t.Run("Custom", func(t *testing.T) {
+		pod := *pod.DeepCopy()
+		addCustomContainersToInstancePodSpec(&v1beta1.PostgresInstanceSetSpec{
+			InitContainers: []corev1.Container{{Name: "fetch-agent"}},
+			Containers: []corev1.Container{{
+				Name:         "log-shipper",
+				VolumeMounts: []corev1.VolumeMount{{Name: "shipper-config", MountPath: "/etc/shipper"}},
+			}},
+			Volumes: []corev1.Volume{{Name: "shipper-config"}},
+		}, &pod)
+
+		// PGO containers come first.
+		assert.DeepEqual(t, []string{pod.InitContainers[0].Name, pod.InitContainers[1].Name},
+			[]string{"postgres-startup", "fetch-agent"})
+		assert.DeepEqual(t, []string{pod.Containers[0].Name, pod.Containers[1].Name},
+			[]string{"database", "log-shipper"})
+		assert.DeepEqual(t, []string{pod.Volumes[0].Name, pod.Volumes[1].Name},
+			[]string{"postgres-data", "shipper-config"})
+	})
+}
+
 func TestPodsToKeep(t *testing.T) {
 	for _, test := range []struct {
 		name      string
@@ -1236,6 +1317,17 @@ func TestGenerateInstanceStatefulSetIntent(t *testing.T) {
 		run: func(t *testing.T, ss *appsv1.StatefulSet) {
 			assert.Assert(t, ss.Spec.Template.Spec.Affinity != nil)
 		},
+	}, {
+		name: "custom node selector",
+		ip: intentParams{
+			spec: &v1beta1.PostgresInstanceSetSpec{
+				NodeSelector: map[string]string{"node-pool": "postgres"},
+			},
+		},
+		run: func(t *testing.T, ss *appsv1.StatefulSet) {
+			assert.DeepEqual(t, ss.Spec.Template.Spec.NodeSelector,
+				map[string]string{"node-pool": "postgres"})
+		},
 	}, {
 		name: "custom tolerations",
 		ip: intentParams{
@@ -0,0 +1,114 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const (
+	// ConditionMaintenancePending is the type used in a condition to indicate
+	// whether or not disruptive changes are waiting for the maintenance window
+	// of a PostgresCluster
+	ConditionMaintenancePending = "MaintenancePending"
+)
+
+// maintenance decides whether disruptive changes to a PostgresCluster may
+// happen now and remembers the ones that must wait.
+type maintenance struct {
+	closed  bool
+	opens   time.Duration
+	pending []string
+}
+
+// newMaintenance evaluates the maintenance window of cluster at now. Every
+// change is allowed when cluster has no window or it cannot be evaluated.
+func newMaintenance(cluster *v1beta1.PostgresCluster, now time.Time) (*maintenance, error) {
+	spec := cluster.Spec.MaintenanceWindow
+	if spec == nil {
+		return &maintenance{}, nil
+	}
+
+	windows := make([]v1beta1.UptimeWindow, len(spec.Windows))
+	for i := range spec.Windows {
+		windows[i] = v1beta1.UptimeWindow(spec.Windows[i])
+	}
+
+	inside, next, err := withinWindows(spec.TimeZone, windows, now)
+	if err != nil {
+		return nil, err
+	}
+	return &maintenance{closed: !inside, opens: next}, nil
+}
+
+// allow reports whether the disruptive change described by action may happen
+// now. When it may not, action is recorded as pending. A nil maintenance
+// allows everything.
+func (m *maintenance) allow(action string) bool {
+	if m == nil || !m.closed {
+		return true
+	}
+	for _, pending := range m.pending {
+		if pending == action {
+			return false
+		}
+	}
+	m.pending = append(m.pending, action)
+	return false
+}
+
+// requeueAfter returns how long until the maintenance window opens when there
+// are changes waiting for it, and zero otherwise.
+func (m *maintenance) requeueAfter() time.Duration {
+	if m == nil || len(m.pending) == 0 {
+		return 0
+	}
+	return m.opens
+}
+
+// setMaintenanceCondition sets the MaintenancePending condition of cluster
+// when changes are waiting for its maintenance window, and removes it
+// otherwise.
+func setMaintenanceCondition(cluster *v1beta1.PostgresCluster, m *maintenance) {
+	if m == nil || len(m.pending) == 0 {
+		// TODO: remove guard with move to controller-runtime 0.9.0 https://issue.k8s.io/99714
+		if len(cluster.Status.Conditions) > 0 {
+			meta.RemoveStatusCondition(&cluster.Status.Conditions, ConditionMaintenancePending)
+		}
+		return
+	}
+
+	message := fmt.Sprintf("Waiting for the maintenance window to %s",
+		strings.Join(m.pending, " and "))
+	if m.opens > 0 {
+		message += fmt.Sprintf("; it opens in %s", m.opens.Round(time.Minute))
+	}
+
+	meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+		ObservedGeneration: cluster.GetGeneration(),
+		Type:               ConditionMaintenancePending,
+		Status:             metav1.ConditionTrue,
+		Reason:             "OutsideMaintenanceWindow",
+		Message:            message,
+	})
+}
@@ -0,0 +1,101 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestMaintenance(t *testing.T) {
+	// Sunday, 2022-03-06
+	sunday := time.Date(2022, time.March, 6, 0, 0, 0, 0, time.UTC)
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Spec.MaintenanceWindow = &v1beta1.MaintenanceWindowSpec{
+		Windows: []v1beta1.MaintenanceWindow{{
+			Days:  []v1beta1.Weekday{"Sunday"},
+			Start: "02:00", End: "04:00",
+		}},
+	}
+
+	t.Run("NoWindow", func(t *testing.T) {
+		window, err := newMaintenance(&v1beta1.PostgresCluster{}, sunday)
+		assert.NilError(t, err)
+		assert.Assert(t, window.allow("restart PostgreSQL"))
+		assert.Equal(t, window.requeueAfter(), time.Duration(0))
+
+		var none *maintenance
+		assert.Assert(t, none.allow("restart PostgreSQL"))
+	})
+
+	t.Run("Inside", func(t *testing.T) {
+		window, err := newMaintenance(cluster, sunday.Add(3*time.Hour))
+		assert.NilError(t, err)
+		assert.Assert(t, window.allow("restart PostgreSQL"))
+		assert.Equal(t, window.requeueAfter(), time.Duration(0))
+
+		cluster := cluster.DeepCopy()
+		setMaintenanceCondition(cluster, window)
+		assert.Assert(t, meta.FindStatusCondition(cluster.Status.Conditions,
+			ConditionMaintenancePending) == nil)
+	})
+
+	t.Run("Outside", func(t *testing.T) {
+		window, err := newMaintenance(cluster, sunday.Add(5*time.Hour))
+		assert.NilError(t, err)
+		assert.Equal(t, window.requeueAfter(), time.Duration(0),
+			"expected no requeue until something waits")
+
+		assert.Assert(t, !window.allow("recreate instance Pods"))
+		assert.Assert(t, !window.allow("restart PostgreSQL"))
+		assert.Assert(t, !window.allow("restart PostgreSQL"))
+		assert.DeepEqual(t, window.pending,
+			[]string{"recreate instance Pods", "restart PostgreSQL"})
+		assert.Equal(t, window.requeueAfter(), 7*24*time.Hour-3*time.Hour)
+
+		cluster := cluster.DeepCopy()
+		setMaintenanceCondition(cluster, window)
+		condition := meta.FindStatusCondition(cluster.Status.Conditions,
+			ConditionMaintenancePending)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionTrue)
+		assert.Equal(t, condition.Reason, "OutsideMaintenanceWindow")
+		assert.Assert(t, cmp.Contains(condition.Message,
+			"recreate instance Pods and restart PostgreSQL"))
+
+		// The condition goes away once nothing waits.
+		setMaintenanceCondition(cluster, &maintenance{})
+		assert.Assert(t, meta.FindStatusCondition(cluster.Status.Conditions,
+			ConditionMaintenancePending) == nil)
+	})
+
+	t.Run("InvalidTimeZone", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.MaintenanceWindow.TimeZone = "Mars/Olympus_Mons"
+
+		window, err := newMaintenance(cluster, sunday)
+		assert.ErrorContains(t, err, "Mars")
+		assert.Assert(t, window.allow("restart PostgreSQL"))
+	})
+}
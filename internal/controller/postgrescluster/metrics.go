@@ -0,0 +1,245 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// Types of pgBackRest backup Jobs, for use as the "type" label of metrics.
+const (
+	backupTypeManual        = "manual"
+	backupTypeReplicaCreate = "replica-create"
+	backupTypeScheduled     = "scheduled"
+)
+
+// backupJobsFinished counts the pgBackRest backup Jobs that ran to completion
+// or failed. The controller-runtime manager serves it alongside its own metrics
+// about reconcile durations, work queues, and Kubernetes API requests.
+var backupJobsFinished = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "pgo_backup_jobs_finished_total",
+	Help: "Number of pgBackRest backup Jobs that finished, by type and result",
+}, []string{"namespace", "cluster", "type", "result"})
+
+// configurationDrift counts the objects that PGO changed back to match the
+// spec of their PostgresCluster after someone else changed them.
+var configurationDrift = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "pgo_configuration_drift_total",
+	Help: "Number of times PGO reverted changes made outside of it, by kind of object",
+}, []string{"namespace", "cluster", "kind"})
+
+// clusterState reports the state of each PostgresCluster according to its
+// conditions. Exactly one state of each cluster has the value 1.
+var clusterState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "pgo_cluster_state",
+	Help: "Whether the PostgresCluster is in a state: ready, progressing, degraded, unavailable, or shutdown",
+}, []string{"namespace", "cluster", "state"})
+
+// States of a PostgresCluster, for use as the "state" label of clusterState.
+var clusterStates = []string{"ready", "progressing", "degraded", "unavailable", "shutdown"}
+
+// backupLastSuccess is the completion time of the most recent pgBackRest
+// backup Job of each PostgresCluster that succeeded.
+var backupLastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "pgo_backup_last_success_timestamp_seconds",
+	Help: "Completion time of the most recent successful pgBackRest backup Job",
+}, []string{"namespace", "cluster"})
+
+// volumeUsedPercent is the percentage of volumes in use, as reported in the
+// status of each instance set when spec.diskUsage is set.
+var volumeUsedPercent = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "pgo_volume_used_percent",
+	Help: "Highest percentage of a volume in use among the pods of an instance set",
+}, []string{"namespace", "cluster", "instance_set", "volume"})
+
+// maintenancePending reports whether changes to each PostgresCluster are
+// waiting for its maintenance window or for PostgreSQL to restart.
+var maintenancePending = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "pgo_maintenance_pending",
+	Help: "Whether changes to the PostgresCluster are waiting for its maintenance window or a restart",
+}, []string{"namespace", "cluster"})
+
+// certificateExpiration is the expiration time of each custom TLS certificate.
+var certificateExpiration = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "pgo_certificate_expiration_timestamp_seconds",
+	Help: "Expiration time of a custom TLS certificate",
+}, []string{"namespace", "cluster", "secret"})
+
+// passwordExpiration is the expiration time of the password of each
+// PostgreSQL user that has one.
+var passwordExpiration = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "pgo_user_password_expiration_timestamp_seconds",
+	Help: "Expiration time of the password of a PostgreSQL user",
+}, []string{"namespace", "cluster", "user"})
+
+func init() {
+	metrics.Registry.MustRegister(backupJobsFinished, configurationDrift,
+		clusterState, backupLastSuccess, volumeUsedPercent, maintenancePending,
+		certificateExpiration, passwordExpiration)
+}
+
+// recordBackupJobFinished increments the count of backupType Jobs of cluster
+// that finished and records an event about it.
+func (r *Reconciler) recordBackupJobFinished(
+	cluster *v1beta1.PostgresCluster, backupType string, succeeded bool,
+) {
+	result := "failed"
+	if succeeded {
+		result = "succeeded"
+		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventBackupSucceeded,
+			"pgBackRest %s backup completed successfully", backupType)
+	} else {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventBackupFailed,
+			"pgBackRest %s backup failed", backupType)
+	}
+	backupJobsFinished.WithLabelValues(
+		cluster.Namespace, cluster.Name, backupType, result).Inc()
+}
+
+// recordClusterMetrics sets the gauges that describe the health of cluster
+// according to its spec and status. Call it after [setClusterConditions].
+func recordClusterMetrics(cluster *v1beta1.PostgresCluster) {
+	// Instance sets and users can be removed from the spec; start over so that
+	// their gauges go away with them.
+	forgetSeries(volumeUsedPercent, cluster)
+	forgetSeries(passwordExpiration, cluster)
+
+	conditions := cluster.Status.Conditions
+	ready := meta.FindStatusCondition(conditions, ConditionReady)
+
+	var state string
+	switch {
+	case ready != nil && ready.Reason == "Shutdown":
+		state = "shutdown"
+	case meta.IsStatusConditionTrue(conditions, ConditionReady):
+		state = "ready"
+	case meta.IsStatusConditionTrue(conditions, ConditionDegraded):
+		state = "degraded"
+	case meta.IsStatusConditionTrue(conditions, ConditionProgressing):
+		state = "progressing"
+	default:
+		state = "unavailable"
+	}
+	for _, s := range clusterStates {
+		value := 0.0
+		if s == state {
+			value = 1
+		}
+		clusterState.WithLabelValues(cluster.Namespace, cluster.Name, s).Set(value)
+	}
+
+	pending := meta.IsStatusConditionTrue(conditions, ConditionMaintenancePending)
+	for _, set := range cluster.Status.InstanceSets {
+		pending = pending || set.PendingRestartReplicas > 0
+
+		for volume, percent := range map[string]*int32{
+			"data": set.DataVolumeUsedPercent,
+			"wal":  set.WALVolumeUsedPercent,
+		} {
+			if percent != nil {
+				volumeUsedPercent.WithLabelValues(
+					cluster.Namespace, cluster.Name, set.Name, volume).Set(float64(*percent))
+			}
+		}
+	}
+	if pending {
+		maintenancePending.WithLabelValues(cluster.Namespace, cluster.Name).Set(1)
+	} else {
+		maintenancePending.WithLabelValues(cluster.Namespace, cluster.Name).Set(0)
+	}
+
+	if last := lastSuccessfulBackup(cluster); last != nil {
+		backupLastSuccess.WithLabelValues(cluster.Namespace, cluster.Name).
+			Set(float64(last.Unix()))
+	} else {
+		backupLastSuccess.DeleteLabelValues(cluster.Namespace, cluster.Name)
+	}
+
+	for user, expiration := range cluster.Status.PasswordExpirations {
+		passwordExpiration.WithLabelValues(cluster.Namespace, cluster.Name, user).
+			Set(float64(expiration.Unix()))
+	}
+}
+
+// lastSuccessfulBackup returns the completion time of the most recent backup
+// Job of cluster that succeeded, or nil when there is none.
+func lastSuccessfulBackup(cluster *v1beta1.PostgresCluster) *metav1.Time {
+	var last *metav1.Time
+	later := func(completed *metav1.Time) {
+		if completed != nil && (last == nil || last.Before(completed)) {
+			last = completed
+		}
+	}
+	if status := cluster.Status.PGBackRest; status != nil {
+		if manual := status.ManualBackup; manual != nil && manual.Succeeded > 0 {
+			later(manual.CompletionTime)
+		}
+		for i := range status.ScheduledBackups {
+			if status.ScheduledBackups[i].Succeeded > 0 {
+				later(status.ScheduledBackups[i].CompletionTime)
+			}
+		}
+	}
+	return last
+}
+
+// forgetClusterMetrics removes the gauges of cluster so that clusters that no
+// longer exist are not reported.
+func forgetClusterMetrics(cluster *v1beta1.PostgresCluster) {
+	forgetSeries(clusterState, cluster)
+	forgetSeries(volumeUsedPercent, cluster)
+	forgetSeries(certificateExpiration, cluster)
+	forgetSeries(passwordExpiration, cluster)
+	forgetSeries(backupLastSuccess, cluster)
+	forgetSeries(maintenancePending, cluster)
+	forgetSeries(configurationDrift, cluster)
+}
+
+// forgetSeries deletes every series of vec that has the "namespace" and
+// "cluster" labels of cluster, whatever the values of its other labels.
+func forgetSeries(vec interface {
+	prometheus.Collector
+	Delete(prometheus.Labels) bool
+}, cluster *v1beta1.PostgresCluster) {
+	metrics := make(chan prometheus.Metric)
+	go func() { vec.Collect(metrics); close(metrics) }()
+
+	// Collect holds a lock that Delete needs, so delete after collecting.
+	var matches []prometheus.Labels
+	for metric := range metrics {
+		var written dto.Metric
+		if metric.Write(&written) != nil {
+			continue
+		}
+		labels := prometheus.Labels{}
+		for _, pair := range written.GetLabel() {
+			labels[pair.GetName()] = pair.GetValue()
+		}
+		if labels["namespace"] == cluster.Namespace && labels["cluster"] == cluster.Name {
+			matches = append(matches, labels)
+		}
+	}
+	for _, labels := range matches {
+		vec.Delete(labels)
+	}
+}
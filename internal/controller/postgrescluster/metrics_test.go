@@ -0,0 +1,168 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestRecordBackupJobFinished(t *testing.T) {
+	cluster := testCluster()
+	cluster.Namespace = "ns1"
+
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{Recorder: recorder}
+
+	succeeded := backupJobsFinished.WithLabelValues("ns1", "hippo", backupTypeManual, "succeeded")
+	failed := backupJobsFinished.WithLabelValues("ns1", "hippo", backupTypeManual, "failed")
+	succeededBefore := testutil.ToFloat64(succeeded)
+	failedBefore := testutil.ToFloat64(failed)
+
+	reconciler.recordBackupJobFinished(cluster, backupTypeManual, true)
+	reconciler.recordBackupJobFinished(cluster, backupTypeManual, true)
+	reconciler.recordBackupJobFinished(cluster, backupTypeManual, false)
+
+	assert.Equal(t, testutil.ToFloat64(succeeded), succeededBefore+2)
+	assert.Equal(t, testutil.ToFloat64(failed), failedBefore+1)
+
+	assert.Equal(t, len(recorder.Events), 3)
+	assert.Equal(t, <-recorder.Events, "Normal BackupSucceeded pgBackRest manual backup completed successfully")
+	assert.Equal(t, <-recorder.Events, "Normal BackupSucceeded pgBackRest manual backup completed successfully")
+	assert.Equal(t, <-recorder.Events, "Warning BackupFailed pgBackRest manual backup failed")
+}
+
+func TestRecordClusterMetrics(t *testing.T) {
+	cluster := testCluster()
+	cluster.Namespace = "ns2"
+	cluster.Status.Conditions = []metav1.Condition{
+		{Type: ConditionReady, Status: metav1.ConditionFalse, Reason: "NoPrimary"},
+		{Type: ConditionProgressing, Status: metav1.ConditionTrue, Reason: "InstancesUpdating"},
+	}
+	cluster.Status.InstanceSets = []v1beta1.PostgresInstanceSetStatus{{
+		Name:                  "instance1",
+		DataVolumeUsedPercent: initialize.Int32(42),
+	}}
+
+	recordClusterMetrics(cluster)
+
+	for state, expected := range map[string]float64{
+		"ready": 0, "progressing": 1, "degraded": 0, "unavailable": 0, "shutdown": 0,
+	} {
+		assert.Equal(t, testutil.ToFloat64(
+			clusterState.WithLabelValues("ns2", "hippo", state)), expected, state)
+	}
+	assert.Equal(t, testutil.ToFloat64(
+		volumeUsedPercent.WithLabelValues("ns2", "hippo", "instance1", "data")), float64(42))
+	assert.Equal(t, testutil.ToFloat64(
+		maintenancePending.WithLabelValues("ns2", "hippo")), float64(0))
+	assert.Assert(t, !backupLastSuccess.DeleteLabelValues("ns2", "hippo"))
+
+	t.Run("Changes", func(t *testing.T) {
+		now := time.Now().Truncate(time.Second)
+		cluster.Status.Conditions = []metav1.Condition{
+			{Type: ConditionReady, Status: metav1.ConditionTrue, Reason: "AllInstancesReady"},
+			{Type: ConditionMaintenancePending, Status: metav1.ConditionTrue},
+		}
+		cluster.Status.InstanceSets[0].DataVolumeUsedPercent = nil
+		cluster.Status.PGBackRest = &v1beta1.PGBackRestStatus{
+			ManualBackup: &v1beta1.PGBackRestJobStatus{
+				Succeeded: 1, CompletionTime: &metav1.Time{Time: now.Add(-time.Hour)},
+			},
+			ScheduledBackups: []v1beta1.PGBackRestScheduledBackupStatus{
+				{Succeeded: 1, CompletionTime: &metav1.Time{Time: now.Add(-2 * time.Hour)}},
+				{Failed: 1, CompletionTime: &metav1.Time{Time: now}},
+			},
+		}
+		cluster.Status.PasswordExpirations = map[string]metav1.Time{
+			"rhino": {Time: now.Add(24 * time.Hour)},
+		}
+
+		recordClusterMetrics(cluster)
+
+		assert.Equal(t, testutil.ToFloat64(
+			clusterState.WithLabelValues("ns2", "hippo", "ready")), float64(1))
+		assert.Equal(t, testutil.ToFloat64(
+			clusterState.WithLabelValues("ns2", "hippo", "progressing")), float64(0))
+		assert.Assert(t, !volumeUsedPercent.DeleteLabelValues("ns2", "hippo", "instance1", "data"))
+		assert.Equal(t, testutil.ToFloat64(
+			maintenancePending.WithLabelValues("ns2", "hippo")), float64(1))
+		assert.Equal(t, testutil.ToFloat64(
+			backupLastSuccess.WithLabelValues("ns2", "hippo")),
+			float64(now.Add(-time.Hour).Unix()))
+		assert.Equal(t, testutil.ToFloat64(
+			passwordExpiration.WithLabelValues("ns2", "hippo", "rhino")),
+			float64(now.Add(24*time.Hour).Unix()))
+	})
+
+	t.Run("Removed", func(t *testing.T) {
+		cluster.Status.InstanceSets = []v1beta1.PostgresInstanceSetStatus{{
+			Name:                  "instance2",
+			DataVolumeUsedPercent: initialize.Int32(7),
+		}}
+		volumeUsedPercent.WithLabelValues("ns2", "hippo", "instance1", "wal").Set(50)
+		cluster.Status.PasswordExpirations = nil
+
+		recordClusterMetrics(cluster)
+
+		assert.Assert(t, !volumeUsedPercent.DeleteLabelValues("ns2", "hippo", "instance1", "wal"))
+		assert.Assert(t, !passwordExpiration.DeleteLabelValues("ns2", "hippo", "rhino"))
+		assert.Equal(t, testutil.ToFloat64(
+			volumeUsedPercent.WithLabelValues("ns2", "hippo", "instance2", "data")), float64(7))
+	})
+
+	t.Run("Shutdown", func(t *testing.T) {
+		cluster.Status.Conditions = []metav1.Condition{
+			{Type: ConditionReady, Status: metav1.ConditionFalse, Reason: "Shutdown"},
+		}
+
+		recordClusterMetrics(cluster)
+
+		assert.Equal(t, testutil.ToFloat64(
+			clusterState.WithLabelValues("ns2", "hippo", "shutdown")), float64(1))
+		assert.Equal(t, testutil.ToFloat64(
+			clusterState.WithLabelValues("ns2", "hippo", "ready")), float64(0))
+	})
+
+	t.Run("Forget", func(t *testing.T) {
+		configurationDrift.WithLabelValues("ns2", "hippo", "Service").Inc()
+		configurationDrift.WithLabelValues("ns2", "hippo", "StatefulSet").Inc()
+		configurationDrift.WithLabelValues("ns2", "rhino", "Service").Inc()
+
+		forgetClusterMetrics(cluster)
+
+		// Nothing remains to be deleted.
+		for _, state := range clusterStates {
+			assert.Assert(t, !clusterState.DeleteLabelValues("ns2", "hippo", state), state)
+		}
+		assert.Assert(t, !maintenancePending.DeleteLabelValues("ns2", "hippo"))
+		assert.Assert(t, !backupLastSuccess.DeleteLabelValues("ns2", "hippo"))
+		assert.Assert(t, !volumeUsedPercent.DeleteLabelValues("ns2", "hippo", "instance2", "data"))
+		assert.Assert(t, !configurationDrift.DeleteLabelValues("ns2", "hippo", "Service"))
+		assert.Assert(t, !configurationDrift.DeleteLabelValues("ns2", "hippo", "StatefulSet"))
+
+		// Other clusters are not forgotten.
+		assert.Assert(t, configurationDrift.DeleteLabelValues("ns2", "rhino", "Service"))
+	})
+}
@@ -0,0 +1,113 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+
+	"github.com/crunchydata/postgres-operator/internal/util"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// passwordPolicyError is returned when a password breaks the password policy
+// of a cluster.
+type passwordPolicyError struct{ error }
+
+// isPasswordPolicyError returns whether or not err is or wraps a
+// passwordPolicyError.
+func isPasswordPolicyError(err error) bool {
+	var violation passwordPolicyError
+	return errors.As(err, &violation)
+}
+
+// checkPasswordPolicy returns an error that describes the first rule of policy
+// that the password of username breaks. It returns nil when policy is nil.
+func checkPasswordPolicy(
+	policy *v1beta1.PostgresPasswordPolicySpec, username, password string,
+) error {
+	if policy == nil {
+		return nil
+	}
+
+	if policy.MinLength != nil && len(password) < int(*policy.MinLength) {
+		return errors.Errorf("password must be at least %d characters", *policy.MinLength)
+	}
+
+	for _, class := range policy.RequiredCharacters {
+		var matches func(rune) bool
+		switch class {
+		case v1beta1.PostgresPasswordCharactersDigit:
+			matches = unicode.IsDigit
+		case v1beta1.PostgresPasswordCharactersLowercase:
+			matches = unicode.IsLower
+		case v1beta1.PostgresPasswordCharactersUppercase:
+			matches = unicode.IsUpper
+		case v1beta1.PostgresPasswordCharactersSymbol:
+			matches = func(r rune) bool {
+				return unicode.IsPunct(r) || unicode.IsSymbol(r)
+			}
+		default:
+			continue
+		}
+		if strings.IndexFunc(password, matches) < 0 {
+			return errors.Errorf("password must contain a %s character",
+				strings.ToLower(string(class)))
+		}
+	}
+
+	lower := strings.ToLower(password)
+	if username != "" && strings.Contains(lower, strings.ToLower(username)) {
+		return errors.New("password cannot contain the name of its user")
+	}
+	for _, word := range policy.ForbiddenWords {
+		if word != "" && strings.Contains(lower, strings.ToLower(word)) {
+			return errors.Errorf("password cannot contain %q", word)
+		}
+	}
+
+	return nil
+}
+
+// generatePolicyPassword calls generate until it returns a password for
+// username that follows policy. Generated passwords are at least
+// [util.DefaultGeneratedPasswordLength] characters long.
+func generatePolicyPassword(
+	generate func(int) (string, error),
+	policy *v1beta1.PostgresPasswordPolicySpec, username string,
+) (string, error) {
+	length := util.DefaultGeneratedPasswordLength
+	if policy != nil && policy.MinLength != nil && int(*policy.MinLength) > length {
+		length = int(*policy.MinLength)
+	}
+
+	// Random passwords of this length almost always contain every kind of
+	// character, so a few attempts are plenty.
+	var err error
+	for attempt := 0; attempt < 10; attempt++ {
+		var password string
+		password, err = generate(length)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		if err = checkPasswordPolicy(policy, username, password); err == nil {
+			return password, nil
+		}
+	}
+	return "", errors.Wrap(err, "unable to generate a password")
+}
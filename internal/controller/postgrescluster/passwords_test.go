@@ -0,0 +1,146 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/util"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestCheckPasswordPolicy(t *testing.T) {
+	assert.NilError(t, checkPasswordPolicy(nil, "rhino", "rhino"))
+
+	policy := &v1beta1.PostgresPasswordPolicySpec{
+		MinLength: initialize.Int32(12),
+		RequiredCharacters: []v1beta1.PostgresPasswordCharacters{
+			"Lowercase", "Uppercase", "Digit", "Symbol",
+		},
+		ForbiddenWords: []string{"hippo"},
+	}
+
+	for _, tt := range []struct {
+		password, message string
+	}{
+		{password: "Short1!", message: "at least 12 characters"},
+		{password: "NODIGITSORLOWER", message: "lowercase character"},
+		{password: "NoDigitsAtAll!", message: "digit character"},
+		{password: "NoSymbols12345", message: "symbol character"},
+		{password: "MyRhino-12345", message: "name of its user"},
+		{password: "HIPPO-hungry-42", message: `cannot contain "hippo"`},
+	} {
+		err := checkPasswordPolicy(policy, "rhino", tt.password)
+		assert.ErrorContains(t, err, tt.message, "password %q", tt.password)
+	}
+
+	assert.NilError(t, checkPasswordPolicy(policy, "rhino", "Correct-Horse-42"))
+}
+
+func TestGeneratePolicyPassword(t *testing.T) {
+	t.Run("Length", func(t *testing.T) {
+		password, err := generatePolicyPassword(util.GenerateAlphaNumericPassword, nil, "rhino")
+		assert.NilError(t, err)
+		assert.Equal(t, len(password), util.DefaultGeneratedPasswordLength)
+
+		password, err = generatePolicyPassword(util.GenerateAlphaNumericPassword,
+			&v1beta1.PostgresPasswordPolicySpec{MinLength: initialize.Int32(40)}, "rhino")
+		assert.NilError(t, err)
+		assert.Equal(t, len(password), 40)
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		passwords := []string{"nosymbols", "S0me-Symbols"}
+		generate := func(int) (string, error) {
+			p := passwords[0]
+			passwords = passwords[1:]
+			return p, nil
+		}
+
+		password, err := generatePolicyPassword(generate,
+			&v1beta1.PostgresPasswordPolicySpec{
+				RequiredCharacters: []v1beta1.PostgresPasswordCharacters{"Symbol"},
+			}, "rhino")
+		assert.NilError(t, err)
+		assert.Equal(t, password, "S0me-Symbols")
+	})
+
+	t.Run("Impossible", func(t *testing.T) {
+		_, err := generatePolicyPassword(util.GenerateAlphaNumericPassword,
+			&v1beta1.PostgresPasswordPolicySpec{
+				RequiredCharacters: []v1beta1.PostgresPasswordCharacters{"Symbol"},
+			}, "rhino")
+		assert.ErrorContains(t, err, "unable to generate")
+		assert.Assert(t, !isPasswordPolicyError(err))
+	})
+}
+
+func TestGeneratePostgresUserSecretPasswordPolicy(t *testing.T) {
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	r := &Reconciler{Client: fake.NewClientBuilder().WithScheme(scheme).Build()}
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace = "ns1"
+	cluster.Name = "hippo"
+	cluster.Spec.Port = initialize.Int32(5432)
+	cluster.Spec.PasswordPolicy = &v1beta1.PostgresPasswordPolicySpec{
+		MinLength: initialize.Int32(30),
+	}
+	spec := &v1beta1.PostgresUserSpec{Name: "rhino"}
+
+	t.Run("Generated", func(t *testing.T) {
+		secret, err := r.generatePostgresUserSecret(cluster, spec, nil)
+		assert.NilError(t, err)
+		assert.Equal(t, len(secret.Data["password"]), 30)
+		assert.Assert(t, len(secret.Data["verifier"]) > 0)
+	})
+
+	t.Run("Changed", func(t *testing.T) {
+		existing := &corev1.Secret{Data: map[string][]byte{
+			"password": []byte("too-short"),
+		}}
+
+		_, err := r.generatePostgresUserSecret(cluster, spec, existing)
+		assert.Assert(t, isPasswordPolicyError(err))
+		assert.ErrorContains(t, err, "at least 30 characters")
+
+		existing.Data["password"] = []byte(strings.Repeat("long-enough", 3))
+		secret, err := r.generatePostgresUserSecret(cluster, spec, existing)
+		assert.NilError(t, err)
+		assert.Assert(t, len(secret.Data["verifier"]) > 0)
+	})
+
+	t.Run("Unchanged", func(t *testing.T) {
+		// Passwords that already have a verifier are not checked again.
+		existing := &corev1.Secret{Data: map[string][]byte{
+			"password": []byte("too-short"),
+			"verifier": []byte("SCRAM-SHA-256$x"),
+		}}
+
+		secret, err := r.generatePostgresUserSecret(cluster, spec, existing)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, secret.Data["verifier"], existing.Data["verifier"])
+	})
+}
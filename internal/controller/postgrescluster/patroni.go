@@ -17,6 +17,7 @@ package postgrescluster
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"time"
 
@@ -28,6 +29,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/kubeapi"
 	"github.com/crunchydata/postgres-operator/internal/logging"
 	"github.com/crunchydata/postgres-operator/internal/naming"
 	"github.com/crunchydata/postgres-operator/internal/patroni"
@@ -59,6 +61,7 @@ func (r *Reconciler) deletePatroniArtifacts(
 
 func (r *Reconciler) handlePatroniRestarts(
 	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+	window *maintenance,
 ) error {
 	const container = naming.ContainerDatabase
 	var primaryNeedsRestart, replicaNeedsRestart *Instance
@@ -86,6 +89,12 @@ func (r *Reconciler) handlePatroniRestarts(
 		}
 	}
 
+	// Restarts interrupt connections, so they wait for the maintenance window.
+	if (primaryNeedsRestart != nil || replicaNeedsRestart != nil) &&
+		!window.allow("restart PostgreSQL") {
+		return nil
+	}
+
 	// When the primary instance needs to restart, restart it and return early.
 	// Some PostgreSQL settings must be changed on the primary before any
 	// progress can be made on the replicas, e.g. decreasing "max_connections".
@@ -137,6 +146,86 @@ func (r *Reconciler) handlePatroniRestarts(
 	return nil
 }
 
+// +kubebuilder:rbac:groups="",resources=pods,verbs=patch
+
+// reconcileReplicaRemediation reinitializes one replica of cluster that Patroni
+// reports failed to start PostgreSQL. It waits for the cooldown of
+// spec.patroni.replicaRemediation between attempts on the same replica, and
+// does nothing without a running primary to copy from.
+func (r *Reconciler) reconcileReplicaRemediation(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) (reconcile.Result, error) {
+	const container = naming.ContainerDatabase
+	result := reconcile.Result{}
+
+	enabled, cooldown := patroni.ReplicaRemediation(cluster)
+	if !enabled {
+		return result, nil
+	}
+
+	var primary *Instance
+	for _, instance := range instances.forCluster {
+		if isPrimary, known := instance.IsPrimary(); isPrimary && known {
+			if running, known := instance.IsRunning(container); running && known {
+				primary = instance
+			}
+		}
+	}
+	if primary == nil {
+		return result, nil
+	}
+
+	now := time.Now()
+	for _, instance := range instances.forCluster {
+		if instance == primary || len(instance.Pods) == 0 ||
+			!patroni.PodFailedToStart(instance.Pods[0]) {
+			continue
+		}
+		if terminating, known := instance.IsTerminating(); terminating || !known {
+			continue
+		}
+
+		pod := instance.Pods[0]
+		if last, err := time.Parse(time.RFC3339,
+			pod.GetAnnotations()[naming.PatroniReinitialized]); err == nil {
+			if wait := last.Add(cooldown).Sub(now); wait > 0 {
+				result = updateReconcileResult(result, reconcile.Result{RequeueAfter: wait})
+				continue
+			}
+		}
+
+		// Record the attempt before making it so that a failure to reinitialize
+		// also waits for the cooldown.
+		patch, err := kubeapi.NewMergePatch().
+			Add("metadata", "annotations")(map[string]string{
+			naming.PatroniReinitialized: now.UTC().Format(time.RFC3339),
+		}).Bytes()
+		if err == nil {
+			err = r.patch(ctx, pod, client.RawPatch(client.Merge.Type(), patch))
+		}
+		if err != nil {
+			return result, errors.WithStack(err)
+		}
+
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventReplicaReinitialized,
+			"Reinitializing replica %q because PostgreSQL failed to start", pod.Name)
+
+		exec := patroni.Executor(func(
+			ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			pod := primary.Pods[0]
+			return r.PodExec(pod.Namespace, pod.Name, container, stdin, stdout, stderr, command...)
+		})
+
+		// Reinitialize one replica at a time. Another reconcile happens when its
+		// Pod reports a new state.
+		return result, errors.WithStack(
+			exec.ReinitializeMember(ctx, naming.PatroniScope(cluster), pod.Name))
+	}
+
+	return result, nil
+}
+
 // +kubebuilder:rbac:groups="",resources=services,verbs=create;patch
 
 // reconcilePatroniDistributedConfiguration sets labels and ownership on the
@@ -246,11 +335,6 @@ func (r *Reconciler) generatePatroniLeaderLeaseService(
 	// Patroni will ensure that they always route to the elected leader.
 	// - https://docs.k8s.io/concepts/services-networking/service/#services-without-selectors
 	service.Spec.Selector = nil
-	if cluster.Spec.Service != nil {
-		service.Spec.Type = corev1.ServiceType(cluster.Spec.Service.Type)
-	} else {
-		service.Spec.Type = corev1.ServiceTypeClusterIP
-	}
 
 	// The TargetPort must be the name (not the number) of the PostgreSQL
 	// ContainerPort. This name allows the port number to differ between
@@ -261,6 +345,7 @@ func (r *Reconciler) generatePatroniLeaderLeaseService(
 		Protocol:   corev1.ProtocolTCP,
 		TargetPort: intstr.FromString(naming.PortPostgreSQL),
 	}}
+	setServiceSpec(service, cluster.Spec.Service)
 
 	err := errors.WithStack(r.setControllerReference(cluster, service))
 	return service, err
@@ -296,10 +381,24 @@ func (r *Reconciler) reconcilePatroniStatus(
 	log := logging.FromContext(ctx)
 
 	var readyInstance bool
+	var leader string
 	for _, instance := range observedInstances.forCluster {
 		if r, _ := instance.IsReady(); r {
 			readyInstance = true
 		}
+		if writable, known := instance.IsWritable(); writable && known {
+			leader = instance.Name
+		}
+	}
+
+	// Record when the leader changes, whether by switchover or failover. Keep
+	// the previous value while there is no leader, e.g. during an election.
+	if leader != "" && leader != cluster.Status.Patroni.Leader {
+		if previous := cluster.Status.Patroni.Leader; previous != "" {
+			r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventPrimaryChanged,
+				"Primary changed from instance %q to %q", previous, leader)
+		}
+		cluster.Status.Patroni.Leader = leader
 	}
 
 	dcs := &corev1.Endpoints{ObjectMeta: naming.PatroniDistributedConfiguration(cluster)}
@@ -309,6 +408,10 @@ func (r *Reconciler) reconcilePatroniStatus(
 	if err == nil {
 		if dcs.Annotations["initialize"] != "" {
 			// After bootstrap, Patroni writes the cluster system identifier to DCS.
+			if cluster.Status.Patroni.SystemIdentifier != dcs.Annotations["initialize"] {
+				r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventBootstrapped,
+					"PostgreSQL initialized with system identifier %s", dcs.Annotations["initialize"])
+			}
 			cluster.Status.Patroni.SystemIdentifier = dcs.Annotations["initialize"]
 		} else if readyInstance {
 			// While we typically expect a value for the initialize key to be present in the
@@ -327,6 +430,76 @@ func (r *Reconciler) reconcilePatroniStatus(
 	return result, err
 }
 
+// +kubebuilder:rbac:groups="",resources="pods",verbs={patch}
+
+// reconcileReplicaLag labels each Pod of cluster according to whether it is too
+// far behind the primary to serve reads. When spec.replicaServiceMaxLag is set,
+// the replica Service selects only Pods that are not lagging. Replication lag
+// changes without any Kubernetes event, so the returned Result asks to check
+// again later.
+func (r *Reconciler) reconcileReplicaLag(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+	observed *observedInstances,
+) (reconcile.Result, error) {
+	// There is nothing to measure while the cluster is shutdown.
+	if cluster.Spec.ReplicaServiceMaxLag == nil ||
+		(cluster.Spec.Shutdown != nil && *cluster.Spec.Shutdown) {
+		return reconcile.Result{}, nil
+	}
+	result := reconcile.Result{RequeueAfter: 30 * time.Second}
+
+	// Any running member can report on all the others.
+	var running *corev1.Pod
+	for _, instance := range observed.forCluster {
+		if r, _ := instance.IsRunning(naming.ContainerDatabase); r && running == nil {
+			running = instance.Pods[0]
+		}
+	}
+	if running == nil {
+		return result, nil
+	}
+
+	exec := patroni.Executor(func(
+		ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error {
+		return r.PodExec(running.Namespace, running.Name, naming.ContainerDatabase,
+			stdin, stdout, stderr, command...)
+	})
+	members, err := exec.ListMembers(ctx, naming.PatroniScope(cluster))
+	if err != nil {
+		return result, errors.WithStack(err)
+	}
+
+	// Patroni reports lag in mebibytes; compare in bytes.
+	maxLag := cluster.Spec.ReplicaServiceMaxLag.Value()
+	lagging := make(map[string]bool, len(members))
+	for _, member := range members {
+		lagging[member.Name] = member.State != "running" ||
+			member.LagMB == nil || *member.LagMB*1024*1024 > maxLag
+	}
+
+	for _, instance := range observed.forCluster {
+		for _, pod := range instance.Pods {
+			// Members that Patroni does not know about are lagging, too.
+			value := "true"
+			if behind, ok := lagging[pod.Name]; ok && !behind {
+				value = "false"
+			}
+			if pod.Labels[naming.LabelReplicaLagging] == value {
+				continue
+			}
+
+			patch := client.RawPatch(client.Merge.Type(), []byte(fmt.Sprintf(
+				`{"metadata":{"labels":{%q:%q}}}`, naming.LabelReplicaLagging, value)))
+			if err == nil {
+				err = errors.WithStack(r.patch(ctx, pod, patch))
+			}
+		}
+	}
+
+	return result, err
+}
+
 // reconcileReplicationSecret creates a secret containing the TLS
 // certificate, key and CA certificate for use with the replication and
 // pg_rewind accounts in Postgres.
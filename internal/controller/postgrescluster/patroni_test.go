@@ -33,13 +33,18 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
 	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
 	"github.com/crunchydata/postgres-operator/internal/testing/require"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
@@ -340,7 +345,11 @@ func TestReconcilePatroniStatus(t *testing.T) {
 	require.ParallelCapacity(t, 0)
 
 	ns := setupNamespace(t, tClient)
-	r := &Reconciler{Client: tClient, Owner: client.FieldOwner(t.Name())}
+	r := &Reconciler{
+		Client:   tClient,
+		Owner:    client.FieldOwner(t.Name()),
+		Recorder: new(record.FakeRecorder),
+	}
 
 	systemIdentifier := "6952526174828511264"
 	createResources := func(index, readyReplicas int,
@@ -439,6 +448,202 @@ func TestReconcilePatroniStatus(t *testing.T) {
 	}
 }
 
+func TestReconcileReplicaLag(t *testing.T) {
+	ctx := context.Background()
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace, cluster.Name = "ns1", "hippo"
+
+	pod := func(name string) *corev1.Pod {
+		p := &corev1.Pod{}
+		p.Namespace, p.Name = "ns1", name
+		p.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name:  naming.ContainerDatabase,
+			State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+		}}
+		return p
+	}
+	pods := []*corev1.Pod{pod("one"), pod("two"), pod("three"), pod("four")}
+	observed := &observedInstances{}
+	for _, p := range pods {
+		observed.forCluster = append(observed.forCluster,
+			&Instance{Name: p.Name, Pods: []*corev1.Pod{p}})
+	}
+
+	calls := 0
+	reconciler := &Reconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(pods[0], pods[1], pods[2], pods[3]).Build(),
+		PodExec: func(
+			namespace, pod, container string,
+			stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			calls++
+			assert.Equal(t, container, naming.ContainerDatabase)
+			assert.Equal(t, strings.Join(command, " "), "patronictl list --format=json hippo-ha")
+			_, _ = stdout.Write([]byte(`[
+				{"Member": "one", "Role": "Leader", "State": "running"},
+				{"Member": "two", "Role": "Replica", "State": "running", "Lag in MB": 1},
+				{"Member": "three", "Role": "Replica", "State": "running", "Lag in MB": 100}
+			]`))
+			return nil
+		},
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		result, err := reconciler.reconcileReplicaLag(ctx, cluster, observed)
+		assert.NilError(t, err)
+		assert.Equal(t, result, reconcile.Result{})
+		assert.Equal(t, calls, 0)
+	})
+
+	t.Run("Shutdown", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		limit := resource.MustParse("16Mi")
+		cluster.Spec.ReplicaServiceMaxLag = &limit
+		cluster.Spec.Shutdown = initialize.Bool(true)
+
+		result, err := reconciler.reconcileReplicaLag(ctx, cluster, observed)
+		assert.NilError(t, err)
+		assert.Equal(t, result, reconcile.Result{})
+		assert.Equal(t, calls, 0)
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		limit := resource.MustParse("16Mi")
+		cluster.Spec.ReplicaServiceMaxLag = &limit
+
+		result, err := reconciler.reconcileReplicaLag(ctx, cluster, observed)
+		assert.NilError(t, err)
+		assert.Assert(t, result.RequeueAfter > 0)
+		assert.Equal(t, calls, 1)
+
+		for name, expected := range map[string]string{
+			"one": "true", "two": "false", "three": "true", "four": "true",
+		} {
+			var p corev1.Pod
+			assert.NilError(t, reconciler.Client.Get(ctx,
+				client.ObjectKey{Namespace: "ns1", Name: name}, &p))
+			assert.Equal(t, p.Labels[naming.LabelReplicaLagging], expected, "pod %q", name)
+		}
+	})
+}
+
+func TestReconcileReplicaRemediation(t *testing.T) {
+	ctx := context.Background()
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace, cluster.Name = "ns1", "hippo"
+
+	pod := func(name, role, state string) *corev1.Pod {
+		p := &corev1.Pod{}
+		p.Namespace, p.Name = "ns1", name
+		p.Labels = map[string]string{naming.LabelRole: role}
+		p.Annotations = map[string]string{"status": `{"state":"` + state + `"}`}
+		p.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name:  naming.ContainerDatabase,
+			State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+		}}
+		return p
+	}
+	observe := func(pods ...*corev1.Pod) *observedInstances {
+		observed := &observedInstances{}
+		for _, p := range pods {
+			observed.forCluster = append(observed.forCluster,
+				&Instance{Name: p.Name, Pods: []*corev1.Pod{p}})
+		}
+		return observed
+	}
+
+	var commands []string
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{
+		Recorder: recorder,
+		PodExec: func(
+			namespace, pod, container string,
+			stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Equal(t, pod, "one", "expected exec on the primary")
+			commands = append(commands, strings.Join(command, " "))
+			return nil
+		},
+	}
+
+	t.Run("Healthy", func(t *testing.T) {
+		commands = nil
+		pods := []*corev1.Pod{pod("one", "master", "running"), pod("two", "replica", "running")}
+		reconciler.Client = fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(pods[0], pods[1]).Build()
+
+		result, err := reconciler.reconcileReplicaRemediation(ctx, cluster, observe(pods...))
+		assert.NilError(t, err)
+		assert.Equal(t, result, reconcile.Result{})
+		assert.Equal(t, len(commands), 0)
+	})
+
+	t.Run("NoPrimary", func(t *testing.T) {
+		commands = nil
+		pods := []*corev1.Pod{pod("two", "replica", "start failed")}
+		reconciler.Client = fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(pods[0]).Build()
+
+		result, err := reconciler.reconcileReplicaRemediation(ctx, cluster, observe(pods...))
+		assert.NilError(t, err)
+		assert.Equal(t, result, reconcile.Result{})
+		assert.Equal(t, len(commands), 0)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		commands = nil
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Patroni = &v1beta1.PatroniSpec{
+			ReplicaRemediation: &v1beta1.PatroniReplicaRemediation{
+				Enabled: initialize.Bool(false),
+			},
+		}
+		pods := []*corev1.Pod{pod("one", "master", "running"), pod("two", "replica", "start failed")}
+		reconciler.Client = fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(pods[0], pods[1]).Build()
+
+		result, err := reconciler.reconcileReplicaRemediation(ctx, cluster, observe(pods...))
+		assert.NilError(t, err)
+		assert.Equal(t, result, reconcile.Result{})
+		assert.Equal(t, len(commands), 0)
+	})
+
+	t.Run("FailedToStart", func(t *testing.T) {
+		commands = nil
+		pods := []*corev1.Pod{pod("one", "master", "running"), pod("two", "replica", "start failed")}
+		reconciler.Client = fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(pods[0], pods[1]).Build()
+
+		result, err := reconciler.reconcileReplicaRemediation(ctx, cluster, observe(pods...))
+		assert.NilError(t, err)
+		assert.Equal(t, result, reconcile.Result{})
+		assert.DeepEqual(t, commands, []string{"patronictl reinit --force hippo-ha two"})
+
+		var p corev1.Pod
+		assert.NilError(t, reconciler.Client.Get(ctx,
+			client.ObjectKey{Namespace: "ns1", Name: "two"}, &p))
+		assert.Assert(t, p.Annotations[naming.PatroniReinitialized] != "")
+
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "ReplicaReinitialized"))
+
+		// The same replica waits for the cooldown.
+		commands = nil
+		result, err = reconciler.reconcileReplicaRemediation(ctx, cluster, observe(pods...))
+		assert.NilError(t, err)
+		assert.Assert(t, result.RequeueAfter > 9*time.Minute)
+		assert.Equal(t, len(commands), 0)
+	})
+}
+
 func TestReconcilePatroniSwitchover(t *testing.T) {
 	_, client := setupKubernetes(t)
 	require.ParallelCapacity(t, 0)
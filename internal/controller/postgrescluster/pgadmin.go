@@ -149,12 +149,6 @@ func (r *Reconciler) generatePGAdminService(
 		naming.LabelCluster: cluster.Name,
 		naming.LabelRole:    naming.RolePGAdmin,
 	}
-	if spec := cluster.Spec.UserInterface.PGAdmin.Service; spec != nil {
-		service.Spec.Type = corev1.ServiceType(spec.Type)
-	} else {
-		service.Spec.Type = corev1.ServiceTypeClusterIP
-	}
-
 	// The TargetPort must be the name (not the number) of the pgAdmin
 	// ContainerPort. This name allows the port number to differ between Pods,
 	// which can happen during a rolling update.
@@ -167,6 +161,7 @@ func (r *Reconciler) generatePGAdminService(
 		Protocol:   corev1.ProtocolTCP,
 		TargetPort: intstr.FromString(naming.PortPGAdmin),
 	}}
+	setServiceSpec(service, cluster.Spec.UserInterface.PGAdmin.Service)
 
 	err := errors.WithStack(r.setControllerReference(cluster, service))
 
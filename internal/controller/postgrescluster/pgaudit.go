@@ -0,0 +1,89 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/config"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// addPGAuditToInstancePodSpec adds a Fluent Bit container that ships pgAudit
+// log entries when that is enabled in cluster.
+func addPGAuditToInstancePodSpec(
+	cluster *v1beta1.PostgresCluster, outPod *corev1.PodSpec,
+) {
+	if cluster.Spec.Audit == nil || cluster.Spec.Audit.LogShipping == nil {
+		return
+	}
+	shipping := cluster.Spec.Audit.LogShipping
+
+	// Follow every log file that PostgreSQL writes and keep only the entries
+	// written by pgAudit. Offsets are kept in a temporary directory, so some
+	// entries may be sent again after the container restarts.
+	// - https://docs.fluentbit.io/manual/pipeline/inputs/tail
+	// - https://docs.fluentbit.io/manual/pipeline/filters/grep
+	command := []string{
+		"/fluent-bit/bin/fluent-bit",
+		"--input=tail",
+		"--prop=path=" + naming.PostgresPGDataLogPath + "/*.log",
+		"--prop=db=/tmp/pgaudit-log-shipper.db",
+		"--prop=tag=pgaudit",
+		"--filter=grep",
+		"--prop=regex=log AUDIT:",
+		"--match=pgaudit",
+		"--output=" + shipping.Output,
+	}
+
+	// Sort the output parameters so the command is the same every time.
+	keys := make([]string, 0, len(shipping.Parameters))
+	for key := range shipping.Parameters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		command = append(command, "--prop="+key+"="+shipping.Parameters[key])
+	}
+	command = append(command, "--match=pgaudit")
+
+	// Mount the data volume read-only; Fluent Bit never writes to it.
+	data := postgres.DataVolumeMount()
+	data.ReadOnly = true
+
+	// The Fluent Bit image runs as root by default. Run it as the same user as
+	// PostgreSQL so that it can start and read the log files. OpenShift assigns
+	// a user to every container based on a SecurityContextConstraint.
+	securityContext := initialize.RestrictedSecurityContext()
+	if cluster.Spec.OpenShift == nil || !*cluster.Spec.OpenShift {
+		securityContext.RunAsUser = initialize.Int64(26)
+	}
+
+	outPod.Containers = append(outPod.Containers, corev1.Container{
+		Name:            naming.ContainerPGAuditLogShipper,
+		Image:           config.FluentBitContainerImage(cluster),
+		ImagePullPolicy: cluster.Spec.ImagePullPolicy,
+		Command:         command,
+		Resources:       shipping.Resources,
+		SecurityContext: securityContext,
+		VolumeMounts:    []corev1.VolumeMount{data},
+	})
+}
@@ -0,0 +1,96 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestAddPGAuditToInstancePodSpec(t *testing.T) {
+	cluster := testCluster()
+
+	t.Run("Disabled", func(t *testing.T) {
+		pod := new(corev1.PodSpec)
+		addPGAuditToInstancePodSpec(cluster, pod)
+		assert.Equal(t, len(pod.Containers), 0)
+
+		cluster.Spec.Audit = &v1beta1.PGAuditSpec{}
+		addPGAuditToInstancePodSpec(cluster, pod)
+		assert.Equal(t, len(pod.Containers), 0)
+	})
+
+	t.Run("LogShipping", func(t *testing.T) {
+		cluster.Spec.Audit = &v1beta1.PGAuditSpec{
+			LogShipping: &v1beta1.PGAuditLogShippingSpec{
+				Image:  "fluent/fluent-bit",
+				Output: "http",
+				Parameters: map[string]string{
+					"port": "9880",
+					"host": "collector.logging.svc",
+				},
+			},
+		}
+
+		pod := new(corev1.PodSpec)
+		addPGAuditToInstancePodSpec(cluster, pod)
+
+		assert.Assert(t, marshalMatches(pod.Containers, `
+- command:
+  - /fluent-bit/bin/fluent-bit
+  - --input=tail
+  - --prop=path=/pgdata/pglogs/*.log
+  - --prop=db=/tmp/pgaudit-log-shipper.db
+  - --prop=tag=pgaudit
+  - --filter=grep
+  - '--prop=regex=log AUDIT:'
+  - --match=pgaudit
+  - --output=http
+  - --prop=host=collector.logging.svc
+  - --prop=port=9880
+  - --match=pgaudit
+  image: fluent/fluent-bit
+  name: pgaudit-log-shipper
+  resources: {}
+  securityContext:
+    allowPrivilegeEscalation: false
+    privileged: false
+    readOnlyRootFilesystem: true
+    runAsNonRoot: true
+    runAsUser: 26
+    seccompProfile:
+      type: RuntimeDefault
+  volumeMounts:
+  - mountPath: /pgdata
+    name: postgres-data
+    readOnly: true
+		`))
+
+		t.Run("OpenShift", func(t *testing.T) {
+			cluster := cluster.DeepCopy()
+			cluster.Spec.OpenShift = initialize.Bool(true)
+
+			pod := new(corev1.PodSpec)
+			addPGAuditToInstancePodSpec(cluster, pod)
+			assert.Assert(t, pod.Containers[0].SecurityContext.RunAsUser == nil)
+		})
+	})
+}
@@ -22,6 +22,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -37,11 +38,13 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/yaml"
 
 	"github.com/crunchydata/postgres-operator/internal/config"
 	"github.com/crunchydata/postgres-operator/internal/initialize"
@@ -79,6 +82,11 @@ const (
 	// and in-place pgBackRest restore is in progress
 	ConditionPGBackRestRestoreProgressing = "PGBackRestoreProgressing"
 
+	// ConditionPGBackRestRestoreSuccessful is the type used in a condition to indicate whether or
+	// not the in-place restore for the current restore ID (as provided via annotation) was
+	// successful
+	ConditionPGBackRestRestoreSuccessful = "PGBackRestRestoreSuccessful"
+
 	// EventRepoHostNotFound is used to indicate that a pgBackRest repository was not
 	// found when reconciling
 	EventRepoHostNotFound = "RepoDeploymentNotFound"
@@ -95,6 +103,17 @@ const (
 	// completes successfully
 	EventStanzasCreated = "StanzasCreated"
 
+	// EventBackupSucceeded is the event reason utilized when a pgBackRest backup Job completes
+	// successfully
+	EventBackupSucceeded = "BackupSucceeded"
+
+	// EventBackupFailed is the event reason utilized when a pgBackRest backup Job fails
+	EventBackupFailed = "BackupFailed"
+
+	// EventBackupStopped is the event reason utilized when PGO stops the pgBackRest processes of
+	// a backup Job that ran past its deadline
+	EventBackupStopped = "BackupStopped"
+
 	// EventUnableToCreatePGBackRestCronJob is the event reason utilized when a pgBackRest backup
 	// CronJob fails to create successfully
 	EventUnableToCreatePGBackRestCronJob = "UnableToCreatePGBackRestCronJob"
@@ -444,10 +463,20 @@ func (r *Reconciler) setScheduledJobStatus(ctx context.Context,
 		return
 	}
 
+	// Remember the Jobs that had already finished so that each is counted once.
+	finished := map[string]bool{}
+	if postgresCluster.Status.PGBackRest != nil {
+		for _, sbs := range postgresCluster.Status.PGBackRest.ScheduledBackups {
+			if sbs.StartTime != nil && sbs.Active == 0 && (sbs.Succeeded > 0 || sbs.Failed > 0) {
+				finished[sbs.CronJobName+" "+sbs.StartTime.String()] = true
+			}
+		}
+	}
+
 	// TODO(tjmoore4): PGBackRestScheduledBackupStatus can likely be combined with
 	// PGBackRestJobStatus as they both contain most of the same information
 	scheduledStatus := []v1beta1.PGBackRestScheduledBackupStatus{}
-	for _, job := range jobList.Items {
+	for i, job := range jobList.Items {
 		// we only care about the scheduled backup Jobs created by the
 		// associated CronJobs
 		sbs := v1beta1.PGBackRestScheduledBackupStatus{}
@@ -462,6 +491,13 @@ func (r *Reconciler) setScheduledJobStatus(ctx context.Context,
 			sbs.Active = job.Status.Active
 			sbs.Succeeded = job.Status.Succeeded
 			sbs.Failed = job.Status.Failed
+			sbs.Phase, sbs.Message = jobPhase(&jobList.Items[i])
+
+			completed, failed := jobCompleted(&jobList.Items[i]), jobFailed(&jobList.Items[i])
+			if (completed || failed) && sbs.StartTime != nil &&
+				!finished[sbs.CronJobName+" "+sbs.StartTime.String()] {
+				r.recordBackupJobFinished(postgresCluster, backupTypeScheduled, completed)
+			}
 
 			scheduledStatus = append(scheduledStatus, sbs)
 		}
@@ -728,6 +764,14 @@ func generateBackupJobSpecIntent(postgresCluster *v1beta1.PostgresCluster,
 			*postgresCluster.Spec.Backups.PGBackRest.Jobs.PriorityClassName
 	}
 
+	// Stop the backup when it runs longer than allowed. Kubernetes deletes the
+	// Pod of the Job, but the backup it started keeps running in another Pod;
+	// see [Reconciler.reconcileBackupDeadlines].
+	if postgresCluster.Spec.Backups.PGBackRest.Jobs != nil {
+		jobSpec.ActiveDeadlineSeconds =
+			postgresCluster.Spec.Backups.PGBackRest.Jobs.ActiveDeadlineSeconds
+	}
+
 	// Set the image pull secrets, if any exist.
 	// This is set here rather than using the service account due to the lack
 	// of propagation to existing pods when the CRD is updated:
@@ -744,6 +788,77 @@ func generateBackupJobSpecIntent(postgresCluster *v1beta1.PostgresCluster,
 	return jobSpec, nil
 }
 
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=list;patch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=list
+// +kubebuilder:rbac:groups="",resources=pods/exec,verbs=create
+
+// reconcileBackupDeadlines stops the pgBackRest processes of backup Jobs that
+// failed because they ran past spec.backups.pgbackrest.jobs.activeDeadlineSeconds.
+// A backup Job only executes pgBackRest in an instance or repository host Pod,
+// so the backup keeps running there and holds the stanza lock after Kubernetes
+// stops the Job. Each such Job is annotated once its processes are stopped.
+func (r *Reconciler) reconcileBackupDeadlines(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+) error {
+	backups, _ := labels.NewRequirement(naming.LabelPGBackRestBackup, selection.Exists, nil)
+	jobs := &batchv1.JobList{}
+	err := errors.WithStack(r.Client.List(ctx, jobs,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabelsSelector{
+			Selector: naming.PGBackRestSelector(cluster.Name).Add(*backups),
+		}))
+
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		if err != nil || !jobDeadlineExceeded(job) ||
+			job.Annotations[naming.PGBackRestBackupStopped] != "" {
+			continue
+		}
+
+		var repo *v1beta1.PGBackRestRepo
+		for j := range cluster.Spec.Backups.PGBackRest.Repos {
+			if cluster.Spec.Backups.PGBackRest.Repos[j].Name == job.Labels[naming.LabelPGBackRestRepo] {
+				repo = &cluster.Spec.Backups.PGBackRest.Repos[j]
+			}
+		}
+		if repo == nil {
+			continue
+		}
+
+		// Find the Pod where the Job executed pgBackRest.
+		selector, container, selectorErr := getPGBackRestExecSelector(cluster, *repo)
+		pods := &corev1.PodList{}
+		err = errors.WithStack(selectorErr)
+		if err == nil {
+			err = errors.WithStack(r.Client.List(ctx, pods,
+				client.InNamespace(cluster.Namespace),
+				client.MatchingLabelsSelector{Selector: selector}))
+		}
+		if err != nil || len(pods.Items) != 1 {
+			continue
+		}
+
+		pod := &pods.Items[0]
+		err = pgbackrest.Executor(func(
+			ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			return r.PodExec(pod.Namespace, pod.Name, container, stdin, stdout, stderr, command...)
+		}).Stop(ctx)
+
+		if err == nil {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventBackupStopped,
+				"Stopped the backup of Job %q after it ran past its deadline", job.Name)
+
+			before := job.DeepCopy()
+			job.Annotations = naming.Merge(job.Annotations,
+				map[string]string{naming.PGBackRestBackupStopped: "true"})
+			err = errors.WithStack(r.Client.Patch(ctx, job, client.MergeFrom(before)))
+		}
+	}
+
+	return err
+}
+
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=list;delete
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=list;delete
 // +kubebuilder:rbac:groups="",resources=endpoints,verbs=get
@@ -811,6 +926,8 @@ func (r *Reconciler) observeRestoreEnv(ctx context.Context,
 			cluster.Status.PGBackRest.Restore.Succeeded = restoreJob.Status.Succeeded
 			cluster.Status.PGBackRest.Restore.Failed = restoreJob.Status.Failed
 			cluster.Status.PGBackRest.Restore.Active = restoreJob.Status.Active
+			cluster.Status.PGBackRest.Restore.Phase,
+				cluster.Status.PGBackRest.Restore.Message = jobPhase(restoreJob)
 			if completed || failed {
 				cluster.Status.PGBackRest.Restore.Finished = true
 			}
@@ -826,6 +943,15 @@ func (r *Reconciler) observeRestoreEnv(ctx context.Context,
 				Reason:             "PGBackRestRestoreComplete",
 				Message:            "pgBackRest restore completed successfully",
 			})
+			if cluster.Status.PGBackRest != nil && cluster.Status.PGBackRest.Restore != nil {
+				meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+					ObservedGeneration: cluster.GetGeneration(),
+					Type:               ConditionPGBackRestRestoreSuccessful,
+					Status:             metav1.ConditionTrue,
+					Reason:             "RestoreComplete",
+					Message:            "pgBackRest in-place restore completed successfully",
+				})
+			}
 			// TODO: remove guard with move to controller-runtime 0.9.0 https://issue.k8s.io/99714
 			if len(cluster.Status.Conditions) > 0 {
 				meta.RemoveStatusCondition(&cluster.Status.Conditions,
@@ -865,6 +991,15 @@ func (r *Reconciler) observeRestoreEnv(ctx context.Context,
 				Reason:             "PGBackRestRestoreFailed",
 				Message:            "pgBackRest restore failed",
 			})
+			if cluster.Status.PGBackRest != nil && cluster.Status.PGBackRest.Restore != nil {
+				meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+					ObservedGeneration: cluster.GetGeneration(),
+					Type:               ConditionPGBackRestRestoreSuccessful,
+					Status:             metav1.ConditionFalse,
+					Reason:             "RestoreFailed",
+					Message:            "pgBackRest in-place restore failed",
+				})
+			}
 		}
 	}
 
@@ -900,6 +1035,13 @@ func (r *Reconciler) prepareForRestore(ctx context.Context,
 		ID: restoreID,
 	}
 
+	// Remove the result of any previous restore.  It will be set again once the restore for the
+	// current ID finishes.
+	// TODO: remove guard with move to controller-runtime 0.9.0 https://issue.k8s.io/99714
+	if len(cluster.Status.Conditions) > 0 {
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, ConditionPGBackRestRestoreSuccessful)
+	}
+
 	// find all runners, the primary, and determine if the cluster is still running
 	var clusterRunning bool
 	runners := []*appsv1.StatefulSet{}
@@ -1003,7 +1145,7 @@ func (r *Reconciler) prepareForRestore(ctx context.Context,
 // order to populate a PGDATA directory.
 func (r *Reconciler) reconcileRestoreJob(ctx context.Context,
 	cluster *v1beta1.PostgresCluster, sourceCluster *v1beta1.PostgresCluster,
-	pgdataVolume, pgwalVolume *corev1.PersistentVolumeClaim,
+	pgdataVolume, pgwalVolume, repoVolume *corev1.PersistentVolumeClaim,
 	dataSource *v1beta1.PostgresClusterDataSource,
 	instanceName, instanceSetName, configHash, stanzaName string) error {
 
@@ -1031,7 +1173,7 @@ func (r *Reconciler) reconcileRestoreJob(ctx context.Context,
 				"option "
 		}
 		if msg != "" {
-			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "InvalidDataSource", msg, repoName)
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventInvalidDataSource, msg, repoName)
 			return nil
 		}
 	}
@@ -1111,12 +1253,32 @@ func (r *Reconciler) reconcileRestoreJob(ctx context.Context,
 		volumeMounts = append(volumeMounts, walVolumeMount)
 	}
 
+	// restoring from a volume repo requires that repo's PVC to be mounted locally
+	if repoVolume != nil {
+		repoVolumeMount := pgbackrest.RepoVolumeMount()
+		volumes = append(volumes, corev1.Volume{
+			Name: repoVolumeMount.Name,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: repoVolume.GetName(),
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, repoVolumeMount)
+	}
+
 	restoreJob := &batchv1.Job{}
 	if err := r.generateRestoreJobIntent(cluster, configHash, instanceName, cmd,
 		volumeMounts, volumes, dataSource, restoreJob); err != nil {
 		return errors.WithStack(err)
 	}
 
+	// pgBackRest restores tablespaces to their original location. The files
+	// of the temporary tablespace are recreated when PostgreSQL starts, so an
+	// emptyDir is enough for the restore.
+	postgres.AddTempVolumeToPod(cluster, nil, &restoreJob.Spec.Template.Spec,
+		naming.PGBackRestRestoreContainerName)
+
 	// add pgBackRest configs to template
 	pgbackrest.AddConfigToRestorePod(cluster, sourceCluster, &restoreJob.Spec.Template.Spec)
 
@@ -1348,12 +1510,20 @@ func (r *Reconciler) reconcilePGBackRest(ctx context.Context,
 		result = updateReconcileResult(result, reconcile.Result{Requeue: true})
 	}
 
+	// Stop the backups of Jobs that ran past their deadline so they release their locks.
+	if err := r.reconcileBackupDeadlines(ctx, postgresCluster); err != nil {
+		log.Error(err, "unable to stop backups that exceeded their deadline")
+		result = updateReconcileResult(result, reconcile.Result{Requeue: true})
+	}
+
 	// Reconcile a manual backup as defined in the spec, and triggered by the end-user via
-	// annotation
-	if err := r.reconcileManualBackup(ctx, postgresCluster, repoResources.manualBackupJobs,
-		sa, instances); err != nil {
+	// annotation. When the backup is queued behind other manual backups, check again in a bit.
+	if queued, err := r.reconcileManualBackup(ctx, postgresCluster,
+		repoResources.manualBackupJobs, sa, instances); err != nil {
 		log.Error(err, "unable to reconcile manual backup")
 		result = updateReconcileResult(result, reconcile.Result{Requeue: true})
+	} else if queued {
+		result = updateReconcileResult(result, reconcile.Result{RequeueAfter: 30 * time.Second})
 	}
 
 	return result, nil
@@ -1460,7 +1630,7 @@ func (r *Reconciler) reconcilePostgresClusterDataSource(ctx context.Context,
 			client.ObjectKey{Name: sourceClusterName, Namespace: sourceClusterNamespace},
 			sourceCluster); err != nil {
 			if apierrors.IsNotFound(err) {
-				r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "InvalidDataSource",
+				r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventInvalidDataSource,
 					"PostgresCluster %q does not exist", sourceClusterName)
 				return nil
 			}
@@ -1483,7 +1653,7 @@ func (r *Reconciler) reconcilePostgresClusterDataSource(ctx context.Context,
 		}
 	}
 	if !foundRepo {
-		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "InvalidDataSource",
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventInvalidDataSource,
 			"PostgresCluster %q does not have a repo named %q defined",
 			sourceClusterName, sourceRepoName)
 		return nil
@@ -1506,7 +1676,7 @@ func (r *Reconciler) reconcilePostgresClusterDataSource(ctx context.Context,
 	}
 
 	// reconcile the pgBackRest restore Job to populate the cluster's data directory
-	if err := r.reconcileRestoreJob(ctx, cluster, sourceCluster, pgdata, pgwal,
+	if err := r.reconcileRestoreJob(ctx, cluster, sourceCluster, pgdata, pgwal, nil,
 		dataSource, instanceName, instanceSetName, configHash, pgbackrest.DefaultStanzaName); err != nil {
 		return errors.WithStack(err)
 	}
@@ -1595,6 +1765,23 @@ func (r *Reconciler) reconcileCloudBasedDataSource(ctx context.Context,
 		return errors.WithStack(err)
 	}
 
+	// A volume repo has no storage outside of Kubernetes, so restoring from one requires
+	// an existing PVC, e.g. the repo volume of a cluster that has since been deleted.
+	// That PVC is defined by the pgBackRest volume of the data source.
+	var repoVolume *corev1.PersistentVolumeClaim
+	if dataSource.Repo.Volume != nil {
+		volumes := cluster.Spec.DataSource.Volumes
+		if volumes == nil || volumes.PGBackRestVolume == nil {
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, EventInvalidDataSource,
+				"Restoring from a volume repo requires an existing pgBackRestVolume")
+			return nil
+		}
+		repoVolume = &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+			Name:      volumes.PGBackRestVolume.PVCName,
+			Namespace: cluster.GetNamespace(),
+		}}
+	}
+
 	// The `reconcileRestoreJob` was originally designed to take a PostgresClusterDataSource
 	// and rather than reconfigure that func's signature, we translate the PGBackRestDataSource
 	tmpDataSource := &v1beta1.PostgresClusterDataSource{
@@ -1608,7 +1795,7 @@ func (r *Reconciler) reconcileCloudBasedDataSource(ctx context.Context,
 
 	// reconcile the pgBackRest restore Job to populate the cluster's data directory
 	// Note that the 'source cluster' is nil as this is not used by this restore type.
-	if err := r.reconcileRestoreJob(ctx, cluster, nil, pgdata, pgwal, tmpDataSource,
+	if err := r.reconcileRestoreJob(ctx, cluster, nil, pgdata, pgwal, repoVolume, tmpDataSource,
 		instanceName, instanceSetName, configHash, dataSource.Stanza); err != nil {
 		return errors.WithStack(err)
 	}
@@ -2044,10 +2231,11 @@ func (r *Reconciler) reconcileDedicatedRepoHost(ctx context.Context,
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=create;patch;delete
 
 // reconcileManualBackup is responsible for reconciling pgBackRest backups that are initiated
-// manually by the end-user
+// manually by the end-user. It returns true when the backup is waiting for other manual backups
+// to finish, and should be reconciled again later.
 func (r *Reconciler) reconcileManualBackup(ctx context.Context,
 	postgresCluster *v1beta1.PostgresCluster, manualBackupJobs []*batchv1.Job,
-	serviceAccount *corev1.ServiceAccount, instances *observedInstances) error {
+	serviceAccount *corev1.ServiceAccount, instances *observedInstances) (bool, error) {
 
 	manualAnnotation := postgresCluster.GetAnnotations()[naming.PGBackRestBackup]
 	manualStatus := postgresCluster.Status.PGBackRest.ManualBackup
@@ -2087,7 +2275,9 @@ func (r *Reconciler) reconcileManualBackup(ctx context.Context,
 			manualStatus.Succeeded = currentBackupJob.Status.Succeeded
 			manualStatus.Failed = currentBackupJob.Status.Failed
 			manualStatus.Active = currentBackupJob.Status.Active
-			if completed || failed {
+			manualStatus.Phase, manualStatus.Message = jobPhase(currentBackupJob)
+			if (completed || failed) && !manualStatus.Finished {
+				r.recordBackupJobFinished(postgresCluster, backupTypeManual, completed)
 				manualStatus.Finished = true
 			}
 		}
@@ -2099,7 +2289,7 @@ func (r *Reconciler) reconcileManualBackup(ctx context.Context,
 		// per a new value for the annotation (unless the user manually deletes the Job).
 		if completed || failed {
 			if manualAnnotation != "" && backupID != manualAnnotation {
-				return errors.WithStack(r.Client.Delete(ctx, currentBackupJob,
+				return false, errors.WithStack(r.Client.Delete(ctx, currentBackupJob,
 					client.PropagationPolicy(metav1.DeletePropagationBackground)))
 			}
 		}
@@ -2126,7 +2316,7 @@ func (r *Reconciler) reconcileManualBackup(ctx context.Context,
 	// Pods in the cluster for leader election events, and trigger reconciles accordingly.
 	if !clusterWritable || manualAnnotation == "" ||
 		postgresCluster.Spec.Backups.PGBackRest.Manual == nil {
-		return nil
+		return false, nil
 	}
 
 	// if there is an existing status, see if a new backup id has been provided, and if so reset
@@ -2148,7 +2338,7 @@ func (r *Reconciler) reconcileManualBackup(ctx context.Context,
 	// if the status shows the Job is no longer in progress, then simply exit (which means a Job
 	// that has reached a "completed" or "failed" status is no longer reconciled)
 	if manualStatus != nil && manualStatus.Finished {
-		return nil
+		return false, nil
 	}
 
 	// determine if the dedicated repository host is ready (if enabled) using the repo host ready
@@ -2156,7 +2346,7 @@ func (r *Reconciler) reconcileManualBackup(ctx context.Context,
 	if pgbackrest.DedicatedRepoHostEnabled(postgresCluster) {
 		condition := meta.FindStatusCondition(postgresCluster.Status.Conditions, ConditionRepoHostReady)
 		if condition == nil || condition.Status != metav1.ConditionTrue {
-			return nil
+			return false, nil
 		}
 	}
 
@@ -2165,7 +2355,7 @@ func (r *Reconciler) reconcileManualBackup(ctx context.Context,
 	condition := meta.FindStatusCondition(postgresCluster.Status.Conditions,
 		ConditionReplicaCreate)
 	if condition == nil || condition.Status != metav1.ConditionTrue {
-		return nil
+		return false, nil
 	}
 
 	// Verify that status exists for the repo configured for the manual backup, and that a stanza
@@ -2182,15 +2372,15 @@ func (r *Reconciler) reconcileManualBackup(ctx context.Context,
 		}
 	}
 	if !statusFound {
-		r.Recorder.Eventf(postgresCluster, corev1.EventTypeWarning, "InvalidBackupRepo",
+		r.Recorder.Eventf(postgresCluster, corev1.EventTypeWarning, EventInvalidBackupRepo,
 			"Unable to find status for %q as configured for a manual backup.  Please ensure "+
 				"this repo is defined in the spec.", repoName)
-		return nil
+		return false, nil
 	}
 	if !stanzaCreated {
-		r.Recorder.Eventf(postgresCluster, corev1.EventTypeWarning, "StanzaNotCreated",
+		r.Recorder.Eventf(postgresCluster, corev1.EventTypeWarning, EventStanzaNotCreated,
 			"Stanza not created for %q as specified for a manual backup", repoName)
-		return nil
+		return false, nil
 	}
 
 	var repo v1beta1.PGBackRestRepo
@@ -2200,7 +2390,7 @@ func (r *Reconciler) reconcileManualBackup(ctx context.Context,
 		}
 	}
 	if repo.Name == "" {
-		return errors.Errorf("repo %q is not defined for this cluster", repoName)
+		return false, errors.Errorf("repo %q is not defined for this cluster", repoName)
 	}
 
 	// Users should specify the repo for the command using the "manual.repoName" field in the spec,
@@ -2210,10 +2400,29 @@ func (r *Reconciler) reconcileManualBackup(ctx context.Context,
 	backupOpts := postgresCluster.Spec.Backups.PGBackRest.Manual.Options
 	for _, opt := range backupOpts {
 		if strings.Contains(opt, "--repo") {
-			r.Recorder.Eventf(postgresCluster, corev1.EventTypeWarning, "InvalidManualBackup",
+			r.Recorder.Eventf(postgresCluster, corev1.EventTypeWarning, EventInvalidManualBackup,
 				"Option '--repo' is not allowed: please use the 'repoName' field instead.",
 				repoName)
-			return nil
+			return false, nil
+		}
+	}
+
+	// When there is no Job for this backup yet, wait for a free slot if too many manual backups
+	// are already running. Hold the lock until the Job is created so that concurrent reconciles
+	// cannot take the same slot.
+	limited := currentBackupJob == nil && r.MaxConcurrentBackups > 0
+	if limited {
+		r.manualBackups.Lock()
+		defer r.manualBackups.Unlock()
+
+		running, err := r.runningManualBackups(ctx)
+		if err != nil {
+			return false, err
+		}
+		if running >= r.MaxConcurrentBackups {
+			r.Recorder.Eventf(postgresCluster, corev1.EventTypeNormal, EventManualBackupQueued,
+				"Waiting for %d running manual backups to finish", running)
+			return true, nil
 		}
 	}
 
@@ -2240,7 +2449,7 @@ func (r *Reconciler) reconcileManualBackup(ctx context.Context,
 	spec, err := generateBackupJobSpecIntent(postgresCluster, repo,
 		serviceAccount.GetName(), labels, annotations, backupOpts...)
 	if err != nil {
-		return errors.WithStack(err)
+		return false, errors.WithStack(err)
 	}
 	backupJob.Spec = *spec
 
@@ -2248,15 +2457,191 @@ func (r *Reconciler) reconcileManualBackup(ctx context.Context,
 	backupJob.SetGroupVersionKind(batchv1.SchemeGroupVersion.WithKind("Job"))
 	if err := controllerutil.SetControllerReference(postgresCluster, backupJob,
 		r.Client.Scheme()); err != nil {
-		return errors.WithStack(err)
+		return false, errors.WithStack(err)
 	}
 
 	// server-side apply the backup Job intent
 	if err := r.apply(ctx, backupJob); err != nil {
-		return errors.WithStack(err)
+		return false, errors.WithStack(err)
 	}
 
-	return nil
+	if limited {
+		r.manualBackups.created(client.ObjectKeyFromObject(backupJob), time.Now())
+	}
+
+	return false, nil
+}
+
+// manualBackupJobs remembers the manual backup Jobs this process created. The
+// cache of Jobs can lag behind the API, and a Job that is not in the cache yet
+// still takes a slot of Reconciler.MaxConcurrentBackups.
+type manualBackupJobs struct {
+	sync.Mutex
+	pending map[client.ObjectKey]time.Time
+}
+
+// created records that the Job named key was created at now. Callers must hold
+// the lock.
+func (m *manualBackupJobs) created(key client.ObjectKey, now time.Time) {
+	if m.pending == nil {
+		m.pending = make(map[client.ObjectKey]time.Time)
+	}
+	m.pending[key] = now
+}
+
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=list
+
+// runningManualBackups returns the number of manual backup Jobs, across all
+// PostgresClusters, that have neither completed nor failed. Jobs created by
+// this process that are not in the cache yet are counted as running. Callers
+// must hold the lock of r.manualBackups.
+func (r *Reconciler) runningManualBackups(ctx context.Context) (int, error) {
+	jobs := &batchv1.JobList{}
+	if err := r.Client.List(ctx, jobs, client.MatchingLabels{
+		naming.LabelPGBackRestBackup: string(naming.BackupManual),
+	}); err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	var running int
+	for i := range jobs.Items {
+		delete(r.manualBackups.pending, client.ObjectKeyFromObject(&jobs.Items[i]))
+
+		if !jobCompleted(&jobs.Items[i]) && !jobFailed(&jobs.Items[i]) {
+			running++
+		}
+	}
+
+	// A Job that was deleted before the cache saw it never appears; forget it
+	// after a while.
+	for key, created := range r.manualBackups.pending {
+		if time.Since(created) > time.Minute {
+			delete(r.manualBackups.pending, key)
+		} else {
+			running++
+		}
+	}
+	return running, nil
+}
+
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=create;patch
+
+// reconcileFinalBackup takes a full pgBackRest backup of a cluster that is being
+// deleted and records how to restore it in a ConfigMap that outlives the cluster.
+// It returns (nil, nil) when there is no final backup to take or it is complete.
+func (r *Reconciler) reconcileFinalBackup(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+) (*reconcile.Result, error) {
+	final := cluster.Spec.Backups.PGBackRest.FinalBackup
+	if final == nil {
+		return nil, nil
+	}
+
+	// The backup Job connects to a running PostgreSQL instance, and instances
+	// do not start while the cluster is being deleted.
+	if cluster.Spec.Shutdown != nil && *cluster.Spec.Shutdown {
+		r.Recorder.Event(cluster, corev1.EventTypeWarning, EventFinalBackupSkipped,
+			"The cluster is shut down; deleting without a final backup")
+		return nil, nil
+	}
+
+	// Wait for the spec to change when the backup cannot be taken as configured.
+	var repo v1beta1.PGBackRestRepo
+	for i := range cluster.Spec.Backups.PGBackRest.Repos {
+		if cluster.Spec.Backups.PGBackRest.Repos[i].Name == final.RepoName {
+			repo = cluster.Spec.Backups.PGBackRest.Repos[i]
+		}
+	}
+	if repo.Name == "" {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventInvalidBackupRepo,
+			"Unable to find %q as configured for the final backup", final.RepoName)
+		return &reconcile.Result{}, nil
+	}
+	for _, opt := range final.Options {
+		if strings.Contains(opt, "--repo") {
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, EventInvalidFinalBackup,
+				"Option '--repo' is not allowed: please use the 'repoName' field instead.")
+			return &reconcile.Result{}, nil
+		}
+	}
+
+	job := &batchv1.Job{ObjectMeta: naming.PGBackRestFinalBackup(cluster)}
+	err := errors.WithStack(r.Client.Get(ctx, client.ObjectKeyFromObject(job), job))
+
+	if apierrors.IsNotFound(err) {
+		labels := naming.Merge(cluster.Spec.Metadata.GetLabelsOrNil(),
+			cluster.Spec.Backups.PGBackRest.Metadata.GetLabelsOrNil(),
+			naming.PGBackRestBackupJobLabels(cluster.GetName(), repo.Name,
+				naming.BackupFinal))
+		annotations := naming.Merge(cluster.Spec.Metadata.GetAnnotationsOrNil(),
+			cluster.Spec.Backups.PGBackRest.Metadata.GetAnnotationsOrNil())
+
+		var spec *batchv1.JobSpec
+		spec, err = generateBackupJobSpecIntent(cluster, repo,
+			naming.PGBackRestRBAC(cluster).Name, labels, annotations,
+			append([]string{"--type=full"}, final.Options...)...)
+
+		job = &batchv1.Job{ObjectMeta: naming.PGBackRestFinalBackup(cluster)}
+		job.SetGroupVersionKind(batchv1.SchemeGroupVersion.WithKind("Job"))
+		job.Annotations = annotations
+		job.Labels = labels
+		if err == nil {
+			job.Spec = *spec
+			err = errors.WithStack(r.setControllerReference(cluster, job))
+		}
+		if err == nil {
+			err = r.apply(ctx, job)
+		}
+		if err == nil {
+			r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventFinalBackupStarted,
+				"Taking a full backup to %q before deleting the cluster", repo.Name)
+		}
+
+		// Wait for events from the Job.
+		return &reconcile.Result{}, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if jobFailed(job) {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventFinalBackupFailed,
+			"Delete Job %q to try again, or remove spec.backups.pgbackrest.finalBackup "+
+				"to delete the cluster without it", job.Name)
+		return &reconcile.Result{}, nil
+	}
+	if !jobCompleted(job) {
+		return &reconcile.Result{}, nil
+	}
+
+	// Record everything needed to restore this backup into a new cluster using
+	// spec.dataSource.pgbackrest.
+	source, err := yaml.Marshal(v1beta1.PGBackRestDataSource{
+		Configuration: cluster.Spec.Backups.PGBackRest.Configuration,
+		Global:        cluster.Spec.Backups.PGBackRest.Global,
+		Repo:          repo,
+		Stanza:        pgbackrest.DefaultStanzaName,
+	})
+
+	configmap := &corev1.ConfigMap{ObjectMeta: naming.PGBackRestFinalBackup(cluster)}
+	configmap.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+	configmap.Annotations = naming.Merge(cluster.Spec.Metadata.GetAnnotationsOrNil())
+	configmap.Labels = naming.Merge(cluster.Spec.Metadata.GetLabelsOrNil(),
+		naming.PGBackRestBackupJobLabels(cluster.GetName(), repo.Name, naming.BackupFinal))
+	configmap.Data = map[string]string{
+		"data-source.yaml": string(source),
+		"postgres-version": fmt.Sprint(cluster.Spec.PostgresVersion),
+	}
+	if job.Status.CompletionTime != nil {
+		configmap.Data["completion-time"] = job.Status.CompletionTime.UTC().Format(time.RFC3339)
+	}
+
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, configmap))
+	}
+	return nil, err
 }
 
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=create;patch;delete
@@ -2375,11 +2760,15 @@ func (r *Reconciler) reconcileReplicaCreateBackup(ctx context.Context,
 				client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
 				return errors.WithStack(err)
 			}
+			if failed {
+				r.recordBackupJobFinished(postgresCluster, backupTypeReplicaCreate, false)
+			}
 			return nil
 		}
 
 		// if the Job completed then update status and return
 		if completed {
+			r.recordBackupJobFinished(postgresCluster, backupTypeReplicaCreate, true)
 			replicaCreateRepoStatus.ReplicaCreateBackupComplete = true
 			return nil
 		}
@@ -2782,6 +3171,24 @@ func (r *Reconciler) reconcileScheduledBackups(
 	return requeue
 }
 
+// withCronJobTimeZone returns cronjob with spec.timeZone set to timeZone. The
+// CronJob type of this module is older than that field, so the result is
+// unstructured. When timeZone is empty, it returns cronjob unchanged.
+// - https://docs.k8s.io/concepts/workloads/controllers/cron-jobs/#time-zones
+func withCronJobTimeZone(
+	cronjob *batchv1beta1.CronJob, timeZone string,
+) (client.Object, error) {
+	if timeZone == "" {
+		return cronjob, nil
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cronjob)
+	if err == nil {
+		err = unstructured.SetNestedField(content, timeZone, "spec", "timeZone")
+	}
+	return &unstructured.Unstructured{Object: content}, errors.WithStack(err)
+}
+
 // +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=create;patch
 
 // reconcilePGBackRestCronJob creates the CronJob for the given repo, pgBackRest
@@ -2851,13 +3258,13 @@ func (r *Reconciler) reconcilePGBackRestCronJob(
 		}
 	}
 	if !statusFound {
-		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "InvalidBackupRepo",
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventInvalidBackupRepo,
 			"Unable to find status for %q as configured for a scheduled backup.  Please ensure "+
 				"this repo is defined in the spec.", repo.Name)
 		return nil
 	}
 	if !stanzaCreated {
-		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "StanzaNotCreated",
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventStanzaNotCreated,
 			"Stanza not created for %q as specified for a scheduled backup", repo.Name)
 		return nil
 	}
@@ -2871,11 +3278,22 @@ func (r *Reconciler) reconcilePGBackRestCronJob(
 		return errors.WithStack(err)
 	}
 
-	// Suspend cronjobs when shutdown or read-only. Any jobs that have already
-	// started will continue.
+	// Suspend cronjobs when shutdown, read-only, or when the schedules ask to
+	// be suspended. Any jobs that have already started will continue.
 	// - https://docs.k8s.io/reference/kubernetes-api/workload-resources/cron-job-v1beta1/#CronJobSpec
 	suspend := (cluster.Spec.Shutdown != nil && *cluster.Spec.Shutdown) ||
-		(cluster.Spec.Standby != nil && cluster.Spec.Standby.Enabled)
+		(cluster.Spec.Standby != nil && cluster.Spec.Standby.Enabled) ||
+		(repo.BackupSchedules.Suspend != nil && *repo.BackupSchedules.Suspend)
+
+	// Kubernetes drops the time zone of CronJobs when it does not support
+	// them. Rather than back up at the wrong time of day, suspend the CronJob.
+	timeZone := repo.BackupSchedules.TimeZone
+	if timeZone != "" && !r.CronJobTimeZones {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventUnableToCreatePGBackRestCronJob,
+			"Suspended %s backups of %q: Kubernetes does not support the time zone of CronJobs",
+			backupType, repo.Name)
+		suspend, timeZone = true, ""
+	}
 
 	pgBackRestCronJob := &batchv1beta1.CronJob{
 		ObjectMeta: objectmeta,
@@ -2899,12 +3317,23 @@ func (r *Reconciler) reconcilePGBackRestCronJob(
 	pgBackRestCronJob.Spec.JobTemplate.Spec.Template.Spec.ImagePullSecrets =
 		cluster.Spec.ImagePullSecrets
 
+	// Keep the number of finished Jobs that the spec asks for. When these are
+	// nil, Kubernetes keeps its defaults.
+	if jobs := cluster.Spec.Backups.PGBackRest.Jobs; jobs != nil {
+		pgBackRestCronJob.Spec.SuccessfulJobsHistoryLimit = jobs.SuccessfulJobsHistoryLimit
+		pgBackRestCronJob.Spec.FailedJobsHistoryLimit = jobs.FailedJobsHistoryLimit
+	}
+
 	// set metadata
 	pgBackRestCronJob.SetGroupVersionKind(batchv1beta1.SchemeGroupVersion.WithKind("CronJob"))
 	err = errors.WithStack(r.setControllerReference(cluster, pgBackRestCronJob))
 
+	var intent client.Object
+	if err == nil {
+		intent, err = withCronJobTimeZone(pgBackRestCronJob, timeZone)
+	}
 	if err == nil {
-		err = r.apply(ctx, pgBackRestCronJob)
+		err = r.apply(ctx, intent)
 	}
 	if err != nil {
 		// record and log any errors resulting from trying to create the pgBackRest backup CronJob
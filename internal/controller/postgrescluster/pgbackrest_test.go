@@ -47,7 +47,9 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -56,6 +58,7 @@ import (
 	"github.com/crunchydata/postgres-operator/internal/naming"
 	"github.com/crunchydata/postgres-operator/internal/pgbackrest"
 	"github.com/crunchydata/postgres-operator/internal/pki"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
 	"github.com/crunchydata/postgres-operator/internal/testing/require"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
@@ -609,6 +612,47 @@ topologySpreadConstraints:
 
 			assert.Assert(t, *returnedCronJob.Spec.Suspend)
 		})
+
+		t.Run("schedules", func(t *testing.T) {
+			suspend := true
+			postgresCluster.Spec.Standby = nil
+			postgresCluster.Spec.Backups.PGBackRest.Repos[0].BackupSchedules.Suspend = &suspend
+			t.Cleanup(func() {
+				postgresCluster.Spec.Backups.PGBackRest.Repos[0].BackupSchedules.Suspend = nil
+			})
+
+			requeue := r.reconcileScheduledBackups(ctx,
+				postgresCluster, serviceAccount, fakeObservedCronJobs())
+			assert.Assert(t, !requeue)
+
+			assert.NilError(t, tClient.Get(ctx, types.NamespacedName{
+				Name:      postgresCluster.Name + "-repo1-full",
+				Namespace: postgresCluster.GetNamespace(),
+			}, returnedCronJob))
+
+			assert.Assert(t, *returnedCronJob.Spec.Suspend)
+		})
+	})
+
+	t.Run("pgbackrest schedule history limits", func(t *testing.T) {
+		postgresCluster.Spec.Backups.PGBackRest.Jobs = &v1beta1.BackupJobs{
+			SuccessfulJobsHistoryLimit: initialize.Int32(5),
+			FailedJobsHistoryLimit:     initialize.Int32(2),
+		}
+		t.Cleanup(func() { postgresCluster.Spec.Backups.PGBackRest.Jobs = nil })
+
+		requeue := r.reconcileScheduledBackups(ctx,
+			postgresCluster, serviceAccount, fakeObservedCronJobs())
+		assert.Assert(t, !requeue)
+
+		returnedCronJob := &batchv1beta1.CronJob{}
+		assert.NilError(t, tClient.Get(ctx, types.NamespacedName{
+			Name:      postgresCluster.Name + "-repo1-full",
+			Namespace: postgresCluster.GetNamespace(),
+		}, returnedCronJob))
+
+		assert.Equal(t, *returnedCronJob.Spec.SuccessfulJobsHistoryLimit, int32(5))
+		assert.Equal(t, *returnedCronJob.Spec.FailedJobsHistoryLimit, int32(2))
 	})
 }
 
@@ -857,7 +901,11 @@ func TestReconcileReplicaCreateBackup(t *testing.T) {
 	_, tClient := setupKubernetes(t)
 	require.ParallelCapacity(t, 1)
 
-	r := &Reconciler{Client: tClient, Owner: client.FieldOwner(t.Name())}
+	r := &Reconciler{
+		Client:   tClient,
+		Owner:    client.FieldOwner(t.Name()),
+		Recorder: new(record.FakeRecorder),
+	}
 
 	clusterName := "hippocluster"
 	clusterUID := "hippouid"
@@ -1367,7 +1415,7 @@ func TestReconcileManualBackup(t *testing.T) {
 					}
 				}
 
-				err := r.reconcileManualBackup(ctx, postgresCluster, currentJobs, sa, instances)
+				_, err := r.reconcileManualBackup(ctx, postgresCluster, currentJobs, sa, instances)
 
 				if tc.expectReconcile {
 
@@ -2127,6 +2175,59 @@ func TestReconcileCloudBasedDataSource(t *testing.T) {
 				expectedClusterCondition: nil,
 				conf:                     "|\n  # Generated by postgres-operator. DO NOT EDIT.\n  # Your changes will not be saved.\n\n  [global]\n  log-path = /pgdata/pgbackrest/log\n  repo1-path = /pgbackrest/repo1\n\n  [db]\n  pg1-path = /pgdata/pg13\n  pg1-port = 5432\n  pg1-socket-path = /tmp/postgres\n",
 			},
+		}, {
+			desc: "volume repo",
+			dataSource: &v1beta1.DataSource{
+				PGBackRest: &v1beta1.PGBackRestDataSource{
+					Stanza: "db",
+					Repo: v1beta1.PGBackRestRepo{
+						Name: "repo1",
+						Volume: &v1beta1.RepoPVC{
+							VolumeClaimSpec: corev1.PersistentVolumeClaimSpec{
+								AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+								Resources: corev1.ResourceRequirements{
+									Requests: map[corev1.ResourceName]resource.Quantity{
+										corev1.ResourceStorage: resource.MustParse("1Gi"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Volumes: &v1beta1.DataSourceVolumes{
+					PGBackRestVolume: &v1beta1.DataSourceVolume{PVCName: "deleted-repo1"},
+				},
+			},
+			clusterBootstrapped: false,
+			result: testResult{
+				configCount: 1, jobCount: 1, pvcCount: 1,
+				expectedClusterCondition: nil,
+				conf:                     "|\n  # Generated by postgres-operator. DO NOT EDIT.\n  # Your changes will not be saved.\n\n  [global]\n  log-path = /pgdata/pgbackrest/log\n  repo1-path = /pgbackrest/repo1\n\n  [db]\n  pg1-path = /pgdata/pg13\n  pg1-port = 5432\n  pg1-socket-path = /tmp/postgres\n",
+			},
+		}, {
+			desc: "volume repo without existing volume",
+			dataSource: &v1beta1.DataSource{PGBackRest: &v1beta1.PGBackRestDataSource{
+				Stanza: "db",
+				Repo: v1beta1.PGBackRestRepo{
+					Name: "repo1",
+					Volume: &v1beta1.RepoPVC{
+						VolumeClaimSpec: corev1.PersistentVolumeClaimSpec{
+							AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+							Resources: corev1.ResourceRequirements{
+								Requests: map[corev1.ResourceName]resource.Quantity{
+									corev1.ResourceStorage: resource.MustParse("1Gi"),
+								},
+							},
+						},
+					},
+				},
+			}},
+			clusterBootstrapped: false,
+			result: testResult{
+				configCount: 1, jobCount: 0, pvcCount: 1,
+				expectedClusterCondition: nil,
+				conf:                     "|\n  # Generated by postgres-operator. DO NOT EDIT.\n  # Your changes will not be saved.\n\n  [global]\n  log-path = /pgdata/pgbackrest/log\n  repo1-path = /pgbackrest/repo1\n\n  [db]\n  pg1-path = /pgdata/pg13\n  pg1-port = 5432\n  pg1-socket-path = /tmp/postgres\n",
+			},
 		}, {
 			desc: "cluster bootstrapped init condition missing",
 			dataSource: &v1beta1.DataSource{PGBackRest: &v1beta1.PGBackRestDataSource{
@@ -2201,6 +2302,16 @@ func TestReconcileCloudBasedDataSource(t *testing.T) {
 				if len(restoreJobs.Items) == 1 {
 					assert.Assert(t, restoreJobs.Items[0].Labels[naming.LabelStartupInstance] != "")
 					assert.Assert(t, restoreJobs.Items[0].Annotations[naming.PGBackRestConfigHash] != "")
+
+					var claims []string
+					for _, volume := range restoreJobs.Items[0].Spec.Template.Spec.Volumes {
+						if volume.PersistentVolumeClaim != nil {
+							claims = append(claims, volume.PersistentVolumeClaim.ClaimName)
+						}
+					}
+					if tc.dataSource.Volumes != nil {
+						assert.Assert(t, cmp.Contains(claims, "deleted-repo1"))
+					}
 				}
 
 				dataPVCs := &corev1.PersistentVolumeClaimList{}
@@ -2590,6 +2701,27 @@ volumes:
 		})
 	})
 
+	t.Run("ActiveDeadlineSeconds", func(t *testing.T) {
+		cluster := &v1beta1.PostgresCluster{}
+		job, err := generateBackupJobSpecIntent(
+			cluster, v1beta1.PGBackRestRepo{},
+			"",
+			nil, nil,
+		)
+		assert.NilError(t, err)
+		assert.Assert(t, job.ActiveDeadlineSeconds == nil)
+
+		cluster.Spec.Backups.PGBackRest.Jobs = &v1beta1.BackupJobs{
+			ActiveDeadlineSeconds: initialize.Int64(3600),
+		}
+		job, err = generateBackupJobSpecIntent(
+			cluster, v1beta1.PGBackRestRepo{},
+			"",
+			nil, nil,
+		)
+		assert.NilError(t, err)
+		assert.Equal(t, *job.ActiveDeadlineSeconds, int64(3600))
+	})
 }
 
 func TestGenerateRepoHostIntent(t *testing.T) {
@@ -3229,6 +3361,13 @@ func TestPrepareForRestore(t *testing.T) {
 					Reason:             "PGBackRestRestoreComplete",
 					Message:            "pgBackRest restore completed successfully",
 				})
+				meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+					ObservedGeneration: cluster.GetGeneration(),
+					Type:               ConditionPGBackRestRestoreSuccessful,
+					Status:             metav1.ConditionTrue,
+					Reason:             "RestoreComplete",
+					Message:            "pgBackRest in-place restore completed successfully",
+				})
 
 				job, endpoints := tc.createResources(t, cluster)
 				restoreID := "test-restore-id"
@@ -3240,6 +3379,10 @@ func TestPrepareForRestore(t *testing.T) {
 				assert.NilError(t, r.prepareForRestore(ctx, cluster, fakeObserved, endpoints,
 					job, restoreID))
 
+				// the result of the previous restore is no longer relevant
+				assert.Assert(t, meta.FindStatusCondition(cluster.Status.Conditions,
+					ConditionPGBackRestRestoreSuccessful) == nil)
+
 				var primaryInstance *Instance
 				for i, instance := range fakeObserved.forCluster {
 					isPrimary, _ := instance.IsPrimary()
@@ -3575,12 +3718,39 @@ func TestReconcileScheduledBackups(t *testing.T) {
 	}
 }
 
+func TestWithCronJobTimeZone(t *testing.T) {
+	cronjob := &batchv1beta1.CronJob{
+		Spec: batchv1beta1.CronJobSpec{Schedule: "30 2 * * 1-5"},
+	}
+	cronjob.Name = "hippo-repo1-full"
+
+	intent, err := withCronJobTimeZone(cronjob, "")
+	assert.NilError(t, err)
+	assert.Equal(t, intent, client.Object(cronjob))
+
+	intent, err = withCronJobTimeZone(cronjob, "Europe/Berlin")
+	assert.NilError(t, err)
+
+	zoned, ok := intent.(*unstructured.Unstructured)
+	assert.Assert(t, ok, "expected unstructured, got %T", intent)
+	assert.Equal(t, zoned.GetName(), "hippo-repo1-full")
+
+	schedule, _, _ := unstructured.NestedString(zoned.Object, "spec", "schedule")
+	assert.Equal(t, schedule, "30 2 * * 1-5")
+	timeZone, _, _ := unstructured.NestedString(zoned.Object, "spec", "timeZone")
+	assert.Equal(t, timeZone, "Europe/Berlin")
+}
+
 func TestSetScheduledJobStatus(t *testing.T) {
 	ctx := context.Background()
 	_, tClient := setupKubernetes(t)
 	require.ParallelCapacity(t, 0)
 
-	r := &Reconciler{Client: tClient, Owner: client.FieldOwner(t.Name())}
+	r := &Reconciler{
+		Client:   tClient,
+		Owner:    client.FieldOwner(t.Name()),
+		Recorder: new(record.FakeRecorder),
+	}
 
 	clusterName := "hippocluster"
 	clusterUID := "hippouid"
@@ -3624,6 +3794,7 @@ func TestSetScheduledJobStatus(t *testing.T) {
 		assert.Equal(t, postgresCluster.Status.PGBackRest.ScheduledBackups[0].Active, int32(1))
 		assert.Equal(t, postgresCluster.Status.PGBackRest.ScheduledBackups[0].Succeeded, int32(2))
 		assert.Equal(t, postgresCluster.Status.PGBackRest.ScheduledBackups[0].Failed, int32(3))
+		assert.Equal(t, postgresCluster.Status.PGBackRest.ScheduledBackups[0].Phase, "Running")
 	})
 
 	t.Run("fail to set scheduled backup status due to missing label", func(t *testing.T) {
@@ -3660,3 +3831,184 @@ func TestSetScheduledJobStatus(t *testing.T) {
 		assert.Assert(t, len(postgresCluster.Status.PGBackRest.ScheduledBackups) == 0)
 	})
 }
+
+func TestReconcileFinalBackup(t *testing.T) {
+	ctx := context.Background()
+	_, tClient := setupKubernetes(t)
+	require.ParallelCapacity(t, 1)
+
+	recorder := record.NewFakeRecorder(10)
+	r := &Reconciler{
+		Client:   tClient,
+		Owner:    client.FieldOwner(t.Name()),
+		Recorder: recorder,
+	}
+
+	ns := setupNamespace(t, tClient)
+	cluster := testCluster()
+	cluster.Namespace = ns.Name
+	cluster.Spec.Backups.PGBackRest.Repos = append(cluster.Spec.Backups.PGBackRest.Repos,
+		v1beta1.PGBackRestRepo{Name: "repo2", S3: &v1beta1.RepoS3{
+			Bucket: "bucket", Endpoint: "s3.example.com", Region: "east",
+		}})
+	assert.NilError(t, tClient.Create(ctx, cluster))
+
+	t.Run("Disabled", func(t *testing.T) {
+		result, err := r.reconcileFinalBackup(ctx, cluster)
+		assert.NilError(t, err)
+		assert.Assert(t, result == nil)
+	})
+
+	cluster.Spec.Backups.PGBackRest.FinalBackup = &v1beta1.PGBackRestFinalBackup{
+		RepoName: "repo2",
+	}
+
+	t.Run("Shutdown", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Shutdown = initialize.Bool(true)
+
+		result, err := r.reconcileFinalBackup(ctx, cluster)
+		assert.NilError(t, err)
+		assert.Assert(t, result == nil)
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "FinalBackupSkipped"))
+	})
+
+	t.Run("Backup", func(t *testing.T) {
+		result, err := r.reconcileFinalBackup(ctx, cluster)
+		assert.NilError(t, err)
+		assert.Assert(t, result != nil)
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "FinalBackupStarted"))
+
+		job := &batchv1.Job{ObjectMeta: naming.PGBackRestFinalBackup(cluster)}
+		assert.NilError(t, tClient.Get(ctx, client.ObjectKeyFromObject(job), job))
+		assert.Equal(t, job.Labels[naming.LabelPGBackRestBackup], string(naming.BackupFinal))
+		assert.Assert(t, marshalMatches(job.Spec.Template.Spec.Containers[0].Env[:2], `
+- name: COMMAND
+  value: backup
+- name: COMMAND_OPTS
+  value: --stanza=db --repo=2 --type=full
+		`))
+
+		// Deletion waits while the Job runs.
+		result, err = r.reconcileFinalBackup(ctx, cluster)
+		assert.NilError(t, err)
+		assert.Assert(t, result != nil)
+
+		now := metav1.Now()
+		job.Status.StartTime = &now
+		job.Status.CompletionTime = &now
+		job.Status.Conditions = []batchv1.JobCondition{{
+			Type: batchv1.JobComplete, Status: corev1.ConditionTrue,
+		}}
+		assert.NilError(t, tClient.Status().Update(ctx, job))
+
+		result, err = r.reconcileFinalBackup(ctx, cluster)
+		assert.NilError(t, err)
+		assert.Assert(t, result == nil)
+
+		configmap := &corev1.ConfigMap{ObjectMeta: naming.PGBackRestFinalBackup(cluster)}
+		assert.NilError(t, tClient.Get(ctx, client.ObjectKeyFromObject(configmap), configmap))
+		assert.Assert(t, len(configmap.OwnerReferences) == 0,
+			"expected the ConfigMap to outlive the cluster")
+		assert.Equal(t, configmap.Data["postgres-version"], "13")
+		assert.Assert(t, configmap.Data["completion-time"] != "")
+		assert.Assert(t, cmp.Contains(configmap.Data["data-source.yaml"], "bucket: bucket"))
+		assert.Assert(t, cmp.Contains(configmap.Data["data-source.yaml"], "stanza: db"))
+	})
+}
+
+func TestRunningManualBackups(t *testing.T) {
+	ctx := context.Background()
+
+	job := func(namespace, name string, backupType naming.BackupJobType,
+		conditions ...batchv1.JobConditionType) *batchv1.Job {
+		job := &batchv1.Job{}
+		job.Namespace, job.Name = namespace, name
+		job.Labels = map[string]string{naming.LabelPGBackRestBackup: string(backupType)}
+		for _, condition := range conditions {
+			job.Status.Conditions = append(job.Status.Conditions, batchv1.JobCondition{
+				Type: condition, Status: corev1.ConditionTrue,
+			})
+		}
+		return job
+	}
+
+	r := &Reconciler{Client: fake.NewClientBuilder().WithObjects(
+		job("ns1", "running", naming.BackupManual),
+		job("ns2", "running", naming.BackupManual),
+		job("ns1", "complete", naming.BackupManual, batchv1.JobComplete),
+		job("ns2", "failed", naming.BackupManual, batchv1.JobFailed),
+		job("ns1", "replica-create", naming.BackupReplicaCreate),
+	).Build()}
+
+	running, err := r.runningManualBackups(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, running, 2)
+
+	t.Run("NotCached", func(t *testing.T) {
+		r.manualBackups.created(client.ObjectKey{Namespace: "ns1", Name: "running"}, time.Now())
+		r.manualBackups.created(client.ObjectKey{Namespace: "ns3", Name: "new"}, time.Now())
+		r.manualBackups.created(client.ObjectKey{Namespace: "ns3", Name: "deleted"},
+			time.Now().Add(-time.Hour))
+
+		running, err := r.runningManualBackups(ctx)
+		assert.NilError(t, err)
+		assert.Equal(t, running, 3, "expected the new Job to take a slot")
+		assert.Equal(t, len(r.manualBackups.pending), 1,
+			"expected cached and old Jobs to be forgotten")
+	})
+}
+
+func TestReconcileBackupDeadlines(t *testing.T) {
+	ctx := context.Background()
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace, cluster.Name = "ns1", "hippo"
+	cluster.Spec.Backups.PGBackRest.Repos = []v1beta1.PGBackRestRepo{{Name: "repo1"}}
+
+	job := func(name, reason string, annotations map[string]string) *batchv1.Job {
+		job := &batchv1.Job{}
+		job.Namespace, job.Name = "ns1", name
+		job.Annotations = annotations
+		job.Labels = naming.PGBackRestBackupJobLabels("hippo", "repo1", naming.BackupManual)
+		job.Status.Conditions = []batchv1.JobCondition{{
+			Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: reason,
+		}}
+		return job
+	}
+
+	primary := &corev1.Pod{}
+	primary.Namespace, primary.Name = "ns1", "hippo-instance1-abcd-0"
+	primary.Labels = naming.Merge(naming.ClusterPrimary("hippo").MatchLabels,
+		map[string]string{naming.LabelInstance: "hippo-instance1-abcd"})
+
+	var calls []string
+	recorder := record.NewFakeRecorder(10)
+	r := &Reconciler{
+		Client: fake.NewClientBuilder().WithObjects(primary,
+			job("deadline", "DeadlineExceeded", nil),
+			job("backoff", "BackoffLimitExceeded", nil),
+			job("stopped", "DeadlineExceeded",
+				map[string]string{naming.PGBackRestBackupStopped: "true"}),
+		).Build(),
+		PodExec: func(namespace, pod, container string,
+			stdin io.Reader, stdout, stderr io.Writer, command ...string) error {
+			calls = append(calls, pod+" "+container)
+			return nil
+		},
+		Recorder: recorder,
+	}
+
+	assert.NilError(t, r.reconcileBackupDeadlines(ctx, cluster))
+	assert.DeepEqual(t, calls, []string{"hippo-instance1-abcd-0 database"})
+	assert.Assert(t, cmp.Contains(<-recorder.Events, `Stopped the backup of Job "deadline"`))
+
+	stopped := &batchv1.Job{}
+	assert.NilError(t, r.Client.Get(ctx,
+		client.ObjectKey{Namespace: "ns1", Name: "deadline"}, stopped))
+	assert.Equal(t, stopped.Annotations[naming.PGBackRestBackupStopped], "true")
+
+	// Each Job is handled once.
+	assert.NilError(t, r.reconcileBackupDeadlines(ctx, cluster))
+	assert.Equal(t, len(calls), 1)
+}
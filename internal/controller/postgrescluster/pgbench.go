@@ -0,0 +1,233 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/config"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const (
+	// pgbenchResultsLimit is the maximum number of benchmarks kept in status.
+	pgbenchResultsLimit = 10
+
+	// pgbenchScript initializes the "pgbench_" tables and runs pgbench. It then
+	// writes the measurements as JSON to the termination message of the
+	// container so they can be recorded without reading any logs.
+	// - https://www.postgresql.org/docs/current/pgbench.html
+	// - https://docs.k8s.io/tasks/debug/debug-application/determine-reason-pod-failure/
+	pgbenchScript = `
+pgbench --initialize --quiet --scale="${PGBENCH_SCALE}"
+output=$(pgbench --client="${PGBENCH_CLIENTS}" --jobs="${PGBENCH_JOBS}" --time="${PGBENCH_DURATION}")
+printf '%s\n' "${output}"
+
+tps=$(printf '%s\n' "${output}" | sed -n 's/^tps = \([0-9.]*\).*/\1/p' | tail -n 1)
+latency=$(printf '%s\n' "${output}" | sed -n 's/^latency average = \([0-9.]*\) ms$/\1/p')
+printf '{"tps":"%s","latencyAverage":"%s"}' "${tps}" "${latency}" > /dev/termination-log
+`
+)
+
+// generatePGBenchJobIntent returns a Job that runs pgbench as user against the
+// primary Service of cluster.
+func generatePGBenchJobIntent(
+	cluster *v1beta1.PostgresCluster, id string,
+) *batchv1.Job {
+	spec := cluster.Spec.Benchmark
+
+	job := &batchv1.Job{ObjectMeta: naming.PGBenchJob(cluster)}
+	job.SetGroupVersionKind(batchv1.SchemeGroupVersion.WithKind("Job"))
+
+	job.Annotations = naming.Merge(cluster.Spec.Metadata.GetAnnotationsOrNil(),
+		map[string]string{naming.PGBench: id})
+	job.Labels = naming.Merge(cluster.Spec.Metadata.GetLabelsOrNil(),
+		naming.PGBenchJobLabels(cluster.Name))
+
+	container := corev1.Container{
+		Name:    naming.ContainerPGBench,
+		Command: []string{"bash", "-ceu", "--", pgbenchScript},
+		Env: append(postgresUserEnvironment(cluster, spec.User), []corev1.EnvVar{
+			{Name: "PGBENCH_CLIENTS", Value: fmt.Sprint(*spec.Clients)},
+			{Name: "PGBENCH_DURATION", Value: fmt.Sprint(*spec.DurationSeconds)},
+			{Name: "PGBENCH_JOBS", Value: fmt.Sprint(*spec.Jobs)},
+			{Name: "PGBENCH_SCALE", Value: fmt.Sprint(*spec.Scale)},
+		}...),
+		Image:           config.PostgresContainerImage(cluster),
+		ImagePullPolicy: cluster.Spec.ImagePullPolicy,
+		Resources:       spec.Resources,
+		SecurityContext: initialize.RestrictedSecurityContext(),
+	}
+
+	job.Spec = batchv1.JobSpec{
+		// Benchmarks that fail are not retried; their measurements would
+		// not be comparable.
+		BackoffLimit: initialize.Int32(0),
+		Template: corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: job.Annotations,
+				Labels:      job.Labels,
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{container},
+
+				// Disable environment variables for services other than the Kubernetes API.
+				// - https://docs.k8s.io/concepts/services-networking/connect-applications-service/#accessing-the-service
+				// - https://releases.k8s.io/v1.23.0/pkg/kubelet/kubelet_pods.go#L553-L563
+				EnableServiceLinks: initialize.Bool(false),
+
+				ImagePullSecrets: cluster.Spec.ImagePullSecrets,
+				RestartPolicy:    corev1.RestartPolicyNever,
+				SecurityContext:  initialize.RestrictedPodSecurityContext(),
+			},
+		},
+	}
+
+	return job
+}
+
+// pgbenchMeasurements copies the measurements written by pgbenchScript from
+// the termination message of pod into result.
+func pgbenchMeasurements(pod *corev1.Pod, result *v1beta1.PGBenchResult) {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == naming.ContainerPGBench && status.State.Terminated != nil {
+			var measured struct{ TPS, LatencyAverage string }
+			if json.Unmarshal([]byte(status.State.Terminated.Message), &measured) == nil {
+				result.TPS = measured.TPS
+				result.LatencyAverage = measured.LatencyAverage
+			}
+		}
+	}
+}
+
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=list;create;patch;delete
+// +kubebuilder:rbac:groups="",resources=pods,verbs=list
+
+// reconcilePGBench runs a pgbench benchmark when the "pgbench" annotation on
+// cluster changes, and records its measurements in cluster.Status.
+func (r *Reconciler) reconcilePGBench(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+) error {
+	id := cluster.GetAnnotations()[naming.PGBench]
+
+	jobs := &batchv1.JobList{}
+	err := errors.WithStack(r.Client.List(ctx, jobs,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabelsSelector{Selector: naming.PGBenchJobSelector(cluster.Name)}))
+	if err != nil {
+		return err
+	}
+
+	var current *batchv1.Job
+	for i := range jobs.Items {
+		if metav1.IsControlledBy(&jobs.Items[i], cluster) {
+			current = &jobs.Items[i]
+		}
+	}
+
+	var results []v1beta1.PGBenchResult
+	if cluster.Status.Benchmark != nil {
+		results = cluster.Status.Benchmark.Results
+	}
+
+	// First update status according to any existing benchmark Job.
+	if current != nil {
+		completed := jobCompleted(current)
+		failed := jobFailed(current)
+		currentID := current.GetAnnotations()[naming.PGBench]
+
+		if len(results) > 0 && results[0].ID == currentID && !results[0].Finished {
+			result := &results[0]
+			result.StartTime = current.Status.StartTime
+			result.CompletionTime = current.Status.CompletionTime
+			result.Finished = completed || failed
+
+			if completed {
+				pods := &corev1.PodList{}
+				err = errors.WithStack(r.Client.List(ctx, pods,
+					client.InNamespace(current.Namespace),
+					client.MatchingLabels{"job-name": current.Name}))
+
+				for i := range pods.Items {
+					pgbenchMeasurements(&pods.Items[i], result)
+				}
+			}
+			if failed {
+				r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventBenchmarkFailed,
+					"Benchmark %q did not complete successfully", currentID)
+			}
+		}
+
+		// Wait for a running Job to finish. Once finished, delete it so that
+		// another can be created with a new benchmark ID.
+		if err != nil || !(completed || failed) || id == "" || id == currentID {
+			return err
+		}
+		return errors.WithStack(r.Client.Delete(ctx, current,
+			client.PropagationPolicy(metav1.DeletePropagationBackground)))
+	}
+
+	// Nothing more to do when a benchmark has not been requested or has
+	// already been run.
+	if id == "" || cluster.Spec.Benchmark == nil ||
+		(len(results) > 0 && results[0].ID == id) {
+		return nil
+	}
+
+	spec := cluster.Spec.Benchmark
+	if !postgresUserHasDatabase(cluster, spec.User) {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventInvalidBenchmark,
+			"Unable to find user %q with a database as configured for a benchmark", spec.User)
+		return nil
+	}
+	if postgresUserPasswordInVault(cluster, spec.User) {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventInvalidBenchmark,
+			"Unable to run a benchmark as user %q; its password is in Vault", spec.User)
+		return nil
+	}
+
+	job := generatePGBenchJobIntent(cluster, id)
+	err = errors.WithStack(r.setControllerReference(cluster, job))
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, job))
+	}
+
+	// Record the new benchmark, trimming the oldest.
+	if err == nil {
+		results = append([]v1beta1.PGBenchResult{{
+			ID:              id,
+			Clients:         *spec.Clients,
+			Jobs:            *spec.Jobs,
+			DurationSeconds: *spec.DurationSeconds,
+		}}, results...)
+		if len(results) > pgbenchResultsLimit {
+			results = results[:pgbenchResultsLimit]
+		}
+		cluster.Status.Benchmark = &v1beta1.PGBenchStatus{Results: results}
+	}
+
+	return err
+}
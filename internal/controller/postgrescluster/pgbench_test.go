@@ -0,0 +1,181 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/testing/require"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestGeneratePGBenchJobIntent(t *testing.T) {
+	cluster := testCluster()
+	cluster.Namespace = "ns1"
+	cluster.Spec.Benchmark = &v1beta1.PGBenchSpec{User: "rhino"}
+	cluster.Default()
+
+	job := generatePGBenchJobIntent(cluster, "some-id")
+
+	assert.Assert(t, marshalMatches(job.ObjectMeta, `
+annotations:
+  postgres-operator.crunchydata.com/pgbench: some-id
+creationTimestamp: null
+labels:
+  postgres-operator.crunchydata.com/cluster: hippo
+  postgres-operator.crunchydata.com/role: pgbench
+name: hippo-pgbench
+namespace: ns1
+	`))
+
+	assert.Equal(t, *job.Spec.BackoffLimit, int32(0))
+	assert.Equal(t, job.Spec.Template.Spec.RestartPolicy, corev1.RestartPolicyNever)
+	assert.DeepEqual(t, job.Spec.Template.Spec.ImagePullSecrets, cluster.Spec.ImagePullSecrets)
+
+	assert.Equal(t, len(job.Spec.Template.Spec.Containers), 1)
+	container := job.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, container.Name, naming.ContainerPGBench)
+	assert.Equal(t, container.Image, CrunchyPostgresHAImage)
+	assert.Assert(t, marshalMatches(container.Env, `
+- name: PGHOST
+  valueFrom:
+    secretKeyRef:
+      key: host
+      name: hippo-pguser-rhino
+- name: PGPORT
+  valueFrom:
+    secretKeyRef:
+      key: port
+      name: hippo-pguser-rhino
+- name: PGUSER
+  valueFrom:
+    secretKeyRef:
+      key: user
+      name: hippo-pguser-rhino
+- name: PGPASSWORD
+  valueFrom:
+    secretKeyRef:
+      key: password
+      name: hippo-pguser-rhino
+- name: PGDATABASE
+  valueFrom:
+    secretKeyRef:
+      key: dbname
+      name: hippo-pguser-rhino
+- name: PGSSLMODE
+  value: require
+- name: PGBENCH_CLIENTS
+  value: "1"
+- name: PGBENCH_DURATION
+  value: "60"
+- name: PGBENCH_JOBS
+  value: "1"
+- name: PGBENCH_SCALE
+  value: "1"
+	`))
+
+	t.Run("SecretKeyRef", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Users = []v1beta1.PostgresUserSpec{{
+			Name: "rhino", Databases: []v1beta1.PostgresIdentifier{"zoo"},
+			Password: &v1beta1.PostgresPasswordSpec{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "passwords"},
+					Key:                  "rhino",
+				},
+			},
+		}}
+
+		job := generatePGBenchJobIntent(cluster, "some-id")
+		env := job.Spec.Template.Spec.Containers[0].Env
+
+		// The password comes from the other Secret; the rest does not.
+		assert.Assert(t, marshalMatches(env[2:4], `
+- name: PGUSER
+  valueFrom:
+    secretKeyRef:
+      key: user
+      name: hippo-pguser-rhino
+- name: PGPASSWORD
+  valueFrom:
+    secretKeyRef:
+      key: rhino
+      name: passwords
+		`))
+
+		assert.Assert(t, !postgresUserPasswordInVault(cluster, "rhino"))
+		cluster.Spec.Users[0].Password = &v1beta1.PostgresPasswordSpec{
+			Vault: &v1beta1.PostgresPasswordVaultSpec{},
+		}
+		assert.Assert(t, postgresUserPasswordInVault(cluster, "rhino"))
+	})
+
+	t.Run("ShellCheck", func(t *testing.T) {
+		shellcheck := require.ShellCheck(t)
+
+		// Expect a bash command with an inline script.
+		assert.DeepEqual(t, container.Command[:3], []string{"bash", "-ceu", "--"})
+		assert.Equal(t, len(container.Command), 4)
+
+		// Write out that inline script.
+		dir := t.TempDir()
+		file := filepath.Join(dir, "script.bash")
+		assert.NilError(t, os.WriteFile(file, []byte(container.Command[3]), 0o600))
+
+		// Expect shellcheck to be happy.
+		cmd := exec.Command(shellcheck, "--enable=all", "--shell=bash", file)
+		output, err := cmd.CombinedOutput()
+		assert.NilError(t, err, "%q\n%s", cmd.Args, output)
+	})
+}
+
+func TestPGBenchMeasurements(t *testing.T) {
+	pod := &corev1.Pod{}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+		{Name: "other", State: corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{Message: `{"tps":"9"}`},
+		}},
+		{Name: naming.ContainerPGBench, State: corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{
+				Message: `{"tps":"811.912","latencyAverage":"1.232"}`,
+			},
+		}},
+	}
+
+	result := v1beta1.PGBenchResult{ID: "x", StartTime: &metav1.Time{}}
+	pgbenchMeasurements(pod, &result)
+
+	assert.Equal(t, result.TPS, "811.912")
+	assert.Equal(t, result.LatencyAverage, "1.232")
+
+	t.Run("Unparsable", func(t *testing.T) {
+		pod.Status.ContainerStatuses[1].State.Terminated.Message = `oops`
+
+		result := v1beta1.PGBenchResult{ID: "x"}
+		pgbenchMeasurements(pod, &result)
+
+		assert.Equal(t, result.TPS, "")
+		assert.Equal(t, result.LatencyAverage, "")
+	})
+}
@@ -276,12 +276,6 @@ func (r *Reconciler) generatePGBouncerService(
 		naming.LabelCluster: cluster.Name,
 		naming.LabelRole:    naming.RolePGBouncer,
 	}
-	if spec := cluster.Spec.Proxy.PGBouncer.Service; spec != nil {
-		service.Spec.Type = corev1.ServiceType(spec.Type)
-	} else {
-		service.Spec.Type = corev1.ServiceTypeClusterIP
-	}
-
 	// The TargetPort must be the name (not the number) of the PgBouncer
 	// ContainerPort. This name allows the port number to differ between Pods,
 	// which can happen during a rolling update.
@@ -291,6 +285,7 @@ func (r *Reconciler) generatePGBouncerService(
 		Protocol:   corev1.ProtocolTCP,
 		TargetPort: intstr.FromString(naming.PortPGBouncer),
 	}}
+	setServiceSpec(service, cluster.Spec.Proxy.PGBouncer.Service)
 
 	err := errors.WithStack(r.setControllerReference(cluster, service))
 
@@ -492,6 +487,14 @@ func (r *Reconciler) reconcilePGBouncerDeployment(
 		return client.IgnoreNotFound(err)
 	}
 
+	// Leave the Deployment as it is until an invalid overlay changes. Read it
+	// so that its status is still observed.
+	if err == nil && !r.overlayPodTemplate(cluster, "PgBouncer",
+		&deploy.Spec.Template, cluster.Spec.Proxy.PGBouncer.PodTemplateOverlay) {
+		err := errors.WithStack(r.Client.Get(ctx, client.ObjectKeyFromObject(deploy), deploy))
+		return client.IgnoreNotFound(err)
+	}
+
 	if err == nil {
 		err = errors.WithStack(r.apply(ctx, deploy))
 	}
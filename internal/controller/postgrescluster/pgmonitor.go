@@ -22,6 +22,9 @@ import (
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/crunchydata/postgres-operator/internal/config"
@@ -236,6 +239,319 @@ func (r *Reconciler) reconcileMonitoringSecret(
 	return nil, err
 }
 
+// generateExporterService returns a Service that selects the exporters of
+// cluster for a Prometheus Operator ServiceMonitor. It returns false when the
+// Service should not exist.
+func (r *Reconciler) generateExporterService(
+	cluster *v1beta1.PostgresCluster,
+) (*corev1.Service, bool, error) {
+	service := &corev1.Service{ObjectMeta: naming.ClusterExporterService(cluster)}
+	service.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Service"))
+
+	if !pgmonitor.ExporterEnabled(cluster) ||
+		cluster.Spec.Monitoring.PGMonitor.Exporter.ServiceMonitor == nil {
+		return service, false, nil
+	}
+
+	service.Annotations = naming.Merge(cluster.Spec.Metadata.GetAnnotationsOrNil())
+	service.Labels = naming.Merge(
+		cluster.Spec.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			naming.LabelCluster: cluster.Name,
+			naming.LabelRole:    naming.RoleMonitoring,
+		})
+
+	// Prometheus scrapes the endpoints of the Service, so it does not need an
+	// IP address of its own.
+	service.Spec.ClusterIP = corev1.ClusterIPNone
+	service.Spec.Selector = map[string]string{
+		naming.LabelCluster:            cluster.Name,
+		naming.LabelPGMonitorDiscovery: "true",
+	}
+	service.Spec.Ports = []corev1.ServicePort{{
+		Name:       naming.PortExporter,
+		Port:       exporterPort,
+		Protocol:   corev1.ProtocolTCP,
+		TargetPort: intstr.FromString(naming.PortExporter),
+	}}
+
+	err := errors.WithStack(r.setControllerReference(cluster, service))
+
+	return service, true, err
+}
+
+// generateExporterServiceMonitor returns a Prometheus Operator ServiceMonitor
+// that scrapes the endpoints of service.
+// - https://prometheus-operator.dev/docs/operator/api/#monitoring.coreos.com/v1.ServiceMonitor
+func generateExporterServiceMonitor(
+	cluster *v1beta1.PostgresCluster, service *corev1.Service,
+) *unstructured.Unstructured {
+	spec := cluster.Spec.Monitoring.PGMonitor.Exporter.ServiceMonitor
+
+	endpoint := map[string]interface{}{"port": naming.PortExporter}
+	if spec.Interval != "" {
+		endpoint["interval"] = spec.Interval
+	}
+
+	selector := map[string]interface{}{}
+	for key, value := range service.Labels {
+		if key == naming.LabelCluster || key == naming.LabelRole {
+			selector[key] = value
+		}
+	}
+
+	monitor := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"endpoints": []interface{}{endpoint},
+			"selector":  map[string]interface{}{"matchLabels": selector},
+		},
+	}}
+	monitor.SetAPIVersion("monitoring.coreos.com/v1")
+	monitor.SetKind("ServiceMonitor")
+	monitor.SetNamespace(service.Namespace)
+	monitor.SetName(service.Name)
+
+	if annotations := naming.Merge(
+		cluster.Spec.Metadata.GetAnnotationsOrNil(),
+		spec.Metadata.GetAnnotationsOrNil(),
+	); len(annotations) > 0 {
+		monitor.SetAnnotations(annotations)
+	}
+	monitor.SetLabels(naming.Merge(
+		cluster.Spec.Metadata.GetLabelsOrNil(),
+		spec.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			naming.LabelCluster: cluster.Name,
+			naming.LabelRole:    naming.RoleMonitoring,
+		}))
+
+	return monitor
+}
+
+// generateExporterPrometheusRule returns a Prometheus Operator PrometheusRule
+// with the recommended alerts for the exporters behind service.
+// - https://prometheus-operator.dev/docs/operator/api/#monitoring.coreos.com/v1.PrometheusRule
+func generateExporterPrometheusRule(
+	cluster *v1beta1.PostgresCluster, service *corev1.Service,
+) *unstructured.Unstructured {
+	spec := cluster.Spec.Monitoring.PGMonitor.Exporter.ServiceMonitor.PrometheusRule
+
+	// The Prometheus Operator labels metrics with the namespace and name of
+	// the Service through which they were scraped.
+	selector := fmt.Sprintf(`namespace=%q,service=%q`, service.Namespace, service.Name)
+
+	alerts := pgmonitor.AlertRules(selector)
+	rules := make([]interface{}, len(alerts))
+	for i, alert := range alerts {
+		rules[i] = map[string]interface{}{
+			"alert": alert.Alert,
+			"expr":  alert.Expr,
+			"for":   alert.For,
+			"labels": map[string]interface{}{
+				"severity": alert.Severity,
+				"cluster":  cluster.Name,
+			},
+			"annotations": map[string]interface{}{
+				"summary": alert.Summary,
+			},
+		}
+	}
+
+	rule := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"groups": []interface{}{map[string]interface{}{
+				"name":  cluster.Namespace + "-" + cluster.Name,
+				"rules": rules,
+			}},
+		},
+	}}
+	rule.SetAPIVersion("monitoring.coreos.com/v1")
+	rule.SetKind("PrometheusRule")
+	rule.SetNamespace(service.Namespace)
+	rule.SetName(service.Name)
+
+	if annotations := naming.Merge(
+		cluster.Spec.Metadata.GetAnnotationsOrNil(),
+		spec.Metadata.GetAnnotationsOrNil(),
+	); len(annotations) > 0 {
+		rule.SetAnnotations(annotations)
+	}
+	rule.SetLabels(naming.Merge(
+		cluster.Spec.Metadata.GetLabelsOrNil(),
+		spec.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			naming.LabelCluster: cluster.Name,
+			naming.LabelRole:    naming.RoleMonitoring,
+		}))
+
+	return rule
+}
+
+// generateExporterGrafanaDashboard returns a ConfigMap containing a Grafana
+// dashboard for the exporters behind service. It returns false when the
+// ConfigMap should not exist.
+func (r *Reconciler) generateExporterGrafanaDashboard(
+	cluster *v1beta1.PostgresCluster, service *corev1.Service, serviceSpecified bool,
+) (*corev1.ConfigMap, bool, error) {
+	configmap := &corev1.ConfigMap{ObjectMeta: naming.ClusterGrafanaDashboard(cluster)}
+	configmap.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+
+	if !serviceSpecified ||
+		cluster.Spec.Monitoring.PGMonitor.Exporter.ServiceMonitor.GrafanaDashboard == nil {
+		return configmap, false, nil
+	}
+	spec := cluster.Spec.Monitoring.PGMonitor.Exporter.ServiceMonitor.GrafanaDashboard
+
+	folder := map[string]string{}
+	if spec.Folder != "" {
+		folder["grafana_folder"] = spec.Folder
+	}
+
+	configmap.Annotations = naming.Merge(
+		cluster.Spec.Metadata.GetAnnotationsOrNil(),
+		spec.Metadata.GetAnnotationsOrNil(),
+		folder)
+	configmap.Labels = naming.Merge(
+		cluster.Spec.Metadata.GetLabelsOrNil(),
+		spec.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			"grafana_dashboard": "1",
+			naming.LabelCluster: cluster.Name,
+			naming.LabelRole:    naming.RoleMonitoring,
+		})
+
+	selector := fmt.Sprintf(`namespace=%q,service=%q`, service.Namespace, service.Name)
+	dashboard, err := pgmonitor.GrafanaDashboard(
+		"PostgreSQL / "+cluster.Namespace+" / "+cluster.Name, selector)
+
+	if err == nil {
+		// The dashboard sidecar of Grafana writes every key to a file in the same
+		// directory, so include the namespace to keep each file name unique.
+		configmap.Data = map[string]string{
+			cluster.Namespace + "-" + cluster.Name + ".json": dashboard,
+		}
+		err = errors.WithStack(r.setControllerReference(cluster, configmap))
+	}
+
+	return configmap, true, err
+}
+
+// deletePrometheusOperatorObject deletes the Prometheus Operator object of
+// kind at key when it exists and is controlled by cluster.
+func (r *Reconciler) deletePrometheusOperatorObject(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, kind string, key client.ObjectKey,
+) error {
+	object := &unstructured.Unstructured{}
+	object.SetAPIVersion("monitoring.coreos.com/v1")
+	object.SetKind(kind)
+
+	err := r.Client.Get(ctx, key, object)
+	if err == nil {
+		err = r.deleteControlled(ctx, cluster, object)
+	}
+	if meta.IsNoMatchError(err) {
+		err = nil
+	}
+	return errors.WithStack(client.IgnoreNotFound(err))
+}
+
+// applyPrometheusOperatorObject writes object when its kind is installed.
+func (r *Reconciler) applyPrometheusOperatorObject(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, object *unstructured.Unstructured,
+) error {
+	err := errors.WithStack(r.setControllerReference(cluster, object))
+	if err == nil {
+		err = r.apply(ctx, object)
+
+		// The Prometheus Operator is not installed; there is nothing more to do.
+		if meta.IsNoMatchError(err) {
+			err = nil
+		}
+		err = errors.WithStack(err)
+	}
+	return err
+}
+
+// +kubebuilder:rbac:groups="monitoring.coreos.com",resources="servicemonitors",verbs={get,create,delete,patch}
+// +kubebuilder:rbac:groups="monitoring.coreos.com",resources="prometheusrules",verbs={get,create,delete,patch}
+
+// reconcileExporterServiceMonitor writes the Service, ServiceMonitor, and
+// PrometheusRule that let the Prometheus Operator discover and alert on the
+// exporters of cluster. The ServiceMonitor and PrometheusRule are skipped when
+// their CustomResourceDefinitions are not installed.
+func (r *Reconciler) reconcileExporterServiceMonitor(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+) error {
+	service, specified, err := r.generateExporterService(cluster)
+	key := client.ObjectKeyFromObject(service)
+
+	if err == nil && !specified {
+		// The ServiceMonitor is disabled; delete the Service and the objects
+		// that refer to it if they exist. Check the client cache for the
+		// Service first.
+		err := r.reconcileExporterGrafanaDashboard(ctx, cluster, service, false)
+		if err == nil {
+			err = errors.WithStack(r.Client.Get(ctx, key, service))
+		}
+		if err == nil {
+			err = r.deletePrometheusOperatorObject(ctx, cluster, "PrometheusRule", key)
+		}
+		if err == nil {
+			err = r.deletePrometheusOperatorObject(ctx, cluster, "ServiceMonitor", key)
+		}
+		if err == nil {
+			err = errors.WithStack(r.deleteControlled(ctx, cluster, service))
+		}
+		return client.IgnoreNotFound(err)
+	}
+
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, service))
+	}
+	if err == nil {
+		err = r.applyPrometheusOperatorObject(ctx, cluster,
+			generateExporterServiceMonitor(cluster, service))
+	}
+
+	if err == nil {
+		err = r.reconcileExporterGrafanaDashboard(ctx, cluster, service, true)
+	}
+
+	if err == nil && cluster.Spec.Monitoring.PGMonitor.Exporter.ServiceMonitor.PrometheusRule == nil {
+		err = r.deletePrometheusOperatorObject(ctx, cluster, "PrometheusRule", key)
+	} else if err == nil {
+		err = r.applyPrometheusOperatorObject(ctx, cluster,
+			generateExporterPrometheusRule(cluster, service))
+	}
+
+	return err
+}
+
+// reconcileExporterGrafanaDashboard writes the ConfigMap containing the
+// Grafana dashboard of cluster, or deletes it when it is not specified.
+func (r *Reconciler) reconcileExporterGrafanaDashboard(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+	service *corev1.Service, serviceSpecified bool,
+) error {
+	configmap, specified, err := r.generateExporterGrafanaDashboard(cluster, service, serviceSpecified)
+
+	if err == nil && !specified {
+		// The dashboard is disabled; delete the ConfigMap if it exists. Check
+		// the client cache first using Get.
+		key := client.ObjectKeyFromObject(configmap)
+		err := errors.WithStack(r.Client.Get(ctx, key, configmap))
+		if err == nil {
+			err = errors.WithStack(r.deleteControlled(ctx, cluster, configmap))
+		}
+		return client.IgnoreNotFound(err)
+	}
+
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, configmap))
+	}
+	return err
+}
+
 // addPGMonitorToInstancePodSpec performs the necessary setup to add
 // pgMonitor resources on a PodTemplateSpec
 func addPGMonitorToInstancePodSpec(
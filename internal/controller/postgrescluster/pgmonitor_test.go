@@ -32,9 +32,12 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
 	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/naming"
 	"github.com/crunchydata/postgres-operator/internal/pgmonitor"
@@ -575,3 +578,164 @@ func TestReconcilePGMonitorSecret(t *testing.T) {
 		})
 	})
 }
+
+func TestGenerateExporterServiceMonitor(t *testing.T) {
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	reconciler := &Reconciler{Client: fake.NewClientBuilder().WithScheme(scheme).Build()}
+
+	cluster := testCluster()
+	cluster.Namespace = "ns1"
+
+	t.Run("Disabled", func(t *testing.T) {
+		_, specified, err := reconciler.generateExporterService(cluster)
+		assert.NilError(t, err)
+		assert.Assert(t, !specified)
+
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Monitoring = &v1beta1.MonitoringSpec{
+			PGMonitor: &v1beta1.PGMonitorSpec{Exporter: &v1beta1.ExporterSpec{}},
+		}
+
+		_, specified, err = reconciler.generateExporterService(cluster)
+		assert.NilError(t, err)
+		assert.Assert(t, !specified)
+	})
+
+	cluster.Spec.Monitoring = &v1beta1.MonitoringSpec{
+		PGMonitor: &v1beta1.PGMonitorSpec{Exporter: &v1beta1.ExporterSpec{
+			ServiceMonitor: &v1beta1.ExporterServiceMonitorSpec{
+				Interval: "30s",
+				Metadata: &v1beta1.Metadata{
+					Labels: map[string]string{"release": "prometheus"},
+				},
+			},
+		}},
+	}
+
+	service, specified, err := reconciler.generateExporterService(cluster)
+	assert.NilError(t, err)
+	assert.Assert(t, specified)
+	assert.Assert(t, metav1.IsControlledBy(service, cluster))
+
+	assert.Assert(t, marshalMatches(service.Spec, `
+clusterIP: None
+ports:
+- name: exporter
+  port: 9187
+  protocol: TCP
+  targetPort: exporter
+selector:
+  postgres-operator.crunchydata.com/cluster: hippo
+  postgres-operator.crunchydata.com/crunchy-postgres-exporter: "true"
+	`))
+
+	monitor := generateExporterServiceMonitor(cluster, service)
+	assert.Assert(t, marshalMatches(monitor, `
+apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  labels:
+    postgres-operator.crunchydata.com/cluster: hippo
+    postgres-operator.crunchydata.com/role: monitoring
+    release: prometheus
+  name: hippo-exporter
+  namespace: ns1
+spec:
+  endpoints:
+  - interval: 30s
+    port: exporter
+  selector:
+    matchLabels:
+      postgres-operator.crunchydata.com/cluster: hippo
+      postgres-operator.crunchydata.com/role: monitoring
+	`))
+}
+
+func TestGenerateExporterPrometheusRule(t *testing.T) {
+	cluster := testCluster()
+	cluster.Namespace = "ns1"
+	cluster.Spec.Monitoring = &v1beta1.MonitoringSpec{
+		PGMonitor: &v1beta1.PGMonitorSpec{Exporter: &v1beta1.ExporterSpec{
+			ServiceMonitor: &v1beta1.ExporterServiceMonitorSpec{
+				PrometheusRule: &v1beta1.ExporterPrometheusRuleSpec{
+					Metadata: &v1beta1.Metadata{
+						Labels: map[string]string{"release": "prometheus"},
+					},
+				},
+			},
+		}},
+	}
+
+	service := &corev1.Service{ObjectMeta: naming.ClusterExporterService(cluster)}
+	rule := generateExporterPrometheusRule(cluster, service)
+
+	assert.Equal(t, rule.GetAPIVersion(), "monitoring.coreos.com/v1")
+	assert.Equal(t, rule.GetKind(), "PrometheusRule")
+	assert.Equal(t, rule.GetNamespace(), "ns1")
+	assert.Equal(t, rule.GetName(), "hippo-exporter")
+	assert.DeepEqual(t, rule.GetLabels(), map[string]string{
+		"postgres-operator.crunchydata.com/cluster": "hippo",
+		"postgres-operator.crunchydata.com/role":    "monitoring",
+		"release":                                   "prometheus",
+	})
+
+	groups, _, _ := unstructured.NestedSlice(rule.Object, "spec", "groups")
+	assert.Equal(t, len(groups), 1)
+
+	rules, _, _ := unstructured.NestedSlice(groups[0].(map[string]interface{}), "rules")
+	assert.Equal(t, len(rules), len(pgmonitor.AlertRules("")))
+
+	for _, item := range rules {
+		expr, _, _ := unstructured.NestedString(item.(map[string]interface{}), "expr")
+		assert.Assert(t, strings.Contains(expr, `{namespace="ns1",service="hippo-exporter"}`), "%q", expr)
+
+		cluster, _, _ := unstructured.NestedString(item.(map[string]interface{}), "labels", "cluster")
+		assert.Equal(t, cluster, "hippo")
+	}
+}
+
+func TestGenerateExporterGrafanaDashboard(t *testing.T) {
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	reconciler := &Reconciler{Client: fake.NewClientBuilder().WithScheme(scheme).Build()}
+
+	cluster := testCluster()
+	cluster.Namespace = "ns1"
+	cluster.Spec.Monitoring = &v1beta1.MonitoringSpec{
+		PGMonitor: &v1beta1.PGMonitorSpec{Exporter: &v1beta1.ExporterSpec{
+			ServiceMonitor: &v1beta1.ExporterServiceMonitorSpec{},
+		}},
+	}
+	service := &corev1.Service{ObjectMeta: naming.ClusterExporterService(cluster)}
+
+	t.Run("Disabled", func(t *testing.T) {
+		_, specified, err := reconciler.generateExporterGrafanaDashboard(cluster, service, true)
+		assert.NilError(t, err)
+		assert.Assert(t, !specified)
+	})
+
+	cluster.Spec.Monitoring.PGMonitor.Exporter.ServiceMonitor.GrafanaDashboard =
+		&v1beta1.ExporterGrafanaDashboardSpec{Folder: "Databases"}
+
+	t.Run("NoService", func(t *testing.T) {
+		_, specified, err := reconciler.generateExporterGrafanaDashboard(cluster, service, false)
+		assert.NilError(t, err)
+		assert.Assert(t, !specified)
+	})
+
+	configmap, specified, err := reconciler.generateExporterGrafanaDashboard(cluster, service, true)
+	assert.NilError(t, err)
+	assert.Assert(t, specified)
+	assert.Assert(t, metav1.IsControlledBy(configmap, cluster))
+
+	assert.Equal(t, configmap.Name, "hippo-grafana-dashboard")
+	assert.Equal(t, configmap.Labels["grafana_dashboard"], "1")
+	assert.Equal(t, configmap.Annotations["grafana_folder"], "Databases")
+
+	dashboard := configmap.Data["ns1-hippo.json"]
+	assert.Assert(t, strings.Contains(dashboard, `"title":"PostgreSQL / ns1 / hippo"`))
+	assert.Assert(t, strings.Contains(dashboard, `namespace=\"ns1\",service=\"hippo-exporter\"`))
+}
@@ -17,6 +17,8 @@ package postgrescluster
 
 import (
 	"context"
+	"crypto/x509"
+	"time"
 
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
@@ -35,6 +37,10 @@ const (
 	rootCertFile    = "ca.crt"
 )
 
+// certificateExpiringSoon is how long before a custom TLS certificate expires
+// that PGO starts to warn about it. PGO replaces the certificates it generates.
+const certificateExpiringSoon = 14 * 24 * time.Hour
+
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=create;patch
 
@@ -134,7 +140,8 @@ func (r *Reconciler) reconcileClusterCertificate(
 		r.Client.Get(ctx, client.ObjectKeyFromObject(existing), existing)))
 
 	leaf := pki.NewLeafCertificate("", nil, nil)
-	leaf.DNSNames = naming.ServiceDNSNames(ctx, primaryService)
+	leaf.DNSNames = append(naming.ServiceDNSNames(ctx, primaryService),
+		externalDNSNames(cluster)...)
 	leaf.CommonName = leaf.DNSNames[0] // FQDN
 
 	if data, ok := existing.Data[keyCertificate]; err == nil && ok {
@@ -192,6 +199,20 @@ func (r *Reconciler) reconcileClusterCertificate(
 	return clusterCertSecretProjection(intent), err
 }
 
+// externalDNSNames returns the hostnames that external-dns publishes for the
+// Services that expose PostgreSQL instances of cluster.
+func externalDNSNames(cluster *v1beta1.PostgresCluster) []string {
+	var names []string
+	for _, spec := range []*v1beta1.ServiceSpec{
+		cluster.Spec.Service, cluster.Spec.ReplicaService,
+	} {
+		if spec != nil {
+			names = append(names, naming.ExternalDNSNames(spec.Metadata.GetAnnotationsOrNil())...)
+		}
+	}
+	return names
+}
+
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=create;patch
 
@@ -267,3 +288,54 @@ func clusterCertSecretProjection(certificate *corev1.Secret) *corev1.SecretProje
 		},
 	}
 }
+
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get
+
+// reportExpiringCertificates records an event for each custom TLS certificate
+// of cluster that expires within certificateExpiringSoon of now.
+func (r *Reconciler) reportExpiringCertificates(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, now time.Time,
+) error {
+	// The Secrets can change or be removed from the spec; start over so that
+	// only the current certificates are reported.
+	forgetSeries(certificateExpiration, cluster)
+
+	var err error
+	for _, projection := range []*corev1.SecretProjection{
+		cluster.Spec.CustomTLSSecret,
+		cluster.Spec.CustomReplicationClientTLSSecret,
+	} {
+		if projection == nil || err != nil {
+			continue
+		}
+
+		// The certificate is whichever key is projected to "tls.crt".
+		key := clusterCertFile
+		for _, item := range projection.Items {
+			if item.Path == clusterCertFile {
+				key = item.Key
+			}
+		}
+
+		secret := &corev1.Secret{}
+		err = errors.WithStack(client.IgnoreNotFound(r.Client.Get(ctx,
+			client.ObjectKey{Namespace: cluster.Namespace, Name: projection.Name}, secret)))
+
+		// Ignore certificates that cannot be parsed; PostgreSQL reports those.
+		var parsed *x509.Certificate
+		if encoded, parseErr := pki.ParseCertificate(secret.Data[key]); parseErr == nil {
+			parsed, _ = x509.ParseCertificate(encoded.Certificate)
+		}
+
+		if err == nil && parsed != nil {
+			certificateExpiration.WithLabelValues(cluster.Namespace, cluster.Name,
+				projection.Name).Set(float64(parsed.NotAfter.Unix()))
+		}
+		if err == nil && parsed != nil && parsed.NotAfter.Sub(now) < certificateExpiringSoon {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventCertExpiring,
+				"Certificate %q in Secret %q expires at %s", key, projection.Name,
+				parsed.NotAfter.UTC().Format(time.RFC3339))
+		}
+	}
+	return err
+}
@@ -25,15 +25,20 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"gotest.tools/v3/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
 	"github.com/crunchydata/postgres-operator/internal/naming"
 	"github.com/crunchydata/postgres-operator/internal/pki"
 	"github.com/crunchydata/postgres-operator/internal/testing/require"
@@ -410,3 +415,63 @@ func getCertFromSecret(
 		return fromSecret, nil
 	}
 }
+
+func TestReportExpiringCertificates(t *testing.T) {
+	ctx := context.Background()
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	root := pki.NewRootCertificateAuthority()
+	assert.NilError(t, root.Generate())
+	leaf := pki.NewLeafCertificate("hippo", []string{"hippo"}, nil)
+	assert.NilError(t, leaf.Generate(root))
+
+	certificate, err := leaf.Certificate.MarshalText()
+	assert.NilError(t, err)
+	parsed, err := x509.ParseCertificate(leaf.Certificate.Certificate)
+	assert.NilError(t, err)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "custom"},
+		Data:       map[string][]byte{"cert": certificate},
+	}
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace, cluster.Name = "ns1", "hippo"
+	cluster.Spec.CustomTLSSecret = &corev1.SecretProjection{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "custom"},
+		Items:                []corev1.KeyToPath{{Key: "cert", Path: "tls.crt"}},
+	}
+	cluster.Spec.CustomReplicationClientTLSSecret = &corev1.SecretProjection{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "missing"},
+	}
+
+	recorder := record.NewFakeRecorder(10)
+	r := &Reconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build(),
+		Recorder: recorder,
+	}
+
+	// Nothing is recorded while the certificate has weeks left.
+	assert.NilError(t, r.reportExpiringCertificates(ctx, cluster,
+		parsed.NotAfter.Add(-certificateExpiringSoon-time.Hour)))
+	assert.Equal(t, len(recorder.Events), 0)
+
+	assert.NilError(t, r.reportExpiringCertificates(ctx, cluster,
+		parsed.NotAfter.Add(-24*time.Hour)))
+	assert.Equal(t, len(recorder.Events), 1)
+	assert.Equal(t, <-recorder.Events, fmt.Sprintf(
+		`Warning CertExpiring Certificate "cert" in Secret "custom" expires at %s`,
+		parsed.NotAfter.UTC().Format(time.RFC3339)))
+	assert.Equal(t, testutil.ToFloat64(
+		certificateExpiration.WithLabelValues("ns1", "hippo", "custom")),
+		float64(parsed.NotAfter.Unix()))
+
+	t.Run("Removed", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.CustomTLSSecret = nil
+
+		assert.NilError(t, r.reportExpiringCertificates(ctx, cluster, time.Now()))
+		assert.Assert(t, !certificateExpiration.DeleteLabelValues("ns1", "hippo", "custom"))
+	})
+}
@@ -58,9 +58,7 @@ func getMinAvailable(
 	minAvailable *intstr.IntOrString,
 	replicas int32,
 ) *intstr.IntOrString {
-	// TODO: Webhook Validation for minAvailable in the spec
-	// - MinAvailable should be less than replicas
-	// - MinAvailable as a string value should be a percentage string <= 100%
+	// The validating webhook rejects values that exceed replicas or 100%.
 	if minAvailable != nil {
 		return minAvailable
 	}
@@ -24,22 +24,28 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	"github.com/crunchydata/postgres-operator/internal/config"
 	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/logging"
 	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/patroni"
 	"github.com/crunchydata/postgres-operator/internal/pgaudit"
 	"github.com/crunchydata/postgres-operator/internal/postgis"
 	"github.com/crunchydata/postgres-operator/internal/postgres"
 	pgpassword "github.com/crunchydata/postgres-operator/internal/postgres/password"
+	"github.com/crunchydata/postgres-operator/internal/timescaledb"
 	"github.com/crunchydata/postgres-operator/internal/util"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
@@ -84,9 +90,9 @@ func (r *Reconciler) generatePostgresUserSecret(
 			}
 		}
 
-		password, err := generate(util.DefaultGeneratedPasswordLength)
+		password, err := generatePolicyPassword(generate, cluster.Spec.PasswordPolicy, username)
 		if err != nil {
-			return nil, errors.WithStack(err)
+			return nil, err
 		}
 		intent.Data["password"] = []byte(password)
 		intent.Data["verifier"] = nil
@@ -97,6 +103,15 @@ func (r *Reconciler) generatePostgresUserSecret(
 	// NOTE(cbandy): We don't have a function to compare a plaintext
 	// password to a SCRAM verifier.
 	if len(intent.Data["verifier"]) == 0 {
+		// Passwords that PGO did not generate might break the policy.
+		if existing != nil && len(existing.Data["password"]) > 0 {
+			if err := checkPasswordPolicy(
+				cluster.Spec.PasswordPolicy, username, string(existing.Data["password"]),
+			); err != nil {
+				return nil, passwordPolicyError{err}
+			}
+		}
+
 		verifier, err := pgpassword.NewSCRAMPassword(string(intent.Data["password"])).Build()
 		if err != nil {
 			return nil, errors.WithStack(err)
@@ -182,7 +197,195 @@ func (r *Reconciler) generatePostgresUserSecret(
 	return intent, err
 }
 
-// reconcilePostgresDatabases creates databases inside of PostgreSQL.
+// generateExternalPostgresUserSecret returns a Secret containing the verifier
+// of password and connection details that do not include password. The
+// verifier of existing is kept until revision of the password changes.
+func (r *Reconciler) generateExternalPostgresUserSecret(
+	cluster *v1beta1.PostgresCluster, spec *v1beta1.PostgresUserSpec,
+	existing *corev1.Secret, password, revision string,
+) (*corev1.Secret, error) {
+	current := &corev1.Secret{Data: map[string][]byte{"password": []byte(password)}}
+	if existing != nil && existing.Annotations[naming.PostgresPasswordRevision] == revision {
+		current.Data["verifier"] = existing.Data["verifier"]
+	}
+
+	intent, err := r.generatePostgresUserSecret(cluster, spec, current)
+	if err == nil {
+		// Remove every key that contains the password.
+		for _, key := range []string{
+			"password", "uri", "jdbc-uri", "pgbouncer-uri", "pgbouncer-jdbc-uri",
+		} {
+			delete(intent.Data, key)
+		}
+
+		intent.Annotations = naming.Merge(intent.Annotations,
+			map[string]string{naming.PostgresPasswordRevision: revision})
+	}
+	return intent, err
+}
+
+// generateSecretRefPostgresUserSecret returns a Secret containing the verifier
+// of a password stored in another Secret and connection details that do not
+// include that password.
+func (r *Reconciler) generateSecretRefPostgresUserSecret(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+	spec *v1beta1.PostgresUserSpec, existing *corev1.Secret,
+) (*corev1.Secret, error) {
+	ref := spec.Password.SecretKeyRef
+	source := &corev1.Secret{}
+	err := errors.WithStack(r.Client.Get(ctx,
+		client.ObjectKey{Namespace: cluster.Namespace, Name: ref.Name}, source))
+
+	if err == nil && len(source.Data[ref.Key]) == 0 {
+		err = errors.Errorf("secret %q has no %q key", ref.Name, ref.Key)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return r.generateExternalPostgresUserSecret(cluster, spec, existing,
+		string(source.Data[ref.Key]),
+		"secret/"+string(source.UID)+"/"+source.ResourceVersion)
+}
+
+// +kubebuilder:rbac:groups="",resources="secrets",verbs={get}
+
+// sourcePostgresUserSecret returns the Secret of userName in the PostgresCluster
+// that cluster is cloned from when its passwords should be copied. It returns
+// nil when there is no such Secret or PostgreSQL of cluster is already running.
+// The restore of a clone finishes before the Secrets of cluster are created, so
+// this cannot wait for the data to be initialized.
+// A Secret in another namespace is returned only when it allows the namespace
+// of cluster in its [naming.PostgresPasswordCopyNamespaces] annotation.
+func (r *Reconciler) sourcePostgresUserSecret(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, userName string,
+) (*corev1.Secret, error) {
+	if cluster.Spec.DataSource == nil || cluster.Spec.DataSource.PostgresCluster == nil {
+		return nil, nil
+	}
+	source := cluster.Spec.DataSource.PostgresCluster
+	if source.CopyUserPasswords == nil || !*source.CopyUserPasswords ||
+		patroni.ClusterBootstrapped(cluster) {
+		return nil, nil
+	}
+
+	sourceCluster := &v1beta1.PostgresCluster{}
+	sourceCluster.Name = source.ClusterName
+	sourceCluster.Namespace = source.ClusterNamespace
+	if sourceCluster.Name == "" {
+		sourceCluster.Name = cluster.Name
+	}
+	if sourceCluster.Namespace == "" {
+		sourceCluster.Namespace = cluster.Namespace
+	}
+
+	secret := &corev1.Secret{ObjectMeta: naming.PostgresUserSecret(sourceCluster, userName)}
+	err := errors.WithStack(r.Client.Get(ctx, client.ObjectKeyFromObject(secret), secret))
+
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err == nil && secret.Namespace != cluster.Namespace &&
+		!postgresPasswordCopyAllowed(secret, cluster.Namespace) {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventPasswordSecretUnavailable,
+			"Not copying the password of %q: Secret %q does not allow namespace %q in %q",
+			userName, secret.Namespace+"/"+secret.Name, cluster.Namespace,
+			naming.PostgresPasswordCopyNamespaces)
+		return nil, nil
+	}
+	return secret, err
+}
+
+// postgresPasswordCopyAllowed returns whether or not the annotations of secret
+// allow its password to be copied to namespace.
+func postgresPasswordCopyAllowed(secret *corev1.Secret, namespace string) bool {
+	for _, allowed := range strings.Split(
+		secret.Annotations[naming.PostgresPasswordCopyNamespaces], ",",
+	) {
+		if strings.TrimSpace(allowed) == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// postgresUserSpec returns the spec of user in cluster, or nil when user is not
+// listed in the users of cluster.
+func postgresUserSpec(
+	cluster *v1beta1.PostgresCluster, user v1beta1.PostgresIdentifier,
+) *v1beta1.PostgresUserSpec {
+	for i := range cluster.Spec.Users {
+		if cluster.Spec.Users[i].Name == user {
+			return &cluster.Spec.Users[i]
+		}
+	}
+	return nil
+}
+
+// postgresUserHasDatabase returns whether or not user is listed in the users of
+// cluster with at least one database. Only those users have a Secret with the
+// keys of [postgresUserEnvironment].
+func postgresUserHasDatabase(
+	cluster *v1beta1.PostgresCluster, user v1beta1.PostgresIdentifier,
+) bool {
+	spec := postgresUserSpec(cluster, user)
+	return spec != nil && len(spec.Databases) > 0
+}
+
+// postgresUserPasswordInVault returns whether or not the password of user is
+// stored in HashiCorp Vault. That password is never written to a Secret, so
+// Pods cannot use it through [postgresUserEnvironment].
+func postgresUserPasswordInVault(
+	cluster *v1beta1.PostgresCluster, user v1beta1.PostgresIdentifier,
+) bool {
+	spec := postgresUserSpec(cluster, user)
+	return spec != nil && spec.Password != nil && spec.Password.Vault != nil
+}
+
+// postgresUserEnvironment returns the libpq environment variables that connect
+// as user to its first database through the primary Service. The values come
+// from the Secret of user except the password of a user that keeps it in
+// another Secret; that password comes from the other Secret.
+// - https://www.postgresql.org/docs/current/libpq-envars.html
+func postgresUserEnvironment(
+	cluster *v1beta1.PostgresCluster, user v1beta1.PostgresIdentifier,
+) []corev1.EnvVar {
+	secret := naming.PostgresUserSecret(cluster, string(user))
+	fromSecret := func(name, key string) corev1.EnvVar {
+		return corev1.EnvVar{
+			Name: name,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: secret.Name},
+					Key:                  key,
+				},
+			},
+		}
+	}
+
+	password := fromSecret("PGPASSWORD", "password")
+	if spec := postgresUserSpec(cluster, user); spec != nil &&
+		spec.Password != nil && spec.Password.SecretKeyRef != nil {
+		password.ValueFrom.SecretKeyRef = &corev1.SecretKeySelector{
+			LocalObjectReference: spec.Password.SecretKeyRef.LocalObjectReference,
+			Key:                  spec.Password.SecretKeyRef.Key,
+		}
+	}
+
+	return []corev1.EnvVar{
+		fromSecret("PGHOST", "host"),
+		fromSecret("PGPORT", "port"),
+		fromSecret("PGUSER", "user"),
+		password,
+		fromSecret("PGDATABASE", "dbname"),
+
+		// Connections through the primary Service must use TLS.
+		{Name: "PGSSLMODE", Value: "require"},
+	}
+}
+
+// reconcilePostgresDatabases creates databases inside of PostgreSQL. Their
+// owners and schemas are written along with users.
 func (r *Reconciler) reconcilePostgresDatabases(
 	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
 ) error {
@@ -216,7 +419,7 @@ func (r *Reconciler) reconcilePostgresDatabases(
 		// Database names cannot be too long. PostgresCluster.Name is a DNS
 		// subdomain, so use len() to count characters.
 		if n := len(cluster.Name); n > 63 {
-			r.Recorder.Event(cluster, corev1.EventTypeWarning, "InvalidDatabase",
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, EventInvalidDatabase,
 				field.Invalid(path, cluster.Name,
 					fmt.Sprintf("should be at most %d chars long", 63)).Error())
 		} else {
@@ -229,17 +432,34 @@ func (r *Reconciler) reconcilePostgresDatabases(
 			}
 		}
 	}
+	for _, database := range cluster.Spec.Databases {
+		databases.Insert(string(database.Name))
+	}
 
 	// Calculate a hash of the SQL that should be executed in PostgreSQL.
 
-	var pgAuditOK, postgisInstallOK bool
+	// TimescaleDB is updated to the version in the image running PostgreSQL.
+	// Do not consider it updated until the writable pod is running the
+	// intended image.
+	timescaleImage := ""
+	timescaleImageOK := true
+	if cluster.Spec.TimescaleDBVersion != "" {
+		timescaleImage = config.PostgresContainerImage(cluster)
+		for _, c := range pod.Spec.Containers {
+			if c.Name == container {
+				timescaleImageOK = c.Image == timescaleImage
+			}
+		}
+	}
+
+	var extensionsOK, pgAuditOK, postgisInstallOK, timescaleInstallOK bool
 	create := func(ctx context.Context, exec postgres.Executor) error {
 		if pgAuditOK = pgaudit.EnableInPostgreSQL(ctx, exec) == nil; !pgAuditOK {
 			// pgAudit can only be enabled after its shared library is loaded,
 			// but early versions of PGO do not load it automatically. Assume
 			// that an error here is because the cluster started during one of
 			// those versions and has not been restarted.
-			r.Recorder.Event(cluster, corev1.EventTypeWarning, "pgAuditDisabled",
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, EventPGAuditDisabled,
 				"Unable to install pgAudit")
 		}
 
@@ -251,14 +471,44 @@ func (r *Reconciler) reconcilePostgresDatabases(
 			postgisInstallOK = true
 		} else if postgisInstallOK = postgis.EnableInPostgreSQL(ctx, exec) == nil; !postgisInstallOK {
 			// TODO(benjb): Investigate under what conditions postgis would fail install
-			r.Recorder.Event(cluster, corev1.EventTypeWarning, "PostGISDisabled",
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, EventPostGISDisabled,
 				"Unable to install PostGIS")
 		}
 
-		return postgres.CreateDatabasesInPostgreSQL(ctx, exec, databases.List())
+		// Like PostGIS, TimescaleDB is never removed. Changing its version (and
+		// therefore the image) updates the extension in every database.
+		if cluster.Spec.TimescaleDBVersion == "" {
+			timescaleInstallOK = true
+		} else if timescaleInstallOK = timescaledb.EnableInPostgreSQL(ctx, exec) == nil; !timescaleInstallOK {
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, EventTimescaleDBDisabled,
+				"Unable to install TimescaleDB")
+		}
+
+		err := postgres.CreateDatabasesInPostgreSQL(ctx, exec, databases.List())
+
+		// Extensions are created after databases so that they can be created
+		// in any of them. Like pgAudit, an extension with a shared library
+		// cannot be created until PostgreSQL restarts to load it. Assume that
+		// an error here is because that restart has not happened yet.
+		if len(cluster.Spec.Extensions) == 0 {
+			extensionsOK = true
+		} else if err == nil {
+			if extensionsOK = postgres.CreateExtensionsInPostgreSQL(
+				ctx, exec, cluster.Spec.Extensions) == nil; !extensionsOK {
+				r.Recorder.Event(cluster, corev1.EventTypeWarning, EventExtensionsDisabled,
+					"Unable to create extensions")
+			}
+		}
+
+		return err
 	}
 
 	revision, err := safeHash32(func(hasher io.Writer) error {
+		// Execute the SQL again when the TimescaleDB image changes.
+		if _, err := fmt.Fprint(hasher, timescaleImage); err != nil {
+			return err
+		}
+
 		// Discard log messages about executing SQL.
 		return create(logging.NewContext(ctx, logging.Discard()), func(
 			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
@@ -286,7 +536,8 @@ func (r *Reconciler) reconcilePostgresDatabases(
 		log := logging.FromContext(ctx).WithValues("revision", revision)
 		err = errors.WithStack(create(logging.NewContext(ctx, log), podExecutor))
 	}
-	if err == nil && pgAuditOK && postgisInstallOK {
+	if err == nil && extensionsOK && pgAuditOK && postgisInstallOK &&
+		timescaleInstallOK && timescaleImageOK {
 		cluster.Status.DatabaseRevision = revision
 	}
 
@@ -297,11 +548,26 @@ func (r *Reconciler) reconcilePostgresDatabases(
 // passwords in PostgreSQL.
 func (r *Reconciler) reconcilePostgresUsers(
 	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
-) error {
+) (reconcile.Result, error) {
+	var result reconcile.Result
 	users, secrets, err := r.reconcilePostgresUserSecrets(ctx, cluster)
-	if err == nil {
+
+	// Passwords in Vault change without any event in Kubernetes. Read them
+	// again once those read now are no longer recent, or once Vault is back.
+	for i := range users {
+		if users[i].Password != nil && users[i].Password.Vault != nil {
+			result.RequeueAfter = vaultRefreshInterval
+		}
+	}
+
+	// Keep the Secrets up to date while PostgreSQL refuses writes, but wait
+	// to change users inside it. See [Reconciler.reconcileDiskUsage].
+	if err == nil && !diskUsageReadOnly(cluster) {
 		err = r.reconcilePostgresUsersInPostgreSQL(ctx, cluster, instances, users, secrets)
 	}
+	if err == nil {
+		err = r.reconcilePostgresPasswordExpirations(ctx, cluster, instances, users)
+	}
 	if err == nil {
 		// Copy PostgreSQL users and passwords into pgAdmin. This is here because
 		// reconcilePostgresUserSecrets is building a (default) PostgresUserSpec
@@ -309,7 +575,7 @@ func (r *Reconciler) reconcilePostgresUsers(
 		// are available here, too.
 		err = r.reconcilePGAdminUsers(ctx, cluster, users, secrets)
 	}
-	return err
+	return result, err
 }
 
 // +kubebuilder:rbac:groups="",resources="secrets",verbs={list}
@@ -349,7 +615,7 @@ func (r *Reconciler) reconcilePostgresUserSecrets(
 		}
 
 		if len(allErrors) > 0 {
-			r.Recorder.Event(cluster, corev1.EventTypeWarning, "InvalidUser",
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, EventInvalidUser,
 				allErrors.ToAggregate().Error())
 		} else {
 			identifier := v1beta1.PostgresIdentifier(cluster.Name)
@@ -413,17 +679,134 @@ func (r *Reconciler) reconcilePostgresUserSecrets(
 			secret = defaultSecret
 		}
 
-		if err == nil {
-			userSecrets[userName], err = r.generatePostgresUserSecret(cluster, user, secret)
+		if err == nil && user.Password != nil && user.Password.Vault != nil {
+			// Keep a password that was read from Vault recently rather than
+			// reading it on every reconcile.
+			if vaultPasswordIsRecent(user.Password.Vault, secret, time.Now()) {
+				userSecrets[userName] = secret
+				continue
+			}
+
+			var intent *corev1.Secret
+			intent, err = r.generateVaultPostgresUserSecret(ctx, cluster, user, secret)
+
+			// When Vault is unavailable, keep using the verifier that is
+			// already in PostgreSQL, if any. Wait for Vault without blocking
+			// the other users; [Reconciler.reconcilePostgresUsers] asks to
+			// try again later.
+			if err != nil && !isPasswordPolicyError(err) {
+				r.Recorder.Event(cluster, corev1.EventTypeWarning, EventVaultUnavailable,
+					fmt.Sprintf("Unable to read the password of %q: %v", userName, err))
+
+				if secret != nil && len(secret.Data["verifier"]) > 0 {
+					userSecrets[userName], err = secret, nil
+				} else {
+					delete(userSecrets, userName)
+					err = nil
+				}
+				continue
+			}
+			userSecrets[userName] = intent
+
+		} else if err == nil && user.Password != nil && user.Password.SecretKeyRef != nil {
+			var intent *corev1.Secret
+			intent, err = r.generateSecretRefPostgresUserSecret(ctx, cluster, user, secret)
+
+			// The referenced Secret may not be synced yet. Wait for it
+			// without blocking the other users.
+			if err != nil && !isPasswordPolicyError(err) {
+				r.Recorder.Event(cluster, corev1.EventTypeWarning, EventPasswordSecretUnavailable,
+					fmt.Sprintf("Unable to read the password of %q: %v", userName, err))
+
+				if secret != nil && len(secret.Data["verifier"]) > 0 {
+					userSecrets[userName], err = secret, nil
+				} else {
+					delete(userSecrets, userName)
+					err = nil
+				}
+				continue
+			}
+			userSecrets[userName] = intent
+
+		} else if err == nil {
+			existing := secret
+			if existing == nil {
+				existing, err = r.sourcePostgresUserSecret(ctx, cluster, userName)
+			}
+			if err == nil {
+				userSecrets[userName], err = r.generatePostgresUserSecret(cluster, user, existing)
+			}
+		}
+
+		// Do not change PostgreSQL to a password that breaks the policy. Keep
+		// the verifier that it already has, if any.
+		if isPasswordPolicyError(err) {
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, EventPasswordPolicyViolation,
+				fmt.Sprintf("The password of %q was not changed: %v", userName, err))
+
+			if secret != nil {
+				userSecrets[userName] = secret
+			} else {
+				delete(userSecrets, userName)
+			}
+			err = nil
+			continue
 		}
 		if err == nil {
 			err = errors.WithStack(r.apply(ctx, userSecrets[userName]))
 		}
+		if err == nil && secret == nil {
+			r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventUserCreated,
+				"Created Secret %q for PostgreSQL user %q", userSecrets[userName].Name, userName)
+		}
 	}
 
 	return specUsers, userSecrets, err
 }
 
+// databasesOfUsers returns the databases of cluster without the owners,
+// readers, and writers that are not in specUsers. Those roles might not exist
+// in PostgreSQL. A database whose owner is unknown is left out entirely, so
+// that it is not given to the "postgres" superuser. Each omission is recorded
+// as an event; the webhook rejects them, but it is optional.
+func (r *Reconciler) databasesOfUsers(
+	cluster *v1beta1.PostgresCluster, specUsers []v1beta1.PostgresUserSpec,
+) []v1beta1.PostgresDatabaseSpec {
+	users := sets.NewString()
+	for i := range specUsers {
+		users.Insert(string(specUsers[i].Name))
+	}
+
+	known := func(database string, roles []v1beta1.PostgresIdentifier) []v1beta1.PostgresIdentifier {
+		var out []v1beta1.PostgresIdentifier
+		for _, role := range roles {
+			if users.Has(string(role)) {
+				out = append(out, role)
+			} else {
+				r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventInvalidDatabase,
+					"Skipped user %q of database %q; it is not in spec.users", role, database)
+			}
+		}
+		return out
+	}
+
+	var databases []v1beta1.PostgresDatabaseSpec
+	for _, database := range cluster.Spec.Databases {
+		if database.Owner != "" && !users.Has(string(database.Owner)) {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventInvalidDatabase,
+				"Skipped database %q; its owner %q is not in spec.users",
+				database.Name, database.Owner)
+			continue
+		}
+
+		database = *database.DeepCopy()
+		database.Readers = known(string(database.Name), database.Readers)
+		database.Writers = known(string(database.Name), database.Writers)
+		databases = append(databases, database)
+	}
+	return databases
+}
+
 // reconcilePostgresUsersInPostgreSQL creates users inside of PostgreSQL and
 // sets their options and database access as specified.
 func (r *Reconciler) reconcilePostgresUsersInPostgreSQL(
@@ -467,8 +850,21 @@ func (r *Reconciler) reconcilePostgresUsersInPostgreSQL(
 		verifiers[userName] = string(userSecrets[userName].Data["verifier"])
 	}
 
+	databases := r.databasesOfUsers(cluster, specUsers)
+
 	write := func(ctx context.Context, exec postgres.Executor) error {
-		return postgres.WriteUsersInPostgreSQL(ctx, exec, specUsers, verifiers)
+		err := postgres.WriteUsersInPostgreSQL(ctx, exec, specUsers, verifiers)
+
+		// Configure pgAudit for roles after they exist.
+		if err == nil && cluster.Spec.Audit != nil && len(cluster.Spec.Audit.Roles) > 0 {
+			err = pgaudit.RoleSettingsInPostgreSQL(ctx, exec, cluster.Spec.Audit.Roles)
+		}
+
+		// Give databases to their owners, readers, and writers after those exist, too.
+		if err == nil && len(databases) > 0 {
+			err = postgres.WriteDatabasesInPostgreSQL(ctx, exec, databases)
+		}
+		return err
 	}
 
 	revision, err := safeHash32(func(hasher io.Writer) error {
@@ -506,6 +902,188 @@ func (r *Reconciler) reconcilePostgresUsersInPostgreSQL(
 	return err
 }
 
+// reconcilePostgresPasswordExpirations reads when the passwords of specUsers
+// expire and reports them in the status of cluster. Users can set an expiration
+// with the VALID UNTIL option.
+func (r *Reconciler) reconcilePostgresPasswordExpirations(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+	specUsers []v1beta1.PostgresUserSpec,
+) error {
+	const container = naming.ContainerDatabase
+
+	report := func(expirations map[string]time.Time) {
+		// Forget the metrics of users whose passwords no longer expire.
+		for user := range cluster.Status.PasswordExpirations {
+			if _, ok := expirations[user]; !ok {
+				passwordExpiration.DeleteLabelValues(cluster.Namespace, cluster.Name, user)
+			}
+		}
+
+		cluster.Status.PasswordExpirations = nil
+		for user, expiration := range expirations {
+			if cluster.Status.PasswordExpirations == nil {
+				cluster.Status.PasswordExpirations = make(map[string]metav1.Time, len(expirations))
+			}
+			cluster.Status.PasswordExpirations[user] = metav1.NewTime(expiration)
+		}
+	}
+
+	// When no user has an expiration, there is nothing to read.
+	expires := false
+	for i := range specUsers {
+		expires = expires || postgresUserPasswordExpires(specUsers[i])
+	}
+	if !expires {
+		report(nil)
+		cluster.Status.PasswordExpirationsRevision = ""
+		return nil
+	}
+
+	// Find the PostgreSQL instance that has the latest expirations. When there
+	// is none, return early.
+	pod, _ := instances.writablePod(container)
+	if pod == nil {
+		return nil
+	}
+
+	// Expirations change only when users and their options are written to
+	// PostgreSQL. Calculate a hash of those users and the revision written.
+	revision, err := safeHash32(func(hasher io.Writer) error {
+		_, err := fmt.Fprint(hasher, cluster.Status.UsersRevision)
+		for i := 0; err == nil && i < len(specUsers); i++ {
+			_, err = fmt.Fprint(hasher, " ", specUsers[i].Name)
+		}
+		return err
+	})
+
+	if err == nil && revision == cluster.Status.PasswordExpirationsRevision {
+		// The expirations have already been read; there's nothing more to do.
+		return nil
+	}
+
+	var expirations map[string]time.Time
+	if err == nil {
+		ctx = logging.NewContext(ctx, logging.FromContext(ctx).WithValues("pod", pod.Name))
+		podExecutor := postgres.Executor(func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			return r.PodExec(pod.Namespace, pod.Name, container, stdin, stdout, stderr, command...)
+		})
+
+		expirations, err = postgres.PasswordExpirationsInPostgreSQL(ctx, podExecutor, specUsers)
+	}
+	if err == nil {
+		report(expirations)
+		cluster.Status.PasswordExpirationsRevision = revision
+	}
+	return errors.WithStack(err)
+}
+
+// postgresUserPasswordExpires returns whether or not the options of user set
+// when its password expires.
+func postgresUserPasswordExpires(user v1beta1.PostgresUserSpec) bool {
+	return strings.Contains(
+		strings.ToUpper(strings.Join(strings.Fields(user.Options), " ")), "VALID UNTIL")
+}
+
+// reconcilePostgresLogicalReplication creates publications and subscriptions
+// inside of PostgreSQL.
+func (r *Reconciler) reconcilePostgresLogicalReplication(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) error {
+	const container = naming.ContainerDatabase
+	var podExecutor postgres.Executor
+
+	spec := cluster.Spec.LogicalReplication
+	if spec == nil || (len(spec.Publications) == 0 && len(spec.Subscriptions) == 0) {
+		cluster.Status.LogicalReplicationRevision = ""
+		return nil
+	}
+
+	// Find the PostgreSQL instance that can execute SQL that writes system
+	// catalogs. When there is none, return early.
+	pod, _ := instances.writablePod(container)
+	if pod == nil {
+		return nil
+	}
+
+	ctx = logging.NewContext(ctx, logging.FromContext(ctx).WithValues("pod", pod.Name))
+	podExecutor = func(
+		_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error {
+		return r.PodExec(pod.Namespace, pod.Name, container, stdin, stdout, stderr, command...)
+	}
+
+	// Gather the connection string of each subscription from its Secret.
+
+	var err error
+	connections := make(map[string]string, len(spec.Subscriptions))
+	for i := 0; err == nil && i < len(spec.Subscriptions); i++ {
+		subscription := spec.Subscriptions[i]
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Namespace: cluster.Namespace,
+			Name:      subscription.Connection.Name,
+		}}
+
+		err = errors.WithStack(client.IgnoreNotFound(
+			r.Client.Get(ctx, client.ObjectKeyFromObject(secret), secret)))
+
+		if value, ok := secret.Data[subscription.Connection.Key]; ok {
+			connections[string(subscription.Name)] = string(value)
+		} else if err == nil && (subscription.Connection.Optional == nil ||
+			!*subscription.Connection.Optional) {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventInvalidSubscription,
+				"Unable to find key %q of Secret %q for subscription %q",
+				subscription.Connection.Key, subscription.Connection.Name, subscription.Name)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	// Calculate a hash of the SQL that should be executed in PostgreSQL.
+
+	write := func(ctx context.Context, exec postgres.Executor) error {
+		err := postgres.WritePublicationsInPostgreSQL(ctx, exec, spec.Publications)
+		if err == nil {
+			err = postgres.WriteSubscriptionsInPostgreSQL(ctx, exec,
+				spec.Subscriptions, connections)
+		}
+		return err
+	}
+
+	revision, err := safeHash32(func(hasher io.Writer) error {
+		// Discard log messages about executing SQL.
+		return write(logging.NewContext(ctx, logging.Discard()), func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			_, err := fmt.Fprint(hasher, command)
+			if err == nil && stdin != nil {
+				_, err = io.Copy(hasher, stdin)
+			}
+			return err
+		})
+	})
+
+	if err == nil && revision == cluster.Status.LogicalReplicationRevision {
+		// The necessary SQL has already been applied; there's nothing more to do.
+		return nil
+	}
+
+	// Apply the necessary SQL and record its hash in cluster.Status. Include
+	// the hash in any log messages.
+
+	if err == nil {
+		log := logging.FromContext(ctx).WithValues("revision", revision)
+		err = errors.WithStack(write(logging.NewContext(ctx, log), podExecutor))
+	}
+	if err == nil && len(connections) == len(spec.Subscriptions) {
+		cluster.Status.LogicalReplicationRevision = revision
+	}
+
+	return err
+}
+
 // +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=create;patch
 
 // reconcilePostgresDataVolume writes the PersistentVolumeClaim for instance's
@@ -552,7 +1130,9 @@ func (r *Reconciler) reconcilePostgresDataVolume(
 		labelMap,
 	)
 
-	pvc.Spec = instanceSpec.DataVolumeClaimSpec
+	pvc.Spec = *instanceSpec.DataVolumeClaimSpec.DeepCopy()
+
+	keepExpandedVolumeSize(cluster, &pvc.Spec, existingPVCName, clusterVolumes)
 
 	if err == nil {
 		err = r.handlePersistentVolumeClaimError(cluster,
@@ -655,7 +1235,80 @@ func (r *Reconciler) reconcilePostgresWALVolume(
 		labelMap,
 	)
 
-	pvc.Spec = *instanceSpec.WALVolumeClaimSpec
+	pvc.Spec = *instanceSpec.WALVolumeClaimSpec.DeepCopy()
+	keepExpandedVolumeSize(cluster, &pvc.Spec, existingPVCName, clusterVolumes)
+
+	if err == nil {
+		err = r.handlePersistentVolumeClaimError(cluster,
+			errors.WithStack(r.apply(ctx, pvc)))
+	}
+
+	return pvc, err
+}
+
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;create;delete;patch
+
+// reconcilePostgresTempVolume writes the PersistentVolumeClaim for instance's
+// temporary files when spec.tempVolume.volumeClaimSpec is set. Otherwise, it
+// deletes that PersistentVolumeClaim, if any.
+func (r *Reconciler) reconcilePostgresTempVolume(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+	instanceSpec *v1beta1.PostgresInstanceSetSpec, instance *appsv1.StatefulSet,
+	clusterVolumes []corev1.PersistentVolumeClaim,
+) (*corev1.PersistentVolumeClaim, error) {
+
+	labelMap := map[string]string{
+		naming.LabelCluster:     cluster.Name,
+		naming.LabelInstanceSet: instanceSpec.Name,
+		naming.LabelInstance:    instance.Name,
+		naming.LabelRole:        naming.RolePostgresTemp,
+		naming.LabelData:        naming.DataPostgres,
+	}
+
+	var pvc *corev1.PersistentVolumeClaim
+	existingPVCName, err := getPGPVCName(labelMap, clusterVolumes)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if existingPVCName != "" {
+		pvc = &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+			Namespace: cluster.GetNamespace(),
+			Name:      existingPVCName,
+		}}
+	} else {
+		pvc = &corev1.PersistentVolumeClaim{ObjectMeta: naming.InstancePostgresTempVolume(instance)}
+	}
+
+	pvc.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("PersistentVolumeClaim"))
+
+	if cluster.Spec.TempVolume == nil || cluster.Spec.TempVolume.VolumeClaimSpec == nil {
+		// Temporary files do not outlive the processes that write them, so
+		// there is nothing to move before deleting the PVC. It continues to
+		// exist until the Pods using it are also deleted.
+		// - https://docs.k8s.io/concepts/storage/persistent-volumes/#storage-object-in-use-protection
+		if existingPVCName == "" {
+			return nil, nil
+		}
+		err := errors.WithStack(r.Client.Get(ctx, client.ObjectKeyFromObject(pvc), pvc))
+		if err == nil && pvc.DeletionTimestamp == nil {
+			err = errors.WithStack(r.deleteControlled(ctx, cluster, pvc))
+		}
+		return nil, client.IgnoreNotFound(err)
+	}
+
+	err = errors.WithStack(r.setControllerReference(cluster, pvc))
+
+	pvc.Annotations = naming.Merge(
+		cluster.Spec.Metadata.GetAnnotationsOrNil(),
+		instanceSpec.Metadata.GetAnnotationsOrNil())
+
+	pvc.Labels = naming.Merge(
+		cluster.Spec.Metadata.GetLabelsOrNil(),
+		instanceSpec.Metadata.GetLabelsOrNil(),
+		labelMap,
+	)
+
+	pvc.Spec = *cluster.Spec.TempVolume.VolumeClaimSpec.DeepCopy()
 
 	if err == nil {
 		err = r.handlePersistentVolumeClaimError(cluster,
@@ -665,6 +1318,134 @@ func (r *Reconciler) reconcilePostgresWALVolume(
 	return pvc, err
 }
 
+// reconcilePostgresTempTablespace creates the temporary tablespace inside of
+// PostgreSQL once every instance has mounted the temp volume, and drops it
+// when spec.tempVolume is removed.
+func (r *Reconciler) reconcilePostgresTempTablespace(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) error {
+	const container = naming.ContainerDatabase
+	create := cluster.Spec.TempVolume != nil
+
+	// There is nothing to drop when the tablespace was never created.
+	if !create && cluster.Status.TempTablespaceRevision == "" {
+		return nil
+	}
+
+	// Replicas replay the creation of a tablespace and stop when its location
+	// does not exist. Wait until the temp volume is mounted everywhere.
+	if create {
+		for _, instance := range instances.forCluster {
+			for _, pod := range instance.Pods {
+				mounted := false
+				for _, volume := range pod.Spec.Volumes {
+					mounted = mounted || volume.Name == postgres.TempVolumeMount().Name
+				}
+				if !mounted {
+					return nil
+				}
+			}
+		}
+	}
+
+	// Find the PostgreSQL instance that can execute SQL that writes system
+	// catalogs. When there is none, return early.
+	pod, _ := instances.writablePod(container)
+	if pod == nil {
+		return nil
+	}
+
+	ctx = logging.NewContext(ctx, logging.FromContext(ctx).WithValues("pod", pod.Name))
+	podExecutor := postgres.Executor(func(
+		_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error {
+		return r.PodExec(pod.Namespace, pod.Name, container, stdin, stdout, stderr, command...)
+	})
+
+	// Calculate a hash of the SQL that should be executed in PostgreSQL.
+
+	revision, err := safeHash32(func(hasher io.Writer) error {
+		// Discard log messages about executing SQL.
+		return postgres.WriteTempTablespaceInPostgreSQL(
+			logging.NewContext(ctx, logging.Discard()), func(
+				_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+			) error {
+				_, err := fmt.Fprint(hasher, command)
+				if err == nil && stdin != nil {
+					_, err = io.Copy(hasher, stdin)
+				}
+				return err
+			}, create)
+	})
+
+	if err == nil && revision == cluster.Status.TempTablespaceRevision {
+		// The necessary SQL has already been applied; there's nothing more to do.
+		return nil
+	}
+
+	// Apply the necessary SQL and record its hash in cluster.Status. Include
+	// the hash in any log messages.
+
+	if err == nil {
+		log := logging.FromContext(ctx).WithValues("revision", revision)
+		err = errors.WithStack(postgres.WriteTempTablespaceInPostgreSQL(
+			logging.NewContext(ctx, log), podExecutor, create))
+	}
+	if err == nil {
+		cluster.Status.TempTablespaceRevision = revision
+		if !create {
+			cluster.Status.TempTablespaceRevision = ""
+		}
+	}
+
+	// PostgreSQL refuses to drop a tablespace that still has files, such as
+	// those of a long running query. Report it and try again next time rather
+	// than hold up the rest of reconciliation.
+	if err != nil && !create {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventTempTablespaceNotDropped,
+			"Unable to drop the temporary tablespace: %v", err)
+		err = nil
+	}
+
+	return err
+}
+
+// reconcileQueryStatisticsReset resets the statistics of pg_stat_statements
+// when the reset-query-statistics annotation of cluster changes.
+func (r *Reconciler) reconcileQueryStatisticsReset(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) error {
+	const container = naming.ContainerDatabase
+	annotation := cluster.GetAnnotations()[naming.ResetQueryStatistics]
+
+	if annotation == "" || annotation == cluster.Status.QueryStatisticsReset {
+		return nil
+	}
+
+	// Replicas have statistics of their own, but only those of the primary
+	// describe writes. Reset the primary; when there is none, return early.
+	pod, _ := instances.writablePod(container)
+	if pod == nil {
+		return nil
+	}
+
+	ctx = logging.NewContext(ctx, logging.FromContext(ctx).WithValues("pod", pod.Name))
+	podExecutor := postgres.Executor(func(
+		_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error {
+		return r.PodExec(pod.Namespace, pod.Name, container, stdin, stdout, stderr, command...)
+	})
+
+	err := errors.WithStack(postgres.ResetQueryStatisticsInPostgreSQL(ctx, podExecutor))
+	if err == nil {
+		cluster.Status.QueryStatisticsReset = annotation
+		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventQueryStatisticsReset,
+			"Reset the statistics of pg_stat_statements on %q", pod.Name)
+	}
+
+	return err
+}
+
 // reconcileDatabaseInitSQL runs custom SQL files in the database. When
 // DatabaseInitSQL is defined, the function will find the primary pod and run
 // SQL from the defined ConfigMap
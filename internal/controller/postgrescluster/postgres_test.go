@@ -22,17 +22,23 @@ import (
 	"context"
 	"io"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/pkg/errors"
 	"gotest.tools/v3/assert"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/yaml"
 
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
 	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/naming"
 	"github.com/crunchydata/postgres-operator/internal/postgres"
@@ -237,6 +243,238 @@ func TestGeneratePostgresUserSecret(t *testing.T) {
 	})
 }
 
+func TestGenerateSecretRefPostgresUserSecret(t *testing.T) {
+	ctx := context.Background()
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	source := &corev1.Secret{}
+	source.Namespace, source.Name = "ns1", "synced"
+	source.Data = map[string][]byte{"pw": []byte("from-store")}
+
+	reconciler := &Reconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(source).Build(),
+	}
+
+	cluster := testCluster()
+	cluster.Namespace = "ns1"
+	cluster.Spec.Port = initialize.Int32(5432)
+	cluster.Spec.Proxy = nil
+
+	spec := &v1beta1.PostgresUserSpec{
+		Name:      "hippo",
+		Databases: []v1beta1.PostgresIdentifier{"zoo"},
+		Password: &v1beta1.PostgresPasswordSpec{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "synced"},
+				Key:                  "pw",
+			},
+		},
+	}
+
+	secret, err := reconciler.generateSecretRefPostgresUserSecret(ctx, cluster, spec, nil)
+	assert.NilError(t, err)
+
+	assert.Assert(t, secret.Annotations[naming.PostgresPasswordRevision] != "")
+	assert.Assert(t, len(secret.Data["verifier"]) > 0)
+	assert.Equal(t, string(secret.Data["dbname"]), "zoo")
+
+	for _, key := range []string{"password", "uri", "jdbc-uri"} {
+		_, found := secret.Data[key]
+		assert.Assert(t, !found, "expected no %q", key)
+	}
+
+	t.Run("Unchanged", func(t *testing.T) {
+		again, err := reconciler.generateSecretRefPostgresUserSecret(ctx, cluster, spec, secret)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, again.Data["verifier"], secret.Data["verifier"])
+	})
+
+	t.Run("MissingKey", func(t *testing.T) {
+		spec := spec.DeepCopy()
+		spec.Password.SecretKeyRef.Key = "other"
+
+		_, err := reconciler.generateSecretRefPostgresUserSecret(ctx, cluster, spec, nil)
+		assert.ErrorContains(t, err, `no "other" key`)
+	})
+
+	t.Run("MissingSecret", func(t *testing.T) {
+		spec := spec.DeepCopy()
+		spec.Password.SecretKeyRef.Name = "nonexistent"
+
+		_, err := reconciler.generateSecretRefPostgresUserSecret(ctx, cluster, spec, nil)
+		assert.Assert(t, apierrors.IsNotFound(err))
+	})
+}
+
+func TestSourcePostgresUserSecret(t *testing.T) {
+	ctx := context.Background()
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	source := &corev1.Secret{}
+	source.Namespace, source.Name = "old", "hippo-pguser-hippo"
+	source.Annotations = map[string]string{
+		"postgres-operator.crunchydata.com/copy-password-to-namespaces": "other, new",
+	}
+	source.Data = map[string][]byte{"password": []byte("same"), "verifier": []byte("SCRAM")}
+
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(source).Build(),
+		Recorder: recorder,
+	}
+
+	cluster := testCluster()
+	cluster.Namespace = "new"
+	cluster.Spec.DataSource = &v1beta1.DataSource{
+		PostgresCluster: &v1beta1.PostgresClusterDataSource{
+			ClusterNamespace: "old", RepoName: "repo1",
+		},
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		secret, err := reconciler.sourcePostgresUserSecret(ctx, cluster, "hippo")
+		assert.NilError(t, err)
+		assert.Assert(t, secret == nil)
+	})
+
+	cluster.Spec.DataSource.PostgresCluster.CopyUserPasswords = initialize.Bool(true)
+
+	secret, err := reconciler.sourcePostgresUserSecret(ctx, cluster, "hippo")
+	assert.NilError(t, err)
+	assert.Assert(t, secret != nil)
+	assert.Equal(t, string(secret.Data["password"]), "same")
+
+	t.Run("Generated", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Port = initialize.Int32(5432)
+		cluster.Spec.Proxy = nil
+
+		intent, err := reconciler.generatePostgresUserSecret(cluster,
+			&v1beta1.PostgresUserSpec{Name: "hippo"}, secret)
+		assert.NilError(t, err)
+		assert.Equal(t, intent.Namespace, "new")
+		assert.Equal(t, string(intent.Data["password"]), "same")
+		assert.Equal(t, string(intent.Data["verifier"]), "SCRAM")
+	})
+
+	t.Run("NamespaceNotAllowed", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Namespace = "elsewhere"
+
+		secret, err := reconciler.sourcePostgresUserSecret(ctx, cluster, "hippo")
+		assert.NilError(t, err)
+		assert.Assert(t, secret == nil)
+
+		assert.Equal(t, len(recorder.Events), 1)
+		event := <-recorder.Events
+		assert.Assert(t, cmp.Contains(event, "PasswordSecretUnavailable"))
+		assert.Assert(t, cmp.Contains(event, `"elsewhere"`))
+	})
+
+	t.Run("MissingSecret", func(t *testing.T) {
+		secret, err := reconciler.sourcePostgresUserSecret(ctx, cluster, "rhino")
+		assert.NilError(t, err)
+		assert.Assert(t, secret == nil)
+	})
+
+	t.Run("Initialized", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Status.Conditions = []metav1.Condition{{
+			Type: ConditionPostgresDataInitialized, Status: metav1.ConditionTrue,
+		}}
+
+		// The restore of a clone finishes before user Secrets are created.
+		secret, err := reconciler.sourcePostgresUserSecret(ctx, cluster, "hippo")
+		assert.NilError(t, err)
+		assert.Assert(t, secret != nil)
+	})
+
+	t.Run("Bootstrapped", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Status.Patroni.SystemIdentifier = "123456789"
+
+		secret, err := reconciler.sourcePostgresUserSecret(ctx, cluster, "hippo")
+		assert.NilError(t, err)
+		assert.Assert(t, secret == nil)
+	})
+}
+
+func TestReconcilePostgresUserSecretsAfterClone(t *testing.T) {
+	ctx := context.Background()
+	_, tClient := setupKubernetes(t)
+	require.ParallelCapacity(t, 0)
+
+	reconciler := &Reconciler{
+		Client:   tClient,
+		Owner:    client.FieldOwner(t.Name()),
+		Recorder: new(record.FakeRecorder),
+	}
+
+	old, ns := setupNamespace(t, tClient), setupNamespace(t, tClient)
+
+	source := &corev1.Secret{}
+	source.Namespace, source.Name = old.Name, "hippo-pguser-hippo"
+	source.Annotations = map[string]string{
+		naming.PostgresPasswordCopyNamespaces: ns.Name,
+	}
+	source.Data = map[string][]byte{"password": []byte("same"), "verifier": []byte("SCRAM")}
+	assert.NilError(t, tClient.Create(ctx, source))
+
+	cluster := testCluster()
+	cluster.Namespace = ns.Name
+	cluster.Spec.DataSource = &v1beta1.DataSource{
+		PostgresCluster: &v1beta1.PostgresClusterDataSource{
+			ClusterName: "hippo", ClusterNamespace: old.Name, RepoName: "repo1",
+			CopyUserPasswords: initialize.Bool(true),
+		},
+	}
+	assert.NilError(t, tClient.Create(ctx, cluster))
+
+	// The restore Job of the clone has completed.
+	job := &batchv1.Job{ObjectMeta: naming.PGBackRestRestoreJob(cluster)}
+	job.Labels = naming.PGBackRestRestoreJobLabels(cluster.Name)
+	job.Annotations = map[string]string{naming.PGBackRestConfigHash: "testhash"}
+	job.Spec.Template.Spec = corev1.PodSpec{
+		Containers: []corev1.Container{{
+			Image: "test", Name: naming.PGBackRestRestoreContainerName,
+		}},
+		RestartPolicy: corev1.RestartPolicyNever,
+	}
+	assert.NilError(t, tClient.Create(ctx, job))
+	job.Status.Conditions = []batchv1.JobCondition{{
+		Type: batchv1.JobComplete, Status: corev1.ConditionTrue,
+	}}
+	assert.NilError(t, tClient.Status().Update(ctx, job))
+
+	cluster.Status.PGBackRest = &v1beta1.PGBackRestStatus{
+		Restore: &v1beta1.PGBackRestJobStatus{ID: "~pgo-bootstrap-" + cluster.Name},
+	}
+
+	returnEarly, err := reconciler.reconcileDataSource(ctx, cluster, &observedInstances{}, nil, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, !returnEarly)
+	assert.Assert(t, meta.IsStatusConditionTrue(
+		cluster.Status.Conditions, ConditionPostgresDataInitialized))
+
+	_, secrets, err := reconciler.reconcilePostgresUserSecrets(ctx, cluster)
+	assert.NilError(t, err)
+	assert.Assert(t, secrets["hippo"] != nil)
+	assert.Equal(t, string(secrets["hippo"].Data["password"]), "same")
+	assert.Equal(t, string(secrets["hippo"].Data["verifier"]), "SCRAM")
+
+	t.Run("Bootstrapped", func(t *testing.T) {
+		cluster.Status.Patroni.SystemIdentifier = "123456789"
+		assert.NilError(t, tClient.Delete(ctx, secrets["hippo"]))
+
+		_, secrets, err := reconciler.reconcilePostgresUserSecrets(ctx, cluster)
+		assert.NilError(t, err)
+		assert.Assert(t, secrets["hippo"] != nil)
+		assert.Assert(t, string(secrets["hippo"].Data["password"]) != "same")
+	})
+}
+
 func TestReconcilePostgresVolumes(t *testing.T) {
 	ctx := context.Background()
 	_, tClient := setupKubernetes(t)
@@ -661,3 +899,270 @@ func TestReconcileDatabaseInitSQLConfigMap(t *testing.T) {
 		assert.Assert(t, called)
 	})
 }
+
+func TestReconcilePostgresTempTablespace(t *testing.T) {
+	ctx := context.Background()
+
+	var calls []string
+	r := &Reconciler{
+		PodExec: func(namespace, pod, container string, stdin io.Reader, stdout,
+			stderr io.Writer, command ...string) error {
+			b, err := io.ReadAll(stdin)
+			calls = append(calls, string(b))
+			return err
+		},
+	}
+
+	primary := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "primary",
+			Annotations: map[string]string{"status": `{"role":"master"}`},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: naming.ContainerDatabase,
+				State: corev1.ContainerState{
+					Running: new(corev1.ContainerStateRunning),
+				},
+			}},
+		},
+	}
+	replica := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "replica"}}
+	observed := &observedInstances{forCluster: []*Instance{
+		{Name: "one", Pods: []*corev1.Pod{primary}, Runner: &appsv1.StatefulSet{}},
+		{Name: "two", Pods: []*corev1.Pod{replica}, Runner: &appsv1.StatefulSet{}},
+	}}
+
+	cluster := testCluster()
+
+	t.Run("Disabled", func(t *testing.T) {
+		assert.NilError(t, r.reconcilePostgresTempTablespace(ctx, cluster, observed))
+		assert.Equal(t, len(calls), 0)
+	})
+
+	cluster.Spec.TempVolume = new(v1beta1.PostgresTempVolumeSpec)
+
+	t.Run("WaitForVolumes", func(t *testing.T) {
+		primary.Spec.Volumes = []corev1.Volume{{Name: postgres.TempVolumeMount().Name}}
+
+		assert.NilError(t, r.reconcilePostgresTempTablespace(ctx, cluster, observed))
+		assert.Equal(t, len(calls), 0, "expected to wait for the replica")
+		assert.Equal(t, cluster.Status.TempTablespaceRevision, "")
+	})
+
+	t.Run("Create", func(t *testing.T) {
+		replica.Spec.Volumes = []corev1.Volume{{Name: postgres.TempVolumeMount().Name}}
+
+		assert.NilError(t, r.reconcilePostgresTempTablespace(ctx, cluster, observed))
+		assert.Equal(t, len(calls), 1)
+		assert.Assert(t, cmp.Contains(calls[0], "CREATE TABLESPACE"))
+		assert.Assert(t, cluster.Status.TempTablespaceRevision != "")
+
+		// Nothing happens once the tablespace exists.
+		assert.NilError(t, r.reconcilePostgresTempTablespace(ctx, cluster, observed))
+		assert.Equal(t, len(calls), 1)
+	})
+
+	t.Run("Drop", func(t *testing.T) {
+		cluster.Spec.TempVolume = nil
+
+		assert.NilError(t, r.reconcilePostgresTempTablespace(ctx, cluster, observed))
+		assert.Equal(t, len(calls), 2)
+		assert.Assert(t, cmp.Contains(calls[1], "DROP TABLESPACE"))
+		assert.Equal(t, cluster.Status.TempTablespaceRevision, "")
+
+		assert.NilError(t, r.reconcilePostgresTempTablespace(ctx, cluster, observed))
+		assert.Equal(t, len(calls), 2)
+	})
+
+	t.Run("DropFailed", func(t *testing.T) {
+		cluster.Status.TempTablespaceRevision = "previous"
+
+		recorder := record.NewFakeRecorder(1)
+		r := &Reconciler{
+			Recorder: recorder,
+			PodExec: func(namespace, pod, container string, stdin io.Reader, stdout,
+				stderr io.Writer, command ...string) error {
+				return errors.New(`tablespace "pgo_temp" is not empty`)
+			},
+		}
+
+		// The error is reported, and the drop is tried again next time.
+		assert.NilError(t, r.reconcilePostgresTempTablespace(ctx, cluster, observed))
+		assert.Equal(t, cluster.Status.TempTablespaceRevision, "previous")
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "TempTablespaceNotDropped"))
+	})
+}
+
+func TestDatabasesOfUsers(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	r := &Reconciler{Recorder: recorder}
+
+	cluster := testCluster()
+	cluster.Spec.Databases = []v1beta1.PostgresDatabaseSpec{
+		{Name: "zoo", Owner: "hippo", Readers: []v1beta1.PostgresIdentifier{"rhino", "elephant"}},
+		{Name: "lake", Owner: "elephant"},
+		{Name: "pond", Writers: []v1beta1.PostgresIdentifier{"hippo", "zebra"}},
+	}
+	users := []v1beta1.PostgresUserSpec{{Name: "hippo"}, {Name: "rhino"}}
+
+	databases := r.databasesOfUsers(cluster, users)
+	assert.DeepEqual(t, databases, []v1beta1.PostgresDatabaseSpec{
+		{Name: "zoo", Owner: "hippo", Readers: []v1beta1.PostgresIdentifier{"rhino"}},
+		{Name: "pond", Writers: []v1beta1.PostgresIdentifier{"hippo"}},
+	})
+
+	// The spec is not changed.
+	assert.Equal(t, len(cluster.Spec.Databases[0].Readers), 2)
+
+	assert.Equal(t, len(recorder.Events), 3)
+	assert.Assert(t, cmp.Contains(<-recorder.Events, `Skipped user "elephant" of database "zoo"`))
+	assert.Assert(t, cmp.Contains(<-recorder.Events, `Skipped database "lake"`))
+	assert.Assert(t, cmp.Contains(<-recorder.Events, `Skipped user "zebra" of database "pond"`))
+}
+
+func TestReconcileQueryStatisticsReset(t *testing.T) {
+	ctx := context.Background()
+
+	var calls []string
+	recorder := record.NewFakeRecorder(10)
+	r := &Reconciler{
+		Recorder: recorder,
+		PodExec: func(namespace, pod, container string, stdin io.Reader, stdout,
+			stderr io.Writer, command ...string) error {
+			b, err := io.ReadAll(stdin)
+			calls = append(calls, string(b))
+			return err
+		},
+	}
+
+	primary := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "primary",
+			Annotations: map[string]string{"status": `{"role":"master"}`},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: naming.ContainerDatabase,
+				State: corev1.ContainerState{
+					Running: new(corev1.ContainerStateRunning),
+				},
+			}},
+		},
+	}
+	observed := &observedInstances{forCluster: []*Instance{
+		{Name: "one", Pods: []*corev1.Pod{primary}, Runner: &appsv1.StatefulSet{}},
+	}}
+
+	cluster := testCluster()
+
+	t.Run("NoAnnotation", func(t *testing.T) {
+		assert.NilError(t, r.reconcileQueryStatisticsReset(ctx, cluster, observed))
+		assert.Equal(t, len(calls), 0)
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		cluster.Annotations = map[string]string{naming.ResetQueryStatistics: "one"}
+
+		assert.NilError(t, r.reconcileQueryStatisticsReset(ctx, cluster, observed))
+		assert.Equal(t, len(calls), 1)
+		assert.Assert(t, cmp.Contains(calls[0], "pg_stat_statements_reset"))
+		assert.Equal(t, cluster.Status.QueryStatisticsReset, "one")
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Equal(t, <-recorder.Events,
+			`Normal QueryStatisticsReset Reset the statistics of pg_stat_statements on "primary"`)
+
+		// Nothing happens until the annotation changes.
+		assert.NilError(t, r.reconcileQueryStatisticsReset(ctx, cluster, observed))
+		assert.Equal(t, len(calls), 1)
+
+		cluster.Annotations[naming.ResetQueryStatistics] = "two"
+		assert.NilError(t, r.reconcileQueryStatisticsReset(ctx, cluster, observed))
+		assert.Equal(t, len(calls), 2)
+		assert.Equal(t, cluster.Status.QueryStatisticsReset, "two")
+	})
+}
+
+func TestReconcilePostgresPasswordExpirations(t *testing.T) {
+	ctx := context.Background()
+
+	var calls int
+	var output string
+	r := &Reconciler{
+		PodExec: func(namespace, pod, container string, stdin io.Reader, stdout,
+			stderr io.Writer, command ...string) error {
+			calls++
+			_, err := io.WriteString(stdout, output)
+			return err
+		},
+	}
+
+	primary := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "primary",
+			Annotations: map[string]string{"status": `{"role":"master"}`},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: naming.ContainerDatabase,
+				State: corev1.ContainerState{
+					Running: new(corev1.ContainerStateRunning),
+				},
+			}},
+		},
+	}
+	observed := &observedInstances{forCluster: []*Instance{
+		{Name: "one", Pods: []*corev1.Pod{primary}, Runner: &appsv1.StatefulSet{}},
+	}}
+
+	cluster := testCluster()
+	users := []v1beta1.PostgresUserSpec{
+		{Name: "hippo"},
+		{Name: "rhino", Options: "LOGIN valid  until '2027-01-01'"},
+	}
+
+	t.Run("NoExpirations", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Status.PasswordExpirations = map[string]metav1.Time{"rhino": {}}
+		cluster.Status.PasswordExpirationsRevision = "before"
+
+		calls = 0
+		assert.NilError(t, r.reconcilePostgresPasswordExpirations(
+			ctx, cluster, observed, []v1beta1.PostgresUserSpec{{Name: "hippo"}}))
+		assert.Equal(t, calls, 0, "expected no exec")
+		assert.Assert(t, cluster.Status.PasswordExpirations == nil)
+		assert.Equal(t, cluster.Status.PasswordExpirationsRevision, "")
+	})
+
+	t.Run("NoPrimary", func(t *testing.T) {
+		calls = 0
+		assert.NilError(t, r.reconcilePostgresPasswordExpirations(
+			ctx, cluster, &observedInstances{}, users))
+		assert.Equal(t, calls, 0, "expected no exec")
+		assert.Assert(t, cluster.Status.PasswordExpirations == nil)
+	})
+
+	t.Run("Expirations", func(t *testing.T) {
+		calls = 0
+		output = `{"rhino": 1798761600}`
+		assert.NilError(t, r.reconcilePostgresPasswordExpirations(ctx, cluster, observed, users))
+		assert.Equal(t, calls, 1)
+		assert.DeepEqual(t, cluster.Status.PasswordExpirations, map[string]metav1.Time{
+			"rhino": metav1.NewTime(time.Unix(1798761600, 0).UTC()),
+		})
+		assert.Assert(t, cluster.Status.PasswordExpirationsRevision != "")
+
+		t.Run("Unchanged", func(t *testing.T) {
+			assert.NilError(t, r.reconcilePostgresPasswordExpirations(ctx, cluster, observed, users))
+			assert.Equal(t, calls, 1, "expected no exec")
+		})
+
+		t.Run("UsersChanged", func(t *testing.T) {
+			cluster.Status.UsersRevision = "changed"
+			output = `{}`
+			assert.NilError(t, r.reconcilePostgresPasswordExpirations(ctx, cluster, observed, users))
+			assert.Equal(t, calls, 2)
+			assert.Assert(t, cluster.Status.PasswordExpirations == nil)
+		})
+	})
+}
@@ -0,0 +1,445 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crunchydata/postgres-operator/internal/config"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// sqlPolicyMountPath is where the SQL of a policy is mounted in its container.
+const sqlPolicyMountPath = "/etc/sqlpolicy"
+
+const (
+	// sqlPolicyConfigMapSpecKey is the key of a ConfigMap that defines a SQL
+	// policy for the clusters chosen by its [naming.SQLPolicyClusterSelector]
+	// annotation. Its value has the fields of spec.sqlPolicies in YAML.
+	sqlPolicyConfigMapSpecKey = "policy.yaml"
+
+	// sqlPolicyConfigMapSQLKey is the key of that ConfigMap containing the SQL
+	// to run.
+	sqlPolicyConfigMapSQLKey = "policy.sql"
+
+	// sqlPolicyJobNameMaxLength is the longest name Kubernetes allows for a
+	// CronJob. Jobs of policies without a schedule are held to it as well so
+	// that adding a schedule does not break a policy.
+	// - https://docs.k8s.io/concepts/workloads/controllers/cron-jobs/#writing-a-cronjob-spec
+	sqlPolicyJobNameMaxLength = 52
+)
+
+// generateSQLPolicyJobSpecIntent returns a JobSpec that runs the SQL of policy
+// as its user against the primary Service of cluster.
+func generateSQLPolicyJobSpecIntent(
+	cluster *v1beta1.PostgresCluster, policy *v1beta1.SQLPolicySpec,
+	labels, annotations map[string]string,
+) batchv1.JobSpec {
+	volume := corev1.Volume{Name: "sql"}
+	volume.ConfigMap = &corev1.ConfigMapVolumeSource{
+		LocalObjectReference: policy.SQL.LocalObjectReference,
+		Items:                []corev1.KeyToPath{{Key: policy.SQL.Key, Path: "policy.sql"}},
+	}
+
+	env := postgresUserEnvironment(cluster, policy.User)
+
+	// Pass the lock timeout as a session default; psql sends PGOPTIONS when
+	// it connects.
+	// - https://www.postgresql.org/docs/current/libpq-envars.html
+	if policy.LockTimeout != nil {
+		env = append(env, corev1.EnvVar{
+			Name:  "PGOPTIONS",
+			Value: fmt.Sprintf("-c lock_timeout=%dms", policy.LockTimeout.Milliseconds()),
+		})
+	}
+
+	container := corev1.Container{
+		Name: naming.ContainerSQLPolicy,
+
+		// Execute the SQL without reading config files nor prompting for a
+		// password. Stop at the first statement that fails.
+		// - https://www.postgresql.org/docs/current/app-psql.html
+		Command: []string{
+			"psql", "-Xw", "--set=ON_ERROR_STOP=on",
+			"--file=" + sqlPolicyMountPath + "/policy.sql",
+		},
+
+		Env:             env,
+		Image:           config.PostgresContainerImage(cluster),
+		ImagePullPolicy: cluster.Spec.ImagePullPolicy,
+		Resources:       policy.Resources,
+		SecurityContext: initialize.RestrictedSecurityContext(),
+		VolumeMounts: []corev1.VolumeMount{{
+			Name:      volume.Name,
+			MountPath: sqlPolicyMountPath,
+			ReadOnly:  true,
+		}},
+	}
+
+	return batchv1.JobSpec{
+		// SQL that fails is not retried; it may not be safe to run twice.
+		BackoffLimit: initialize.Int32(0),
+		Template: corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: labels, Annotations: annotations},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{container},
+
+				// Disable environment variables for services other than the Kubernetes API.
+				// - https://docs.k8s.io/concepts/services-networking/connect-applications-service/#accessing-the-service
+				// - https://releases.k8s.io/v1.23.0/pkg/kubelet/kubelet_pods.go#L553-L563
+				EnableServiceLinks: initialize.Bool(false),
+
+				ImagePullSecrets: cluster.Spec.ImagePullSecrets,
+				RestartPolicy:    corev1.RestartPolicyNever,
+				SecurityContext:  initialize.RestrictedPodSecurityContext(),
+				Volumes:          []corev1.Volume{volume},
+			},
+		},
+	}
+}
+
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=list
+
+// sqlPolicies returns the SQL policies listed in cluster followed by those
+// defined by ConfigMaps in its namespace that select it. A ConfigMap defines a
+// policy when it has the [naming.LabelSQLPolicy] label; the value of that label
+// is the name of the policy. A listed policy takes precedence over a ConfigMap
+// of the same name.
+func (r *Reconciler) sqlPolicies(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+) ([]v1beta1.SQLPolicySpec, error) {
+	policies := append([]v1beta1.SQLPolicySpec(nil), cluster.Spec.SQLPolicies...)
+	names := sets.NewString()
+	for _, policy := range policies {
+		names.Insert(policy.Name)
+	}
+
+	defined, _ := labels.NewRequirement(naming.LabelSQLPolicy, selection.Exists, nil)
+	configmaps := &corev1.ConfigMapList{}
+	if err := errors.WithStack(r.Client.List(ctx, configmaps,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabelsSelector{Selector: labels.NewSelector().Add(*defined)},
+	)); err != nil {
+		return nil, err
+	}
+
+	// Consider ConfigMaps in a consistent order so that the same one wins when
+	// more than one defines a policy of the same name.
+	sort.Slice(configmaps.Items, func(i, j int) bool {
+		return configmaps.Items[i].Name < configmaps.Items[j].Name
+	})
+
+	for i := range configmaps.Items {
+		configmap := &configmaps.Items[i]
+		value, ok := configmap.Annotations[naming.SQLPolicyClusterSelector]
+		if !ok {
+			continue
+		}
+
+		selector, err := labels.Parse(value)
+		if err != nil {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventInvalidSQLPolicy,
+				"Unable to parse the cluster selector of ConfigMap %q: %v", configmap.Name, err)
+			continue
+		}
+		if !selector.Matches(labels.Set(cluster.Labels)) {
+			continue
+		}
+
+		var policy v1beta1.SQLPolicySpec
+		err = yaml.UnmarshalStrict([]byte(configmap.Data[sqlPolicyConfigMapSpecKey]), &policy)
+		policy.Name = configmap.Labels[naming.LabelSQLPolicy]
+		policy.SQL = corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: configmap.Name},
+			Key:                  sqlPolicyConfigMapSQLKey,
+		}
+
+		switch {
+		case err != nil:
+		case len(policy.Name) > 32 || len(validation.IsDNS1123Label(policy.Name)) > 0:
+			err = errors.Errorf("invalid policy name %q", policy.Name)
+		case policy.User == "":
+			err = errors.Errorf("%q has no user", sqlPolicyConfigMapSpecKey)
+		case policy.Schedule != nil && len(*policy.Schedule) < 6:
+			err = errors.Errorf("invalid schedule %q", *policy.Schedule)
+		}
+		if err != nil {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventInvalidSQLPolicy,
+				"Unable to read the SQL policy of ConfigMap %q: %v", configmap.Name, err)
+			continue
+		}
+
+		if names.Has(policy.Name) {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventInvalidSQLPolicy,
+				"Ignoring ConfigMap %q; SQL policy %q is already defined", configmap.Name, policy.Name)
+			continue
+		}
+		names.Insert(policy.Name)
+		policies = append(policies, policy)
+	}
+
+	return policies, nil
+}
+
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=list;create;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=list;create;patch;delete
+
+// reconcileSQLPolicies writes the Jobs and CronJobs that run the SQL policies
+// of cluster, and records the most recent run of each in cluster.Status.
+func (r *Reconciler) reconcileSQLPolicies(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+	window *maintenance,
+) error {
+	selector := client.MatchingLabelsSelector{
+		Selector: naming.SQLPolicySelector(cluster.Name),
+	}
+
+	jobs := &batchv1.JobList{}
+	cronjobs := &batchv1beta1.CronJobList{}
+	err := errors.WithStack(r.Client.List(ctx, jobs,
+		client.InNamespace(cluster.Namespace), selector))
+	if err == nil {
+		err = errors.WithStack(r.Client.List(ctx, cronjobs,
+			client.InNamespace(cluster.Namespace), selector))
+	}
+	var specs []v1beta1.SQLPolicySpec
+	if err == nil {
+		specs, err = r.sqlPolicies(ctx, cluster)
+	}
+	if err != nil {
+		return err
+	}
+
+	policies := make(map[string]*v1beta1.SQLPolicySpec, len(specs))
+	for i := range specs {
+		policies[specs[i].Name] = &specs[i]
+	}
+
+	// Delete the objects of policies that are no longer specified as well as
+	// those that no longer match the schedule of their policy.
+	remove := func(object client.Object) error {
+		if !metav1.IsControlledBy(object, cluster) {
+			return nil
+		}
+		return errors.WithStack(client.IgnoreNotFound(r.Client.Delete(ctx, object,
+			client.PropagationPolicy(metav1.DeletePropagationBackground))))
+	}
+
+	current := make(map[string]*batchv1.Job)
+	for i := range cronjobs.Items {
+		policy := policies[cronjobs.Items[i].Labels[naming.LabelSQLPolicy]]
+		if err == nil && (policy == nil || policy.Schedule == nil) {
+			err = remove(&cronjobs.Items[i])
+		}
+	}
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		policy := policies[job.Labels[naming.LabelSQLPolicy]]
+		if err == nil && metav1.IsControlledBy(job, cluster) {
+			if policy == nil || policy.Schedule != nil {
+				err = remove(job)
+			} else {
+				current[policy.Name] = job
+			}
+		}
+	}
+
+	// Record the most recent run of each policy, including those started by
+	// a CronJob.
+	var statuses []v1beta1.SQLPolicyStatus
+	for _, policy := range specs {
+		status := v1beta1.SQLPolicyStatus{Name: policy.Name}
+
+		var latest *batchv1.Job
+		for i := range jobs.Items {
+			job := &jobs.Items[i]
+			if job.Labels[naming.LabelSQLPolicy] == policy.Name &&
+				(latest == nil || latest.CreationTimestamp.Before(&job.CreationTimestamp)) {
+				latest = job
+			}
+		}
+		if latest != nil {
+			status.JobName = latest.Name
+			status.StartTime = latest.Status.StartTime
+			status.CompletionTime = latest.Status.CompletionTime
+			status.Active = latest.Status.Active
+			status.Succeeded = latest.Status.Succeeded
+			status.Failed = latest.Status.Failed
+		}
+		statuses = append(statuses, status)
+	}
+	cluster.Status.SQLPolicies = statuses
+
+	// Policies connect through the primary Service. Wait for a writable
+	// instance before running any of them.
+	if pod, _ := instances.writablePod(naming.ContainerDatabase); err != nil || pod == nil {
+		return err
+	}
+
+	for i := 0; err == nil && i < len(specs); i++ {
+		policy := &specs[i]
+		if !postgresUserHasDatabase(cluster, policy.User) {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventInvalidSQLPolicy,
+				"Unable to find user %q with a database as configured for SQL policy %q",
+				policy.User, policy.Name)
+			continue
+		}
+		if postgresUserPasswordInVault(cluster, policy.User) {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventInvalidSQLPolicy,
+				"Unable to run SQL policy %q as user %q; its password is in Vault",
+				policy.Name, policy.User)
+			continue
+		}
+		if name := naming.SQLPolicyJob(cluster, policy.Name).Name; len(name) > sqlPolicyJobNameMaxLength {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventInvalidSQLPolicy,
+				"Unable to run SQL policy %q; the name %q is longer than %d characters",
+				policy.Name, name, sqlPolicyJobNameMaxLength)
+			continue
+		}
+
+		if policy.Schedule != nil {
+			err = r.reconcileSQLPolicyCronJob(ctx, cluster, policy)
+		} else {
+			err = r.reconcileSQLPolicyJob(ctx, cluster, policy, current[policy.Name], window)
+		}
+	}
+
+	return err
+}
+
+// reconcileSQLPolicyCronJob writes the CronJob that runs policy on its schedule.
+func (r *Reconciler) reconcileSQLPolicyCronJob(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, policy *v1beta1.SQLPolicySpec,
+) error {
+	labels := naming.Merge(cluster.Spec.Metadata.GetLabelsOrNil(),
+		naming.SQLPolicyLabels(cluster.Name, policy.Name))
+	annotations := naming.Merge(cluster.Spec.Metadata.GetAnnotationsOrNil())
+
+	// Suspend cronjobs when shutdown or read-only. Any jobs that have already
+	// started will continue.
+	// - https://docs.k8s.io/reference/kubernetes-api/workload-resources/cron-job-v1beta1/#CronJobSpec
+	suspend := (cluster.Spec.Shutdown != nil && *cluster.Spec.Shutdown) ||
+		(cluster.Spec.Standby != nil && cluster.Spec.Standby.Enabled)
+
+	cronjob := &batchv1beta1.CronJob{ObjectMeta: naming.SQLPolicyJob(cluster, policy.Name)}
+	cronjob.SetGroupVersionKind(batchv1beta1.SchemeGroupVersion.WithKind("CronJob"))
+	cronjob.Annotations = annotations
+	cronjob.Labels = labels
+	cronjob.Spec = batchv1beta1.CronJobSpec{
+		Schedule:          *policy.Schedule,
+		Suspend:           &suspend,
+		ConcurrencyPolicy: batchv1beta1.ForbidConcurrent,
+		JobTemplate: batchv1beta1.JobTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: labels, Annotations: annotations},
+			Spec:       generateSQLPolicyJobSpecIntent(cluster, policy, labels, annotations),
+		},
+	}
+
+	err := errors.WithStack(r.setControllerReference(cluster, cronjob))
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, cronjob))
+	}
+	return err
+}
+
+// reconcileSQLPolicyJob writes the Job that runs policy once. When the SQL of
+// policy changes, the current Job is replaced once it finishes. Policies that
+// ask for it wait for the maintenance window to do either.
+func (r *Reconciler) reconcileSQLPolicyJob(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, policy *v1beta1.SQLPolicySpec,
+	current *batchv1.Job, window *maintenance,
+) error {
+	configmap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Namespace: cluster.Namespace,
+		Name:      policy.SQL.Name,
+	}}
+	err := errors.WithStack(client.IgnoreNotFound(
+		r.Client.Get(ctx, client.ObjectKeyFromObject(configmap), configmap)))
+	if err != nil {
+		return err
+	}
+
+	sql, ok := configmap.Data[policy.SQL.Key]
+	if !ok {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventInvalidSQLPolicy,
+			"Unable to find key %q of ConfigMap %q for SQL policy %q",
+			policy.SQL.Key, policy.SQL.Name, policy.Name)
+		return nil
+	}
+
+	hash, err := safeHash32(func(w io.Writer) error {
+		_, err := io.WriteString(w, string(policy.User)+"\x00"+sql)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// The SQL has already been run or is running now.
+	if current != nil && current.Annotations[naming.SQLPolicyHash] == hash {
+		return nil
+	}
+
+	if policy.MaintenanceWindow != nil && *policy.MaintenanceWindow &&
+		!window.allow(fmt.Sprintf("run SQL policy %q", policy.Name)) {
+		return nil
+	}
+
+	if current != nil {
+		// Wait for a running Job to finish. Once finished, delete it so that
+		// another can be created with the new SQL.
+		if !jobCompleted(current) && !jobFailed(current) {
+			return nil
+		}
+		return errors.WithStack(r.Client.Delete(ctx, current,
+			client.PropagationPolicy(metav1.DeletePropagationBackground)))
+	}
+
+	labels := naming.Merge(cluster.Spec.Metadata.GetLabelsOrNil(),
+		naming.SQLPolicyLabels(cluster.Name, policy.Name))
+	annotations := naming.Merge(cluster.Spec.Metadata.GetAnnotationsOrNil(),
+		map[string]string{naming.SQLPolicyHash: hash})
+
+	job := &batchv1.Job{ObjectMeta: naming.SQLPolicyJob(cluster, policy.Name)}
+	job.SetGroupVersionKind(batchv1.SchemeGroupVersion.WithKind("Job"))
+	job.Annotations = annotations
+	job.Labels = labels
+	job.Spec = generateSQLPolicyJobSpecIntent(cluster, policy, labels, annotations)
+
+	err = errors.WithStack(r.setControllerReference(cluster, job))
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, job))
+	}
+	return err
+}
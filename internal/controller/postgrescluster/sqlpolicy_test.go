@@ -0,0 +1,220 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestGenerateSQLPolicyJobSpecIntent(t *testing.T) {
+	cluster := testCluster()
+	policy := &v1beta1.SQLPolicySpec{
+		Name: "nightly",
+		User: "rhino",
+		SQL: corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "some-cm"},
+			Key:                  "some.sql",
+		},
+	}
+
+	spec := generateSQLPolicyJobSpecIntent(cluster, policy,
+		map[string]string{"some": "label"}, map[string]string{"some": "annotation"})
+
+	assert.Assert(t, marshalMatches(spec.Template.ObjectMeta, `
+annotations:
+  some: annotation
+creationTimestamp: null
+labels:
+  some: label
+	`))
+
+	assert.DeepEqual(t, spec.BackoffLimit, initialize.Int32(0))
+	assert.Equal(t, spec.Template.Spec.RestartPolicy, corev1.RestartPolicyNever)
+	assert.DeepEqual(t, spec.Template.Spec.ImagePullSecrets, cluster.Spec.ImagePullSecrets)
+	assert.Assert(t, marshalMatches(spec.Template.Spec.Volumes, `
+- configMap:
+    items:
+    - key: some.sql
+      path: policy.sql
+    name: some-cm
+  name: sql
+	`))
+
+	assert.Equal(t, len(spec.Template.Spec.Containers), 1)
+	container := spec.Template.Spec.Containers[0]
+	assert.Equal(t, container.Image, CrunchyPostgresHAImage)
+	assert.DeepEqual(t, container.Command, []string{
+		"psql", "-Xw", "--set=ON_ERROR_STOP=on", "--file=/etc/sqlpolicy/policy.sql",
+	})
+	assert.Assert(t, marshalMatches(container.VolumeMounts, `
+- mountPath: /etc/sqlpolicy
+  name: sql
+  readOnly: true
+	`))
+
+	// The container connects as the user of the policy.
+	assert.Equal(t, container.Env[2].Name, "PGUSER")
+	assert.Equal(t, container.Env[2].ValueFrom.SecretKeyRef.Name, "hippo-pguser-rhino")
+}
+
+func TestGenerateSQLPolicyJobSpecIntentLockTimeout(t *testing.T) {
+	cluster := testCluster()
+	policy := &v1beta1.SQLPolicySpec{
+		Name:        "reindex",
+		User:        "rhino",
+		LockTimeout: &metav1.Duration{Duration: 90 * time.Second},
+	}
+
+	spec := generateSQLPolicyJobSpecIntent(cluster, policy, nil, nil)
+	env := spec.Template.Spec.Containers[0].Env
+	assert.DeepEqual(t, env[len(env)-1], corev1.EnvVar{
+		Name: "PGOPTIONS", Value: "-c lock_timeout=90000ms",
+	})
+}
+
+func TestGenerateSQLPolicyJobSpecIntentResources(t *testing.T) {
+	cluster := testCluster()
+	policy := &v1beta1.SQLPolicySpec{
+		Name: "reindex",
+		User: "rhino",
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("100m"),
+			},
+		},
+	}
+
+	spec := generateSQLPolicyJobSpecIntent(cluster, policy, nil, nil)
+	assert.DeepEqual(t, spec.Template.Spec.Containers[0].Resources, policy.Resources)
+}
+
+func TestReconcileSQLPolicyJobMaintenanceWindow(t *testing.T) {
+	ctx := context.Background()
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	cluster := testCluster()
+	configmap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: cluster.Namespace, Name: "some-cm"},
+		Data:       map[string]string{"reindex.sql": "REINDEX DATABASE CONCURRENTLY zoo;"},
+	}
+	reconciler := &Reconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(configmap).Build(),
+	}
+
+	policy := &v1beta1.SQLPolicySpec{
+		Name:              "reindex",
+		User:              "rhino",
+		MaintenanceWindow: initialize.Bool(true),
+		SQL: corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "some-cm"},
+			Key:                  "reindex.sql",
+		},
+	}
+
+	window := &maintenance{closed: true}
+	assert.NilError(t, reconciler.reconcileSQLPolicyJob(ctx, cluster, policy, nil, window))
+	assert.DeepEqual(t, window.pending, []string{`run SQL policy "reindex"`})
+
+	jobs := &batchv1.JobList{}
+	assert.NilError(t, reconciler.Client.List(ctx, jobs))
+	assert.Equal(t, len(jobs.Items), 0, "expected no Job outside the window")
+}
+
+func TestSQLPolicies(t *testing.T) {
+	ctx := context.Background()
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	cluster := testCluster()
+	cluster.Namespace = "ns1"
+	cluster.Labels = map[string]string{"environment": "production"}
+	cluster.Spec.SQLPolicies = []v1beta1.SQLPolicySpec{{Name: "listed", User: "rhino"}}
+
+	policy := func(name, policy, selector, spec string) *corev1.ConfigMap {
+		configmap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns1", Name: name,
+			Labels: map[string]string{"postgres-operator.crunchydata.com/sqlpolicy": policy},
+		}}
+		if selector != "-" {
+			configmap.Annotations = map[string]string{
+				"postgres-operator.crunchydata.com/sqlpolicy-clusters": selector,
+			}
+		}
+		configmap.Data = map[string]string{"policy.yaml": spec, "policy.sql": "ANALYZE;"}
+		return configmap
+	}
+
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			policy("b-analyze", "analyze", "environment=production",
+				"user: rhino\nschedule: '0 1 * * *'"),
+			policy("a-everywhere", "everywhere", "", "user: rhino"),
+			policy("c-analyze", "analyze", "environment", "user: rhino"),
+			policy("d-other", "other", "environment=staging", "user: rhino"),
+			policy("e-unselected", "unselected", "-", "user: rhino"),
+			policy("f-listed", "listed", "", "user: rhino"),
+			policy("g-invalid", "invalid", "", "user: rhino\ncolor: blue"),
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "h-plain"}},
+		).Build(),
+		Recorder: recorder,
+	}
+
+	policies, err := reconciler.sqlPolicies(ctx, cluster)
+	assert.NilError(t, err)
+	assert.Assert(t, marshalMatches(policies, `
+- name: listed
+  resources: {}
+  sql:
+    key: ""
+  user: rhino
+- name: everywhere
+  resources: {}
+  sql:
+    key: policy.sql
+    name: a-everywhere
+  user: rhino
+- name: analyze
+  resources: {}
+  schedule: 0 1 * * *
+  sql:
+    key: policy.sql
+    name: b-analyze
+  user: rhino
+	`))
+
+	assert.Equal(t, len(recorder.Events), 3)
+	assert.Assert(t, cmp.Contains(<-recorder.Events, `Ignoring ConfigMap "c-analyze"`))
+	assert.Assert(t, cmp.Contains(<-recorder.Events, `Ignoring ConfigMap "f-listed"`))
+	assert.Assert(t, cmp.Contains(<-recorder.Events,
+		`Unable to read the SQL policy of ConfigMap "g-invalid"`))
+}
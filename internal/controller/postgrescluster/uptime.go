@@ -0,0 +1,107 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// outsideUptimeSchedule reports whether cluster should be shutdown at now
+// according to its uptime schedule, and how long until that may change. It
+// returns false and zero when cluster has no schedule.
+func outsideUptimeSchedule(
+	cluster *v1beta1.PostgresCluster, now time.Time,
+) (bool, time.Duration, error) {
+	schedule := cluster.Spec.UptimeSchedule
+	if schedule == nil {
+		return false, 0, nil
+	}
+
+	inside, next, err := withinWindows(schedule.TimeZone, schedule.Windows, now)
+	return !inside, next, err
+}
+
+// withinWindows reports whether now falls inside any of windows in timeZone,
+// and how long until that may change. It returns zero when no window opens or
+// closes during the next week.
+func withinWindows(
+	timeZone string, windows []v1beta1.UptimeWindow, now time.Time,
+) (bool, time.Duration, error) {
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return false, 0, errors.WithStack(err)
+	}
+	now = now.In(location)
+
+	var next time.Time
+	inside := false
+	boundary := func(t time.Time) {
+		if t.After(now) && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+
+	for _, window := range windows {
+		start, err := time.Parse("15:04", window.Start)
+		if err != nil {
+			return false, 0, errors.WithStack(err)
+		}
+		end, err := time.Parse("15:04", window.End)
+		if err != nil {
+			return false, 0, errors.WithStack(err)
+		}
+		if !end.After(start) {
+			end = end.AddDate(0, 0, 1)
+		}
+		minutes := int(end.Sub(start) / time.Minute)
+
+		days := sets.NewString()
+		for _, day := range window.Days {
+			days.Insert(string(day))
+		}
+
+		// A window that started yesterday may still be open. Windows that
+		// start during the next week determine when the next one opens.
+		for offset := -1; offset <= 7; offset++ {
+			opens := time.Date(now.Year(), now.Month(), now.Day()+offset,
+				start.Hour(), start.Minute(), 0, 0, location)
+			if days.Len() > 0 && !days.Has(opens.Weekday().String()) {
+				continue
+			}
+
+			// Count minutes on the wall clock so that a window keeps its end
+			// time when daylight saving time begins or ends.
+			closes := time.Date(opens.Year(), opens.Month(), opens.Day(),
+				start.Hour(), start.Minute()+minutes, 0, 0, location)
+
+			if !now.Before(opens) && now.Before(closes) {
+				inside = true
+			}
+			boundary(opens)
+			boundary(closes)
+		}
+	}
+
+	if next.IsZero() {
+		return inside, 0, nil
+	}
+	return inside, next.Sub(now), nil
+}
@@ -0,0 +1,122 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestOutsideUptimeSchedule(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NilError(t, err)
+
+	at := func(layout string) time.Time {
+		t.Helper()
+		v, err := time.ParseInLocation("2006-01-02 15:04", layout, newYork)
+		assert.NilError(t, err)
+		return v
+	}
+
+	cluster := &v1beta1.PostgresCluster{}
+
+	t.Run("NoSchedule", func(t *testing.T) {
+		outside, next, err := outsideUptimeSchedule(cluster, time.Now())
+		assert.NilError(t, err)
+		assert.Assert(t, !outside)
+		assert.Equal(t, next, time.Duration(0))
+	})
+
+	t.Run("Weekdays", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.UptimeSchedule = &v1beta1.UptimeScheduleSpec{
+			TimeZone: "America/New_York",
+			Windows: []v1beta1.UptimeWindow{{
+				Days:  []v1beta1.Weekday{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"},
+				Start: "08:00", End: "18:00",
+			}},
+		}
+
+		for _, tt := range []struct {
+			now     string
+			outside bool
+			next    time.Duration
+		}{
+			{now: "2022-03-09 12:00", outside: false, next: 6 * time.Hour},  // Wednesday
+			{now: "2022-03-09 20:00", outside: true, next: 12 * time.Hour},  // Wednesday night
+			{now: "2022-03-04 20:00", outside: true, next: 60 * time.Hour},  // Friday night
+			{now: "2022-03-07 08:00", outside: false, next: 10 * time.Hour}, // Monday morning
+		} {
+			outside, next, err := outsideUptimeSchedule(cluster, at(tt.now).UTC())
+			assert.NilError(t, err)
+			assert.Equal(t, outside, tt.outside, "at %v", tt.now)
+			assert.Equal(t, next, tt.next, "at %v", tt.now)
+		}
+	})
+
+	t.Run("Overnight", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.UptimeSchedule = &v1beta1.UptimeScheduleSpec{
+			TimeZone: "America/New_York",
+			Windows:  []v1beta1.UptimeWindow{{Start: "22:00", End: "06:00"}},
+		}
+
+		outside, next, err := outsideUptimeSchedule(cluster, at("2022-03-09 02:00"))
+		assert.NilError(t, err)
+		assert.Assert(t, !outside)
+		assert.Equal(t, next, 4*time.Hour)
+
+		outside, next, err = outsideUptimeSchedule(cluster, at("2022-03-09 12:00"))
+		assert.NilError(t, err)
+		assert.Assert(t, outside)
+		assert.Equal(t, next, 10*time.Hour)
+	})
+
+	t.Run("DaylightSavingTime", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.UptimeSchedule = &v1beta1.UptimeScheduleSpec{
+			TimeZone: "America/New_York",
+			Windows:  []v1beta1.UptimeWindow{{Start: "00:00", End: "12:00"}},
+		}
+
+		// Clocks in New York skip from 02:00 to 03:00 on this day.
+		outside, next, err := outsideUptimeSchedule(cluster, at("2022-03-13 01:00"))
+		assert.NilError(t, err)
+		assert.Assert(t, !outside)
+		assert.Equal(t, next, 10*time.Hour)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.UptimeSchedule = &v1beta1.UptimeScheduleSpec{
+			TimeZone: "Mars/Olympus_Mons",
+			Windows:  []v1beta1.UptimeWindow{{Start: "08:00", End: "18:00"}},
+		}
+
+		_, _, err := outsideUptimeSchedule(cluster, time.Now())
+		assert.ErrorContains(t, err, "Mars")
+
+		cluster.Spec.UptimeSchedule.TimeZone = "UTC"
+		cluster.Spec.UptimeSchedule.Windows[0].Start = "8am"
+
+		_, _, err = outsideUptimeSchedule(cluster, time.Now())
+		assert.ErrorContains(t, err, "8am")
+	})
+}
@@ -20,14 +20,18 @@ import (
 	"hash/fnv"
 	"io"
 
+	"github.com/pkg/errors"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 var tmpDirSizeLimit = resource.MustParse("16Mi")
@@ -180,6 +184,123 @@ func addTMPEmptyDir(template *corev1.PodTemplateSpec) {
 	}
 }
 
+// addGuaranteedQoS sets the CPU and memory requests of every container in the
+// Pod template to its limits. It returns the names of containers that lack
+// either limit; a Pod with any such container does not receive the Guaranteed
+// quality of service class.
+// - https://docs.k8s.io/tasks/configure-pod-container/quality-service-pod/
+func addGuaranteedQoS(template *corev1.PodTemplateSpec) []string {
+	var missing []string
+	guarantee := func(container *corev1.Container) {
+		// Resources are often shared with the cluster spec; copy before modifying.
+		container.Resources = *container.Resources.DeepCopy()
+
+		complete := true
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			limit, ok := container.Resources.Limits[name]
+			if !ok {
+				complete = false
+				continue
+			}
+			if container.Resources.Requests == nil {
+				container.Resources.Requests = corev1.ResourceList{}
+			}
+			container.Resources.Requests[name] = limit
+		}
+		if !complete {
+			missing = append(missing, container.Name)
+		}
+	}
+
+	for i := range template.Spec.InitContainers {
+		guarantee(&template.Spec.InitContainers[i])
+	}
+	for i := range template.Spec.Containers {
+		guarantee(&template.Spec.Containers[i])
+	}
+	return missing
+}
+
+// hasDedicatedCPUs returns whether the named container in the Pod template
+// would get exclusive cores from the static CPU manager policy of the kubelet:
+// its CPU request must be a whole number equal to its limit. The Pod must also
+// have the Guaranteed quality of service class; see addGuaranteedQoS.
+// - https://docs.k8s.io/tasks/administer-cluster/cpu-management-policies/
+func hasDedicatedCPUs(template *corev1.PodTemplateSpec, name string) bool {
+	for i := range template.Spec.Containers {
+		if container := &template.Spec.Containers[i]; container.Name == name {
+			limit, ok := container.Resources.Limits[corev1.ResourceCPU]
+			request := container.Resources.Requests[corev1.ResourceCPU]
+			return ok && limit.Cmp(request) == 0 &&
+				limit.MilliValue() > 0 && limit.MilliValue()%1000 == 0
+		}
+	}
+	return false
+}
+
+// applyPodTemplateOverlay merges overlay into the Pod template as a strategic
+// merge patch. Lists such as containers and env are merged by name, the same
+// as "kubectl patch".
+// - https://docs.k8s.io/tasks/manage-kubernetes-objects/update-api-object-kubectl-patch/
+func applyPodTemplateOverlay(template *corev1.PodTemplateSpec, overlay map[string]interface{}) error {
+	if len(overlay) == 0 {
+		return nil
+	}
+
+	original, err := runtime.DefaultUnstructuredConverter.ToUnstructured(template)
+	if err == nil {
+		original, err = strategicpatch.StrategicMergeMapPatch(
+			original, overlay, corev1.PodTemplateSpec{})
+	}
+	if err == nil {
+		merged := corev1.PodTemplateSpec{}
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(original, &merged)
+		if err == nil {
+			*template = merged
+		}
+	}
+	return errors.WithStack(err)
+}
+
+// overlayPodTemplate applies overlay to template. When overlay cannot be
+// applied, it records a warning event on cluster about the overlay of owner,
+// leaves template unchanged, and returns false.
+func (r *Reconciler) overlayPodTemplate(
+	cluster *v1beta1.PostgresCluster, owner string,
+	template *corev1.PodTemplateSpec, overlay map[string]interface{},
+) bool {
+	if err := applyPodTemplateOverlay(template, overlay); err != nil {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventInvalidPodTemplateOverlay,
+			"The podTemplateOverlay of %s cannot be applied: %v", owner, err)
+		return false
+	}
+	return true
+}
+
+// setServiceSpec applies the user's Service settings in spec, if any, to a
+// generated Service and its ports. Annotations in spec take precedence over
+// those already on the Service, but labels already on the Service take
+// precedence over those in spec so PGO can still find it.
+func setServiceSpec(service *corev1.Service, spec *v1beta1.ServiceSpec) {
+	if spec == nil {
+		service.Spec.Type = corev1.ServiceTypeClusterIP
+		return
+	}
+
+	service.Annotations = naming.Merge(service.Annotations, spec.Metadata.GetAnnotationsOrNil())
+	service.Labels = naming.Merge(spec.Metadata.GetLabelsOrNil(), service.Labels)
+
+	service.Spec.Type = corev1.ServiceType(spec.Type)
+	service.Spec.LoadBalancerSourceRanges = spec.LoadBalancerSourceRanges
+
+	// A ClusterIP Service cannot have a node port.
+	if spec.NodePort != nil && service.Spec.Type != corev1.ServiceTypeClusterIP {
+		for i := range service.Spec.Ports {
+			service.Spec.Ports[i].NodePort = *spec.NodePort
+		}
+	}
+}
+
 // addNSSWrapper adds nss_wrapper environment variables to the database and pgBackRest
 // containers in the Pod template.  Additionally, an init container is added to the Pod template
 // as needed to setup the nss_wrapper. Please note that the nss_wrapper is required for
@@ -263,6 +384,27 @@ func addNSSWrapper(image string, imagePullPolicy corev1.PullPolicy, template *co
 	template.Spec.InitContainers = append(template.Spec.InitContainers, container)
 }
 
+// jobPhase returns the phase of the Job provided (Pending, Running, Succeeded, or Failed) along
+// with the message of its "Failed" condition, if any.
+func jobPhase(job *batchv1.Job) (phase, message string) {
+	for i := range job.Status.Conditions {
+		condition := job.Status.Conditions[i]
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return "Succeeded", ""
+		case batchv1.JobFailed:
+			return "Failed", condition.Message
+		}
+	}
+	if job.Status.Active > 0 {
+		return "Running", ""
+	}
+	return "Pending", ""
+}
+
 // jobFailed returns "true" if the Job provided has failed.  Otherwise it returns "false".
 func jobFailed(job *batchv1.Job) bool {
 	conditions := job.Status.Conditions
@@ -274,6 +416,19 @@ func jobFailed(job *batchv1.Job) bool {
 	return false
 }
 
+// jobDeadlineExceeded returns "true" if the Job provided failed because it ran
+// longer than its ActiveDeadlineSeconds. Otherwise it returns "false".
+func jobDeadlineExceeded(job *batchv1.Job) bool {
+	conditions := job.Status.Conditions
+	for i := range conditions {
+		if conditions[i].Type == batchv1.JobFailed {
+			return conditions[i].Status == corev1.ConditionTrue &&
+				conditions[i].Reason == "DeadlineExceeded"
+		}
+	}
+	return false
+}
+
 // jobCompleted returns "true" if the Job provided completed successfully.  Otherwise it returns
 // "false".
 func jobCompleted(job *batchv1.Job) bool {
@@ -26,10 +26,12 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/crunchydata/postgres-operator/internal/naming"
 	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 func TestSafeHash32(t *testing.T) {
@@ -257,6 +259,195 @@ func TestAddDevSHM(t *testing.T) {
 	}
 }
 
+func TestAddGuaranteedQoS(t *testing.T) {
+	limits := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("2"),
+		corev1.ResourceMemory: resource.MustParse("4Gi"),
+	}
+
+	template := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+		InitContainers: []corev1.Container{{
+			Name: "init",
+			Resources: corev1.ResourceRequirements{
+				Limits: limits,
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("100m"),
+				},
+			},
+		}},
+		Containers: []corev1.Container{{
+			Name:      naming.ContainerDatabase,
+			Resources: corev1.ResourceRequirements{Limits: limits},
+		}, {
+			Name: "memory-only",
+			Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			}},
+		}, {
+			Name: "none",
+		}},
+	}}
+
+	missing := addGuaranteedQoS(template)
+	assert.DeepEqual(t, missing, []string{"memory-only", "none"})
+
+	assert.DeepEqual(t, template.Spec.InitContainers[0].Resources.Requests, limits)
+	assert.DeepEqual(t, template.Spec.Containers[0].Resources.Requests, limits)
+	assert.DeepEqual(t, template.Spec.Containers[1].Resources.Requests,
+		template.Spec.Containers[1].Resources.Limits)
+	assert.Assert(t, template.Spec.Containers[2].Resources.Requests == nil)
+}
+
+func TestHasDedicatedCPUs(t *testing.T) {
+	template := func(request, limit string) *corev1.PodTemplateSpec {
+		container := corev1.Container{Name: naming.ContainerDatabase}
+		if request != "" {
+			container.Resources.Requests = corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse(request),
+			}
+		}
+		if limit != "" {
+			container.Resources.Limits = corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse(limit),
+			}
+		}
+		return &corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "other"}, container},
+		}}
+	}
+
+	assert.Assert(t, hasDedicatedCPUs(template("2", "2"), naming.ContainerDatabase))
+	assert.Assert(t, hasDedicatedCPUs(template("1000m", "1"), naming.ContainerDatabase))
+
+	assert.Assert(t, !hasDedicatedCPUs(template("", ""), naming.ContainerDatabase))
+	assert.Assert(t, !hasDedicatedCPUs(template("", "2"), naming.ContainerDatabase))
+	assert.Assert(t, !hasDedicatedCPUs(template("1", "2"), naming.ContainerDatabase))
+	assert.Assert(t, !hasDedicatedCPUs(template("1500m", "1500m"), naming.ContainerDatabase))
+	assert.Assert(t, !hasDedicatedCPUs(template("2", "2"), "missing"))
+}
+
+func TestApplyPodTemplateOverlay(t *testing.T) {
+	generated := func() *corev1.PodTemplateSpec {
+		return &corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  naming.ContainerDatabase,
+				Image: "postgres",
+				Env:   []corev1.EnvVar{{Name: "PGDATA", Value: "/pgdata"}},
+			}, {
+				Name: "other",
+			}},
+		}}
+	}
+
+	t.Run("Empty", func(t *testing.T) {
+		template := generated()
+		assert.NilError(t, applyPodTemplateOverlay(template, nil))
+		assert.DeepEqual(t, template, generated())
+	})
+
+	t.Run("Merge", func(t *testing.T) {
+		template := generated()
+		assert.NilError(t, applyPodTemplateOverlay(template, map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]interface{}{"some": "annotation"},
+			},
+			"spec": map[string]interface{}{
+				"containers": []interface{}{map[string]interface{}{
+					"name": "database",
+					"env":  []interface{}{map[string]interface{}{"name": "TZ", "value": "UTC"}},
+				}},
+				"securityContext": map[string]interface{}{"fsGroup": int64(26)},
+			},
+		}))
+
+		assert.DeepEqual(t, template.Annotations, map[string]string{"some": "annotation"})
+		assert.Equal(t, len(template.Spec.Containers), 2)
+
+		// Containers merge by name and env merges by name.
+		database := template.Spec.Containers[0]
+		assert.Equal(t, database.Image, "postgres")
+		assert.DeepEqual(t, database.Env, []corev1.EnvVar{
+			{Name: "TZ", Value: "UTC"}, {Name: "PGDATA", Value: "/pgdata"},
+		})
+		assert.Equal(t, *template.Spec.SecurityContext.FSGroup, int64(26))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		template := generated()
+		err := applyPodTemplateOverlay(template, map[string]interface{}{
+			"spec": map[string]interface{}{"containers": "database"},
+		})
+		assert.Assert(t, err != nil)
+		assert.DeepEqual(t, template, generated())
+	})
+
+	t.Run("Event", func(t *testing.T) {
+		cluster := testCluster()
+		recorder := record.NewFakeRecorder(1)
+		reconciler := &Reconciler{Recorder: recorder}
+
+		template := generated()
+		assert.Assert(t, !reconciler.overlayPodTemplate(cluster, "PgBouncer", template,
+			map[string]interface{}{"spec": "invalid"}))
+		assert.DeepEqual(t, template, generated())
+
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Assert(t, cmp.Contains(<-recorder.Events,
+			"Warning InvalidPodTemplateOverlay The podTemplateOverlay of PgBouncer cannot be applied"))
+
+		assert.Assert(t, reconciler.overlayPodTemplate(cluster, "PgBouncer", template, nil))
+		assert.Equal(t, len(recorder.Events), 0)
+	})
+}
+
+func TestSetServiceSpec(t *testing.T) {
+	generated := func() *corev1.Service {
+		service := &corev1.Service{}
+		service.Annotations = map[string]string{"cluster": "note"}
+		service.Labels = map[string]string{naming.LabelRole: naming.RolePGBouncer}
+		service.Spec.Ports = []corev1.ServicePort{{Name: "pgbouncer", Port: 5432}}
+		return service
+	}
+
+	t.Run("Nil", func(t *testing.T) {
+		service := generated()
+		setServiceSpec(service, nil)
+		assert.Equal(t, service.Spec.Type, corev1.ServiceTypeClusterIP)
+		assert.DeepEqual(t, service.Annotations, generated().Annotations)
+		assert.DeepEqual(t, service.Labels, generated().Labels)
+	})
+
+	t.Run("Metadata", func(t *testing.T) {
+		service := generated()
+		setServiceSpec(service, &v1beta1.ServiceSpec{
+			Type: "LoadBalancer",
+			Metadata: &v1beta1.Metadata{
+				Annotations: map[string]string{"cluster": "override", "lb": "nlb"},
+				Labels:      map[string]string{naming.LabelRole: "nope", "some": "label"},
+			},
+		})
+		assert.Equal(t, service.Spec.Type, corev1.ServiceTypeLoadBalancer)
+		assert.DeepEqual(t, service.Annotations, map[string]string{
+			"cluster": "override", "lb": "nlb",
+		})
+		assert.DeepEqual(t, service.Labels, map[string]string{
+			naming.LabelRole: naming.RolePGBouncer, "some": "label",
+		})
+	})
+
+	t.Run("NodePort", func(t *testing.T) {
+		port := int32(32000)
+
+		service := generated()
+		setServiceSpec(service, &v1beta1.ServiceSpec{Type: "NodePort", NodePort: &port})
+		assert.Equal(t, service.Spec.Ports[0].NodePort, port)
+
+		service = generated()
+		setServiceSpec(service, &v1beta1.ServiceSpec{Type: "ClusterIP", NodePort: &port})
+		assert.Equal(t, service.Spec.Ports[0].NodePort, int32(0))
+	})
+}
+
 func TestAddNSSWrapper(t *testing.T) {
 
 	image := "test-image"
@@ -476,6 +667,35 @@ func TestJobCompleted(t *testing.T) {
 	}
 }
 
+func TestJobPhase(t *testing.T) {
+	job := &batchv1.Job{}
+	phase, message := jobPhase(job)
+	assert.Equal(t, phase, "Pending")
+	assert.Equal(t, message, "")
+
+	job.Status.Active = 1
+	phase, _ = jobPhase(job)
+	assert.Equal(t, phase, "Running")
+
+	job.Status.Active = 0
+	job.Status.Conditions = []batchv1.JobCondition{{
+		Type:    batchv1.JobFailed,
+		Status:  corev1.ConditionTrue,
+		Message: "Job has reached the specified backoff limit",
+	}}
+	phase, message = jobPhase(job)
+	assert.Equal(t, phase, "Failed")
+	assert.Equal(t, message, "Job has reached the specified backoff limit")
+
+	job.Status.Conditions = []batchv1.JobCondition{{
+		Type:   batchv1.JobComplete,
+		Status: corev1.ConditionTrue,
+	}}
+	phase, message = jobPhase(job)
+	assert.Equal(t, phase, "Succeeded")
+	assert.Equal(t, message, "")
+}
+
 func TestJobFailed(t *testing.T) {
 
 	testCases := []struct {
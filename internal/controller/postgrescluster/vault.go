@@ -0,0 +1,114 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// vaultRefreshInterval is how long PGO uses a password that it read from Vault
+// before it reads the secret again.
+const vaultRefreshInterval = 5 * time.Minute
+
+// vaultPasswordSource returns a string that identifies the Vault secret and key
+// described by spec. It is the prefix of the revisions of that password.
+func vaultPasswordSource(spec *v1beta1.PostgresPasswordVaultSpec) (string, error) {
+	hash, err := safeHash32(func(w io.Writer) error {
+		_, err := io.WriteString(w, spec.Mount+"\x00"+spec.Path+"\x00"+spec.Key)
+		return err
+	})
+	return "vault/" + hash + "/", err
+}
+
+// vaultPasswordIsRecent returns whether existing has the verifier of a password
+// that was read from the Vault secret described by spec less than
+// vaultRefreshInterval before now.
+func vaultPasswordIsRecent(
+	spec *v1beta1.PostgresPasswordVaultSpec, existing *corev1.Secret, now time.Time,
+) bool {
+	if existing == nil || len(existing.Data["verifier"]) == 0 {
+		return false
+	}
+
+	source, err := vaultPasswordSource(spec)
+	if err != nil || !strings.HasPrefix(
+		existing.Annotations[naming.PostgresPasswordRevision], source) {
+		return false
+	}
+
+	read, err := time.Parse(time.RFC3339, existing.Annotations[naming.PostgresPasswordRead])
+	return err == nil && !read.After(now) && now.Sub(read) < vaultRefreshInterval
+}
+
+// readVaultPassword returns the password and version of the Vault secret
+// described by spec.
+func (r *Reconciler) readVaultPassword(
+	ctx context.Context, spec *v1beta1.PostgresPasswordVaultSpec,
+) (string, int64, error) {
+	if r.Vault == nil {
+		return "", 0, errors.New(
+			"vault: PGO is not configured with VAULT_ADDR and PGO_VAULT_ROLE")
+	}
+
+	secret, err := r.Vault.ReadKV(ctx, spec.Mount, spec.Path)
+	err = errors.WithStack(err)
+
+	if err == nil && secret.Data[spec.Key] == "" {
+		err = errors.Errorf("vault: secret %q has no %q key", spec.Path, spec.Key)
+	}
+	if err != nil {
+		return "", 0, err
+	}
+	return secret.Data[spec.Key], secret.Version, nil
+}
+
+// generateVaultPostgresUserSecret returns a Secret containing the verifier of
+// a password stored in Vault and connection details that do not include that
+// password.
+func (r *Reconciler) generateVaultPostgresUserSecret(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+	spec *v1beta1.PostgresUserSpec, existing *corev1.Secret,
+) (*corev1.Secret, error) {
+	source, err := vaultPasswordSource(spec.Password.Vault)
+	if err != nil {
+		return nil, err
+	}
+
+	read := time.Now()
+	password, version, err := r.readVaultPassword(ctx, spec.Password.Vault)
+	if err != nil {
+		return nil, err
+	}
+
+	intent, err := r.generateExternalPostgresUserSecret(cluster, spec, existing,
+		password, source+strconv.FormatInt(version, 10))
+	if err == nil {
+		intent.Annotations = naming.Merge(intent.Annotations, map[string]string{
+			naming.PostgresPasswordRead: read.UTC().Format(time.RFC3339),
+		})
+	}
+	return intent, err
+}
@@ -0,0 +1,191 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/vault"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestGenerateVaultPostgresUserSecret(t *testing.T) {
+	ctx := context.Background()
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	version := `1`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/kubernetes/login":
+			_, _ = w.Write([]byte(`{"auth":{"client_token":"some-token","lease_duration":60}}`))
+		default:
+			assert.Equal(t, r.URL.Path, "/v1/secret/data/postgres/hippo")
+			assert.Equal(t, r.Header.Get("X-Vault-Token"), "some-token")
+			_, _ = w.Write([]byte(`{"data":{"data":{"password":"from-vault"},"metadata":{"version":` + version + `}}}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	reconciler := &Reconciler{Client: fake.NewClientBuilder().WithScheme(scheme).Build()}
+
+	cluster := testCluster()
+	cluster.Namespace = "ns1"
+	cluster.Spec.Port = initialize.Int32(5432)
+	cluster.Spec.Proxy.PGBouncer.Port = initialize.Int32(5432)
+
+	spec := &v1beta1.PostgresUserSpec{
+		Name:      "hippo",
+		Databases: []v1beta1.PostgresIdentifier{"zoo"},
+		Password: &v1beta1.PostgresPasswordSpec{
+			Vault: &v1beta1.PostgresPasswordVaultSpec{
+				Mount: "secret", Path: "postgres/hippo", Key: "password",
+			},
+		},
+	}
+
+	t.Run("NotConfigured", func(t *testing.T) {
+		_, err := reconciler.generateVaultPostgresUserSecret(ctx, cluster, spec, nil)
+		assert.ErrorContains(t, err, "VAULT_ADDR")
+	})
+
+	reconciler.Vault = vault.NewSession(server.URL, "", "pgo")
+	reconciler.Vault.TokenPath = filepath.Join(t.TempDir(), "token")
+	assert.NilError(t, os.WriteFile(reconciler.Vault.TokenPath, []byte("sa-token"), 0o600))
+
+	source, err := vaultPasswordSource(spec.Password.Vault)
+	assert.NilError(t, err)
+
+	secret, err := reconciler.generateVaultPostgresUserSecret(ctx, cluster, spec, nil)
+	assert.NilError(t, err)
+
+	assert.Equal(t, secret.Annotations[naming.PostgresPasswordRevision], source+"1")
+	assert.Assert(t, secret.Annotations[naming.PostgresPasswordRead] != "")
+	assert.Assert(t, len(secret.Data["verifier"]) > 0)
+	assert.Equal(t, string(secret.Data["dbname"]), "zoo")
+
+	for _, key := range []string{
+		"password", "uri", "jdbc-uri", "pgbouncer-uri", "pgbouncer-jdbc-uri",
+	} {
+		_, found := secret.Data[key]
+		assert.Assert(t, !found, "expected no %q", key)
+	}
+
+	t.Run("SameVersion", func(t *testing.T) {
+		existing := &corev1.Secret{Data: map[string][]byte{"verifier": []byte("kept")}}
+		existing.Annotations = map[string]string{naming.PostgresPasswordRevision: source + "1"}
+
+		secret, err := reconciler.generateVaultPostgresUserSecret(ctx, cluster, spec, existing)
+		assert.NilError(t, err)
+		assert.Equal(t, string(secret.Data["verifier"]), "kept")
+	})
+
+	t.Run("NewVersion", func(t *testing.T) {
+		version = `2`
+		existing := &corev1.Secret{Data: map[string][]byte{"verifier": []byte("kept")}}
+		existing.Annotations = map[string]string{naming.PostgresPasswordRevision: source + "1"}
+
+		secret, err := reconciler.generateVaultPostgresUserSecret(ctx, cluster, spec, existing)
+		assert.NilError(t, err)
+		assert.Equal(t, secret.Annotations[naming.PostgresPasswordRevision], source+"2")
+		assert.Assert(t, string(secret.Data["verifier"]) != "kept")
+	})
+}
+
+func TestVaultPasswordIsRecent(t *testing.T) {
+	now := time.Now()
+	spec := &v1beta1.PostgresPasswordVaultSpec{
+		Mount: "secret", Path: "postgres/hippo", Key: "password",
+	}
+	source, err := vaultPasswordSource(spec)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(source, "vault/"))
+
+	secret := func(revision string, read time.Time) *corev1.Secret {
+		secret := &corev1.Secret{Data: map[string][]byte{"verifier": []byte("some")}}
+		secret.Annotations = map[string]string{
+			naming.PostgresPasswordRevision: revision,
+			naming.PostgresPasswordRead:     read.UTC().Format(time.RFC3339),
+		}
+		return secret
+	}
+
+	assert.Assert(t, !vaultPasswordIsRecent(spec, nil, now))
+	assert.Assert(t, vaultPasswordIsRecent(spec, secret(source+"3", now.Add(-time.Minute)), now))
+
+	// Passwords are read again after a while.
+	assert.Assert(t, !vaultPasswordIsRecent(spec, secret(source+"3", now.Add(-time.Hour)), now))
+
+	// Passwords are read again when the spec changes.
+	other := *spec
+	other.Path = "postgres/rhino"
+	assert.Assert(t, !vaultPasswordIsRecent(&other, secret(source+"3", now), now))
+
+	// Passwords are read again when there is no verifier.
+	empty := secret(source+"3", now)
+	empty.Data = nil
+	assert.Assert(t, !vaultPasswordIsRecent(spec, empty, now))
+}
+
+func TestReconcilePostgresUsersVaultUnavailable(t *testing.T) {
+	ctx := context.Background()
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).Build(),
+		Recorder: recorder,
+	}
+
+	cluster := testCluster()
+	cluster.Namespace = "ns1"
+	cluster.Spec.Users = []v1beta1.PostgresUserSpec{{
+		Name: "hippo",
+		Password: &v1beta1.PostgresPasswordSpec{
+			Vault: &v1beta1.PostgresPasswordVaultSpec{
+				Mount: "secret", Path: "postgres/hippo", Key: "password",
+			},
+		},
+	}}
+
+	// Vault is not configured, so the password cannot be read. The error is
+	// reported without stopping the reconcile, which tries again later.
+	result, err := reconciler.reconcilePostgresUsers(ctx, cluster, &observedInstances{})
+	assert.NilError(t, err)
+	assert.Equal(t, result.RequeueAfter, vaultRefreshInterval)
+
+	assert.Equal(t, len(recorder.Events), 1)
+	event := <-recorder.Events
+	assert.Assert(t, cmp.Contains(event, "VaultUnavailable"))
+	assert.Assert(t, cmp.Contains(event, `"hippo"`))
+}
@@ -455,7 +455,10 @@ func (r *Reconciler) reconcileMovePGDataDir(ctx context.Context,
 
 	jobSpec := &batchv1.JobSpec{
 		Template: corev1.PodTemplateSpec{
-			ObjectMeta: metav1.ObjectMeta{Labels: labels},
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      labels,
+				Annotations: moveDirJob.Annotations,
+			},
 			Spec: corev1.PodSpec{
 				// Set the image pull secrets, if any exist.
 				// This is set here rather than using the service account due to the lack
@@ -573,7 +576,10 @@ func (r *Reconciler) reconcileMoveWALDir(ctx context.Context,
 
 	jobSpec := &batchv1.JobSpec{
 		Template: corev1.PodTemplateSpec{
-			ObjectMeta: metav1.ObjectMeta{Labels: labels},
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      labels,
+				Annotations: moveDirJob.Annotations,
+			},
 			Spec: corev1.PodSpec{
 				// Set the image pull secrets, if any exist.
 				// This is set here rather than using the service account due to the lack
@@ -696,7 +702,10 @@ func (r *Reconciler) reconcileMoveRepoDir(ctx context.Context,
 
 	jobSpec := &batchv1.JobSpec{
 		Template: corev1.PodTemplateSpec{
-			ObjectMeta: metav1.ObjectMeta{Labels: labels},
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      labels,
+				Annotations: moveDirJob.Annotations,
+			},
 			Spec: corev1.PodSpec{
 				// Set the image pull secrets, if any exist.
 				// This is set here rather than using the service account due to the lack
@@ -771,7 +780,7 @@ func (r *Reconciler) handlePersistentVolumeClaimError(
 
 	volumeError := func(err error) {
 		r.Recorder.Event(cluster,
-			corev1.EventTypeWarning, "PersistentVolumeError", err.Error())
+			corev1.EventTypeWarning, EventPersistentVolumeError, err.Error())
 	}
 
 	// Forbidden means (RBAC is broken or) the API request was rejected by an
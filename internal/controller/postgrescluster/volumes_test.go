@@ -875,6 +875,10 @@ func TestReconcileMoveDirectories(t *testing.T) {
 			Namespace: ns.GetName(),
 		},
 		Spec: v1beta1.PostgresClusterSpec{
+			Metadata: &v1beta1.Metadata{
+				Labels:      map[string]string{"Global": "test"},
+				Annotations: map[string]string{"sidecar.istio.io/inject": "false"},
+			},
 			PostgresVersion: 13,
 			Image:           "example.com/crunchy-postgres-ha:test",
 			ImagePullPolicy: corev1.PullAlways,
@@ -1130,4 +1134,14 @@ volumes:
 		}
 
 	})
+
+	t.Run("check move job pod metadata", func(t *testing.T) {
+		assert.Equal(t, len(moveJobs.Items), 3)
+
+		for i := range moveJobs.Items {
+			template := moveJobs.Items[i].Spec.Template
+			assert.Equal(t, template.Labels["Global"], "test")
+			assert.Equal(t, template.Annotations["sidecar.istio.io/inject"], "false")
+		}
+	})
 }
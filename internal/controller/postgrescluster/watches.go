@@ -16,14 +16,21 @@
 package postgrescluster
 
 import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	"github.com/crunchydata/postgres-operator/internal/logging"
 	"github.com/crunchydata/postgres-operator/internal/naming"
 	"github.com/crunchydata/postgres-operator/internal/patroni"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 // watchPods returns a handler.EventHandler for Pods.
@@ -46,6 +53,33 @@ func (*Reconciler) watchPods() handler.Funcs {
 				return
 			}
 
+			// Queue an event when a Patroni pod indicates PostgreSQL failed to
+			// start so that it can be reinitialized.
+			if len(cluster) != 0 &&
+				!patroni.PodFailedToStart(e.ObjectOld) &&
+				patroni.PodFailedToStart(e.ObjectNew) {
+				q.Add(reconcile.Request{NamespacedName: client.ObjectKey{
+					Namespace: e.ObjectNew.GetNamespace(),
+					Name:      cluster,
+				}})
+				return
+			}
+
+			// Queue an event when a container of a pod starts or stops crash
+			// looping so that the conditions of the cluster report it.
+			if len(cluster) != 0 {
+				before, _ := e.ObjectOld.(*corev1.Pod)
+				after, _ := e.ObjectNew.(*corev1.Pod)
+				if before != nil && after != nil &&
+					podCrashLooping(before) != podCrashLooping(after) {
+					q.Add(reconcile.Request{NamespacedName: client.ObjectKey{
+						Namespace: e.ObjectNew.GetNamespace(),
+						Name:      cluster,
+					}})
+					return
+				}
+			}
+
 			// Queue an event when a Patroni pod indicates it needs to restart
 			// or finished restarting.
 			if len(cluster) != 0 &&
@@ -60,3 +94,138 @@ func (*Reconciler) watchPods() handler.Funcs {
 		},
 	}
 }
+
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// namespaceSelected returns whether or not PostgresClusters in the Namespace
+// called name should be reconciled according to the NamespaceSelector of r.
+func (r *Reconciler) namespaceSelected(ctx context.Context, name string) (bool, error) {
+	if r.NamespaceSelector == nil {
+		return true, nil
+	}
+
+	namespace := &corev1.Namespace{}
+	err := errors.WithStack(r.Client.Get(ctx, client.ObjectKey{Name: name}, namespace))
+
+	return err == nil && r.NamespaceSelector.Matches(labels.Set(namespace.Labels)), err
+}
+
+// watchNamespaces returns a handler.EventHandler for Namespaces. It queues every
+// PostgresCluster in a Namespace that starts or stops matching NamespaceSelector.
+func (r *Reconciler) watchNamespaces() handler.Funcs {
+	return handler.Funcs{
+		UpdateFunc: func(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			before := r.NamespaceSelector.Matches(labels.Set(e.ObjectOld.GetLabels()))
+			after := r.NamespaceSelector.Matches(labels.Set(e.ObjectNew.GetLabels()))
+			if before == after {
+				return
+			}
+
+			ctx := context.Background()
+			clusters := &v1beta1.PostgresClusterList{}
+			if err := r.Client.List(ctx, clusters,
+				client.InNamespace(e.ObjectNew.GetName()),
+			); err != nil {
+				logging.FromContext(ctx).Error(err, "unable to list PostgresClusters",
+					"namespace", e.ObjectNew.GetName())
+				return
+			}
+
+			for i := range clusters.Items {
+				q.Add(reconcile.Request{
+					NamespacedName: client.ObjectKeyFromObject(&clusters.Items[i]),
+				})
+			}
+		},
+	}
+}
+
+// watchSQLPolicies returns a handler.EventHandler for ConfigMaps. It queues
+// every PostgresCluster in the Namespace of a ConfigMap that defines a SQL
+// policy so that clusters start or stop running the policy as it changes.
+func (r *Reconciler) watchSQLPolicies() handler.Funcs {
+	queue := func(q workqueue.RateLimitingInterface, objects ...client.Object) {
+		defined := false
+		for _, object := range objects {
+			_, ok := object.GetLabels()[naming.LabelSQLPolicy]
+			defined = defined || ok
+		}
+		if !defined {
+			return
+		}
+
+		ctx := context.Background()
+		clusters := &v1beta1.PostgresClusterList{}
+		if err := r.Client.List(ctx, clusters,
+			client.InNamespace(objects[0].GetNamespace()),
+		); err != nil {
+			logging.FromContext(ctx).Error(err, "unable to list PostgresClusters",
+				"namespace", objects[0].GetNamespace())
+			return
+		}
+
+		for i := range clusters.Items {
+			q.Add(reconcile.Request{
+				NamespacedName: client.ObjectKeyFromObject(&clusters.Items[i]),
+			})
+		}
+	}
+
+	return handler.Funcs{
+		CreateFunc: func(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+			queue(q, e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			queue(q, e.ObjectOld, e.ObjectNew)
+		},
+		DeleteFunc: func(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+			queue(q, e.Object)
+		},
+	}
+}
+
+// watchPasswordSecrets returns a handler.EventHandler for Secrets. It queues
+// every PostgresCluster with a user whose password is in the Secret so that
+// PostgreSQL gets the password when the Secret is created or changes.
+func (r *Reconciler) watchPasswordSecrets() handler.Funcs {
+	queue := func(q workqueue.RateLimitingInterface, secret client.Object) {
+		// Secrets of PostgresClusters do not hold these passwords.
+		if _, owned := secret.GetLabels()[naming.LabelCluster]; owned {
+			return
+		}
+
+		ctx := context.Background()
+		clusters := &v1beta1.PostgresClusterList{}
+		if err := r.Client.List(ctx, clusters,
+			client.InNamespace(secret.GetNamespace()),
+		); err != nil {
+			logging.FromContext(ctx).Error(err, "unable to list PostgresClusters",
+				"namespace", secret.GetNamespace())
+			return
+		}
+
+		for i := range clusters.Items {
+			for _, user := range clusters.Items[i].Spec.Users {
+				if user.Password != nil && user.Password.SecretKeyRef != nil &&
+					user.Password.SecretKeyRef.Name == secret.GetName() {
+					q.Add(reconcile.Request{
+						NamespacedName: client.ObjectKeyFromObject(&clusters.Items[i]),
+					})
+					break
+				}
+			}
+		}
+	}
+
+	return handler.Funcs{
+		CreateFunc: func(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+			queue(q, e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			queue(q, e.ObjectNew)
+		},
+		DeleteFunc: func(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+			queue(q, e.Object)
+		},
+	}
+}
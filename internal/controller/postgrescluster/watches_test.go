@@ -16,15 +16,21 @@
 package postgrescluster
 
 import (
+	"context"
 	"testing"
 
 	"gotest.tools/v3/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 func TestWatchPodsUpdate(t *testing.T) {
@@ -140,4 +146,271 @@ func TestWatchPodsUpdate(t *testing.T) {
 		assert.Equal(t, item, expected)
 		queue.Done(item)
 	})
+
+	t.Run("FailedToStart", func(t *testing.T) {
+		expected := reconcile.Request{}
+		expected.Namespace = "some-ns"
+		expected.Name = "starfish"
+
+		base := &corev1.Pod{}
+		base.Namespace = "some-ns"
+		base.Labels = map[string]string{
+			"postgres-operator.crunchydata.com/cluster": "starfish",
+		}
+
+		failed := base.DeepCopy()
+		failed.Annotations = map[string]string{
+			"status": `{"state":"start failed"}`,
+		}
+
+		// Newly failed; one reconcile by label.
+		update(event.UpdateEvent{
+			ObjectOld: base.DeepCopy(),
+			ObjectNew: failed.DeepCopy(),
+		}, queue)
+		assert.Equal(t, queue.Len(), 1, "expected one reconcile")
+
+		item, _ := queue.Get()
+		assert.Equal(t, item, expected)
+		queue.Done(item)
+
+		// Still failed; no reconcile.
+		update(event.UpdateEvent{
+			ObjectOld: failed.DeepCopy(),
+			ObjectNew: failed.DeepCopy(),
+		}, queue)
+		assert.Equal(t, queue.Len(), 0, "expected no reconcile")
+	})
+
+	t.Run("CrashLooping", func(t *testing.T) {
+		expected := reconcile.Request{}
+		expected.Namespace = "some-ns"
+		expected.Name = "starfish"
+
+		base := &corev1.Pod{}
+		base.Namespace = "some-ns"
+		base.Labels = map[string]string{
+			"postgres-operator.crunchydata.com/cluster": "starfish",
+		}
+
+		looping := base.DeepCopy()
+		looping.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name: "database",
+			State: corev1.ContainerState{
+				Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+			},
+		}}
+
+		// Newly crash looping; one reconcile by label.
+		update(event.UpdateEvent{
+			ObjectOld: base.DeepCopy(),
+			ObjectNew: looping.DeepCopy(),
+		}, queue)
+		assert.Equal(t, queue.Len(), 1, "expected one reconcile")
+
+		item, _ := queue.Get()
+		assert.Equal(t, item, expected)
+		queue.Done(item)
+
+		// Still crash looping; no reconcile.
+		update(event.UpdateEvent{
+			ObjectOld: looping.DeepCopy(),
+			ObjectNew: looping.DeepCopy(),
+		}, queue)
+		assert.Equal(t, queue.Len(), 0, "expected no reconcile")
+
+		// No longer crash looping; one reconcile by label.
+		update(event.UpdateEvent{
+			ObjectOld: looping.DeepCopy(),
+			ObjectNew: base.DeepCopy(),
+		}, queue)
+		assert.Equal(t, queue.Len(), 1, "expected one reconcile")
+
+		item, _ = queue.Get()
+		queue.Done(item)
+	})
+}
+
+func TestNamespaceSelected(t *testing.T) {
+	ctx := context.Background()
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	labeled := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name: "labeled", Labels: map[string]string{"pgo-enabled": "true"},
+	}}
+	unlabeled := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "unlabeled"}}
+
+	reconciler := &Reconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(labeled, unlabeled).Build(),
+	}
+
+	// No selector; every namespace.
+	selected, err := reconciler.namespaceSelected(ctx, "anything")
+	assert.NilError(t, err)
+	assert.Assert(t, selected)
+
+	reconciler.NamespaceSelector = labels.SelectorFromSet(labels.Set{"pgo-enabled": "true"})
+
+	selected, err = reconciler.namespaceSelected(ctx, "labeled")
+	assert.NilError(t, err)
+	assert.Assert(t, selected)
+
+	selected, err = reconciler.namespaceSelected(ctx, "unlabeled")
+	assert.NilError(t, err)
+	assert.Assert(t, !selected)
+
+	_, err = reconciler.namespaceSelected(ctx, "missing")
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestWatchNamespacesUpdate(t *testing.T) {
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	cluster := &v1beta1.PostgresCluster{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "some-ns", Name: "hippo",
+	}}
+	other := &v1beta1.PostgresCluster{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "other-ns", Name: "rhino",
+	}}
+
+	queue := controllertest.Queue{Interface: workqueue.New()}
+	reconciler := &Reconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(cluster, other).Build(),
+		NamespaceSelector: labels.SelectorFromSet(labels.Set{"pgo-enabled": "true"}),
+	}
+
+	update := reconciler.watchNamespaces().UpdateFunc
+	assert.Assert(t, update != nil)
+
+	unlabeled := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "some-ns"}}
+	labeled := unlabeled.DeepCopy()
+	labeled.Labels = map[string]string{"pgo-enabled": "true"}
+
+	// Labels did not change; no reconcile.
+	update(event.UpdateEvent{ObjectOld: unlabeled, ObjectNew: unlabeled}, queue)
+	assert.Equal(t, queue.Len(), 0)
+
+	// Namespace became selected; reconcile its clusters.
+	update(event.UpdateEvent{ObjectOld: unlabeled, ObjectNew: labeled}, queue)
+	assert.Equal(t, queue.Len(), 1)
+
+	item, _ := queue.Get()
+	expected := reconcile.Request{}
+	expected.Namespace = "some-ns"
+	expected.Name = "hippo"
+	assert.Equal(t, item, expected)
+	queue.Done(item)
+
+	// Namespace is no longer selected; reconcile its clusters.
+	update(event.UpdateEvent{ObjectOld: labeled, ObjectNew: unlabeled}, queue)
+	assert.Equal(t, queue.Len(), 1)
+}
+
+func TestWatchSQLPolicies(t *testing.T) {
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	cluster := &v1beta1.PostgresCluster{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "some-ns", Name: "hippo",
+	}}
+	other := &v1beta1.PostgresCluster{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "other-ns", Name: "rhino",
+	}}
+
+	queue := controllertest.Queue{Interface: workqueue.New()}
+	reconciler := &Reconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(cluster, other).Build(),
+	}
+
+	handler := reconciler.watchSQLPolicies()
+	assert.Assert(t, handler.CreateFunc != nil)
+	assert.Assert(t, handler.UpdateFunc != nil)
+	assert.Assert(t, handler.DeleteFunc != nil)
+
+	plain := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "some-ns", Name: "settings",
+	}}
+	policy := plain.DeepCopy()
+	policy.Labels = map[string]string{"postgres-operator.crunchydata.com/sqlpolicy": "analyze"}
+
+	// ConfigMap does not define a policy; no reconcile.
+	handler.CreateFunc(event.CreateEvent{Object: plain}, queue)
+	assert.Equal(t, queue.Len(), 0)
+
+	// ConfigMap defines a policy; reconcile clusters in its namespace.
+	handler.CreateFunc(event.CreateEvent{Object: policy}, queue)
+	assert.Equal(t, queue.Len(), 1)
+
+	item, _ := queue.Get()
+	expected := reconcile.Request{}
+	expected.Namespace = "some-ns"
+	expected.Name = "hippo"
+	assert.Equal(t, item, expected)
+	queue.Done(item)
+
+	// ConfigMap stops defining a policy; reconcile clusters in its namespace.
+	handler.UpdateFunc(event.UpdateEvent{ObjectOld: policy, ObjectNew: plain}, queue)
+	assert.Equal(t, queue.Len(), 1)
+}
+
+func TestWatchPasswordSecrets(t *testing.T) {
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	reference := func(name string) *v1beta1.PostgresPasswordSpec {
+		return &v1beta1.PostgresPasswordSpec{SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: name},
+			Key:                  "password",
+		}}
+	}
+
+	cluster := &v1beta1.PostgresCluster{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "some-ns", Name: "hippo",
+	}}
+	cluster.Spec.Users = []v1beta1.PostgresUserSpec{
+		{Name: "rhino"},
+		{Name: "zebra", Password: reference("synced")},
+	}
+	other := &v1beta1.PostgresCluster{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "other-ns", Name: "hippo",
+	}}
+	other.Spec.Users = []v1beta1.PostgresUserSpec{{Name: "zebra", Password: reference("synced")}}
+
+	queue := controllertest.Queue{Interface: workqueue.New()}
+	reconciler := &Reconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(cluster, other).Build(),
+	}
+
+	handler := reconciler.watchPasswordSecrets()
+	assert.Assert(t, handler.CreateFunc != nil)
+	assert.Assert(t, handler.UpdateFunc != nil)
+	assert.Assert(t, handler.DeleteFunc != nil)
+
+	unrelated := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "some-ns", Name: "unrelated",
+	}}
+	synced := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "some-ns", Name: "synced",
+	}}
+
+	// Secret is not referenced; no reconcile.
+	handler.CreateFunc(event.CreateEvent{Object: unrelated}, queue)
+	assert.Equal(t, queue.Len(), 0)
+
+	// Secret is referenced; reconcile the cluster in its namespace.
+	handler.UpdateFunc(event.UpdateEvent{ObjectOld: synced, ObjectNew: synced}, queue)
+	assert.Equal(t, queue.Len(), 1)
+
+	item, _ := queue.Get()
+	expected := reconcile.Request{}
+	expected.Namespace = "some-ns"
+	expected.Name = "hippo"
+	assert.Equal(t, item, expected)
+	queue.Done(item)
 }
@@ -16,20 +16,27 @@ limitations under the License.
 */
 
 import (
+	"os"
+	"strconv"
 	"time"
 
+	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
+	pgoconfig "github.com/crunchydata/postgres-operator/internal/config"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 // default refresh interval in minutes
 var refreshInterval = 60 * time.Minute
 
+// +kubebuilder:rbac:groups="coordination.k8s.io",resources="leases",verbs={get,create,update}
+
 // CreateRuntimeManager creates a new controller runtime manager for the PostgreSQL Operator.  The
 // manager returned is configured specifically for the PostgreSQL Operator, and includes any
 // controllers that will be responsible for managing PostgreSQL clusters using the
@@ -43,11 +50,39 @@ func CreateRuntimeManager(namespace string, config *rest.Config,
 		return nil, err
 	}
 
+	// PGO_RESYNC_INTERVAL changes how often every PostgresCluster, and every
+	// object it owns, is reconciled even when nothing about it has changed.
+	syncPeriod := refreshInterval
+	if s := os.Getenv("PGO_RESYNC_INTERVAL"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err == nil && d <= 0 {
+			err = errors.New("must be a positive duration")
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "PGO_RESYNC_INTERVAL")
+		}
+		syncPeriod = d
+	}
+
 	options := manager.Options{
 		Namespace:  namespace, // if empty then watching all namespaces
-		SyncPeriod: &refreshInterval,
+		SyncPeriod: &syncPeriod,
 		Scheme:     pgoScheme,
 	}
+
+	// When PGO_CONTROLLER_LEASE_NAME is set, replicas of PGO elect a leader
+	// using a Lease of that name in the namespace of PGO. Only the leader
+	// reconciles, while every replica serves webhooks and metrics.
+	if name := os.Getenv("PGO_CONTROLLER_LEASE_NAME"); name != "" {
+		options.LeaderElection = true
+		options.LeaderElectionID = name
+		options.LeaderElectionNamespace = pgoconfig.PGONamespace()
+		options.LeaderElectionResourceLock = resourcelock.LeasesResourceLock
+
+		// Step down when stopping so that another replica takes over without
+		// waiting for the Lease to expire.
+		options.LeaderElectionReleaseOnCancel = true
+	}
 	if disableMetrics {
 		options.HealthProbeBindAddress = "0"
 		options.MetricsBindAddress = "0"
@@ -63,7 +98,36 @@ func CreateRuntimeManager(namespace string, config *rest.Config,
 }
 
 // GetConfig creates a *rest.Config for talking to a Kubernetes API server.
-func GetConfig() (*rest.Config, error) { return config.GetConfig() }
+// The client-side rate limit of requests defaults to 20 per second with bursts
+// of 30. Set PGO_KUBE_API_QPS and PGO_KUBE_API_BURST to change it.
+func GetConfig() (*rest.Config, error) {
+	cfg, err := config.GetConfig()
+
+	if s := os.Getenv("PGO_KUBE_API_QPS"); err == nil && s != "" {
+		var qps float64
+		qps, err = strconv.ParseFloat(s, 32)
+		if err == nil && qps <= 0 {
+			err = errors.New("must be a positive number")
+		}
+		err = errors.Wrap(err, "PGO_KUBE_API_QPS")
+		if err == nil {
+			cfg.QPS = float32(qps)
+		}
+	}
+	if s := os.Getenv("PGO_KUBE_API_BURST"); err == nil && s != "" {
+		var burst int
+		burst, err = strconv.Atoi(s)
+		if err == nil && burst <= 0 {
+			err = errors.New("must be a positive number")
+		}
+		err = errors.Wrap(err, "PGO_KUBE_API_BURST")
+		if err == nil {
+			cfg.Burst = burst
+		}
+	}
+
+	return cfg, err
+}
 
 // CreatePostgresOperatorScheme creates a scheme containing the resource types required by the
 // PostgreSQL Operator.  This includes any custom resource types specific to the PostgreSQL
@@ -25,6 +25,16 @@ const (
 	// Patroni Switchover (or Failover).
 	PatroniSwitchover = annotationPrefix + "trigger-switchover"
 
+	// PatroniReinitialized is the annotation added to an instance Pod to record the time at which
+	// its replica was last reinitialized, as needed to wait before doing so again.
+	PatroniReinitialized = annotationPrefix + "reinitialized"
+
+	// PGBench is the annotation that is added to a PostgresCluster to initiate a pgbench
+	// benchmark. The value of the annotation will be a unique identifier for a benchmark Job
+	// (e.g. a timestamp), which will be stored in the PostgresCluster status to properly track
+	// completion of the Job. Also used to annotate the benchmark Job itself.
+	PGBench = annotationPrefix + "pgbench"
+
 	// PGBackRestBackup is the annotation that is added to a PostgresCluster to initiate a manual
 	// backup.  The value of the annotation will be a unique identifier for a backup Job (e.g. a
 	// timestamp), which will be stored in the PostgresCluster status to properly track completion
@@ -45,9 +55,46 @@ const (
 	// enabled or disabled.
 	PGBackRestCurrentConfig = annotationPrefix + "pgbackrest-config"
 
+	// SQLPolicyHash is an annotation used to specify the hash value of the SQL run by the Job of a
+	// SQL policy without a schedule. A Job with a different hash is replaced once it finishes.
+	SQLPolicyHash = annotationPrefix + "sqlpolicy-hash"
+
+	// SQLPolicyClusterSelector is an annotation on a ConfigMap that defines a SQL policy. Its value
+	// is a label selector, e.g. "environment=production", that chooses the PostgresClusters in the
+	// same namespace that run the policy.
+	SQLPolicyClusterSelector = annotationPrefix + "sqlpolicy-clusters"
+
+	// PGBackRestBackupStopped is an annotation on a backup Job that ran past its deadline. It
+	// indicates that the pgBackRest processes the Job started have already been stopped.
+	PGBackRestBackupStopped = annotationPrefix + "pgbackrest-backup-stopped"
+
 	// PGBackRestRestore is the annotation that is added to a PostgresCluster to initiate an in-place
 	// restore.  The value of the annotation will be a unique identfier for a restore Job (e.g. a
 	// timestamp), which will be stored in the PostgresCluster status to properly track completion
 	// of the Job.
 	PGBackRestRestore = annotationPrefix + "pgbackrest-restore"
+
+	// PostgresPasswordRevision is an annotation used to record the revision of the external
+	// password (e.g. a version of a Vault secret) from which the verifier in a PostgreSQL user
+	// Secret was built.
+	PostgresPasswordRevision = annotationPrefix + "password-revision"
+
+	// PostgresPasswordRead is an annotation used to record when the external password of a
+	// PostgreSQL user Secret was last read, in RFC 3339 format.
+	PostgresPasswordRead = annotationPrefix + "password-read"
+
+	// PostgresPasswordCopyNamespaces is an annotation on a PostgreSQL user Secret that lists the
+	// namespaces, separated by commas, of the PostgresClusters that may copy its password when
+	// they are cloned from its PostgresCluster.
+	PostgresPasswordCopyNamespaces = annotationPrefix + "copy-password-to-namespaces"
+
+	// ResetQueryStatistics is the annotation that is added to a PostgresCluster to reset the
+	// statistics of pg_stat_statements. The value of the annotation will be a unique identifier
+	// (e.g. a timestamp), which will be stored in the PostgresCluster status once the statistics
+	// are reset.
+	ResetQueryStatistics = annotationPrefix + "reset-query-statistics"
+
+	// NotificationEmail is an annotation on a Namespace that lists the email addresses, separated
+	// by commas, that receive notifications about the PostgresClusters in that Namespace.
+	NotificationEmail = annotationPrefix + "notification-email"
 )
@@ -25,8 +25,14 @@ import (
 func TestAnnotationsValid(t *testing.T) {
 	assert.Assert(t, nil == validation.IsQualifiedName(Finalizer))
 	assert.Assert(t, nil == validation.IsQualifiedName(PatroniSwitchover))
+	assert.Assert(t, nil == validation.IsQualifiedName(PGBench))
 	assert.Assert(t, nil == validation.IsQualifiedName(PGBackRestBackup))
+	assert.Assert(t, nil == validation.IsQualifiedName(PGBackRestBackupStopped))
 	assert.Assert(t, nil == validation.IsQualifiedName(PGBackRestConfigHash))
 	assert.Assert(t, nil == validation.IsQualifiedName(PGBackRestCurrentConfig))
 	assert.Assert(t, nil == validation.IsQualifiedName(PGBackRestRestore))
+	assert.Assert(t, nil == validation.IsQualifiedName(SQLPolicyHash))
+	assert.Assert(t, nil == validation.IsQualifiedName(SQLPolicyClusterSelector))
+	assert.Assert(t, nil == validation.IsQualifiedName(PostgresPasswordRevision))
+	assert.Assert(t, nil == validation.IsQualifiedName(NotificationEmail))
 }
@@ -77,6 +77,23 @@ func ServiceDNSNames(ctx context.Context, service *corev1.Service) []string {
 	}
 }
 
+// annotationExternalDNSHostname lists the hostnames that external-dns should
+// publish for a Service, separated by commas.
+const annotationExternalDNSHostname = "external-dns.alpha.kubernetes.io/hostname"
+
+// ExternalDNSNames returns the hostnames that external-dns publishes for a
+// Service with annotations, if any.
+// - https://github.com/kubernetes-sigs/external-dns
+func ExternalDNSNames(annotations map[string]string) []string {
+	var names []string
+	for _, name := range strings.Split(annotations[annotationExternalDNSHostname], ",") {
+		if name = strings.TrimSuffix(strings.TrimSpace(name), "."); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 // KubernetesClusterDomain looks up the Kubernetes cluster domain name.
 func KubernetesClusterDomain(ctx context.Context) string {
 	ctx, span := tracer.Start(ctx, "kubernetes-domain-lookup")
@@ -70,3 +70,12 @@ func TestServiceDNSNames(t *testing.T) {
 	assert.Assert(t, strings.HasPrefix(names[0], names[1]+"."), "wrong FQDN: %q", names[0])
 	assert.Assert(t, strings.HasSuffix(names[0], "."), "expected root, got %q", names[0])
 }
+
+func TestExternalDNSNames(t *testing.T) {
+	assert.Assert(t, ExternalDNSNames(nil) == nil)
+	assert.Assert(t, ExternalDNSNames(map[string]string{"other": "x"}) == nil)
+
+	assert.DeepEqual(t, ExternalDNSNames(map[string]string{
+		"external-dns.alpha.kubernetes.io/hostname": "db.example.com., ,replica.example.com",
+	}), []string{"db.example.com", "replica.example.com"})
+}
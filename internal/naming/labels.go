@@ -33,6 +33,10 @@ const (
 	LabelPatroni = labelPrefix + "patroni"
 	LabelRole    = labelPrefix + "role"
 
+	// LabelReplicaLagging indicates whether a replica is too far behind the
+	// primary to be included in the replica Service.
+	LabelReplicaLagging = labelPrefix + "replica-lagging"
+
 	// LabelClusterCertificate is used to identify a secret containing a cluster certificate
 	LabelClusterCertificate = labelPrefix + "cluster-certificate"
 
@@ -88,6 +92,9 @@ const (
 	// LabelPostgresUser identifies the PostgreSQL user an object is for or about.
 	LabelPostgresUser = labelPrefix + "pguser"
 
+	// LabelSQLPolicy identifies the SQL policy an object is for.
+	LabelSQLPolicy = labelPrefix + "sqlpolicy"
+
 	// LabelStartupInstance is used to indicate the startup instance associated with a resource
 	LabelStartupInstance = labelPrefix + "startup-instance"
 
@@ -105,12 +112,22 @@ const (
 	// RolePGBouncer is the LabelRole applied to PgBouncer objects.
 	RolePGBouncer = "pgbouncer"
 
+	// RolePGBench is the LabelRole applied to pgbench objects.
+	RolePGBench = "pgbench"
+
+	// RoleSQLPolicy is the LabelRole applied to SQL policy objects.
+	RoleSQLPolicy = "sqlpolicy"
+
 	// RolePGAdmin is the LabelRole applied to pgAdmin objects.
 	RolePGAdmin = "pgadmin"
 
 	// RolePostgresData is the LabelRole applied to PostgreSQL data volumes.
 	RolePostgresData = "pgdata"
 
+	// RolePostgresTemp is the LabelRole applied to volumes for the temporary
+	// files of PostgreSQL.
+	RolePostgresTemp = "pgtmp"
+
 	// RolePostgresUser is the LabelRole applied to PostgreSQL user secrets.
 	RolePostgresUser = "pguser"
 
@@ -143,6 +160,9 @@ const (
 	// BackupReplicaCreate is the backup type for the backup taken to enable pgBackRest replica
 	// creation
 	BackupReplicaCreate BackupJobType = "replica-create"
+
+	// BackupFinal is the backup type for the backup taken when a cluster is deleted
+	BackupFinal BackupJobType = "final"
 )
 
 // Merge takes sets of labels and merges them. The last set
@@ -164,6 +184,37 @@ func DirectoryMoveJobLabels(clusterName string) labels.Set {
 	return jobLabels
 }
 
+// PGBenchJobLabels provides labels for pgbench benchmark Jobs.
+func PGBenchJobLabels(clusterName string) labels.Set {
+	return map[string]string{
+		LabelCluster: clusterName,
+		LabelRole:    RolePGBench,
+	}
+}
+
+// PGBenchJobSelector provides a selector for querying pgbench benchmark Jobs.
+func PGBenchJobSelector(clusterName string) labels.Selector {
+	return PGBenchJobLabels(clusterName).AsSelector()
+}
+
+// SQLPolicyLabels provides labels for the Jobs and CronJobs of a SQL policy.
+func SQLPolicyLabels(clusterName, policyName string) labels.Set {
+	return map[string]string{
+		LabelCluster:   clusterName,
+		LabelRole:      RoleSQLPolicy,
+		LabelSQLPolicy: policyName,
+	}
+}
+
+// SQLPolicySelector provides a selector for querying the Jobs and CronJobs of
+// every SQL policy in a cluster.
+func SQLPolicySelector(clusterName string) labels.Selector {
+	return labels.Set{
+		LabelCluster: clusterName,
+		LabelRole:    RoleSQLPolicy,
+	}.AsSelector()
+}
+
 // PGBackRestLabels provides common labels for pgBackRest resources.
 func PGBackRestLabels(clusterName string) labels.Set {
 	return map[string]string{
@@ -44,6 +44,7 @@ func TestLabelsValid(t *testing.T) {
 	assert.Assert(t, nil == validation.IsQualifiedName(LabelPGBackRestRestoreConfig))
 	assert.Assert(t, nil == validation.IsQualifiedName(LabelPGMonitorDiscovery))
 	assert.Assert(t, nil == validation.IsQualifiedName(LabelPostgresUser))
+	assert.Assert(t, nil == validation.IsQualifiedName(LabelSQLPolicy))
 	assert.Assert(t, nil == validation.IsQualifiedName(LabelStartupInstance))
 }
 
@@ -55,12 +56,16 @@ func TestLabelValuesValid(t *testing.T) {
 	assert.Assert(t, nil == validation.IsValidLabelValue(RolePatroniReplica))
 	assert.Assert(t, nil == validation.IsValidLabelValue(RolePGAdmin))
 	assert.Assert(t, nil == validation.IsValidLabelValue(RolePGBouncer))
+	assert.Assert(t, nil == validation.IsValidLabelValue(RolePGBench))
 	assert.Assert(t, nil == validation.IsValidLabelValue(RolePostgresData))
+	assert.Assert(t, nil == validation.IsValidLabelValue(RolePostgresTemp))
 	assert.Assert(t, nil == validation.IsValidLabelValue(RolePostgresUser))
 	assert.Assert(t, nil == validation.IsValidLabelValue(RolePostgresWAL))
 	assert.Assert(t, nil == validation.IsValidLabelValue(RolePrimary))
 	assert.Assert(t, nil == validation.IsValidLabelValue(RoleReplica))
+	assert.Assert(t, nil == validation.IsValidLabelValue(RoleSQLPolicy))
 	assert.Assert(t, nil == validation.IsValidLabelValue(string(BackupReplicaCreate)))
+	assert.Assert(t, nil == validation.IsValidLabelValue(string(BackupFinal)))
 	assert.Assert(t, nil == validation.IsValidLabelValue(RoleMonitoring))
 }
 
@@ -40,9 +40,19 @@ const (
 	// that prepares the filesystem for pgAdmin.
 	ContainerPGAdminStartup = "pgadmin-startup"
 
+	// ContainerPGAuditLogShipper is the name of a container running Fluent Bit
+	// to ship pgAudit logs.
+	ContainerPGAuditLogShipper = "pgaudit-log-shipper"
+
 	// ContainerPGBackRestConfig is the name of a container supporting pgBackRest.
 	ContainerPGBackRestConfig = "pgbackrest-config"
 
+	// ContainerSQLPolicy is the name of a container running the SQL of a policy.
+	ContainerSQLPolicy = "sqlpolicy"
+
+	// ContainerPGBench is the name of a container running pgbench.
+	ContainerPGBench = "pgbench"
+
 	// ContainerPGBouncer is the name of a container running PgBouncer.
 	ContainerPGBouncer = "pgbouncer"
 	// ContainerPGBouncerConfig is the name of a container supporting PgBouncer.
@@ -156,6 +166,10 @@ const (
 	// PostgreSQL instance.
 	PGBackRestPGDataLogPath = "/pgdata/pgbackrest/log"
 
+	// PostgresPGDataLogPath is where PostgreSQL writes log files when they are
+	// shipped elsewhere.
+	PostgresPGDataLogPath = "/pgdata/pglogs"
+
 	// PGBackRestRepoLogPath is the pgBackRest default log path configuration used by the
 	// dedicated repo host, if configured.
 	PGBackRestRepoLogPath = "/pgbackrest/%s/log"
@@ -260,6 +274,24 @@ func ClusterReplicaService(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
 	}
 }
 
+// ClusterExporterService returns the ObjectMeta necessary to lookup the Service
+// and ServiceMonitor that expose the PostgreSQL exporters of cluster to Prometheus.
+func ClusterExporterService(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.Namespace,
+		Name:      cluster.Name + "-exporter",
+	}
+}
+
+// ClusterGrafanaDashboard returns the ObjectMeta necessary to lookup the
+// ConfigMap containing the Grafana dashboard of cluster.
+func ClusterGrafanaDashboard(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.Namespace,
+		Name:      cluster.Name + "-grafana-dashboard",
+	}
+}
+
 // GenerateInstance returns a random name for a member of cluster and set.
 func GenerateInstance(
 	cluster *v1beta1.PostgresCluster, set *v1beta1.PostgresInstanceSetSpec,
@@ -334,6 +366,15 @@ func InstancePostgresWALVolume(instance *appsv1.StatefulSet) metav1.ObjectMeta {
 	}
 }
 
+// InstancePostgresTempVolume returns the ObjectMeta for the volume of the
+// temporary files of PostgreSQL for instance.
+func InstancePostgresTempVolume(instance *appsv1.StatefulSet) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: instance.GetNamespace(),
+		Name:      instance.GetName() + "-pgtmp",
+	}
+}
+
 // MonitoringUserSecret returns ObjectMeta necessary to lookup the Secret
 // containing authentication credentials for monitoring tools.
 func MonitoringUserSecret(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
@@ -352,6 +393,24 @@ func ReplicationClientCertSecret(cluster *v1beta1.PostgresCluster) metav1.Object
 	}
 }
 
+// PostgresCertManagerCertificate returns the ObjectMeta of the cert-manager
+// Certificate, and the Secret it populates, for the PostgreSQL server.
+func PostgresCertManagerCertificate(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.Namespace,
+		Name:      cluster.Name + "-cluster-cert-manager",
+	}
+}
+
+// ReplicationCertManagerCertificate returns the ObjectMeta of the cert-manager
+// Certificate, and the Secret it populates, for the replication user.
+func ReplicationCertManagerCertificate(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.Namespace,
+		Name:      cluster.Name + "-replication-cert-manager",
+	}
+}
+
 // PatroniDistributedConfiguration returns the ObjectMeta necessary to lookup
 // the DCS created by Patroni for cluster. This same name is used for both
 // ConfigMap and Endpoints. See Patroni DCS "config_path".
@@ -397,6 +456,16 @@ func PatroniTrigger(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
 	}
 }
 
+// PGBackRestFinalBackup returns the ObjectMeta for the Job that takes the final
+// backup of cluster and for the ConfigMap that records it. The ConfigMap is not
+// owned by cluster so it remains after cluster is deleted.
+func PGBackRestFinalBackup(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      cluster.GetName() + "-final-backup",
+		Namespace: cluster.GetNamespace(),
+	}
+}
+
 // PGBackRestConfig returns the ObjectMeta for a pgBackRest ConfigMap
 func PGBackRestConfig(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
 	return metav1.ObjectMeta{
@@ -405,6 +474,24 @@ func PGBackRestConfig(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
 	}
 }
 
+// PGBenchJob returns the ObjectMeta for the pgbench benchmark Job.
+func PGBenchJob(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      cluster.GetName() + "-pgbench",
+		Namespace: cluster.GetNamespace(),
+	}
+}
+
+// SQLPolicyJob returns the ObjectMeta for the Job or CronJob of a SQL policy.
+// Kubernetes allows at most 52 characters in the name of a CronJob, so the
+// names of the cluster and policy together can have at most 41.
+func SQLPolicyJob(cluster *v1beta1.PostgresCluster, policyName string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      cluster.GetName() + "-sqlpolicy-" + policyName,
+		Namespace: cluster.GetNamespace(),
+	}
+}
+
 // PGBackRestBackupJob returns the ObjectMeta for the pgBackRest backup Job utilized
 // to create replicas using pgBackRest
 func PGBackRestBackupJob(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
@@ -47,12 +47,15 @@ func TestContainerNamesUniqueAndValid(t *testing.T) {
 		ContainerNSSWrapperInit,
 		ContainerPGAdmin,
 		ContainerPGAdminStartup,
+		ContainerPGAuditLogShipper,
 		ContainerPGBackRestConfig,
 		ContainerPGBackRestLogDirInit,
+		ContainerPGBench,
 		ContainerPGBouncer,
 		ContainerPGBouncerConfig,
 		ContainerPostgresStartup,
 		ContainerPGMonitorExporter,
+		ContainerSQLPolicy,
 	} {
 		assert.Assert(t, !names.Has(name), "%q defined already", name)
 		assert.Assert(t, nil == validation.IsDNS1123Label(name))
@@ -93,12 +96,14 @@ func TestClusterNamesUniqueAndValid(t *testing.T) {
 	t.Run("ConfigMaps", func(t *testing.T) {
 		testUniqueAndValid(t, []test{
 			{"ClusterConfigMap", ClusterConfigMap(cluster)},
+			{"ClusterGrafanaDashboard", ClusterGrafanaDashboard(cluster)},
 			{"ClusterPGAdmin", ClusterPGAdmin(cluster)},
 			{"ClusterPGBouncer", ClusterPGBouncer(cluster)},
 			{"PatroniDistributedConfiguration", PatroniDistributedConfiguration(cluster)},
 			{"PatroniLeaderConfigMap", PatroniLeaderConfigMap(cluster)},
 			{"PatroniTrigger", PatroniTrigger(cluster)},
 			{"PGBackRestConfig", PGBackRestConfig(cluster)},
+			{"PGBackRestFinalBackup", PGBackRestFinalBackup(cluster)},
 			{"PGBackRestSSHConfig", PGBackRestSSHConfig(cluster)},
 		})
 	})
@@ -109,6 +114,7 @@ func TestClusterNamesUniqueAndValid(t *testing.T) {
 			{"PGBackRestCronJon", PGBackRestCronJob(cluster, "incr", "repo2")},
 			{"PGBackRestCronJon", PGBackRestCronJob(cluster, "diff", "repo3")},
 			{"PGBackRestCronJon", PGBackRestCronJob(cluster, "full", "repo4")},
+			{"SQLPolicyCronJob", SQLPolicyJob(cluster, "nightly")},
 		})
 	})
 
@@ -121,7 +127,10 @@ func TestClusterNamesUniqueAndValid(t *testing.T) {
 	t.Run("Jobs", func(t *testing.T) {
 		testUniqueAndValid(t, []test{
 			{"PGBackRestBackupJob", PGBackRestBackupJob(cluster)},
+			{"PGBackRestFinalBackup", PGBackRestFinalBackup(cluster)},
 			{"PGBackRestRestoreJob", PGBackRestRestoreJob(cluster)},
+			{"PGBenchJob", PGBenchJob(cluster)},
+			{"SQLPolicyJob", SQLPolicyJob(cluster, "nightly")},
 		})
 	})
 
@@ -152,6 +161,8 @@ func TestClusterNamesUniqueAndValid(t *testing.T) {
 			{"DeprecatedPostgresUserSecret", DeprecatedPostgresUserSecret(cluster)},
 			{"PostgresTLSSecret", PostgresTLSSecret(cluster)},
 			{"ReplicationClientCertSecret", ReplicationClientCertSecret(cluster)},
+			{"PostgresCertManagerCertificate", PostgresCertManagerCertificate(cluster)},
+			{"ReplicationCertManagerCertificate", ReplicationCertManagerCertificate(cluster)},
 			{"PGBackRestSSHSecret", PGBackRestSSHSecret(cluster)},
 			{"MonitoringUserSecret", MonitoringUserSecret(cluster)},
 		})
@@ -182,6 +193,7 @@ func TestClusterNamesUniqueAndValid(t *testing.T) {
 			{"ClusterPGAdmin", ClusterPGAdmin(cluster)},
 			{"ClusterPodService", ClusterPodService(cluster)},
 			{"ClusterPrimaryService", ClusterPrimaryService(cluster)},
+			{"ClusterExporterService", ClusterExporterService(cluster)},
 			{"ClusterReplicaService", ClusterReplicaService(cluster)},
 			// Patroni can use Endpoints which relate directly to a Service.
 			{"PatroniDistributedConfiguration", PatroniDistributedConfiguration(cluster)},
@@ -236,6 +248,7 @@ func TestInstanceNamesUniqueAndValid(t *testing.T) {
 		for _, tt := range []test{
 			{"InstancePostgresDataVolume", InstancePostgresDataVolume(instance)},
 			{"InstancePostgresWALVolume", InstancePostgresWALVolume(instance)},
+			{"InstancePostgresTempVolume", InstancePostgresTempVolume(instance)},
 		} {
 			t.Run(tt.name, func(t *testing.T) {
 				assert.Equal(t, tt.value.Namespace, instance.Namespace)
@@ -0,0 +1,177 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package notify
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Mailer sends notifications by email through an SMTP server.
+type Mailer struct {
+	// Address is the "host:port" of the SMTP server.
+	Address string
+	Auth    smtp.Auth
+	From    *mail.Address
+
+	// To are the recipients of notifications about clusters in namespaces
+	// that do not list their own.
+	To []*mail.Address
+
+	// Timeout limits how long it takes to connect to the server and how long
+	// it takes to send each email.
+	Timeout time.Duration
+
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewMailer returns a Mailer for the SMTP server at address. It logs in with
+// username and password when username is not empty. The from and to addresses
+// are parsed as described by RFC 5322, and to may be empty.
+func NewMailer(address, from, to, username, password string) (*Mailer, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("notify: SMTP address: %w", err)
+	}
+
+	m := &Mailer{Address: address, Timeout: 30 * time.Second}
+	if m.From, err = mail.ParseAddress(from); err != nil {
+		return nil, fmt.Errorf("notify: from address: %w", err)
+	}
+	if m.To, err = ParseAddressList(to); err != nil {
+		return nil, fmt.Errorf("notify: to addresses: %w", err)
+	}
+	if username != "" {
+		m.Auth = smtp.PlainAuth("", username, password, host)
+	}
+	return m, nil
+}
+
+// ParseAddressList parses a comma-separated list of email addresses. It
+// returns nothing when list is blank.
+func ParseAddressList(list string) ([]*mail.Address, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+	return mail.ParseAddressList(list)
+}
+
+// message returns an email about n addressed to recipients.
+func (m *Mailer) message(n Notification, recipients []*mail.Address) []byte {
+	to := make([]string, len(recipients))
+	for i := range recipients {
+		to[i] = recipients[i].String()
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", m.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: %s: PostgresCluster %s/%s\r\n", n.Reason, n.Namespace, n.Cluster)
+	fmt.Fprintf(&b, "Date: %s\r\n", n.Time.Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "Content-Type: text/plain; charset=utf-8\r\n")
+	fmt.Fprintf(&b, "\r\n")
+	fmt.Fprintf(&b, "%s\r\n\r\n", strings.ReplaceAll(n.Message, "\n", "\r\n"))
+	fmt.Fprintf(&b, "Cluster: %s\r\nNamespace: %s\r\nType: %s\r\nReason: %s\r\nTime: %s\r\n",
+		n.Cluster, n.Namespace, n.Type, n.Reason, n.Time.Format(time.RFC3339))
+
+	return b.Bytes()
+}
+
+// Send emails n to recipients.
+func (m *Mailer) Send(n Notification, recipients []*mail.Address) error {
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	to := make([]string, len(recipients))
+	for i := range recipients {
+		to[i] = recipients[i].Address
+	}
+
+	send := m.send
+	if send == nil {
+		send = m.sendMail
+	}
+	return send(m.Address, m.Auth, m.From.Address, to, m.message(n, recipients))
+}
+
+// sendMail is like smtp.SendMail, but it gives up when the server does not
+// answer within Timeout.
+func (m *Mailer) sendMail(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+
+	dialer := net.Dialer{Timeout: m.Timeout}
+	conn, err := dialer.Dial("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(m.Timeout)); err != nil {
+		return err
+	}
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if a != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("notify: SMTP server does not support AUTH")
+		}
+		if err := c.Auth(a); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+	for _, recipient := range to {
+		if err := c.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+
+	w, err := c.Data()
+	if err == nil {
+		_, err = w.Write(msg)
+	}
+	if err == nil {
+		err = w.Close()
+	}
+	if err == nil {
+		err = c.Quit()
+	}
+	return err
+}
@@ -0,0 +1,172 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package notify
+
+import (
+	"bufio"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestNewMailer(t *testing.T) {
+	m, err := NewMailer("smtp.example.com:587",
+		"PGO <pgo@example.com>", "dba@example.com, Ops <ops@example.com>", "user", "pass")
+	assert.NilError(t, err)
+	assert.Equal(t, m.Address, "smtp.example.com:587")
+	assert.Equal(t, m.From.Address, "pgo@example.com")
+	assert.Equal(t, len(m.To), 2)
+	assert.Equal(t, m.To[1].Name, "Ops")
+	assert.Assert(t, m.Auth != nil)
+
+	m, err = NewMailer("localhost:25", "pgo@example.com", " ", "", "")
+	assert.NilError(t, err)
+	assert.Assert(t, m.To == nil)
+	assert.Assert(t, m.Auth == nil)
+
+	_, err = NewMailer("localhost", "pgo@example.com", "", "", "")
+	assert.ErrorContains(t, err, "SMTP address")
+
+	_, err = NewMailer("localhost:25", "not an address", "", "", "")
+	assert.ErrorContains(t, err, "from address")
+
+	_, err = NewMailer("localhost:25", "pgo@example.com", "dba@", "", "")
+	assert.ErrorContains(t, err, "to addresses")
+}
+
+func TestMailerSend(t *testing.T) {
+	m, err := NewMailer("localhost:25", "pgo@example.com", "", "", "")
+	assert.NilError(t, err)
+
+	var calls []string
+	m.send = func(addr string, _ smtp.Auth, from string, to []string, msg []byte) error {
+		calls = append(calls, addr, from, strings.Join(to, ","), string(msg))
+		return nil
+	}
+
+	n := Notification{
+		Cluster: "hippo", Namespace: "ns1",
+		Type: "Normal", Reason: "PrimaryChanged", Message: `Primary changed from instance "a" to "b"`,
+		Time: time.Date(2022, time.March, 4, 5, 6, 7, 0, time.UTC),
+	}
+
+	assert.NilError(t, m.Send(n, nil))
+	assert.Equal(t, len(calls), 0, "expected no email without recipients")
+
+	assert.NilError(t, m.Send(n, []*mail.Address{
+		{Address: "dba@example.com"}, {Name: "Ops", Address: "ops@example.com"},
+	}))
+	assert.DeepEqual(t, calls[:3], []string{
+		"localhost:25", "pgo@example.com", "dba@example.com,ops@example.com",
+	})
+	assert.Equal(t, calls[3], strings.Join([]string{
+		`From: <pgo@example.com>`,
+		`To: <dba@example.com>, "Ops" <ops@example.com>`,
+		`Subject: PrimaryChanged: PostgresCluster ns1/hippo`,
+		`Date: Fri, 04 Mar 2022 05:06:07 +0000`,
+		`Content-Type: text/plain; charset=utf-8`,
+		``,
+		`Primary changed from instance "a" to "b"`,
+		``,
+		`Cluster: hippo`,
+		`Namespace: ns1`,
+		`Type: Normal`,
+		`Reason: PrimaryChanged`,
+		`Time: 2022-03-04T05:06:07Z`,
+		``,
+	}, "\r\n"))
+}
+
+func TestMailerSendMail(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	m, err := NewMailer(listener.Addr().String(), "pgo@example.com", "", "", "")
+	assert.NilError(t, err)
+	m.Timeout = 200 * time.Millisecond
+
+	t.Run("Delivered", func(t *testing.T) {
+		received := make(chan []string, 1)
+		go func() {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+
+			var lines []string
+			reader := bufio.NewReader(conn)
+			reply := func(s string) { _, _ = conn.Write([]byte(s + "\r\n")) }
+			reply("220 localhost")
+			for data := false; ; {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					break
+				}
+				line = strings.TrimRight(line, "\r\n")
+				lines = append(lines, line)
+
+				switch {
+				case data && line == ".":
+					data = false
+					reply("250 queued")
+				case data:
+				case strings.HasPrefix(line, "EHLO"):
+					reply("250 localhost")
+				case line == "DATA":
+					data = true
+					reply("354 go ahead")
+				case line == "QUIT":
+					reply("221 bye")
+				default:
+					reply("250 ok")
+				}
+			}
+			received <- lines
+		}()
+
+		assert.NilError(t, m.sendMail(m.Address, nil, "pgo@example.com",
+			[]string{"dba@example.com"}, []byte("Subject: test\r\n\r\nbody\r\n")))
+
+		lines := <-received
+		assert.Assert(t, len(lines) > 3)
+		assert.Equal(t, lines[1], "MAIL FROM:<pgo@example.com>")
+		assert.Equal(t, lines[2], "RCPT TO:<dba@example.com>")
+		assert.Equal(t, lines[len(lines)-1], "QUIT")
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		// Accept the connection but never answer.
+		go func() {
+			if conn, err := listener.Accept(); err == nil {
+				time.Sleep(time.Second)
+				conn.Close()
+			}
+		}()
+
+		start := time.Now()
+		err := m.sendMail(m.Address, nil, "pgo@example.com",
+			[]string{"dba@example.com"}, []byte("body"))
+		assert.ErrorContains(t, err, "timeout")
+		assert.Assert(t, time.Since(start) < time.Second)
+	})
+}
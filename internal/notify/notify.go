@@ -0,0 +1,130 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+const (
+	// FormatGeneric posts a Notification as a JSON object.
+	FormatGeneric = "generic"
+
+	// FormatSlack posts a message for a Slack incoming webhook.
+	// - https://api.slack.com/messaging/webhooks
+	FormatSlack = "slack"
+)
+
+// HTTPClient sends requests to webhooks.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// NewHTTPClient returns an HTTPClient that refuses to connect to loopback,
+// link-local, and unspecified addresses. Anyone who can edit a PostgresCluster
+// can choose the URL of its webhook, and those addresses reach PGO itself and
+// the metadata services of cloud providers.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: refuseLocal}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// refuseLocal is a net.Dialer.Control function that returns an error when
+// address is loopback, link-local, or unspecified. It runs after names are
+// resolved, so it also applies to names that resolve to those addresses.
+func refuseLocal(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() {
+		return fmt.Errorf("notify: refusing to connect to %s", host)
+	}
+	return nil
+}
+
+// Notification describes a lifecycle event of a PostgresCluster.
+type Notification struct {
+	Cluster   string    `json:"cluster"`
+	Namespace string    `json:"namespace"`
+	Type      string    `json:"type"`
+	Reason    string    `json:"reason"`
+	Message   string    `json:"message"`
+	Time      time.Time `json:"time"`
+}
+
+// Webhook is an HTTP endpoint that receives notifications in Format.
+type Webhook struct {
+	URL    string
+	Format string
+}
+
+// payload returns the body to send to w for n.
+func (w Webhook) payload(n Notification) ([]byte, error) {
+	switch w.Format {
+	case "", FormatGeneric:
+		return json.Marshal(n)
+	case FormatSlack:
+		return json.Marshal(map[string]string{
+			"text": fmt.Sprintf("*%s* PostgresCluster %s/%s: %s",
+				n.Reason, n.Namespace, n.Cluster, n.Message),
+		})
+	}
+	return nil, fmt.Errorf("notify: unknown format %q", w.Format)
+}
+
+// Send posts n to w and returns an error when w does not accept it.
+func (w Webhook) Send(ctx context.Context, client HTTPClient, n Notification) error {
+	body, err := w.payload(n)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	// The URL of a webhook is often its credential; leave it out of errors.
+	response, err := client.Do(request)
+	if e, ok := err.(*url.Error); ok {
+		return fmt.Errorf("notify: POST: %w", e.Err)
+	}
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("notify: POST: %s", response.Status)
+	}
+	return nil
+}
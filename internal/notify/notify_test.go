@@ -0,0 +1,106 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestWebhookSend(t *testing.T) {
+	ctx := context.Background()
+	n := Notification{
+		Cluster: "hippo", Namespace: "ns1",
+		Type: "Warning", Reason: "BackupFailed", Message: "pgBackRest manual backup failed",
+		Time: time.Date(2022, time.March, 4, 5, 6, 7, 0, time.UTC),
+	}
+
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.Method, http.MethodPost)
+		assert.Equal(t, r.Header.Get("Content-Type"), "application/json")
+
+		received = nil
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(&received))
+
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	t.Run("Generic", func(t *testing.T) {
+		for _, format := range []string{"", FormatGeneric} {
+			assert.NilError(t, Webhook{URL: server.URL, Format: format}.Send(ctx, server.Client(), n))
+			assert.DeepEqual(t, received, map[string]interface{}{
+				"cluster":   "hippo",
+				"namespace": "ns1",
+				"type":      "Warning",
+				"reason":    "BackupFailed",
+				"message":   "pgBackRest manual backup failed",
+				"time":      "2022-03-04T05:06:07Z",
+			})
+		}
+	})
+
+	t.Run("Slack", func(t *testing.T) {
+		assert.NilError(t, Webhook{URL: server.URL, Format: FormatSlack}.Send(ctx, server.Client(), n))
+		assert.DeepEqual(t, received, map[string]interface{}{
+			"text": "*BackupFailed* PostgresCluster ns1/hippo: pgBackRest manual backup failed",
+		})
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		err := Webhook{URL: server.URL, Format: "other"}.Send(ctx, server.Client(), n)
+		assert.ErrorContains(t, err, `unknown format "other"`)
+
+		err = Webhook{URL: server.URL + "/missing"}.Send(ctx, server.Client(), n)
+		assert.ErrorContains(t, err, "404 Not Found")
+
+		// The URL is left out of errors.
+		err = Webhook{URL: "http://127.0.0.1:1/token"}.Send(ctx, http.DefaultClient, n)
+		assert.Assert(t, err != nil)
+		assert.Assert(t, !strings.Contains(err.Error(), "token"), "got %q", err)
+	})
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	t.Cleanup(server.Close)
+
+	// The test server listens on loopback.
+	err := Webhook{URL: server.URL}.Send(context.Background(), NewHTTPClient(time.Second), Notification{})
+	assert.ErrorContains(t, err, "refusing to connect to 127.0.0.1")
+
+	for _, address := range []string{
+		"127.0.0.1:80", "[::1]:80", "0.0.0.0:80",
+		"169.254.169.254:80", "[fe80::1]:80",
+	} {
+		assert.ErrorContains(t, refuseLocal("tcp", address, nil), "refusing", "address: %q", address)
+	}
+	for _, address := range []string{
+		"10.0.0.1:443", "192.0.2.10:443", "[2001:db8::1]:443",
+	} {
+		assert.NilError(t, refuseLocal("tcp", address, nil), "address: %q", address)
+	}
+}
@@ -0,0 +1,225 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// Reasons are the reasons of PostgresCluster events that become notifications.
+var Reasons = sets.NewString(
+	"BackupSucceeded", "BackupFailed", "FinalBackupFailed",
+	"PrimaryChanged",
+	"DiskUsageHigh", "VolumeLimitReached", "ReadOnly",
+	"CertExpiring",
+)
+
+// MailReasons are the reasons of PostgresCluster events that are also sent by
+// email.
+var MailReasons = sets.NewString(
+	"BackupFailed", "FinalBackupFailed",
+	"PrimaryChanged",
+)
+
+// Recorder is a record.EventRecorder that also sends notifications about the
+// events of PostgresClusters that have one of Reasons. It sends them in the
+// background to every Global webhook and to the webhook in the spec of the
+// cluster, if any. When there is a Mail server, events that have one of
+// MailReasons are also emailed to the recipients of the namespace of the
+// cluster. An event with the same reason is sent at most once per Interval
+// for each cluster; some events are recorded every time a cluster is
+// reconciled, often with a different message.
+type Recorder struct {
+	record.EventRecorder
+
+	Client   client.Reader
+	HTTP     HTTPClient
+	Global   []Webhook
+	Mail     *Mailer
+	Interval time.Duration
+
+	// Namespaces reads the email recipients annotated on namespaces. When
+	// nil, every email goes to the recipients of Mail. Leave it nil when PGO
+	// cannot read namespaces, such as when it is installed for one namespace;
+	// otherwise, reading them waits until the notification times out.
+	Namespaces client.Reader
+
+	mutex sync.Mutex
+	sent  map[string]time.Time
+	group sync.WaitGroup
+}
+
+// NewRecorder returns a Recorder that records events to recorder and reads
+// the webhook of each cluster using reader.
+func NewRecorder(recorder record.EventRecorder, reader client.Reader, global ...Webhook) *Recorder {
+	return &Recorder{
+		EventRecorder: recorder,
+		Client:        reader,
+		HTTP:          NewHTTPClient(10 * time.Second),
+		Global:        global,
+		Interval:      10 * time.Minute,
+	}
+}
+
+// Event implements record.EventRecorder.
+func (r *Recorder) Event(object runtime.Object, eventtype, reason, message string) {
+	r.EventRecorder.Event(object, eventtype, reason, message)
+	r.notify(object, eventtype, reason, message)
+}
+
+// Eventf implements record.EventRecorder.
+func (r *Recorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// AnnotatedEventf implements record.EventRecorder.
+func (r *Recorder) AnnotatedEventf(object runtime.Object, annotations map[string]string,
+	eventtype, reason, messageFmt string, args ...interface{},
+) {
+	message := fmt.Sprintf(messageFmt, args...)
+	r.EventRecorder.AnnotatedEventf(object, annotations, eventtype, reason, "%s", message)
+	r.notify(object, eventtype, reason, message)
+}
+
+// first returns whether key has not been sent during the last Interval. It
+// forgets keys that are older than that.
+func (r *Recorder) first(key string, now time.Time) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.sent == nil {
+		r.sent = make(map[string]time.Time)
+	}
+	for k, sent := range r.sent {
+		if now.Sub(sent) >= r.Interval {
+			delete(r.sent, k)
+		}
+	}
+	if _, ok := r.sent[key]; ok {
+		return false
+	}
+	r.sent[key] = now
+	return true
+}
+
+// notify sends a notification about an event of a PostgresCluster in the
+// background.
+func (r *Recorder) notify(object runtime.Object, eventtype, reason, message string) {
+	cluster, ok := object.(*v1beta1.PostgresCluster)
+	if !ok || !Reasons.Has(reason) {
+		return
+	}
+
+	webhooks := r.Global
+	spec := cluster.Spec.Notifications.DeepCopy()
+	email := r.Mail != nil && MailReasons.Has(reason)
+	if len(webhooks) == 0 && spec == nil && !email {
+		return
+	}
+
+	n := Notification{
+		Cluster:   cluster.Name,
+		Namespace: cluster.Namespace,
+		Type:      eventtype,
+		Reason:    reason,
+		Message:   message,
+		Time:      time.Now().UTC().Truncate(time.Second),
+	}
+	key := string(cluster.UID) + "\x00" + reason
+	if !r.first(key, n.Time) {
+		return
+	}
+
+	r.group.Add(1)
+	go func() {
+		defer r.group.Done()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		log := logging.FromContext(ctx).WithValues(
+			"namespace", n.Namespace, "cluster", n.Cluster, "reason", n.Reason)
+
+		if spec != nil {
+			secret := &corev1.Secret{}
+			err := r.Client.Get(ctx,
+				client.ObjectKey{Namespace: n.Namespace, Name: spec.URL.Name}, secret)
+			url := string(secret.Data[spec.URL.Key])
+			if err == nil && url == "" {
+				err = fmt.Errorf("notify: secret %q has no key %q", spec.URL.Name, spec.URL.Key)
+			}
+			if err == nil {
+				webhooks = append(webhooks[:len(webhooks):len(webhooks)],
+					Webhook{URL: url, Format: spec.Format})
+			} else {
+				log.Error(err, "unable to read the notification webhook of the cluster")
+			}
+		}
+
+		for _, webhook := range webhooks {
+			if err := webhook.Send(ctx, r.HTTP, n); err != nil {
+				log.Error(err, "unable to send notification")
+			}
+		}
+
+		if email {
+			if err := r.Mail.Send(n, r.recipients(ctx, n.Namespace)); err != nil {
+				log.Error(err, "unable to email notification")
+			}
+		}
+	}()
+}
+
+// recipients returns the email addresses listed on namespace, or those of
+// Mail when it lists none or Namespaces is nil.
+func (r *Recorder) recipients(ctx context.Context, namespace string) []*mail.Address {
+	log := logging.FromContext(ctx)
+
+	if r.Namespaces == nil {
+		return r.Mail.To
+	}
+
+	object := &corev1.Namespace{}
+	err := r.Namespaces.Get(ctx, client.ObjectKey{Name: namespace}, object)
+	if err != nil {
+		log.V(1).Info("unable to read namespace for email notification", "error", err.Error())
+	}
+
+	if list, ok := object.Annotations[naming.NotificationEmail]; ok {
+		addresses, err := ParseAddressList(list)
+		if err == nil {
+			return addresses
+		}
+		log.Error(err, "unable to parse email addresses", "namespace", namespace)
+	}
+	return r.Mail.To
+}
+
+// Wait blocks until every notification has been sent.
+func (r *Recorder) Wait() { r.group.Wait() }
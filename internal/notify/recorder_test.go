@@ -0,0 +1,172 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestRecorder(t *testing.T) {
+	var mutex sync.Mutex
+	received := map[string][]Notification{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n Notification
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(&n))
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		received[r.URL.Path] = append(received[r.URL.Path], n)
+	}))
+	t.Cleanup(server.Close)
+
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "hooks"},
+		Data:       map[string][]byte{"url": []byte(server.URL + "/cluster")},
+	}, &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "ns2", Annotations: map[string]string{
+			naming.NotificationEmail: "dba@example.com, ops@example.com",
+		}},
+	}).Build()
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace, cluster.Name, cluster.UID = "ns1", "hippo", "uid1"
+
+	events := record.NewFakeRecorder(10)
+	recorder := NewRecorder(events, reader, Webhook{URL: server.URL + "/global"})
+	recorder.HTTP = server.Client()
+
+	t.Run("Global", func(t *testing.T) {
+		recorder.Eventf(cluster, corev1.EventTypeNormal, "BackupSucceeded",
+			"pgBackRest %s backup completed successfully", "manual")
+		recorder.Event(cluster, corev1.EventTypeNormal, "Bootstrapped", "ignored reason")
+		recorder.Event(&corev1.Pod{}, corev1.EventTypeNormal, "BackupSucceeded", "ignored object")
+		recorder.Wait()
+
+		// Every event is recorded.
+		assert.Equal(t, len(events.Events), 3)
+		<-events.Events
+		<-events.Events
+		<-events.Events
+
+		assert.Equal(t, len(received["/global"]), 1)
+		assert.Equal(t, received["/global"][0].Cluster, "hippo")
+		assert.Equal(t, received["/global"][0].Reason, "BackupSucceeded")
+		assert.Equal(t, received["/global"][0].Message,
+			"pgBackRest manual backup completed successfully")
+		assert.Equal(t, len(received["/cluster"]), 0)
+	})
+
+	t.Run("Cluster", func(t *testing.T) {
+		cluster.Spec.Notifications = &v1beta1.NotificationsSpec{}
+		cluster.Spec.Notifications.URL.Name = "hooks"
+		cluster.Spec.Notifications.URL.Key = "url"
+
+		recorder.Event(cluster, corev1.EventTypeWarning, "DiskUsageHigh", "90% full")
+		recorder.Wait()
+		<-events.Events
+
+		assert.Equal(t, len(received["/global"]), 2)
+		assert.Equal(t, len(received["/cluster"]), 1)
+		assert.Equal(t, received["/cluster"][0].Type, "Warning")
+		assert.Equal(t, received["/cluster"][0].Message, "90% full")
+	})
+
+	t.Run("Interval", func(t *testing.T) {
+		// The same reason is not sent again during the interval, even when
+		// the message changes.
+		recorder.Event(cluster, corev1.EventTypeWarning, "DiskUsageHigh", "90% full")
+		recorder.Event(cluster, corev1.EventTypeWarning, "DiskUsageHigh", "91% full")
+		recorder.Wait()
+		<-events.Events
+		<-events.Events
+
+		assert.Equal(t, len(received["/cluster"]), 1)
+
+		recorder.Event(cluster, corev1.EventTypeWarning, "ReadOnly", "91% full")
+		recorder.Wait()
+		<-events.Events
+
+		assert.Equal(t, len(received["/cluster"]), 2)
+		assert.Equal(t, received["/cluster"][1].Reason, "ReadOnly")
+
+		assert.Assert(t, recorder.first("key", time.Unix(0, 0)))
+		assert.Assert(t, !recorder.first("key", time.Unix(0, 0).Add(time.Minute)))
+		assert.Assert(t, recorder.first("key", time.Unix(0, 0).Add(recorder.Interval)))
+	})
+
+	t.Run("Mail", func(t *testing.T) {
+		mailer, err := NewMailer("localhost:25", "pgo@example.com", "default@example.com", "", "")
+		assert.NilError(t, err)
+
+		var sent []string
+		mailer.send = func(_ string, _ smtp.Auth, _ string, to []string, _ []byte) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			sent = append(sent, strings.Join(to, ","))
+			return nil
+		}
+		recorder.Mail = mailer
+		recorder.Global = nil
+		recorder.Namespaces = reader
+		t.Cleanup(func() { recorder.Mail, recorder.Namespaces = nil, nil })
+
+		other := cluster.DeepCopy()
+		other.Namespace, other.UID = "ns2", "uid2"
+		other.Spec.Notifications = nil
+
+		// Only some reasons are emailed.
+		recorder.Event(cluster, corev1.EventTypeWarning, "DiskUsageHigh", "92% full")
+		recorder.Event(cluster, corev1.EventTypeWarning, "BackupFailed", "pgBackRest manual backup failed")
+		recorder.Event(other, corev1.EventTypeWarning, "BackupFailed", "pgBackRest manual backup failed")
+		recorder.Wait()
+
+		sort.Strings(sent)
+		assert.DeepEqual(t, sent, []string{
+			"dba@example.com,ops@example.com", // from the namespace
+			"default@example.com",
+		})
+
+		// Without Namespaces, emails go to the recipients of Mail.
+		sent = nil
+		recorder.Namespaces = nil
+		other.UID = "uid3"
+		recorder.Event(other, corev1.EventTypeWarning, "BackupFailed", "pgBackRest manual backup failed")
+		recorder.Wait()
+
+		assert.DeepEqual(t, sent, []string{"default@example.com"})
+	})
+}
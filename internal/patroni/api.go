@@ -133,6 +133,59 @@ func (exec Executor) FailoverAndWait(
 	return strings.Contains(stdout.String(), "failed over"), err
 }
 
+// Member is a Patroni member as reported by "patronictl list".
+type Member struct {
+	Name  string
+	Role  string
+	State string
+
+	// LagMB is how far this member is behind the leader in mebibytes. It is
+	// nil when Patroni does not know, e.g. for the leader or a stopped member.
+	LagMB *int64
+}
+
+// ListMembers returns the members of scope by calling "patronictl".
+// Similar to the "GET /cluster" REST endpoint.
+func (exec Executor) ListMembers(ctx context.Context, scope string) ([]Member, error) {
+	var stdout, stderr bytes.Buffer
+
+	err := exec(ctx, nil, &stdout, &stderr,
+		"patronictl", "list", "--format=json", scope)
+
+	log := logging.FromContext(ctx)
+	log.V(1).Info("listed members",
+		"stdout", stdout.String(),
+		"stderr", stderr.String(),
+	)
+
+	// Patroni prints "Lag in MB" as a number when it is known and as a string,
+	// such as "unknown", otherwise.
+	var listed []struct {
+		Member string
+		Role   string
+		State  string
+		Lag    interface{} `json:"Lag in MB"`
+	}
+	if err == nil {
+		decoder := json.NewDecoder(&stdout)
+		decoder.UseNumber()
+		err = decoder.Decode(&listed)
+	}
+
+	var members []Member
+	for _, m := range listed {
+		member := Member{Name: m.Member, Role: m.Role, State: m.State}
+		if n, ok := m.Lag.(json.Number); ok {
+			if lag, err := n.Int64(); err == nil {
+				member.LagMB = &lag
+			}
+		}
+		members = append(members, member)
+	}
+
+	return members, err
+}
+
 // ReplaceConfiguration replaces Patroni's entire dynamic configuration by
 // calling "patronictl". Similar to the "POST /switchover" REST endpoint.
 func (exec Executor) ReplaceConfiguration(
@@ -177,3 +230,21 @@ func (exec Executor) RestartPendingMembers(ctx context.Context, role, scope stri
 
 	return err
 }
+
+// ReinitializeMember replaces the data directory of member in scope with a
+// fresh copy from the leader or a backup by calling "patronictl". Similar to
+// the "POST /reinitialize" REST endpoint of that member.
+func (exec Executor) ReinitializeMember(ctx context.Context, scope, member string) error {
+	var stdout, stderr bytes.Buffer
+
+	err := exec(ctx, nil, &stdout, &stderr,
+		"patronictl", "reinit", "--force", scope, member)
+
+	log := logging.FromContext(ctx)
+	log.V(1).Info("reinitialized member",
+		"stdout", stdout.String(),
+		"stderr", stderr.String(),
+	)
+
+	return err
+}
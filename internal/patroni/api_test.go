@@ -195,6 +195,67 @@ func TestExecutorFailoverAndWait(t *testing.T) {
 	})
 }
 
+func TestExecutorListMembers(t *testing.T) {
+	t.Run("Arguments", func(t *testing.T) {
+		called := false
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			called = true
+			assert.DeepEqual(t, command, strings.Fields(
+				`patronictl list --format=json some-scope`,
+			))
+			assert.Assert(t, stdin == nil, "expected no stdin, got %T", stdin)
+			assert.Assert(t, stderr != nil, "should capture stderr")
+			assert.Assert(t, stdout != nil, "should capture stdout")
+			_, _ = stdout.Write([]byte(`[]`))
+			return nil
+		}
+
+		_, err := Executor(exec).ListMembers(context.Background(), "some-scope")
+		assert.NilError(t, err)
+		assert.Assert(t, called)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		expected := errors.New("bang")
+		_, actual := Executor(func(
+			context.Context, io.Reader, io.Writer, io.Writer, ...string,
+		) error {
+			return expected
+		}).ListMembers(context.Background(), "")
+
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("Result", func(t *testing.T) {
+		members, err := Executor(func(
+			_ context.Context, _ io.Reader, stdout, _ io.Writer, _ ...string,
+		) error {
+			_, _ = stdout.Write([]byte(`[
+				{"Cluster": "hippo-ha", "Member": "one", "Role": "Leader", "State": "running", "TL": 2},
+				{"Cluster": "hippo-ha", "Member": "two", "Role": "Replica", "State": "running", "TL": 2, "Lag in MB": 12},
+				{"Cluster": "hippo-ha", "Member": "three", "Role": "Replica", "State": "stopped", "Lag in MB": "unknown"}
+			]`))
+			return nil
+		}).ListMembers(context.Background(), "")
+		assert.NilError(t, err)
+
+		assert.Equal(t, len(members), 3)
+		assert.Equal(t, members[0].Name, "one")
+		assert.Equal(t, members[0].Role, "Leader")
+		assert.Assert(t, members[0].LagMB == nil)
+
+		assert.Equal(t, members[1].Name, "two")
+		assert.Equal(t, members[1].State, "running")
+		assert.Assert(t, members[1].LagMB != nil)
+		assert.Equal(t, *members[1].LagMB, int64(12))
+
+		assert.Equal(t, members[2].State, "stopped")
+		assert.Assert(t, members[2].LagMB == nil)
+	})
+}
+
 func TestExecutorReplaceConfiguration(t *testing.T) {
 	expected := errors.New("bang")
 	exec := func(
@@ -236,3 +297,23 @@ func TestExecutorRestartPendingMembers(t *testing.T) {
 
 	assert.Equal(t, expected, actual, "should call exec")
 }
+
+func TestExecutorReinitializeMember(t *testing.T) {
+	expected := errors.New("oop")
+	exec := func(
+		_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error {
+		assert.DeepEqual(t, command, strings.Fields(
+			`patronictl reinit --force shoe-scope sock-member`,
+		))
+		assert.Assert(t, stdin == nil, "expected no stdin, got %T", stdin)
+		assert.Assert(t, stderr != nil, "should capture stderr")
+		assert.Assert(t, stdout != nil, "should capture stdout")
+		return expected
+	}
+
+	actual := Executor(exec).ReinitializeMember(
+		context.Background(), "shoe-scope", "sock-member")
+
+	assert.Equal(t, expected, actual, "should call exec")
+}
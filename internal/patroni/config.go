@@ -546,6 +546,14 @@ func instanceYAML(
 	// method? This is a list and cannot be merged.
 	postgresql["create_replica_methods"] = methods
 
+	// When a replica cannot follow the primary, e.g. because its timeline
+	// diverged and pg_rewind failed, recreate its data directory using the
+	// methods above rather than leaving it stopped.
+	if enabled, _ := ReplicaRemediation(cluster); enabled {
+		postgresql["remove_data_directory_on_diverged_timelines"] = true
+		postgresql["remove_data_directory_on_rewind_failure"] = true
+	}
+
 	if !ClusterBootstrapped(cluster) {
 		isRestore := (cluster.Status.PGBackRest != nil && cluster.Status.PGBackRest.Restore != nil)
 		isDataSource := (cluster.Spec.DataSource != nil && cluster.Spec.DataSource.Volumes != nil &&
@@ -25,8 +25,10 @@ import (
 	"gotest.tools/v3/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/yaml"
 
+	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/postgres"
 	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
 	"github.com/crunchydata/postgres-operator/internal/testing/require"
@@ -552,6 +554,40 @@ func TestDynamicConfiguration(t *testing.T) {
 	}
 }
 
+func TestDynamicConfigurationSpecParameters(t *testing.T) {
+	t.Parallel()
+
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Default()
+	cluster.Spec.Config.Parameters = map[string]intstr.IntOrString{
+		"max_connections": intstr.FromInt(200),
+		"work_mem":        intstr.FromString("64MB"),
+		"wal_level":       intstr.FromString("replica"),
+	}
+
+	parameters := postgres.NewParameters()
+	postgres.SpecParameters(cluster, &parameters)
+
+	actual := DynamicConfiguration(cluster, map[string]interface{}{
+		"postgresql": map[string]interface{}{
+			"parameters": map[string]interface{}{
+				"work_mem": "2MB",
+			},
+		},
+	}, postgres.HBAs{}, parameters)
+
+	params := actual["postgresql"].(map[string]interface{})["parameters"].(map[string]interface{})
+
+	// Parameters in the spec are used when nothing else sets them.
+	assert.Equal(t, params["max_connections"], "200")
+
+	// Parameters in the dynamic configuration take precedence.
+	assert.Equal(t, params["work_mem"], "2MB")
+
+	// Mandatory parameters take precedence over both.
+	assert.Equal(t, params["wal_level"], "logical")
+}
+
 func TestInstanceConfigFiles(t *testing.T) {
 	t.Parallel()
 
@@ -684,6 +720,8 @@ postgresql:
   create_replica_methods:
   - basebackup
   pgpass: /tmp/.pgpass
+  remove_data_directory_on_diverged_timelines: true
+  remove_data_directory_on_rewind_failure: true
   use_unix_socket: true
 restapi: {}
 tags: {}
@@ -714,10 +752,22 @@ postgresql:
     no_master: true
     no_params: true
   pgpass: /tmp/.pgpass
+  remove_data_directory_on_diverged_timelines: true
+  remove_data_directory_on_rewind_failure: true
   use_unix_socket: true
 restapi: {}
 tags: {}
 	`, "\t\n")+"\n")
+
+	cluster.Spec.Patroni = &v1beta1.PatroniSpec{
+		ReplicaRemediation: &v1beta1.PatroniReplicaRemediation{
+			Enabled: initialize.Bool(false),
+		},
+	}
+
+	dataWithoutRemediation, err := instanceYAML(cluster, instance, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(dataWithoutRemediation, "remove_data_directory"))
 }
 
 func TestPGBackRestCreateReplicaCommand(t *testing.T) {
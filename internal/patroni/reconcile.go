@@ -18,6 +18,7 @@ package patroni
 import (
 	"context"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -213,3 +214,35 @@ func PodRequiresRestart(pod metav1.Object) bool {
 	status := pod.GetAnnotations()["status"]
 	return strings.Contains(status, `"pending_restart":true`)
 }
+
+// PodFailedToStart returns whether or not Patroni inside pod reports that
+// PostgreSQL failed to start.
+func PodFailedToStart(pod metav1.Object) bool {
+	if pod == nil {
+		return false
+	}
+
+	// TODO(cbandy): This works only when using Kubernetes for DCS.
+
+	// Patroni keeps running and reports this state when PostgreSQL exits
+	// before it begins accepting connections.
+	status := pod.GetAnnotations()["status"]
+	return strings.Contains(status, `"state":"start failed"`)
+}
+
+// ReplicaRemediation returns whether or not replicas of cluster should be
+// reinitialized when they fail, and how long to wait before reinitializing the
+// same replica again.
+func ReplicaRemediation(cluster *v1beta1.PostgresCluster) (bool, time.Duration) {
+	enabled, cooldown := true, 600*time.Second
+
+	if spec := cluster.Spec.Patroni; spec != nil && spec.ReplicaRemediation != nil {
+		if spec.ReplicaRemediation.Enabled != nil {
+			enabled = *spec.ReplicaRemediation.Enabled
+		}
+		if spec.ReplicaRemediation.CooldownSeconds != nil {
+			cooldown = time.Duration(*spec.ReplicaRemediation.CooldownSeconds) * time.Second
+		}
+	}
+	return enabled, cooldown
+}
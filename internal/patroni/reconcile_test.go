@@ -18,11 +18,13 @@ package patroni
 import (
 	"context"
 	"testing"
+	"time"
 
 	"gotest.tools/v3/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/naming"
 	"github.com/crunchydata/postgres-operator/internal/pki"
 	"github.com/crunchydata/postgres-operator/internal/postgres"
@@ -234,6 +236,23 @@ func TestPodIsStandbyLeader(t *testing.T) {
 	assert.Assert(t, PodIsStandbyLeader(pod))
 }
 
+func TestPodFailedToStart(t *testing.T) {
+	// No object
+	assert.Assert(t, !PodFailedToStart(nil))
+
+	// No annotations
+	pod := &corev1.Pod{}
+	assert.Assert(t, !PodFailedToStart(pod))
+
+	// Running
+	pod.Annotations = map[string]string{"status": `{"state":"running"}`}
+	assert.Assert(t, !PodFailedToStart(pod))
+
+	// Failed
+	pod.Annotations["status"] = `{"role":"replica","state":"start failed"}`
+	assert.Assert(t, PodFailedToStart(pod))
+}
+
 func TestPodRequiresRestart(t *testing.T) {
 	// No object
 	assert.Assert(t, !PodRequiresRestart(nil))
@@ -254,3 +273,22 @@ func TestPodRequiresRestart(t *testing.T) {
 	pod.Annotations["status"] = `{"pending_restart":true}`
 	assert.Assert(t, PodRequiresRestart(pod))
 }
+
+func TestReplicaRemediation(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+
+	enabled, cooldown := ReplicaRemediation(cluster)
+	assert.Assert(t, enabled)
+	assert.Equal(t, cooldown, 10*time.Minute)
+
+	cluster.Spec.Patroni = &v1beta1.PatroniSpec{
+		ReplicaRemediation: &v1beta1.PatroniReplicaRemediation{
+			Enabled:         initialize.Bool(false),
+			CooldownSeconds: initialize.Int32(120),
+		},
+	}
+
+	enabled, cooldown = ReplicaRemediation(cluster)
+	assert.Assert(t, !enabled)
+	assert.Equal(t, cooldown, 2*time.Minute)
+}
@@ -17,10 +17,13 @@ package pgaudit
 
 import (
 	"context"
+	"encoding/json"
 	"strings"
 
 	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/naming"
 	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 // When the pgAudit shared library is not loaded, the extension cannot be
@@ -60,8 +63,50 @@ func EnableInPostgreSQL(ctx context.Context, exec postgres.Executor) error {
 	return err
 }
 
-// PostgreSQLParameters sets the parameters required by pgAudit.
-func PostgreSQLParameters(outParameters *postgres.Parameters) {
+// RoleSettingsInPostgreSQL calls exec to set the classes of statements that
+// pgAudit logs for each of roles. Roles that do not exist are skipped.
+func RoleSettingsInPostgreSQL(
+	ctx context.Context, exec postgres.Executor, roles []v1beta1.PGAuditRoleSpec,
+) error {
+	log := logging.FromContext(ctx)
+
+	records := make([]map[string]string, 0, len(roles))
+	for _, role := range roles {
+		records = append(records, map[string]string{
+			"name": string(role.Name),
+			"log":  joinClasses(role.Log),
+		})
+	}
+
+	encoded, err := json.Marshal(records)
+
+	// Settings of a role, rather than of a role in a database, are stored
+	// with a zero database OID.
+	// - https://www.postgresql.org/docs/current/sql-alterrole.html
+	// - https://github.com/pgaudit/pgaudit#pgauditlog
+	const sql = `SELECT pg_catalog.format('ALTER ROLE %I SET pgaudit.log = %L', roles.rolname, input.log)
+  FROM pg_catalog.json_to_recordset(:'roles') AS input (name text, log text)
+  JOIN pg_catalog.pg_roles AS roles ON roles.rolname = input.name
+\gexec`
+
+	if err == nil {
+		var stdout, stderr string
+		stdout, stderr, err = exec.Exec(ctx, strings.NewReader(sql),
+			map[string]string{
+				"roles":         string(encoded),
+				"ON_ERROR_STOP": "on", // Abort when any one command fails.
+				"QUIET":         "on", // Do not print successful commands to stdout.
+			})
+
+		log.V(1).Info("wrote pgAudit role settings", "stdout", stdout, "stderr", stderr)
+	}
+
+	return err
+}
+
+// PostgreSQLParameters sets the parameters required by pgAudit and those
+// specified in cluster.
+func PostgreSQLParameters(cluster *v1beta1.PostgresCluster, outParameters *postgres.Parameters) {
 
 	// Load the shared library when PostgreSQL starts.
 	// PostgreSQL must be restarted when changing this value.
@@ -70,4 +115,35 @@ func PostgreSQLParameters(outParameters *postgres.Parameters) {
 	shared := outParameters.Mandatory.Value("shared_preload_libraries")
 	outParameters.Mandatory.Add("shared_preload_libraries",
 		strings.TrimPrefix(shared+",pgaudit", ","))
+
+	if cluster.Spec.Audit == nil {
+		return
+	}
+
+	if len(cluster.Spec.Audit.Log) > 0 {
+		outParameters.Mandatory.Add("pgaudit.log", joinClasses(cluster.Spec.Audit.Log))
+	}
+
+	// Write logs to files where Fluent Bit can read them. One file for each
+	// day of the week is kept and overwritten. The files are readable by the
+	// group of the Pod so that a different user can read them, too.
+	// - https://www.postgresql.org/docs/current/runtime-config-logging.html
+	if cluster.Spec.Audit.LogShipping != nil {
+		outParameters.Mandatory.Add("logging_collector", "on")
+		outParameters.Mandatory.Add("log_directory", naming.PostgresPGDataLogPath)
+		outParameters.Mandatory.Add("log_file_mode", "0640")
+		outParameters.Mandatory.Add("log_filename", "postgresql-%a.log")
+		outParameters.Mandatory.Add("log_rotation_age", "1d")
+		outParameters.Mandatory.Add("log_rotation_size", "0")
+		outParameters.Mandatory.Add("log_truncate_on_rotation", "on")
+	}
+}
+
+// joinClasses returns classes as a value for the "pgaudit.log" parameter.
+func joinClasses(classes []v1beta1.PGAuditLogClass) string {
+	values := make([]string, len(classes))
+	for i := range classes {
+		values[i] = string(classes[i])
+	}
+	return strings.Join(values, ",")
 }
@@ -25,6 +25,8 @@ import (
 	"gotest.tools/v3/assert"
 
 	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 func TestEnableInPostgreSQL(t *testing.T) {
@@ -52,13 +54,39 @@ SET client_min_messages = WARNING; CREATE EXTENSION IF NOT EXISTS pgaudit;
 	assert.Equal(t, expected, EnableInPostgreSQL(ctx, exec))
 }
 
+func TestRoleSettingsInPostgreSQL(t *testing.T) {
+	expected := errors.New("whoops")
+	exec := func(
+		_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error {
+		assert.Assert(t, stdout != nil, "should capture stdout")
+		assert.Assert(t, stderr != nil, "should capture stderr")
+
+		assert.Assert(t, cmp.Contains(command,
+			`--set=roles=[{"log":"ddl,write","name":"app"},{"log":"all","name":"admin"}]`))
+
+		b, err := io.ReadAll(stdin)
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(string(b), `ALTER ROLE %I SET pgaudit.log = %L`))
+
+		return expected
+	}
+
+	ctx := context.Background()
+	assert.Equal(t, expected, RoleSettingsInPostgreSQL(ctx, exec, []v1beta1.PGAuditRoleSpec{
+		{Name: "app", Log: []v1beta1.PGAuditLogClass{"ddl", "write"}},
+		{Name: "admin", Log: []v1beta1.PGAuditLogClass{"all"}},
+	}))
+}
+
 func TestPostgreSQLParameters(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
 	parameters := postgres.Parameters{
 		Mandatory: postgres.NewParameterSet(),
 	}
 
 	// No comma when empty.
-	PostgreSQLParameters(&parameters)
+	PostgreSQLParameters(cluster, &parameters)
 
 	assert.Assert(t, parameters.Default == nil)
 	assert.DeepEqual(t, parameters.Mandatory.AsMap(), map[string]string{
@@ -67,10 +95,37 @@ func TestPostgreSQLParameters(t *testing.T) {
 
 	// Appended when not empty.
 	parameters.Mandatory.Add("shared_preload_libraries", "some,existing")
-	PostgreSQLParameters(&parameters)
+	PostgreSQLParameters(cluster, &parameters)
 
 	assert.Assert(t, parameters.Default == nil)
 	assert.DeepEqual(t, parameters.Mandatory.AsMap(), map[string]string{
 		"shared_preload_libraries": "some,existing,pgaudit",
 	})
+
+	t.Run("Audit", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.Audit = &v1beta1.PGAuditSpec{
+			Log:         []v1beta1.PGAuditLogClass{"ddl", "role"},
+			LogShipping: &v1beta1.PGAuditLogShippingSpec{Output: "http"},
+		}
+
+		parameters := postgres.Parameters{
+			Mandatory: postgres.NewParameterSet(),
+		}
+		PostgreSQLParameters(cluster, &parameters)
+
+		assert.Assert(t, parameters.Default == nil)
+		assert.DeepEqual(t, parameters.Mandatory.AsMap(), map[string]string{
+			"shared_preload_libraries": "pgaudit",
+			"pgaudit.log":              "ddl,role",
+
+			"logging_collector":        "on",
+			"log_directory":            "/pgdata/pglogs",
+			"log_file_mode":            "0640",
+			"log_filename":             "postgresql-%a.log",
+			"log_rotation_age":         "1d",
+			"log_rotation_size":        "0",
+			"log_truncate_on_rotation": "on",
+		})
+	})
 }
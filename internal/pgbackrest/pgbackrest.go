@@ -98,3 +98,26 @@ fi
 
 	return false, nil
 }
+
+// Stop terminates every pgBackRest process of the stanza on the host, such as
+// a backup whose Job was stopped, then allows new processes to start again.
+// Processes that push WAL are terminated, too; PostgreSQL retries them.
+// - https://pgbackrest.org/command.html#command-stop
+func (exec Executor) Stop(ctx context.Context) error {
+	var stderr bytes.Buffer
+
+	// Start pgBackRest again even when stop fails so that the stanza is never
+	// left stopped.
+	const script = `
+declare -r stanza="$1"
+declare status=0
+pgbackrest stop --force --stanza="${stanza}" || status=$?
+pgbackrest start --stanza="${stanza}"
+exit "${status}"
+`
+	if err := exec(ctx, nil, nil, &stderr, "bash", "-ceu", "--",
+		script, "-", DefaultStanzaName); err != nil {
+		return errors.WithStack(fmt.Errorf("%w: %v", err, stderr.String()))
+	}
+	return nil
+}
@@ -24,6 +24,7 @@ import (
 	"testing"
 
 	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
 
 	"github.com/crunchydata/postgres-operator/internal/testing/require"
 )
@@ -72,3 +73,34 @@ fi
 	output, err := cmd.CombinedOutput()
 	assert.NilError(t, err, "%q\n%s", cmd.Args, output)
 }
+
+func TestStop(t *testing.T) {
+	ctx := context.Background()
+
+	var script string
+	assert.NilError(t, Executor(func(
+		_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+	) error {
+		assert.Assert(t, stdin == nil)
+		assert.Equal(t, len(command), 6)
+		assert.DeepEqual(t, command[:3], []string{"bash", "-ceu", "--"})
+		assert.DeepEqual(t, command[4:], []string{"-", "db"})
+		script = command[3]
+		return nil
+	}).Stop(ctx))
+
+	assert.Assert(t, cmp.Contains(script, `pgbackrest stop --force --stanza="${stanza}"`))
+	assert.Assert(t, cmp.Contains(script, `pgbackrest start --stanza="${stanza}"`))
+
+	shellcheck := require.ShellCheck(t)
+
+	// Write out that inline script.
+	dir := t.TempDir()
+	file := filepath.Join(dir, "script.bash")
+	assert.NilError(t, os.WriteFile(file, []byte(script), 0o600))
+
+	// Expect shellcheck to be happy.
+	cmd := exec.Command(shellcheck, "--enable=all", "--shell=bash", file)
+	output, err := cmd.CombinedOutput()
+	assert.NilError(t, err, "%q\n%s", cmd.Args, output)
+}
@@ -302,6 +302,7 @@ func addServerContainerAndVolume(
 	postgresMounts := map[string]corev1.VolumeMount{
 		postgres.DataVolumeMount().Name: postgres.DataVolumeMount(),
 		postgres.WALVolumeMount().Name:  postgres.WALVolumeMount(),
+		postgres.TempVolumeMount().Name: postgres.TempVolumeMount(),
 	}
 	for i := range pod.Volumes {
 		if mount, ok := postgresMounts[pod.Volumes[i].Name]; ok {
@@ -83,7 +83,8 @@ func Secret(ctx context.Context,
 
 	if inCluster.Spec.Proxy.PGBouncer.CustomTLSSecret == nil {
 		leaf := pki.NewLeafCertificate("", nil, nil)
-		leaf.DNSNames = naming.ServiceDNSNames(ctx, inService)
+		leaf.DNSNames = append(naming.ServiceDNSNames(ctx, inService),
+			naming.ExternalDNSNames(inService.Annotations)...)
 		leaf.CommonName = leaf.DNSNames[0] // FQDN
 
 		if err == nil {
@@ -0,0 +1,97 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pgmonitor
+
+import "fmt"
+
+// AlertRule is a Prometheus alerting rule.
+// - https://prometheus.io/docs/prometheus/latest/configuration/alerting_rules/
+type AlertRule struct {
+	Alert    string
+	Expr     string
+	For      string
+	Severity string
+	Summary  string
+}
+
+// AlertRules returns the recommended alerts for the metrics of exporters that
+// match selector, a PromQL label matcher such as `service="hippo-exporter"`.
+// The thresholds follow the defaults of pgMonitor.
+// - https://github.com/CrunchyData/pgmonitor/blob/main/prometheus/containers/alert-rules.d/crunchy-alert-rules-pg.yml.example
+func AlertRules(selector string) []AlertRule {
+	lag := fmt.Sprintf(`ccp_replication_lag_size_bytes{%s}`, selector)
+	backup := fmt.Sprintf(`ccp_backrest_last_full_backup_time_since_completion_seconds{%s}`, selector)
+	disk := fmt.Sprintf(
+		`100 * (1 - ccp_nodemx_data_disk_available_bytes{%[1]s} / ccp_nodemx_data_disk_total_bytes{%[1]s})`,
+		selector)
+	connections := fmt.Sprintf(
+		`100 * ccp_connection_stats_total{%[1]s} / ccp_connection_stats_max_connections{%[1]s}`,
+		selector)
+	wraparound := fmt.Sprintf(`ccp_transaction_wraparound_percent_towards_wraparound{%s}`, selector)
+	freeze := fmt.Sprintf(`ccp_transaction_wraparound_percent_towards_emergency_autovac{%s}`, selector)
+	idle := fmt.Sprintf(`ccp_connection_stats_max_idle_in_txn_time{%s}`, selector)
+
+	return []AlertRule{
+		{
+			Alert: "PGReplicationByteLag", Expr: lag + ` > 52428800`, For: "60s",
+			Severity: "warning", Summary: "A replica is more than 50MB behind its primary",
+		},
+		{
+			Alert: "PGReplicationByteLag", Expr: lag + ` > 104857600`, For: "60s",
+			Severity: "critical", Summary: "A replica is more than 100MB behind its primary",
+		},
+		{
+			Alert: "PGBackRestLastCompletedFull", Expr: backup + ` > 604800`, For: "60s",
+			Severity: "critical", Summary: "No full backup has completed in the last 7 days",
+		},
+		{
+			Alert: "PGDiskSize", Expr: disk + ` > 75`, For: "60s",
+			Severity: "warning", Summary: "The PostgreSQL data volume is more than 75% full",
+		},
+		{
+			Alert: "PGDiskSize", Expr: disk + ` > 90`, For: "60s",
+			Severity: "critical", Summary: "The PostgreSQL data volume is more than 90% full",
+		},
+		{
+			Alert: "PGConnPerc", Expr: connections + ` > 75`, For: "60s",
+			Severity: "warning", Summary: "More than 75% of PostgreSQL connections are in use",
+		},
+		{
+			Alert: "PGConnPerc", Expr: connections + ` > 90`, For: "60s",
+			Severity: "critical", Summary: "More than 90% of PostgreSQL connections are in use",
+		},
+		{
+			Alert: "PGTransactionWraparound", Expr: wraparound + ` > 50`, For: "60s",
+			Severity: "warning", Summary: "A database has used more than 50% of its transaction IDs before wraparound",
+		},
+		{
+			Alert: "PGTransactionWraparound", Expr: wraparound + ` > 75`, For: "60s",
+			Severity: "critical", Summary: "A database has used more than 75% of its transaction IDs before wraparound",
+		},
+		{
+			Alert: "PGEmergencyVacuum", Expr: freeze + ` > 110`, For: "60s",
+			Severity: "warning", Summary: "Autovacuum is not keeping up with freezing old transaction IDs",
+		},
+		{
+			Alert: "PGEmergencyVacuum", Expr: freeze + ` > 125`, For: "60s",
+			Severity: "critical", Summary: "Autovacuum is far behind on freezing old transaction IDs",
+		},
+		{
+			Alert: "PGIdleTxn", Expr: idle + ` > 300`, For: "60s",
+			Severity: "warning", Summary: "A session has been idle in a transaction for more than 5 minutes, keeping vacuum from removing dead rows",
+		},
+	}
+}
@@ -0,0 +1,44 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pgmonitor
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestAlertRules(t *testing.T) {
+	rules := AlertRules(`service="hippo-exporter"`)
+	assert.Assert(t, len(rules) > 0)
+
+	alerts := map[string]bool{}
+	for _, rule := range rules {
+		alerts[rule.Alert] = true
+
+		assert.Assert(t, strings.Contains(rule.Expr, `{service="hippo-exporter"}`), "%q", rule.Expr)
+		assert.Assert(t, rule.Severity == "warning" || rule.Severity == "critical")
+		assert.Assert(t, rule.Summary != "")
+	}
+
+	for _, alert := range []string{
+		"PGReplicationByteLag", "PGBackRestLastCompletedFull", "PGDiskSize", "PGConnPerc",
+		"PGTransactionWraparound", "PGEmergencyVacuum", "PGIdleTxn",
+	} {
+		assert.Assert(t, alerts[alert], "expected %q", alert)
+	}
+}
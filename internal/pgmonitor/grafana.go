@@ -0,0 +1,85 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pgmonitor
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// GrafanaDashboard returns the JSON model of a Grafana dashboard named title
+// that graphs the metrics of exporters that match selector, a PromQL label
+// matcher such as `service="hippo-exporter"`.
+// - https://grafana.com/docs/grafana/latest/dashboards/json-model/
+func GrafanaDashboard(title, selector string) (string, error) {
+	type target struct {
+		Expr         string `json:"expr"`
+		LegendFormat string `json:"legendFormat"`
+		RefID        string `json:"refId"`
+	}
+	type panel struct {
+		ID      int                    `json:"id"`
+		Type    string                 `json:"type"`
+		Title   string                 `json:"title"`
+		GridPos map[string]int         `json:"gridPos"`
+		Targets []target               `json:"targets"`
+		Field   map[string]interface{} `json:"fieldConfig"`
+	}
+
+	graphs := []struct{ title, expr, legend, unit string }{
+		{"Connections", `sum by (pod) (ccp_connection_stats_total{%s})`, "{{pod}}", "short"},
+		{"Connection Saturation", `100 * ccp_connection_stats_total{%[1]s} / ccp_connection_stats_max_connections{%[1]s}`, "{{pod}}", "percent"},
+		{"Transactions", `sum by (pod) (rate(ccp_stat_database_xact_commit{%[1]s}[5m]) + rate(ccp_stat_database_xact_rollback{%[1]s}[5m]))`, "{{pod}}", "ops"},
+		{"Cache Hit Ratio", `100 * sum by (pod) (rate(ccp_stat_database_blks_hit{%[1]s}[5m])) / (sum by (pod) (rate(ccp_stat_database_blks_hit{%[1]s}[5m])) + sum by (pod) (rate(ccp_stat_database_blks_read{%[1]s}[5m])))`, "{{pod}}", "percent"},
+		{"CPU Usage", `rate(ccp_nodemx_cpuacct_usage{%s}[5m]) / 1e9`, "{{pod}}", "short"},
+		{"Memory Usage", `ccp_nodemx_mem_usage_in_bytes{%s}`, "{{pod}}", "bytes"},
+		{"Replication Lag", `ccp_replication_lag_size_bytes{%s}`, "{{pod}} {{replica}}", "bytes"},
+		{"Data Volume Usage", `100 * (1 - ccp_nodemx_data_disk_available_bytes{%[1]s} / ccp_nodemx_data_disk_total_bytes{%[1]s})`, "{{pod}}", "percent"},
+		{"Transaction ID Wraparound", `max by (pod) (ccp_transaction_wraparound_percent_towards_wraparound{%s})`, "{{pod}}", "percent"},
+		{"Time Since Last Full Backup", `ccp_backrest_last_full_backup_time_since_completion_seconds{%s}`, "{{stanza}}", "s"},
+	}
+
+	panels := make([]panel, len(graphs))
+	for i, graph := range graphs {
+		panels[i] = panel{
+			ID:    i + 1,
+			Type:  "timeseries",
+			Title: graph.title,
+			GridPos: map[string]int{
+				"h": 8, "w": 12, "x": 12 * (i % 2), "y": 8 * (i / 2),
+			},
+			Targets: []target{{
+				Expr:         fmt.Sprintf(graph.expr, selector),
+				LegendFormat: graph.legend,
+				RefID:        "A",
+			}},
+			Field: map[string]interface{}{
+				"defaults": map[string]interface{}{"unit": graph.unit},
+			},
+		}
+	}
+
+	dashboard, err := json.Marshal(map[string]interface{}{
+		"title":         title,
+		"tags":          []string{"postgres-operator"},
+		"editable":      false,
+		"schemaVersion": 30,
+		"refresh":       "1m",
+		"time":          map[string]string{"from": "now-6h", "to": "now"},
+		"panels":        panels,
+	})
+	return string(dashboard), err
+}
@@ -0,0 +1,50 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pgmonitor
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestGrafanaDashboard(t *testing.T) {
+	dashboard, err := GrafanaDashboard("some-title", `service="hippo-exporter"`)
+	assert.NilError(t, err)
+
+	var model struct {
+		Title  string `json:"title"`
+		Panels []struct {
+			Title   string `json:"title"`
+			Targets []struct {
+				Expr string `json:"expr"`
+			} `json:"targets"`
+		} `json:"panels"`
+	}
+	assert.NilError(t, json.Unmarshal([]byte(dashboard), &model))
+	assert.Equal(t, model.Title, "some-title")
+	assert.Assert(t, len(model.Panels) > 0)
+
+	for _, panel := range model.Panels {
+		assert.Assert(t, panel.Title != "")
+		assert.Equal(t, len(panel.Targets), 1)
+		assert.Assert(t, !strings.Contains(panel.Targets[0].Expr, "%!"), "%q", panel.Targets[0].Expr)
+		assert.Assert(t, strings.Contains(panel.Targets[0].Expr, `{service="hippo-exporter"}`),
+			"%q", panel.Targets[0].Expr)
+	}
+}
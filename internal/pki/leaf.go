@@ -26,6 +26,8 @@ import (
 	"net"
 	"time"
 
+	"k8s.io/apimachinery/pkg/util/sets"
+
 	"github.com/crunchydata/postgres-operator/internal/logging"
 )
 
@@ -109,7 +111,8 @@ func (c *LeafCertificate) Generate(rootCA *RootCertificateAuthority) error {
 }
 
 // LeafCertIsBad checks at least one leaf cert has been generated, the basic constraints
-// are valid and it has been verified with the root certpool
+// are valid, it has been verified with the root certpool and it covers the DNS
+// names of leaf
 //
 // TODO(tjmoore4): Currently this will return 'true' if any of the parsed certs
 // fail a given check. For scenarios where multiple certs may be returned, such
@@ -171,6 +174,16 @@ func LeafCertIsBad(
 			log.Error(verifyError, "verify failed for leaf cert")
 			return true
 		}
+
+		// a leaf cert is bad if it does not cover every DNS name it should,
+		// e.g. after a Service gains an external hostname
+		names := sets.NewString(cert.DNSNames...)
+		for _, name := range leaf.DNSNames {
+			if !names.Has(name) {
+				log.Info("leaf cert is missing a DNS name", "name", name)
+				return true
+			}
+		}
 	}
 
 	// finally, if no check failed, return false
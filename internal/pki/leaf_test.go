@@ -375,6 +375,13 @@ func TestLeafCertIsBad(t *testing.T) {
 		assert.Assert(t, LeafCertIsBad(ctx, emptyLeaf, testRoot, namespace))
 	})
 
+	t.Run("leaf cert is missing a DNS name", func(t *testing.T) {
+		moreNames := *testLeaf
+		moreNames.DNSNames = append(dnsNames, "db.example.com")
+
+		assert.Assert(t, LeafCertIsBad(ctx, &moreNames, testRoot, namespace))
+	})
+
 	t.Run("error parsing root certificate", func(t *testing.T) {
 		testRoot.Certificate = &Certificate{
 			Certificate: []byte("notacert"),
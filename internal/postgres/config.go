@@ -44,6 +44,17 @@ safelink() (
 	// walMountPath is where to mount the optional WAL volume.
 	walMountPath = "/pgwal"
 
+	// tempMountPath is where to mount the optional volume for temporary files.
+	tempMountPath = "/pgtmp"
+
+	// tempTablespaceDirectory is the location of TempTablespace.
+	tempTablespaceDirectory = tempMountPath + "/tablespace"
+
+	// TempTablespace is the tablespace that PGO creates on the temp volume
+	// for the temporary files of PostgreSQL.
+	// - https://www.postgresql.org/docs/current/manage-ag-tablespaces.html
+	TempTablespace = "pgo_temp"
+
 	// downwardAPIPath is where to mount the downwardAPI volume.
 	downwardAPIPath = "/etc/database-containerinfo"
 
@@ -64,6 +75,10 @@ func ConfigDirectory(cluster *v1beta1.PostgresCluster) string {
 	return DataDirectory(cluster)
 }
 
+// DataMountPath returns the absolute path at which instances mount their data
+// volume.
+func DataMountPath() string { return dataMountPath }
+
 // DataDirectory returns the absolute path to the "data_directory" of cluster.
 // - https://www.postgresql.org/docs/current/runtime-config-file-locations.html
 func DataDirectory(cluster *v1beta1.PostgresCluster) string {
@@ -84,10 +99,45 @@ func WALDirectory(
 	return fmt.Sprintf("%s/pg%d_wal", walStorage, cluster.Spec.PostgresVersion)
 }
 
+// ConfigFiles returns the files to mount at /etc/postgres in instances of
+// cluster: those of spec.config.files followed by those of spec.authentication.
+func ConfigFiles(cluster *v1beta1.PostgresCluster) []corev1.VolumeProjection {
+	files := append([]corev1.VolumeProjection{}, cluster.Spec.Config.Files...)
+
+	if auth := cluster.Spec.Authentication; auth != nil && auth.Kerberos != nil {
+		keytab := auth.Kerberos.Keytab
+		files = append(files, corev1.VolumeProjection{
+			Secret: &corev1.SecretProjection{
+				LocalObjectReference: keytab.LocalObjectReference,
+				Items:                []corev1.KeyToPath{{Key: keytab.Key, Path: "krb5.keytab"}},
+			},
+		})
+		if config := auth.Kerberos.Config; config != nil {
+			files = append(files, corev1.VolumeProjection{
+				ConfigMap: &corev1.ConfigMapProjection{
+					LocalObjectReference: config.LocalObjectReference,
+					Items:                []corev1.KeyToPath{{Key: config.Key, Path: "krb5.conf"}},
+				},
+			})
+		}
+	}
+	if auth := cluster.Spec.Authentication; auth != nil && auth.LDAP != nil && auth.LDAP.CA != nil {
+		ca := auth.LDAP.CA
+		files = append(files, corev1.VolumeProjection{
+			Secret: &corev1.SecretProjection{
+				LocalObjectReference: ca.LocalObjectReference,
+				Items:                []corev1.KeyToPath{{Key: ca.Key, Path: "ldap-ca.crt"}},
+			},
+		})
+	}
+
+	return files
+}
+
 // Environment returns the environment variables required to invoke PostgreSQL
 // utilities.
 func Environment(cluster *v1beta1.PostgresCluster) []corev1.EnvVar {
-	return []corev1.EnvVar{
+	env := []corev1.EnvVar{
 		// - https://www.postgresql.org/docs/current/reference-server.html
 		{
 			Name:  "PGDATA",
@@ -117,6 +167,18 @@ func Environment(cluster *v1beta1.PostgresCluster) []corev1.EnvVar {
 			Value: "/tmp",
 		},
 	}
+
+	// The LDAP library of PostgreSQL reads certificate authorities from this
+	// file when it connects with TLS.
+	// - https://www.openldap.org/software/man.cgi?query=ldap.conf
+	if auth := cluster.Spec.Authentication; auth != nil && auth.LDAP != nil && auth.LDAP.CA != nil {
+		env = append(env, corev1.EnvVar{
+			Name:  "LDAPTLS_CACERT",
+			Value: configMountPath + "/ldap-ca.crt",
+		})
+	}
+
+	return env
 }
 
 // reloadCommand returns an entrypoint that convinces PostgreSQL to reload
@@ -180,9 +242,15 @@ func startupCommand(
 	version := fmt.Sprint(cluster.Spec.PostgresVersion)
 	walDir := WALDirectory(cluster, instance)
 
-	args := []string{version, walDir, naming.PGBackRestPGDataLogPath}
+	// When there is no temp volume, the tablespace location is empty.
+	tempDir := ""
+	if cluster.Spec.TempVolume != nil {
+		tempDir = tempTablespaceDirectory
+	}
+
+	args := []string{version, walDir, naming.PGBackRestPGDataLogPath, tempDir}
 	script := strings.Join([]string{
-		`declare -r expected_major_version="$1" pgwal_directory="$2" pgbrLog_directory="$3"`,
+		`declare -r expected_major_version="$1" pgwal_directory="$2" pgbrLog_directory="$3" pgtmp_directory="$4"`,
 
 		// Function to log values in a basic structured format.
 		`results() { printf '::postgres-operator: %s::%s\n' "$@"; }`,
@@ -237,6 +305,9 @@ func startupCommand(
 			naming.ReplicationCert, naming.ReplicationPrivateKey,
 			naming.ReplicationCACert),
 
+		// Create the location of the temporary tablespace, if any.
+		`[ -z "${pgtmp_directory}" ] || install --directory --mode=0700 "${pgtmp_directory}"`,
+
 		// When the data directory is empty, there's nothing more to do.
 		`[ -f "${postgres_data_directory}/PG_VERSION" ] || exit 0`,
 
@@ -256,6 +327,16 @@ func startupCommand(
 		`safelink "${pgwal_directory}" "${postgres_data_directory}/pg_wal"`,
 		`results 'wal directory' "$(realpath "${postgres_data_directory}/pg_wal")"`,
 
+		// PostgreSQL keeps the files of a tablespace in a subdirectory named
+		// for its version. An emptyDir volume loses that subdirectory when the
+		// Pod is recreated, so create it again once the tablespace exists.
+		// - https://www.postgresql.org/docs/current/storage-file-layout.html
+		`for tablespace in "${postgres_data_directory}"/pg_tblspc/*; do`,
+		` [ -n "${pgtmp_directory}" ] && [ "$(readlink "${tablespace}")" = "${pgtmp_directory}" ] || continue`,
+		` catalog_version=$(LC_ALL=C pg_controldata "${postgres_data_directory}" | sed -n 's/^Catalog version number: *//p')`,
+		` install --directory --mode=0700 "${pgtmp_directory}/PG_${postgres_data_version}_${catalog_version}"`,
+		`done`,
+
 		// Early versions of PGO create replicas with a recovery signal file.
 		// Patroni also creates a standby signal file before starting Postgres,
 		// causing Postgres to remove only one, the standby. Remove the extra
@@ -37,6 +37,61 @@ func TestConfigDirectory(t *testing.T) {
 	assert.Equal(t, ConfigDirectory(cluster), "/pgdata/pg11")
 }
 
+func TestConfigFiles(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	assert.Assert(t, len(ConfigFiles(cluster)) == 0)
+
+	cluster.Spec.Config.Files = []corev1.VolumeProjection{{
+		ConfigMap: &corev1.ConfigMapProjection{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "mine"},
+		},
+	}}
+	cluster.Spec.Authentication = &v1beta1.PostgresAuthenticationSpec{
+		Kerberos: &v1beta1.PostgresKerberosSpec{
+			Keytab: corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "kerberos"},
+				Key:                  "postgres.keytab",
+			},
+			Config: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "kerberos"},
+				Key:                  "krb5.conf",
+			},
+		},
+		LDAP: &v1beta1.PostgresLDAPSpec{
+			CA: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "directory"},
+				Key:                  "ca.crt",
+			},
+		},
+	}
+
+	assert.Assert(t, marshalMatches(ConfigFiles(cluster), `
+- configMap:
+    name: mine
+- secret:
+    items:
+    - key: postgres.keytab
+      path: krb5.keytab
+    name: kerberos
+- configMap:
+    items:
+    - key: krb5.conf
+      path: krb5.conf
+    name: kerberos
+- secret:
+    items:
+    - key: ca.crt
+      path: ldap-ca.crt
+    name: directory
+	`))
+
+	cluster.Spec.Port = new(int32)
+	env := Environment(cluster)
+	assert.DeepEqual(t, env[len(env)-1], corev1.EnvVar{
+		Name: "LDAPTLS_CACERT", Value: "/etc/postgres/ldap-ca.crt",
+	})
+}
+
 func TestDataDirectory(t *testing.T) {
 	cluster := new(v1beta1.PostgresCluster)
 	cluster.Spec.PostgresVersion = 12
@@ -339,4 +394,12 @@ func TestStartupCommand(t *testing.T) {
 	cmd := exec.Command(shellcheck, "--enable=all", file)
 	output, err := cmd.CombinedOutput()
 	assert.NilError(t, err, "%q\n%s", cmd.Args, output)
+
+	t.Run("TempVolume", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.TempVolume = new(v1beta1.PostgresTempVolumeSpec)
+
+		command := startupCommand(cluster, instance)
+		assert.Equal(t, command[len(command)-1], "/pgtmp/tablespace")
+	})
 }
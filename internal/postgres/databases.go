@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 
 	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 // CreateDatabasesInPostgreSQL calls exec to create databases that do not exist
@@ -81,3 +82,101 @@ SELECT pg_catalog.format('CREATE DATABASE %I',
 
 	return err
 }
+
+// WriteDatabasesInPostgreSQL calls exec to set the owners of databases, create
+// their schemas, and grant privileges to their readers and writers. Readers can
+// select from every table in the "public" schema and in schemas of the owner.
+// Writers can change every table in the schemas of the specification. The
+// databases and their users must already exist.
+func WriteDatabasesInPostgreSQL(
+	ctx context.Context, exec Executor, databases []v1beta1.PostgresDatabaseSpec,
+) error {
+	log := logging.FromContext(ctx)
+
+	encoded, err := json.Marshal(databases)
+
+	// Only databases in the specification need anything done.
+	const sqlDatabases = `
+SELECT datname FROM pg_catalog.pg_database
+ WHERE datallowconn AND datname IN (
+       SELECT pg_catalog.json_extract_path_text(input.data, 'name')
+         FROM pg_catalog.json_array_elements(:'databases') AS input (data))`
+
+	// Prevent unexpected dereferences by emptying "search_path". The "pg_catalog"
+	// schema is still searched, and only temporary objects can be created.
+	// - https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-SEARCH-PATH
+	//
+	// Readers are not given the system schemas nor the schema where PgBouncer
+	// finds passwords.
+	//
+	// Quiet the NOTICE from IF NOT EXISTS. The "postgres" superuser owns the
+	// objects of databases without an owner. Each column of each row that
+	// "\gexec" reads is executed as its own statement.
+	// - https://www.postgresql.org/docs/current/sql-alterdatabase.html
+	// - https://www.postgresql.org/docs/current/sql-createschema.html
+	// - https://www.postgresql.org/docs/current/sql-alterdefaultprivileges.html
+	const sql = `SET search_path TO '';
+SET client_min_messages = WARNING;
+CREATE TEMPORARY TABLE input AS
+SELECT COALESCE(spec.owner, 'postgres') AS owner,
+       COALESCE(spec.schemas, '[]') AS schemas,
+       COALESCE(spec.readers, '[]') AS readers,
+       COALESCE(spec.writers, '[]') AS writers
+  FROM pg_catalog.json_to_recordset(:'databases')
+    AS spec (name text, owner text, schemas json, readers json, writers json)
+ WHERE spec.name = pg_catalog.current_database();
+
+SELECT pg_catalog.format('ALTER DATABASE %I OWNER TO %I',
+       pg_catalog.current_database(), input.owner)
+  FROM input
+\gexec
+
+SELECT pg_catalog.format('CREATE SCHEMA IF NOT EXISTS %I AUTHORIZATION %I',
+       schema.name, input.owner)
+  FROM input, pg_catalog.json_array_elements_text(input.schemas) AS schema (name)
+\gexec
+
+SELECT pg_catalog.format('GRANT USAGE ON SCHEMA %I TO %I', schema.nspname, reader.name),
+       pg_catalog.format('GRANT SELECT ON ALL TABLES IN SCHEMA %I TO %I', schema.nspname, reader.name)
+  FROM input,
+       pg_catalog.pg_namespace AS schema,
+       pg_catalog.json_array_elements_text(input.readers) AS reader (name)
+ WHERE schema.nspname = 'public'
+    OR (schema.nspowner = pg_catalog.to_regrole(pg_catalog.quote_ident(input.owner))
+        AND schema.nspname NOT LIKE 'pg\_%'
+        AND schema.nspname NOT IN ('information_schema', 'pgbouncer'))
+\gexec
+
+SELECT pg_catalog.format('ALTER DEFAULT PRIVILEGES FOR ROLE %I GRANT USAGE ON SCHEMAS TO %I',
+                         input.owner, reader.name),
+       pg_catalog.format('ALTER DEFAULT PRIVILEGES FOR ROLE %I GRANT SELECT ON TABLES TO %I',
+                         input.owner, reader.name)
+  FROM input, pg_catalog.json_array_elements_text(input.readers) AS reader (name)
+\gexec
+
+SELECT pg_catalog.format('GRANT USAGE ON SCHEMA %I TO %I', schema.name, writer.name),
+       pg_catalog.format('GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA %I TO %I', schema.name, writer.name),
+       pg_catalog.format('GRANT USAGE, SELECT, UPDATE ON ALL SEQUENCES IN SCHEMA %I TO %I', schema.name, writer.name),
+       pg_catalog.format('ALTER DEFAULT PRIVILEGES FOR ROLE %I IN SCHEMA %I GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO %I',
+                         input.owner, schema.name, writer.name),
+       pg_catalog.format('ALTER DEFAULT PRIVILEGES FOR ROLE %I IN SCHEMA %I GRANT USAGE, SELECT, UPDATE ON SEQUENCES TO %I',
+                         input.owner, schema.name, writer.name)
+  FROM input,
+       pg_catalog.json_array_elements_text(input.schemas) AS schema (name),
+       pg_catalog.json_array_elements_text(input.writers) AS writer (name)
+\gexec`
+
+	if err == nil {
+		var stdout, stderr string
+		stdout, stderr, err = exec.ExecInDatabasesFromQuery(ctx, sqlDatabases, sql,
+			map[string]string{
+				"databases":     string(encoded),
+				"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+				"QUIET":         "on", // Do not print successful statements to stdout.
+			})
+
+		log.V(1).Info("wrote PostgreSQL databases", "stdout", stdout, "stderr", stderr)
+	}
+
+	return err
+}
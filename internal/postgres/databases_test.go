@@ -25,6 +25,7 @@ import (
 	"gotest.tools/v3/assert"
 
 	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 func TestCreateDatabasesInPostgreSQL(t *testing.T) {
@@ -101,3 +102,63 @@ SELECT pg_catalog.format('CREATE DATABASE %I',
 		assert.Equal(t, calls, 1)
 	})
 }
+
+func TestWriteDatabasesInPostgreSQL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Arguments", func(t *testing.T) {
+		expected := errors.New("pass-through")
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Assert(t, stdout != nil, "should capture stdout")
+			assert.Assert(t, stderr != nil, "should capture stderr")
+			assert.Assert(t, cmp.Contains(command, `--set=databases=null`))
+			return expected
+		}
+
+		assert.Equal(t, expected, WriteDatabasesInPostgreSQL(ctx, exec, nil))
+	})
+
+	t.Run("Full", func(t *testing.T) {
+		calls := 0
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			calls++
+
+			assert.Assert(t, cmp.Contains(command,
+				`--set=databases=[`+strings.Join([]string{
+					`{"name":"app","owner":"rhino","schemas":["sales","hr"],"readers":["bi"],"writers":["etl"]}`,
+					`{"name":"zoo"}`,
+				}, ",")+`]`))
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, cmp.Contains(string(b), `ALTER DATABASE %I OWNER TO %I`))
+			assert.Assert(t, cmp.Contains(string(b), `CREATE SCHEMA IF NOT EXISTS %I AUTHORIZATION %I`))
+			assert.Assert(t, cmp.Contains(string(b),
+				`ALTER DEFAULT PRIVILEGES FOR ROLE %I GRANT SELECT ON TABLES TO %I`))
+			assert.Assert(t, cmp.Contains(string(b),
+				`ALTER DEFAULT PRIVILEGES FOR ROLE %I GRANT USAGE ON SCHEMAS TO %I`))
+			assert.Assert(t, cmp.Contains(string(b),
+				`ALTER DEFAULT PRIVILEGES FOR ROLE %I IN SCHEMA %I GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO %I`))
+			assert.Assert(t, cmp.Contains(string(b), `\gexec`))
+			return nil
+		}
+
+		assert.NilError(t, WriteDatabasesInPostgreSQL(ctx, exec,
+			[]v1beta1.PostgresDatabaseSpec{
+				{
+					Name:    "app",
+					Owner:   "rhino",
+					Schemas: []v1beta1.PostgresIdentifier{"sales", "hr"},
+					Readers: []v1beta1.PostgresIdentifier{"bi"},
+					Writers: []v1beta1.PostgresIdentifier{"etl"},
+				},
+				{Name: "zoo"},
+			},
+		))
+		assert.Equal(t, calls, 1)
+	})
+}
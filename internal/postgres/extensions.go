@@ -0,0 +1,142 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// extensionLibraries are the shared libraries that must be loaded when
+// PostgreSQL starts before an extension can be created or function.
+// - https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-SHARED-PRELOAD-LIBRARIES
+var extensionLibraries = map[string]string{
+	"pg_cron":            "pg_cron",
+	"pg_partman":         "pg_partman_bgw",
+	"pg_stat_statements": "pg_stat_statements",
+	"pgaudit":            "pgaudit",
+	"pgnodemx":           "pgnodemx",
+	"timescaledb":        "timescaledb",
+}
+
+// ExtensionParameters adds the shared libraries of extensions to the
+// mandatory "shared_preload_libraries" parameter of outParameters. Libraries
+// that are already present are not added again.
+func ExtensionParameters(
+	extensions []v1beta1.PostgresExtensionSpec, outParameters *Parameters,
+) {
+	shared := outParameters.Mandatory.Value("shared_preload_libraries")
+
+	loaded := make(map[string]bool)
+	for _, library := range strings.Split(shared, ",") {
+		loaded[strings.TrimSpace(library)] = true
+	}
+
+	for _, extension := range extensions {
+		if library, ok := extensionLibraries[string(extension.Name)]; ok && !loaded[library] {
+			loaded[library] = true
+			shared = strings.TrimPrefix(shared+","+library, ",")
+		}
+	}
+
+	if shared != "" {
+		// PostgreSQL must be restarted when changing this value.
+		outParameters.Mandatory.Add("shared_preload_libraries", shared)
+	}
+}
+
+// CreateExtensionsInPostgreSQL calls exec to create extensions that do not
+// exist in their databases. Extensions without databases are created in every
+// database. Extensions on which they depend are created as well.
+func CreateExtensionsInPostgreSQL(
+	ctx context.Context, exec Executor, extensions []v1beta1.PostgresExtensionSpec,
+) error {
+	log := logging.FromContext(ctx)
+
+	records := make([]map[string]interface{}, 0, len(extensions))
+	for i := range extensions {
+		databases := extensions[i].Databases
+
+		// An empty list of databases means every database.
+		if len(databases) == 0 {
+			databases = nil
+		}
+
+		records = append(records, map[string]interface{}{
+			"databases": databases,
+			"extension": string(extensions[i].Name),
+		})
+	}
+
+	encoded, err := json.Marshal(records)
+
+	// Quiet the NOTICE from IF NOT EXISTS, and create the extensions specified
+	// for the current database.
+	// - https://www.postgresql.org/docs/current/runtime-config-client.html
+	// - https://www.postgresql.org/docs/current/sql-createextension.html
+	const sql = `SET client_min_messages = WARNING;
+SELECT pg_catalog.format('CREATE EXTENSION IF NOT EXISTS %I CASCADE',
+       pg_catalog.json_extract_path_text(input.data, 'extension'))
+  FROM pg_catalog.json_array_elements(:'extensions') AS input (data)
+ WHERE pg_catalog.json_typeof(pg_catalog.json_extract_path(input.data, 'databases')) <> 'array'
+    OR pg_catalog.current_database() IN (
+       SELECT pg_catalog.json_array_elements_text(
+              pg_catalog.json_extract_path(input.data, 'databases')))
+\gexec`
+
+	if err == nil {
+		var stdout, stderr string
+		stdout, stderr, err = exec.ExecInAllDatabases(ctx, sql,
+			map[string]string{
+				"extensions":    string(encoded),
+				"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+				"QUIET":         "on", // Do not print successful statements to stdout.
+			})
+
+		log.V(1).Info("created PostgreSQL extensions", "stdout", stdout, "stderr", stderr)
+	}
+
+	return err
+}
+
+// ResetQueryStatisticsInPostgreSQL calls exec to discard the statistics that
+// pg_stat_statements has gathered about every database. It does nothing when
+// pg_stat_statements is not installed.
+func ResetQueryStatisticsInPostgreSQL(ctx context.Context, exec Executor) error {
+	log := logging.FromContext(ctx)
+
+	// The statistics are in shared memory, so resetting them in any one
+	// database resets them all. The extension might be in only some databases,
+	// so look for it in each of them before calling its function.
+	// - https://www.postgresql.org/docs/current/pgstatstatements.html
+	stdout, stderr, err := exec.ExecInAllDatabases(ctx, `
+SET search_path TO '';
+SELECT pg_catalog.format('SELECT %I.pg_stat_statements_reset()', extnamespace::regnamespace)
+  FROM pg_catalog.pg_extension WHERE extname = 'pg_stat_statements'
+\gexec
+`, map[string]string{
+		"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+		"QUIET":         "on", // Do not print successful statements to stdout.
+	})
+
+	log.V(1).Info("reset query statistics", "stdout", stdout, "stderr", stderr)
+
+	return err
+}
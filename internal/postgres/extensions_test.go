@@ -0,0 +1,148 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestExtensionParameters(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		parameters := NewParameters()
+		ExtensionParameters(nil, &parameters)
+
+		assert.Assert(t, !parameters.Mandatory.Has("shared_preload_libraries"))
+	})
+
+	t.Run("Libraries", func(t *testing.T) {
+		parameters := NewParameters()
+		parameters.Mandatory.Add("shared_preload_libraries", "pgaudit,other")
+
+		ExtensionParameters([]v1beta1.PostgresExtensionSpec{
+			{Name: "pg_partman"},
+			{Name: "pgaudit"},
+			{Name: "hstore"},
+			{Name: "pg_cron"},
+		}, &parameters)
+
+		assert.Equal(t, parameters.Mandatory.Value("shared_preload_libraries"),
+			"pgaudit,other,pg_partman_bgw,pg_cron")
+	})
+
+	t.Run("NoLibraries", func(t *testing.T) {
+		parameters := NewParameters()
+		ExtensionParameters([]v1beta1.PostgresExtensionSpec{
+			{Name: "hstore"},
+		}, &parameters)
+
+		assert.Assert(t, !parameters.Mandatory.Has("shared_preload_libraries"))
+	})
+}
+
+func TestCreateExtensionsInPostgreSQL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Arguments", func(t *testing.T) {
+		expected := errors.New("pass-through")
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Assert(t, stdout != nil, "should capture stdout")
+			assert.Assert(t, stderr != nil, "should capture stderr")
+			assert.Assert(t, cmp.Contains(command, `--set=extensions=[]`))
+			return expected
+		}
+
+		assert.Equal(t, expected, CreateExtensionsInPostgreSQL(ctx, exec, nil))
+	})
+
+	t.Run("Full", func(t *testing.T) {
+		calls := 0
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			calls++
+
+			assert.Assert(t, cmp.Contains(command,
+				`--set=extensions=[`+strings.Join([]string{
+					`{"databases":null,"extension":"pg_partman"}`,
+					`{"databases":null,"extension":"hstore"}`,
+					`{"databases":["app","zoo"],"extension":"postgis"}`,
+				}, ",")+`]`))
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, cmp.Contains(string(b), `CREATE EXTENSION IF NOT EXISTS %I CASCADE`))
+			assert.Assert(t, cmp.Contains(string(b), `\gexec`))
+			return nil
+		}
+
+		assert.NilError(t, CreateExtensionsInPostgreSQL(ctx, exec,
+			[]v1beta1.PostgresExtensionSpec{
+				{Name: "pg_partman"},
+				{Name: "hstore", Databases: []v1beta1.PostgresIdentifier{}},
+				{Name: "postgis", Databases: []v1beta1.PostgresIdentifier{"app", "zoo"}},
+			},
+		))
+		assert.Equal(t, calls, 1)
+	})
+}
+
+func TestResetQueryStatisticsInPostgreSQL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Arguments", func(t *testing.T) {
+		expected := errors.New("pass-through")
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Assert(t, stdout != nil, "should capture stdout")
+			assert.Assert(t, stderr != nil, "should capture stderr")
+			assert.Assert(t, cmp.Contains(command, `--set=ON_ERROR_STOP=on`))
+			return expected
+		}
+
+		assert.Equal(t, expected, ResetQueryStatisticsInPostgreSQL(ctx, exec))
+	})
+
+	t.Run("Statements", func(t *testing.T) {
+		calls := 0
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			calls++
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, cmp.Contains(string(b), `pg_stat_statements_reset()`))
+			assert.Assert(t, cmp.Contains(string(b), `WHERE extname = 'pg_stat_statements'`))
+			assert.Assert(t, cmp.Contains(string(b), `\gexec`))
+			return nil
+		}
+
+		assert.NilError(t, ResetQueryStatisticsInPostgreSQL(ctx, exec))
+		assert.Equal(t, calls, 1)
+	})
+}
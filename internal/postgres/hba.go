@@ -17,7 +17,10 @@ package postgres
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 // NewHBAs returns HostBasedAuthentication records required by this package.
@@ -46,6 +49,69 @@ func NewHBAs() HBAs {
 	}
 }
 
+// SpecHBAs appends the HostBasedAuthentication records in the spec of cluster
+// to the mandatory records of outHBAs. They are checked in order after the
+// records required by PGO and before any others.
+func SpecHBAs(cluster *v1beta1.PostgresCluster, outHBAs *HBAs) {
+	if cluster.Spec.Authentication == nil {
+		return
+	}
+
+	// Reject connections without TLS before considering any other rules.
+	if tlsOnly := cluster.Spec.Authentication.TLSOnly; tlsOnly != nil && *tlsOnly {
+		outHBAs.Mandatory = append(outHBAs.Mandatory, *NewHBA().NoSSL().Method("reject"))
+	}
+
+	for _, rule := range cluster.Spec.Authentication.Rules {
+		hba := NewHBA().Method(rule.Method)
+
+		switch rule.Connection {
+		case "host":
+			hba.TCP()
+		case "hostnossl":
+			hba.NoSSL()
+		case "hostgssenc":
+			hba.GSSEncryption()
+		case "hostnogssenc":
+			hba.NoGSSEncryption()
+		default:
+			hba.TLS()
+		}
+
+		if len(rule.Databases) > 0 {
+			names := make([]string, len(rule.Databases))
+			for i := range rule.Databases {
+				names[i] = string(rule.Databases[i])
+			}
+			hba.Databases(names...)
+		}
+		if len(rule.Users) > 0 {
+			names := make([]string, len(rule.Users))
+			for i := range rule.Users {
+				names[i] = string(rule.Users[i])
+			}
+			hba.Users(names...)
+		}
+		if rule.Address != "" {
+			hba.Network(rule.Address)
+		}
+		if len(rule.Options) > 0 {
+			hba.Options(rule.Options)
+		}
+
+		outHBAs.Mandatory = append(outHBAs.Mandatory, *hba)
+	}
+}
+
+// AuthenticationParameters sets the parameters that the authentication methods
+// in the spec of cluster need. See [ConfigFiles].
+// - https://www.postgresql.org/docs/current/runtime-config-connection.html#GUC-KRB-SERVER-KEYFILE
+func AuthenticationParameters(cluster *v1beta1.PostgresCluster, outParameters *Parameters) {
+	if auth := cluster.Spec.Authentication; auth != nil && auth.Kerberos != nil {
+		outParameters.Mandatory.Add("krb_server_keyfile", configMountPath+"/krb5.keytab")
+	}
+}
+
 // HBAs is a pairing of HostBasedAuthentication records.
 type HBAs struct{ Mandatory, Default []HostBasedAuthentication }
 
@@ -88,6 +154,23 @@ func (hba *HostBasedAuthentication) Database(name string) *HostBasedAuthenticati
 	return hba
 }
 
+// Databases makes hba match connections made to any of the specific databases.
+func (hba *HostBasedAuthentication) Databases(names ...string) *HostBasedAuthentication {
+	quoted := make([]string, len(names))
+	for i := range names {
+		quoted[i] = hba.quote(names[i])
+	}
+	hba.database = strings.Join(quoted, ",")
+	return hba
+}
+
+// GSSEncryption makes hba match connection attempts made over TCP/IP with
+// GSSAPI encryption.
+func (hba *HostBasedAuthentication) GSSEncryption() *HostBasedAuthentication {
+	hba.origin = "hostgssenc"
+	return hba
+}
+
 // Local makes hba match connection attempts using Unix-domain sockets.
 func (hba *HostBasedAuthentication) Local() *HostBasedAuthentication {
 	hba.origin = "local"
@@ -106,6 +189,13 @@ func (hba *HostBasedAuthentication) Network(block string) *HostBasedAuthenticati
 	return hba
 }
 
+// NoGSSEncryption makes hba match connection attempts made over TCP/IP without
+// GSSAPI encryption.
+func (hba *HostBasedAuthentication) NoGSSEncryption() *HostBasedAuthentication {
+	hba.origin = "hostnogssenc"
+	return hba
+}
+
 // NoSSL makes hba match connection attempts made over TCP/IP without SSL.
 func (hba *HostBasedAuthentication) NoSSL() *HostBasedAuthentication {
 	hba.origin = "hostnossl"
@@ -114,9 +204,16 @@ func (hba *HostBasedAuthentication) NoSSL() *HostBasedAuthentication {
 
 // Options specifies any options for the authentication method.
 func (hba *HostBasedAuthentication) Options(opts map[string]string) *HostBasedAuthentication {
+	// Sort the options so that hba is the same every time.
+	keys := make([]string, 0, len(opts))
+	for k := range opts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
 	hba.options = ""
-	for k, v := range opts {
-		hba.options = fmt.Sprintf("%s %s=%s", hba.options, k, hba.quote(v))
+	for _, k := range keys {
+		hba.options = fmt.Sprintf("%s %s=%s", hba.options, k, hba.quote(opts[k]))
 	}
 	return hba
 }
@@ -158,6 +255,16 @@ func (hba *HostBasedAuthentication) User(name string) *HostBasedAuthentication {
 	return hba
 }
 
+// Users makes hba match connections by any of the specific users.
+func (hba *HostBasedAuthentication) Users(names ...string) *HostBasedAuthentication {
+	quoted := make([]string, len(names))
+	for i := range names {
+		quoted[i] = hba.quote(names[i])
+	}
+	hba.user = strings.Join(quoted, ",")
+	return hba
+}
+
 // String returns hba formatted for the pg_hba.conf file without a newline.
 func (hba HostBasedAuthentication) String() string {
 	if hba.origin == "local" {
@@ -22,6 +22,7 @@ import (
 	"gotest.tools/v3/assert"
 
 	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 func TestNewHBAs(t *testing.T) {
@@ -70,4 +71,75 @@ func TestHostBasedAuthentication(t *testing.T) {
 
 	assert.Equal(t, `hostnossl all all all reject`,
 		NewHBA().NoSSL().Method("reject").String())
+
+	assert.Equal(t, `host "a","b" "x","y" all ldap  ldapport="389" ldapserver="ldap.example"`,
+		NewHBA().TCP().Databases("a", "b").Users("x", "y").Method("ldap").
+			Options(map[string]string{"ldapserver": "ldap.example", "ldapport": "389"}).
+			String())
+}
+
+func TestSpecHBAs(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+
+	hbas := HBAs{}
+	SpecHBAs(cluster, &hbas)
+	assert.Assert(t, hbas.Mandatory == nil)
+
+	cluster.Spec.Authentication = &v1beta1.PostgresAuthenticationSpec{
+		Rules: []v1beta1.PostgresHBARuleSpec{
+			{Address: "10.0.0.0/8", Method: "reject"},
+			{Connection: "host", Users: []v1beta1.PostgresIdentifier{"app"}, Method: "scram-sha-256"},
+			{
+				Connection: "hostssl",
+				Databases:  []v1beta1.PostgresIdentifier{"reports", "sales"},
+				Method:     "cert",
+				Options:    map[string]string{"clientcert": "verify-full"},
+			},
+			{Connection: "hostgssenc", Method: "gss", Options: map[string]string{"include_realm": "0"}},
+			{Connection: "hostnogssenc", Users: []v1beta1.PostgresIdentifier{"app"}, Method: "reject"},
+		},
+	}
+
+	hbas = HBAs{Mandatory: []HostBasedAuthentication{*NewHBA().Local().Method("peer")}}
+	SpecHBAs(cluster, &hbas)
+
+	printed := make([]string, len(hbas.Mandatory))
+	for i := range hbas.Mandatory {
+		printed[i] = hbas.Mandatory[i].String()
+	}
+	assert.DeepEqual(t, printed, []string{
+		`local all all peer`,
+		`hostssl all all "10.0.0.0/8" reject`,
+		`host all "app" all scram-sha-256`,
+		`hostssl "reports","sales" all all cert  clientcert="verify-full"`,
+		`hostgssenc all all all gss  include_realm="0"`,
+		`hostnogssenc all "app" all reject`,
+	})
+
+	t.Run("TLSOnly", func(t *testing.T) {
+		cluster.Spec.Authentication.TLSOnly = new(bool)
+		*cluster.Spec.Authentication.TLSOnly = true
+
+		hbas := HBAs{}
+		SpecHBAs(cluster, &hbas)
+
+		assert.Assert(t, len(hbas.Mandatory) == 6)
+		assert.Equal(t, hbas.Mandatory[0].String(), `hostnossl all all all reject`)
+	})
+}
+
+func TestAuthenticationParameters(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	parameters := NewParameters()
+
+	AuthenticationParameters(cluster, &parameters)
+	_, found := parameters.Mandatory.Get("krb_server_keyfile")
+	assert.Assert(t, !found)
+
+	cluster.Spec.Authentication = &v1beta1.PostgresAuthenticationSpec{
+		Kerberos: &v1beta1.PostgresKerberosSpec{},
+	}
+	AuthenticationParameters(cluster, &parameters)
+	value, _ := parameters.Mandatory.Get("krb_server_keyfile")
+	assert.Equal(t, value, "/etc/postgres/krb5.keytab")
 }
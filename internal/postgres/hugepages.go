@@ -0,0 +1,51 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgres
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// HugePagesRequested returns whether or not resources has a limit on any size
+// of huge pages.
+// - https://docs.k8s.io/tasks/manage-hugepages/scheduling-hugepages/
+func HugePagesRequested(resources corev1.ResourceRequirements) bool {
+	for name, quantity := range resources.Limits {
+		if strings.HasPrefix(string(name), corev1.ResourceHugePagesPrefix) && !quantity.IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
+// HugePagesParameters requires huge pages when every instance set of cluster
+// requests them, so that PostgreSQL refuses to start rather than run without
+// them. Otherwise, PostgreSQL keeps its default of trying to use them.
+// - https://www.postgresql.org/docs/current/runtime-config-resource.html#GUC-HUGE-PAGES
+func HugePagesParameters(cluster *v1beta1.PostgresCluster, outParameters *Parameters) {
+	every := len(cluster.Spec.InstanceSets) > 0
+	for i := range cluster.Spec.InstanceSets {
+		every = every && HugePagesRequested(cluster.Spec.InstanceSets[i].Resources)
+	}
+
+	if every {
+		outParameters.Default.Add("huge_pages", "on")
+	}
+}
@@ -0,0 +1,84 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgres
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestHugePagesRequested(t *testing.T) {
+	assert.Assert(t, !HugePagesRequested(corev1.ResourceRequirements{}))
+	assert.Assert(t, !HugePagesRequested(corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+	}))
+	assert.Assert(t, !HugePagesRequested(corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{"hugepages-2Mi": resource.MustParse("0")},
+	}))
+	assert.Assert(t, HugePagesRequested(corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{"hugepages-2Mi": resource.MustParse("100Mi")},
+	}))
+	assert.Assert(t, HugePagesRequested(corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{"hugepages-1Gi": resource.MustParse("2Gi")},
+	}))
+}
+
+func TestHugePagesParameters(t *testing.T) {
+	hugepages := corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{"hugepages-2Mi": resource.MustParse("100Mi")},
+	}
+
+	t.Run("None", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{{Name: "one"}}
+
+		parameters := Parameters{Default: NewParameterSet()}
+		HugePagesParameters(cluster, &parameters)
+
+		assert.DeepEqual(t, parameters.Default.AsMap(), map[string]string{})
+	})
+
+	t.Run("Some", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{
+			{Name: "one", Resources: hugepages}, {Name: "two"},
+		}
+
+		parameters := Parameters{Default: NewParameterSet()}
+		HugePagesParameters(cluster, &parameters)
+
+		assert.DeepEqual(t, parameters.Default.AsMap(), map[string]string{})
+	})
+
+	t.Run("Every", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{
+			{Name: "one", Resources: hugepages}, {Name: "two", Resources: hugepages},
+		}
+
+		parameters := Parameters{Default: NewParameterSet()}
+		HugePagesParameters(cluster, &parameters)
+
+		assert.DeepEqual(t, parameters.Default.AsMap(), map[string]string{
+			"huge_pages": "on",
+		})
+	})
+}
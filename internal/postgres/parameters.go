@@ -17,6 +17,8 @@ package postgres
 
 import (
 	"strings"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 // NewParameters returns ParameterSets required by this package.
@@ -59,6 +61,15 @@ func NewParameters() Parameters {
 	return parameters
 }
 
+// SpecParameters adds the parameters in the spec of cluster to the defaults of
+// outParameters. They replace defaults chosen by PGO but not mandatory values.
+// Patroni applies its dynamic configuration over these.
+func SpecParameters(cluster *v1beta1.PostgresCluster, outParameters *Parameters) {
+	for name, value := range cluster.Spec.Config.Parameters {
+		outParameters.Default.Add(name, value.String())
+	}
+}
+
 // Parameters is a pairing of ParameterSets.
 type Parameters struct{ Mandatory, Default *ParameterSet }
 
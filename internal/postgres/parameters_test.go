@@ -19,6 +19,9 @@ import (
 	"testing"
 
 	"gotest.tools/v3/assert"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 func TestNewParameters(t *testing.T) {
@@ -68,3 +71,27 @@ func TestParameterSet(t *testing.T) {
 	ps2.Add("x", "n")
 	assert.Assert(t, ps2.Value("x") != ps.Value("x"))
 }
+
+func TestSpecParameters(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+
+	parameters := NewParameters()
+	SpecParameters(cluster, &parameters)
+	assert.DeepEqual(t, parameters.Default.AsMap(), NewParameters().Default.AsMap())
+
+	cluster.Spec.Config.Parameters = map[string]intstr.IntOrString{
+		"jit":             intstr.FromString("on"),
+		"max_connections": intstr.FromInt(200),
+		"wal_level":       intstr.FromString("replica"),
+	}
+
+	parameters = NewParameters()
+	SpecParameters(cluster, &parameters)
+
+	// Spec parameters replace defaults.
+	assert.Equal(t, parameters.Default.Value("jit"), "on")
+	assert.Equal(t, parameters.Default.Value("max_connections"), "200")
+
+	// Mandatory values are unchanged.
+	assert.Equal(t, parameters.Mandatory.Value("wal_level"), "logical")
+}
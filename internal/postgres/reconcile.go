@@ -20,6 +20,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/crunchydata/postgres-operator/internal/config"
 	"github.com/crunchydata/postgres-operator/internal/initialize"
@@ -42,6 +43,12 @@ func WALVolumeMount() corev1.VolumeMount {
 	return corev1.VolumeMount{Name: "postgres-wal", MountPath: walMountPath}
 }
 
+// TempVolumeMount returns the name and mount path of the volume for the
+// temporary files of PostgreSQL.
+func TempVolumeMount() corev1.VolumeMount {
+	return corev1.VolumeMount{Name: "postgres-temp", MountPath: tempMountPath}
+}
+
 // DownwardAPIVolumeMount returns the name and mount path of the DownwardAPI volume.
 func DownwardAPIVolumeMount() corev1.VolumeMount {
 	return corev1.VolumeMount{
@@ -217,11 +224,11 @@ func InstancePod(ctx context.Context,
 		downwardAPIVolume,
 	}
 
-	if len(inCluster.Spec.Config.Files) != 0 {
+	if files := ConfigFiles(inCluster); len(files) != 0 {
 		additionalConfigVolumeMount := AdditionalConfigVolumeMount()
 		additionalConfigVolume := corev1.Volume{Name: additionalConfigVolumeMount.Name}
 		additionalConfigVolume.Projected = &corev1.ProjectedVolumeSource{
-			Sources: append([]corev1.VolumeProjection{}, inCluster.Spec.Config.Files...),
+			Sources: files,
 		}
 		container.VolumeMounts = append(container.VolumeMounts, additionalConfigVolumeMount)
 		outInstancePod.Volumes = append(outInstancePod.Volumes, additionalConfigVolume)
@@ -277,3 +284,43 @@ func PodSecurityContext(cluster *v1beta1.PostgresCluster) *corev1.PodSecurityCon
 
 	return podSecurityContext
 }
+
+// AddTempVolumeToPod adds the volume for the temporary files of PostgreSQL to
+// pod and mounts it in the named containers. The volume is inTempVolume when
+// that is not nil, otherwise an emptyDir limited to spec.tempVolume.sizeLimit.
+// It does nothing when inCluster has no temp volume.
+func AddTempVolumeToPod(
+	inCluster *v1beta1.PostgresCluster, inTempVolume *corev1.PersistentVolumeClaim,
+	outPod *corev1.PodSpec, containerNames ...string,
+) {
+	if inCluster.Spec.TempVolume == nil {
+		return
+	}
+
+	tempVolumeMount := TempVolumeMount()
+	tempVolume := corev1.Volume{Name: tempVolumeMount.Name}
+	if inTempVolume != nil {
+		tempVolume.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{
+			ClaimName: inTempVolume.Name,
+		}
+	} else {
+		tempVolume.EmptyDir = &corev1.EmptyDirVolumeSource{
+			SizeLimit: inCluster.Spec.TempVolume.SizeLimit,
+		}
+	}
+
+	mount := sets.NewString(containerNames...)
+	for i := range outPod.InitContainers {
+		if mount.Has(outPod.InitContainers[i].Name) {
+			outPod.InitContainers[i].VolumeMounts = append(
+				outPod.InitContainers[i].VolumeMounts, tempVolumeMount)
+		}
+	}
+	for i := range outPod.Containers {
+		if mount.Has(outPod.Containers[i].Name) {
+			outPod.Containers[i].VolumeMounts = append(
+				outPod.Containers[i].VolumeMounts, tempVolumeMount)
+		}
+	}
+	outPod.Volumes = append(outPod.Volumes, tempVolume)
+}
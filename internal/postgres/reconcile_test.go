@@ -48,6 +48,16 @@ func TestWALVolumeMount(t *testing.T) {
 	})
 }
 
+func TestTempVolumeMount(t *testing.T) {
+	mount := TempVolumeMount()
+
+	assert.DeepEqual(t, mount, corev1.VolumeMount{
+		Name:      "postgres-temp",
+		MountPath: "/pgtmp",
+		ReadOnly:  false,
+	})
+}
+
 func TestDownwardAPIVolumeMount(t *testing.T) {
 	mount := DownwardAPIVolumeMount()
 
@@ -195,7 +205,7 @@ initContainers:
   - -ceu
   - --
   - |-
-    declare -r expected_major_version="$1" pgwal_directory="$2" pgbrLog_directory="$3"
+    declare -r expected_major_version="$1" pgwal_directory="$2" pgbrLog_directory="$3" pgtmp_directory="$4"
     results() { printf '::postgres-operator: %s::%s\n' "$@"; }
     safelink() (
       local desired="$1" name="$2" current
@@ -220,16 +230,23 @@ initContainers:
     results 'pgBackRest log directory' "${pgbrLog_directory}"
     install --directory --mode=0775 "${pgbrLog_directory}"
     install -D --mode=0600 -t "/tmp/replication" "/pgconf/tls/replication"/{tls.crt,tls.key,ca.crt}
+    [ -z "${pgtmp_directory}" ] || install --directory --mode=0700 "${pgtmp_directory}"
     [ -f "${postgres_data_directory}/PG_VERSION" ] || exit 0
     results 'data version' "${postgres_data_version:=$(< "${postgres_data_directory}/PG_VERSION")}"
     [ "${postgres_data_version}" = "${expected_major_version}" ]
     safelink "${pgwal_directory}" "${postgres_data_directory}/pg_wal"
     results 'wal directory' "$(realpath "${postgres_data_directory}/pg_wal")"
+    for tablespace in "${postgres_data_directory}"/pg_tblspc/*; do
+     [ -n "${pgtmp_directory}" ] && [ "$(readlink "${tablespace}")" = "${pgtmp_directory}" ] || continue
+     catalog_version=$(LC_ALL=C pg_controldata "${postgres_data_directory}" | sed -n 's/^Catalog version number: *//p')
+     install --directory --mode=0700 "${pgtmp_directory}/PG_${postgres_data_version}_${catalog_version}"
+    done
     rm -f "${postgres_data_directory}/recovery.signal"
   - startup
   - "11"
   - /pgdata/pg11_wal
   - /pgdata/pgbackrest/log
+  - ""
   env:
   - name: PGDATA
     value: /pgdata/pg11
@@ -412,7 +429,7 @@ volumes:
 
 		// Startup moves WAL files to data volume.
 		assert.DeepEqual(t, pod.InitContainers[0].Command[4:],
-			[]string{"startup", "11", "/pgdata/pg11_wal", "/pgdata/pgbackrest/log"})
+			[]string{"startup", "11", "/pgdata/pg11_wal", "/pgdata/pgbackrest/log", ""})
 	})
 
 	t.Run("WithAdditionalConfigFiles", func(t *testing.T) {
@@ -555,7 +572,72 @@ volumes:
 
 		// Startup moves WAL files to WAL volume.
 		assert.DeepEqual(t, pod.InitContainers[0].Command[4:],
-			[]string{"startup", "11", "/pgwal/pg11_wal", "/pgdata/pgbackrest/log"})
+			[]string{"startup", "11", "/pgwal/pg11_wal", "/pgdata/pgbackrest/log", ""})
+	})
+}
+
+func TestAddTempVolumeToPod(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	pod := func() *corev1.PodSpec {
+		return &corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "startup"}},
+			Containers:     []corev1.Container{{Name: "database"}, {Name: "other"}},
+		}
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		out := pod()
+		AddTempVolumeToPod(cluster, nil, out, "startup", "database")
+		assert.DeepEqual(t, out, pod())
+	})
+
+	t.Run("EmptyDir", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		limit := resource.MustParse("2Gi")
+		cluster.Spec.TempVolume = &v1beta1.PostgresTempVolumeSpec{SizeLimit: &limit}
+
+		out := pod()
+		AddTempVolumeToPod(cluster, nil, out, "startup", "database")
+		assert.Assert(t, marshalMatches(out, `
+containers:
+- name: database
+  resources: {}
+  volumeMounts:
+  - mountPath: /pgtmp
+    name: postgres-temp
+- name: other
+  resources: {}
+initContainers:
+- name: startup
+  resources: {}
+  volumeMounts:
+  - mountPath: /pgtmp
+    name: postgres-temp
+volumes:
+- emptyDir:
+    sizeLimit: 2Gi
+  name: postgres-temp
+		`))
+	})
+
+	t.Run("PersistentVolumeClaim", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.TempVolume = &v1beta1.PostgresTempVolumeSpec{
+			VolumeClaimSpec: new(corev1.PersistentVolumeClaimSpec),
+		}
+
+		claim := new(corev1.PersistentVolumeClaim)
+		claim.Name = "tmpvol"
+
+		out := pod()
+		AddTempVolumeToPod(cluster, claim, out, "database")
+		assert.Assert(t, marshalMatches(out.Volumes, `
+- name: postgres-temp
+  persistentVolumeClaim:
+    claimName: tmpvol
+		`))
+		assert.Equal(t, len(out.InitContainers[0].VolumeMounts), 0)
+		assert.Equal(t, len(out.Containers[0].VolumeMounts), 1)
 	})
 }
 
@@ -0,0 +1,245 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// replicationInput returns SQL that fills a temporary table with the JSON of
+// each item in records.
+func replicationInput(records []map[string]interface{}) (*bytes.Buffer, error) {
+	var err error
+	var sql bytes.Buffer
+
+	// Prevent unexpected dereferences by emptying "search_path". The "pg_catalog"
+	// schema is still searched, and only temporary objects can be created.
+	// - https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-SEARCH-PATH
+	_, _ = sql.WriteString(`SET search_path TO '';`)
+
+	// Fill a temporary table with the JSON of the specifications.
+	// "\copy" reads from subsequent lines until the special line "\.".
+	// - https://www.postgresql.org/docs/current/app-psql.html#APP-PSQL-META-COMMANDS-COPY
+	_, _ = sql.WriteString(`
+CREATE TEMPORARY TABLE input (id serial, data json);
+\copy input (data) from stdin with (format text)
+`)
+	encoder := json.NewEncoder(&sql)
+	encoder.SetEscapeHTML(false)
+
+	for i := range records {
+		if err == nil {
+			err = encoder.Encode(records[i])
+		}
+	}
+	_, _ = sql.WriteString(`\.` + "\n")
+
+	return &sql, err
+}
+
+// replicationDatabases returns the JSON of a sorted list of the distinct
+// values in names.
+func replicationDatabases(names []string) (string, error) {
+	databases := sets.NewString(names...).List()
+	b, err := json.Marshal(databases)
+	return string(b), err
+}
+
+// The query that returns the existing databases listed in the "databases"
+// variable.
+const replicationDatabasesQuery = `SELECT datname FROM pg_catalog.pg_database` +
+	` WHERE datname IN (SELECT pg_catalog.json_array_elements_text(:'databases'))`
+
+// WritePublicationsInPostgreSQL calls exec to create publications that do not
+// exist in PostgreSQL. Once they exist, it updates the tables of those that
+// publish specific tables. The databases must already exist.
+func WritePublicationsInPostgreSQL(
+	ctx context.Context, exec Executor, publications []v1beta1.PostgresPublicationSpec,
+) error {
+	log := logging.FromContext(ctx)
+
+	names := make([]string, 0, len(publications))
+	records := make([]map[string]interface{}, 0, len(publications))
+	for i := range publications {
+		tables := publications[i].Tables
+
+		// An empty list of tables means all tables.
+		if len(tables) == 0 {
+			tables = nil
+		}
+
+		names = append(names, string(publications[i].Database))
+		records = append(records, map[string]interface{}{
+			"database":    string(publications[i].Database),
+			"publication": string(publications[i].Name),
+			"tables":      tables,
+		})
+	}
+
+	databases, err := replicationDatabases(names)
+	sql, err2 := replicationInput(records)
+	if err == nil {
+		err = err2
+	}
+
+	// Create publications that do not already exist in the current database.
+	// Publications without tables include all tables in the database.
+	// - https://www.postgresql.org/docs/current/sql-createpublication.html
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.format('CREATE PUBLICATION %I %s',
+       pg_catalog.json_extract_path_text(input.data, 'publication'),
+       CASE WHEN pg_catalog.json_typeof(
+                 pg_catalog.json_extract_path(input.data, 'tables')) = 'array'
+            THEN '' ELSE 'FOR ALL TABLES' END)
+  FROM input
+ WHERE pg_catalog.json_extract_path_text(input.data, 'database') = pg_catalog.current_database()
+   AND NOT EXISTS (
+       SELECT 1 FROM pg_catalog.pg_publication
+       WHERE pubname = pg_catalog.json_extract_path_text(input.data, 'publication'))
+ ORDER BY input.id
+\gexec
+`)
+
+	// Set the tables of publications that publish specific tables. Table names
+	// are resolved while "search_path" is empty, so they must be qualified.
+	// - https://www.postgresql.org/docs/current/sql-alterpublication.html
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.format('ALTER PUBLICATION %I SET TABLE %s',
+       pg_catalog.json_extract_path_text(input.data, 'publication'),
+       (SELECT pg_catalog.string_agg(tables.name::regclass::text, ', ')
+          FROM pg_catalog.json_array_elements_text(
+               pg_catalog.json_extract_path(input.data, 'tables')) AS tables (name)))
+  FROM input
+  JOIN pg_catalog.pg_publication
+    ON pubname = pg_catalog.json_extract_path_text(input.data, 'publication')
+ WHERE pg_catalog.json_extract_path_text(input.data, 'database') = pg_catalog.current_database()
+   AND pg_catalog.json_typeof(pg_catalog.json_extract_path(input.data, 'tables')) = 'array'
+   AND NOT puballtables
+ ORDER BY input.id
+\gexec
+`)
+
+	stdout, stderr, err2 := exec.ExecInDatabasesFromQuery(ctx,
+		replicationDatabasesQuery, sql.String(),
+		map[string]string{
+			"databases":     databases,
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+		})
+	if err == nil {
+		err = err2
+	}
+
+	log.V(1).Info("wrote PostgreSQL publications", "stdout", stdout, "stderr", stderr)
+
+	return err
+}
+
+// WriteSubscriptionsInPostgreSQL calls exec to create subscriptions that do
+// not exist in PostgreSQL. Once they exist, it updates their connection strings
+// and publications. The connections map holds the connection string of each
+// subscription by name; subscriptions without one are skipped. The databases
+// must already exist.
+func WriteSubscriptionsInPostgreSQL(
+	ctx context.Context, exec Executor,
+	subscriptions []v1beta1.PostgresSubscriptionSpec, connections map[string]string,
+) error {
+	log := logging.FromContext(ctx)
+
+	names := make([]string, 0, len(subscriptions))
+	records := make([]map[string]interface{}, 0, len(subscriptions))
+	for i := range subscriptions {
+		if connection, ok := connections[string(subscriptions[i].Name)]; ok {
+			names = append(names, string(subscriptions[i].Database))
+			records = append(records, map[string]interface{}{
+				"connection":   connection,
+				"database":     string(subscriptions[i].Database),
+				"publications": subscriptions[i].Publications,
+				"subscription": string(subscriptions[i].Name),
+			})
+		}
+	}
+
+	databases, err := replicationDatabases(names)
+	sql, err2 := replicationInput(records)
+	if err == nil {
+		err = err2
+	}
+
+	// Update the connection strings and publications of subscriptions that
+	// already exist in the current database. Setting publications refreshes
+	// the tables of the subscription.
+	// - https://www.postgresql.org/docs/current/sql-altersubscription.html
+	_, _ = sql.WriteString(`
+CREATE TEMPORARY TABLE existing AS
+SELECT input.id, pg_catalog.format('%I', subname) AS subscription,
+       (SELECT pg_catalog.string_agg(pg_catalog.quote_ident(publications.name), ', ')
+          FROM pg_catalog.json_array_elements_text(
+               pg_catalog.json_extract_path(input.data, 'publications')) AS publications (name)
+       ) AS publications,
+       pg_catalog.json_extract_path_text(input.data, 'connection') AS connection
+  FROM input
+  JOIN pg_catalog.pg_subscription
+    ON subname = pg_catalog.json_extract_path_text(input.data, 'subscription')
+   AND subdbid = (SELECT oid FROM pg_catalog.pg_database WHERE datname = pg_catalog.current_database())
+ WHERE pg_catalog.json_extract_path_text(input.data, 'database') = pg_catalog.current_database();
+
+SELECT pg_catalog.format('ALTER SUBSCRIPTION %s CONNECTION %L', subscription, connection),
+       pg_catalog.format('ALTER SUBSCRIPTION %s SET PUBLICATION %s', subscription, publications)
+  FROM existing ORDER BY id
+\gexec
+`)
+
+	// Create subscriptions that do not already exist in the current database.
+	// PostgreSQL creates a replication slot of the same name on the publisher
+	// and copies existing data in the published tables.
+	// - https://www.postgresql.org/docs/current/sql-createsubscription.html
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.format('CREATE SUBSCRIPTION %I CONNECTION %L PUBLICATION %s',
+       pg_catalog.json_extract_path_text(input.data, 'subscription'),
+       pg_catalog.json_extract_path_text(input.data, 'connection'),
+       (SELECT pg_catalog.string_agg(pg_catalog.quote_ident(publications.name), ', ')
+          FROM pg_catalog.json_array_elements_text(
+               pg_catalog.json_extract_path(input.data, 'publications')) AS publications (name)))
+  FROM input
+ WHERE pg_catalog.json_extract_path_text(input.data, 'database') = pg_catalog.current_database()
+   AND input.id NOT IN (SELECT id FROM existing)
+ ORDER BY input.id
+\gexec
+`)
+
+	stdout, stderr, err2 := exec.ExecInDatabasesFromQuery(ctx,
+		replicationDatabasesQuery, sql.String(),
+		map[string]string{
+			"databases":     databases,
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+		})
+	if err == nil {
+		err = err2
+	}
+
+	log.V(1).Info("wrote PostgreSQL subscriptions", "stdout", stdout, "stderr", stderr)
+
+	return err
+}
@@ -0,0 +1,69 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+)
+
+// WriteTempTablespaceInPostgreSQL calls exec to create TempTablespace on the
+// temp volume when create is true and to drop it otherwise. Every role may
+// put temporary files in the tablespace.
+func WriteTempTablespaceInPostgreSQL(
+	ctx context.Context, exec Executor, create bool,
+) error {
+	log := logging.FromContext(ctx)
+
+	// Prevent unexpected dereferences by emptying "search_path". The "pg_catalog"
+	// schema is still searched, and only temporary objects can be created.
+	// - https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-SEARCH-PATH
+	sql := `SET search_path TO '';`
+
+	// CREATE TABLESPACE and DROP TABLESPACE cannot run inside a transaction
+	// block, so execute them with "\gexec" rather than in a DO block.
+	// - https://www.postgresql.org/docs/current/sql-createtablespace.html
+	// - https://www.postgresql.org/docs/current/sql-droptablespace.html
+	if create {
+		sql += `
+SELECT pg_catalog.format('CREATE TABLESPACE %I LOCATION %L', :'name', :'location')
+ WHERE NOT EXISTS (SELECT 1 FROM pg_catalog.pg_tablespace WHERE spcname = :'name')
+\gexec
+GRANT CREATE ON TABLESPACE :"name" TO PUBLIC;
+`
+	} else {
+		sql += `
+SELECT pg_catalog.format('DROP TABLESPACE %I', :'name')
+ WHERE EXISTS (SELECT 1 FROM pg_catalog.pg_tablespace WHERE spcname = :'name')
+\gexec
+`
+	}
+
+	stdout, stderr, err := exec.Exec(ctx, strings.NewReader(sql),
+		map[string]string{
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+
+			"location": tempTablespaceDirectory,
+			"name":     TempTablespace,
+		})
+
+	log.V(1).Info("wrote temporary tablespace", "stdout", stdout, "stderr", stderr)
+
+	return err
+}
@@ -0,0 +1,92 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestWriteTempTablespaceInPostgreSQL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Arguments", func(t *testing.T) {
+		expected := errors.New("pass-through")
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Assert(t, stdout != nil, "should capture stdout")
+			assert.Assert(t, stderr != nil, "should capture stderr")
+			assert.DeepEqual(t, command[len(command)-4:], []string{
+				"--set=ON_ERROR_STOP=on", "--set=QUIET=on",
+				"--set=location=/pgtmp/tablespace", "--set=name=pgo_temp",
+			})
+			return expected
+		}
+
+		assert.Equal(t, expected, WriteTempTablespaceInPostgreSQL(ctx, exec, true))
+	})
+
+	t.Run("Create", func(t *testing.T) {
+		calls := 0
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			calls++
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Equal(t, string(b), strings.TrimLeft(`
+SET search_path TO '';
+SELECT pg_catalog.format('CREATE TABLESPACE %I LOCATION %L', :'name', :'location')
+ WHERE NOT EXISTS (SELECT 1 FROM pg_catalog.pg_tablespace WHERE spcname = :'name')
+\gexec
+GRANT CREATE ON TABLESPACE :"name" TO PUBLIC;
+`, "\n"))
+			return nil
+		}
+
+		assert.NilError(t, WriteTempTablespaceInPostgreSQL(ctx, exec, true))
+		assert.Equal(t, calls, 1)
+	})
+
+	t.Run("Drop", func(t *testing.T) {
+		calls := 0
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			calls++
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Equal(t, string(b), strings.TrimLeft(`
+SET search_path TO '';
+SELECT pg_catalog.format('DROP TABLESPACE %I', :'name')
+ WHERE EXISTS (SELECT 1 FROM pg_catalog.pg_tablespace WHERE spcname = :'name')
+\gexec
+`, "\n"))
+			return nil
+		}
+
+		assert.NilError(t, WriteTempTablespaceInPostgreSQL(ctx, exec, false))
+		assert.Equal(t, calls, 1)
+	})
+}
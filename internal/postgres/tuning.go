@@ -0,0 +1,112 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgres
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// smallestResource returns the smallest amount of resource name requested by
+// any instance set of cluster. When an instance set has no request, its limit
+// is used. The result is nil when no instance set has either.
+func smallestResource(
+	cluster *v1beta1.PostgresCluster, name corev1.ResourceName,
+) *resource.Quantity {
+	var smallest *resource.Quantity
+	for i := range cluster.Spec.InstanceSets {
+		resources := cluster.Spec.InstanceSets[i].Resources
+
+		quantity, ok := resources.Requests[name]
+		if !ok {
+			quantity, ok = resources.Limits[name]
+		}
+		if ok && !quantity.IsZero() && (smallest == nil || quantity.Cmp(*smallest) < 0) {
+			smallest = &quantity
+		}
+	}
+	return smallest
+}
+
+// TuningParameters sets default PostgreSQL parameters sized to the memory
+// and CPU of the instances in cluster. Because parameters are the same on
+// every instance, they are calculated from the smallest instance set. Nothing
+// is set unless auto tuning is enabled and the instance sets have resources.
+// - https://www.postgresql.org/docs/current/runtime-config-resource.html
+// - https://wiki.postgresql.org/wiki/Tuning_Your_PostgreSQL_Server
+func TuningParameters(cluster *v1beta1.PostgresCluster, outParameters *Parameters) {
+	if cluster.Spec.AutoTuning == nil || !*cluster.Spec.AutoTuning {
+		return
+	}
+
+	const MiB = 1 << 20
+
+	if memory := smallestResource(cluster, corev1.ResourceMemory); memory != nil {
+		total := memory.Value() / MiB
+
+		// Give a quarter of memory to PostgreSQL buffers and assume the kernel
+		// can cache most of the rest.
+		shared := total / 4
+		outParameters.Default.Add("shared_buffers", fmt.Sprintf("%dMB", max64(shared, 16)))
+		outParameters.Default.Add("effective_cache_size", fmt.Sprintf("%dMB", max64(total*3/4, 16)))
+
+		// Maintenance operations like VACUUM and CREATE INDEX run one at a time
+		// per session; PostgreSQL uses at most 2GB for most of them.
+		outParameters.Default.Add("maintenance_work_mem",
+			fmt.Sprintf("%dMB", min64(max64(total/16, 64), 2048)))
+
+		// Divide the remaining memory among the default 100 connections, each
+		// of which may run a few sort or hash operations at once. Never go
+		// below the PostgreSQL default of 4MB.
+		outParameters.Default.Add("work_mem",
+			fmt.Sprintf("%dMB", max64((total-shared)/(100*3), 4)))
+	}
+
+	if cpu := smallestResource(cluster, corev1.ResourceCPU); cpu != nil {
+		// Round down to whole cores; leave the PostgreSQL defaults alone for
+		// instances with less than two.
+		if cores := cpu.MilliValue() / 1000; cores >= 2 {
+			gather := min64((cores+1)/2, 4)
+
+			outParameters.Default.Add("max_worker_processes", fmt.Sprint(max64(cores, 8)))
+			outParameters.Default.Add("max_parallel_workers", fmt.Sprint(cores))
+			outParameters.Default.Add("max_parallel_workers_per_gather", fmt.Sprint(gather))
+
+			// PostgreSQL 11 introduced parallel maintenance, e.g. CREATE INDEX.
+			if cluster.Spec.PostgresVersion >= 11 {
+				outParameters.Default.Add("max_parallel_maintenance_workers", fmt.Sprint(gather))
+			}
+		}
+	}
+}
+
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
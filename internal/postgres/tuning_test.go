@@ -0,0 +1,117 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgres
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestTuningParameters(t *testing.T) {
+	t.Run("NoResources", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.AutoTuning = initialize.Bool(true)
+		cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{{Name: "one"}}
+
+		parameters := Parameters{Default: NewParameterSet()}
+		TuningParameters(cluster, &parameters)
+
+		assert.DeepEqual(t, parameters.Default.AsMap(), map[string]string{})
+	})
+
+	t.Run("Smallest", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.AutoTuning = initialize.Bool(true)
+		cluster.Spec.PostgresVersion = 14
+		cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{
+			{Name: "big", Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("16"),
+					corev1.ResourceMemory: resource.MustParse("64Gi"),
+				},
+			}},
+			{Name: "limits", Resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("4500m"),
+					corev1.ResourceMemory: resource.MustParse("8Gi"),
+				},
+			}},
+			{Name: "none"},
+		}
+
+		parameters := Parameters{Default: NewParameterSet()}
+		TuningParameters(cluster, &parameters)
+
+		assert.DeepEqual(t, parameters.Default.AsMap(), map[string]string{
+			"shared_buffers":       "2048MB",
+			"effective_cache_size": "6144MB",
+			"maintenance_work_mem": "512MB",
+			"work_mem":             "20MB",
+
+			"max_worker_processes":             "8",
+			"max_parallel_workers":             "4",
+			"max_parallel_workers_per_gather":  "2",
+			"max_parallel_maintenance_workers": "2",
+		})
+	})
+
+	t.Run("Small", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.AutoTuning = initialize.Bool(true)
+		cluster.Spec.PostgresVersion = 10
+		cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{
+			{Name: "small", Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("1"),
+					corev1.ResourceMemory: resource.MustParse("256Mi"),
+				},
+			}},
+		}
+
+		parameters := Parameters{Default: NewParameterSet()}
+		TuningParameters(cluster, &parameters)
+
+		assert.DeepEqual(t, parameters.Default.AsMap(), map[string]string{
+			"shared_buffers":       "64MB",
+			"effective_cache_size": "192MB",
+			"maintenance_work_mem": "64MB",
+			"work_mem":             "4MB",
+		})
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{
+			{Name: "any", Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("8"),
+					corev1.ResourceMemory: resource.MustParse("16Gi"),
+				},
+			}},
+		}
+
+		parameters := Parameters{Default: NewParameterSet()}
+		TuningParameters(cluster, &parameters)
+
+		assert.DeepEqual(t, parameters.Default.AsMap(), map[string]string{})
+	})
+}
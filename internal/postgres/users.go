@@ -19,6 +19,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
 
 	"github.com/crunchydata/postgres-operator/internal/logging"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
@@ -26,8 +30,8 @@ import (
 
 // WriteUsersInPostgreSQL calls exec to create users that do not exist in
 // PostgreSQL. Once they exist, it updates their options and passwords and
-// grants them access to their specified databases. The databases must already
-// exist.
+// grants them access to their specified databases and membership in their
+// specified roles. The databases must already exist.
 func WriteUsersInPostgreSQL(
 	ctx context.Context, exec Executor,
 	users []v1beta1.PostgresUserSpec, verifiers map[string]string,
@@ -57,18 +61,21 @@ CREATE TEMPORARY TABLE input (id serial, data json);
 
 		databases := spec.Databases
 		options := spec.Options
+		roles := spec.Roles
 
 		// The "postgres" user must always be a superuser that can login to
 		// the "postgres" database.
 		if spec.Name == "postgres" {
 			databases = append(databases[:0:0], "postgres")
 			options = `LOGIN SUPERUSER`
+			roles = nil
 		}
 
 		if err == nil {
 			err = encoder.Encode(map[string]interface{}{
 				"databases": databases,
 				"options":   options,
+				"roles":     roles,
 				"username":  spec.Name,
 				"verifier":  verifiers[string(spec.Name)],
 			})
@@ -96,13 +103,17 @@ SELECT pg_catalog.format('CREATE USER %I',
 `)
 
 	// Set any options from the specification. Validation ensures that the value
-	// does not contain semicolons.
+	// does not contain semicolons. Users without a verifier keep the password
+	// they already have.
 	// - https://www.postgresql.org/docs/current/sql-alterrole.html
 	_, _ = sql.WriteString(`
-SELECT pg_catalog.format('ALTER ROLE %I WITH %s PASSWORD %L',
+SELECT pg_catalog.format('ALTER ROLE %I WITH %s %s',
        pg_catalog.json_extract_path_text(input.data, 'username'),
        pg_catalog.json_extract_path_text(input.data, 'options'),
-       pg_catalog.json_extract_path_text(input.data, 'verifier'))
+       CASE WHEN pg_catalog.json_extract_path_text(input.data, 'verifier') <> ''
+       THEN pg_catalog.format('PASSWORD %L',
+            pg_catalog.json_extract_path_text(input.data, 'verifier'))
+       ELSE '' END)
   FROM input ORDER BY input.id
 \gexec
 `)
@@ -117,6 +128,22 @@ SELECT pg_catalog.format('GRANT ALL PRIVILEGES ON DATABASE %I TO %I',
        pg_catalog.json_extract_path_text(input.data, 'username'))
   FROM input ORDER BY input.id
 \gexec
+`)
+
+	// Grant membership in any specified roles that exist. Predefined roles
+	// such as "pg_monitor" let a user inspect and signal the sessions of
+	// others without being a superuser.
+	// - https://www.postgresql.org/docs/current/sql-grant.html
+	// - https://www.postgresql.org/docs/current/predefined-roles.html
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.format('GRANT %I TO %I', roles.name,
+       pg_catalog.json_extract_path_text(input.data, 'username'))
+  FROM input, pg_catalog.json_array_elements_text(
+       pg_catalog.json_extract_path(
+       pg_catalog.json_strip_nulls(input.data), 'roles')) AS roles (name)
+ WHERE EXISTS (SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = roles.name)
+ ORDER BY input.id
+\gexec
 `)
 
 	// Commit (finish) the transaction.
@@ -132,3 +159,56 @@ SELECT pg_catalog.format('GRANT ALL PRIVILEGES ON DATABASE %I TO %I',
 
 	return err
 }
+
+// PasswordExpirationsInPostgreSQL calls exec to find when the passwords of
+// users expire. Users whose passwords do not expire are omitted.
+// - https://www.postgresql.org/docs/current/sql-createrole.html
+func PasswordExpirationsInPostgreSQL(
+	ctx context.Context, exec Executor, users []v1beta1.PostgresUserSpec,
+) (map[string]time.Time, error) {
+	log := logging.FromContext(ctx)
+
+	names := make([]string, 0, len(users))
+	for i := range users {
+		names = append(names, string(users[i].Name))
+	}
+	encoded, err := json.Marshal(names)
+
+	// Print one JSON object of user names and expirations in seconds since the
+	// Unix epoch without any headers or alignment.
+	// - https://www.postgresql.org/docs/current/app-psql.html#APP-PSQL-META-COMMAND-PSET
+	const sql = `\pset tuples_only on
+\pset format unaligned
+SELECT COALESCE(pg_catalog.json_object_agg(rolname,
+       pg_catalog.date_part('epoch', rolvaliduntil)), '{}')
+  FROM pg_catalog.pg_roles
+ WHERE rolvaliduntil IS NOT NULL AND rolvaliduntil <> 'infinity'
+   AND rolname IN (
+       SELECT pg_catalog.json_array_elements_text(:'users'))`
+
+	var expirations map[string]time.Time
+	if err == nil {
+		var stdout, stderr string
+		stdout, stderr, err = exec.Exec(ctx, strings.NewReader(sql),
+			map[string]string{
+				"users":         string(encoded),
+				"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+				"QUIET":         "on", // Do not print successful statements to stdout.
+			})
+
+		log.V(1).Info("read PostgreSQL password expirations", "stdout", stdout, "stderr", stderr)
+
+		var seconds map[string]float64
+		if err == nil {
+			err = errors.WithStack(json.Unmarshal([]byte(stdout), &seconds))
+		}
+		if err == nil {
+			expirations = make(map[string]time.Time, len(seconds))
+			for name, value := range seconds {
+				expirations[name] = time.Unix(int64(value), 0).UTC()
+			}
+		}
+	}
+
+	return expirations, err
+}
@@ -21,6 +21,7 @@ import (
 	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"gotest.tools/v3/assert"
 
@@ -68,10 +69,13 @@ SELECT pg_catalog.format('CREATE USER %I',
  ORDER BY input.id
 \gexec
 
-SELECT pg_catalog.format('ALTER ROLE %I WITH %s PASSWORD %L',
+SELECT pg_catalog.format('ALTER ROLE %I WITH %s %s',
        pg_catalog.json_extract_path_text(input.data, 'username'),
        pg_catalog.json_extract_path_text(input.data, 'options'),
-       pg_catalog.json_extract_path_text(input.data, 'verifier'))
+       CASE WHEN pg_catalog.json_extract_path_text(input.data, 'verifier') <> ''
+       THEN pg_catalog.format('PASSWORD %L',
+            pg_catalog.json_extract_path_text(input.data, 'verifier'))
+       ELSE '' END)
   FROM input ORDER BY input.id
 \gexec
 
@@ -82,6 +86,15 @@ SELECT pg_catalog.format('GRANT ALL PRIVILEGES ON DATABASE %I TO %I',
        pg_catalog.json_extract_path_text(input.data, 'username'))
   FROM input ORDER BY input.id
 \gexec
+
+SELECT pg_catalog.format('GRANT %I TO %I', roles.name,
+       pg_catalog.json_extract_path_text(input.data, 'username'))
+  FROM input, pg_catalog.json_array_elements_text(
+       pg_catalog.json_extract_path(
+       pg_catalog.json_strip_nulls(input.data), 'roles')) AS roles (name)
+ WHERE EXISTS (SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = roles.name)
+ ORDER BY input.id
+\gexec
 COMMIT;`))
 			return nil
 		}
@@ -107,9 +120,10 @@ COMMIT;`))
 			assert.NilError(t, err)
 			assert.Assert(t, cmp.Contains(string(b), `
 \copy input (data) from stdin with (format text)
-{"databases":["db1"],"options":"","username":"user-no-options","verifier":""}
-{"databases":null,"options":"some options here","username":"user-no-databases","verifier":""}
-{"databases":null,"options":"","username":"user-with-verifier","verifier":"some$verifier"}
+{"databases":["db1"],"options":"","roles":null,"username":"user-no-options","verifier":""}
+{"databases":null,"options":"some options here","roles":null,"username":"user-no-databases","verifier":""}
+{"databases":null,"options":"","roles":null,"username":"user-with-verifier","verifier":"some$verifier"}
+{"databases":null,"options":"","roles":["pg_monitor","pg_signal_backend"],"username":"user-with-roles","verifier":""}
 \.
 `))
 			return nil
@@ -128,6 +142,10 @@ COMMIT;`))
 				{
 					Name: "user-with-verifier",
 				},
+				{
+					Name:  "user-with-roles",
+					Roles: []v1beta1.PostgresIdentifier{"pg_monitor", "pg_signal_backend"},
+				},
 			},
 			map[string]string{
 				"no-user":            "ignored",
@@ -148,7 +166,7 @@ COMMIT;`))
 			assert.NilError(t, err)
 			assert.Assert(t, cmp.Contains(string(b), `
 \copy input (data) from stdin with (format text)
-{"databases":["postgres"],"options":"LOGIN SUPERUSER","username":"postgres","verifier":"allowed"}
+{"databases":["postgres"],"options":"LOGIN SUPERUSER","roles":null,"username":"postgres","verifier":"allowed"}
 \.
 `))
 			return nil
@@ -160,6 +178,7 @@ COMMIT;`))
 					Name:      "postgres",
 					Databases: []v1beta1.PostgresIdentifier{"all", "ignored"},
 					Options:   "NOLOGIN CONNECTION LIMIT 0",
+					Roles:     []v1beta1.PostgresIdentifier{"ignored"},
 				},
 			},
 			map[string]string{
@@ -169,3 +188,56 @@ COMMIT;`))
 		assert.Equal(t, calls, 1)
 	})
 }
+
+func TestPasswordExpirationsInPostgreSQL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Arguments", func(t *testing.T) {
+		expected := errors.New("pass-through")
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Assert(t, stdout != nil, "should capture stdout")
+			assert.Assert(t, stderr != nil, "should capture stderr")
+			assert.Assert(t, cmp.Contains(command, `--set=users=[]`))
+			return expected
+		}
+
+		_, err := PasswordExpirationsInPostgreSQL(ctx, exec, nil)
+		assert.Equal(t, expected, err)
+	})
+
+	t.Run("Parse", func(t *testing.T) {
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, _ io.Writer, command ...string,
+		) error {
+			assert.Assert(t, cmp.Contains(command, `--set=users=["hippo","rhino"]`))
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, cmp.Contains(string(b), `rolvaliduntil`))
+
+			_, err = io.WriteString(stdout, `{ "rhino" : 1798761600 }`+"\n")
+			return err
+		}
+
+		expirations, err := PasswordExpirationsInPostgreSQL(ctx, exec,
+			[]v1beta1.PostgresUserSpec{{Name: "hippo"}, {Name: "rhino"}})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, expirations, map[string]time.Time{
+			"rhino": time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC),
+		})
+	})
+
+	t.Run("Unexpected", func(t *testing.T) {
+		exec := func(
+			_ context.Context, _ io.Reader, stdout, _ io.Writer, _ ...string,
+		) error {
+			_, err := io.WriteString(stdout, "nope")
+			return err
+		}
+
+		_, err := PasswordExpirationsInPostgreSQL(ctx, exec, nil)
+		assert.ErrorContains(t, err, "invalid")
+	})
+}
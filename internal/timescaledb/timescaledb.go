@@ -0,0 +1,102 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package timescaledb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+)
+
+// EnableInPostgreSQL installs or updates the timescaledb extension in every
+// database. When the extension is already installed, it is updated to the
+// version that is packaged with the running PostgreSQL image.
+// - https://docs.timescale.com/timescaledb/latest/how-to-guides/update-timescaledb/
+func EnableInPostgreSQL(ctx context.Context, exec postgres.Executor) error {
+	log := logging.FromContext(ctx)
+
+	stdout, stderr, err := exec.ExecInAllDatabases(ctx,
+		strings.Join([]string{
+			// TimescaleDB must be updated before anything else in the session
+			// loads its library, so find the databases that need an update
+			// using only the system catalogs.
+			`SELECT 'ALTER EXTENSION timescaledb UPDATE'`,
+			`  FROM pg_catalog.pg_extension`,
+			` WHERE extname = 'timescaledb'`,
+			`\gexec`,
+
+			// Quiet NOTICE messages from IF NOT EXISTS statements.
+			// - https://www.postgresql.org/docs/current/runtime-config-client.html
+			`SET client_min_messages = WARNING;`,
+			`CREATE EXTENSION IF NOT EXISTS timescaledb;`,
+		}, "\n"),
+		map[string]string{
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+		})
+
+	log.V(1).Info("enabled TimescaleDB", "stdout", stdout, "stderr", stderr)
+
+	return err
+}
+
+// PostgreSQLParameters sets the parameters required by TimescaleDB and some
+// defaults that suit time-series workloads.
+func PostgreSQLParameters(outParameters *postgres.Parameters) {
+
+	// Load the shared library when PostgreSQL starts.
+	// PostgreSQL must be restarted when changing this value.
+	// - https://docs.timescale.com/timescaledb/latest/how-to-guides/configuration/
+	shared := outParameters.Mandatory.Value("shared_preload_libraries")
+	if !strings.Contains(","+shared+",", ",timescaledb,") {
+		outParameters.Mandatory.Add("shared_preload_libraries",
+			strings.TrimPrefix(shared+",timescaledb", ","))
+	}
+
+	// TimescaleDB runs compression, retention, and continuous aggregate policies
+	// in background workers. Those come out of max_worker_processes along with
+	// parallel workers, so make room for both: background workers, parallel
+	// workers, and three more for the launcher and other extensions. This is
+	// calculated after, and never lowers, the default set by tuning.
+	// - https://docs.timescale.com/timescaledb/latest/how-to-guides/configuration/about-configuration/#workers
+	const background = 8
+	parallel := defaultInt(outParameters.Default, "max_parallel_workers", 8)
+	workers := background + parallel + 3
+	if tuned := defaultInt(outParameters.Default, "max_worker_processes", 0); tuned > workers {
+		workers = tuned
+	}
+	outParameters.Default.Add("timescaledb.max_background_workers", fmt.Sprint(background))
+	outParameters.Default.Add("max_worker_processes", fmt.Sprint(workers))
+
+	// Do not send telemetry from inside the cluster.
+	// - https://docs.timescale.com/timescaledb/latest/how-to-guides/configuration/telemetry/
+	outParameters.Default.Add("timescaledb.telemetry_level", "off")
+}
+
+// defaultInt returns the integer value of parameter name in parameters, or
+// fallback when it is not set or not an integer.
+func defaultInt(parameters *postgres.ParameterSet, name string, fallback int64) int64 {
+	if value, ok := parameters.Get(name); ok {
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return i
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,234 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ServiceAccountTokenPath is where Kubernetes mounts the token of the service
+// account running the operator.
+const ServiceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// HTTPClient sends requests to Vault.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client reads secrets from a HashiCorp Vault server after logging in using
+// the Kubernetes auth method.
+// - https://www.vaultproject.io/api-docs/auth/kubernetes
+// - https://www.vaultproject.io/api-docs/secret/kv/kv-v2
+type Client struct {
+	Address string
+	HTTP    HTTPClient
+}
+
+// NewClient returns a Client for the Vault server at address. When address is
+// empty, it comes from the VAULT_ADDR environment variable.
+func NewClient(address string) *Client {
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+	return &Client{
+		Address: strings.TrimSuffix(address, "/"),
+		HTTP:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Secret is a version of a KV version 2 secret.
+type Secret struct {
+	Data    map[string]string
+	Version int64
+}
+
+// do sends a request to Vault and decodes its JSON response into out.
+func (c *Client) do(
+	ctx context.Context, method, path, token string, body, out interface{},
+) error {
+	if c.Address == "" {
+		return fmt.Errorf("vault: no address")
+	}
+
+	var reader bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reader).Encode(body); err != nil {
+			return err
+		}
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, c.Address+path, &reader)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		request.Header.Set("X-Vault-Token", token)
+	}
+
+	response, err := c.HTTP.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("vault: %s %s: %s", method, path, response.Status)
+	}
+	return json.NewDecoder(response.Body).Decode(out)
+}
+
+// Login exchanges a Kubernetes service account token for a Vault token using
+// the role of the Kubernetes auth method mounted at authPath. It returns the
+// token and how long it lasts. Tokens that do not expire last zero.
+func (c *Client) Login(
+	ctx context.Context, authPath, role, jwt string,
+) (string, time.Duration, error) {
+	var response struct {
+		Auth struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int64  `json:"lease_duration"`
+		} `json:"auth"`
+	}
+
+	err := c.do(ctx, http.MethodPost,
+		"/v1/auth/"+strings.Trim(authPath, "/")+"/login", "",
+		map[string]string{"role": role, "jwt": jwt}, &response)
+
+	if err == nil && response.Auth.ClientToken == "" {
+		err = fmt.Errorf("vault: login returned no token")
+	}
+	return response.Auth.ClientToken,
+		time.Duration(response.Auth.LeaseDuration) * time.Second, err
+}
+
+// ReadKV returns the current version of the secret at path in the KV version 2
+// secrets engine mounted at mount.
+func (c *Client) ReadKV(ctx context.Context, token, mount, path string) (*Secret, error) {
+	var response struct {
+		Data struct {
+			Data     map[string]interface{} `json:"data"`
+			Metadata struct {
+				Version int64 `json:"version"`
+			} `json:"metadata"`
+		} `json:"data"`
+	}
+
+	err := c.do(ctx, http.MethodGet,
+		"/v1/"+strings.Trim(mount, "/")+"/data/"+strings.Trim(path, "/"), token,
+		nil, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := &Secret{
+		Data:    make(map[string]string, len(response.Data.Data)),
+		Version: response.Data.Metadata.Version,
+	}
+	for key, value := range response.Data.Data {
+		if s, ok := value.(string); ok {
+			secret.Data[key] = s
+		}
+	}
+	return secret, nil
+}
+
+// Session reads secrets from Vault as the service account running the
+// operator. It logs in once and keeps its token until the token expires.
+type Session struct {
+	*Client
+
+	// AuthPath is the mount path of the Kubernetes auth method, and Role is
+	// the role of that method to log in as.
+	AuthPath, Role string
+
+	// TokenPath is the file containing the service account token.
+	TokenPath string
+
+	mutex   sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewSession returns a Session for the Vault server at address that logs in
+// as role of the Kubernetes auth method mounted at authPath. When authPath is
+// empty, it is "kubernetes".
+func NewSession(address, authPath, role string) *Session {
+	if authPath == "" {
+		authPath = "kubernetes"
+	}
+	return &Session{
+		Client:    NewClient(address),
+		AuthPath:  authPath,
+		Role:      role,
+		TokenPath: ServiceAccountTokenPath,
+	}
+}
+
+// login returns the current Vault token, logging in when there is none or it
+// is about to expire.
+func (s *Session) login(ctx context.Context) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.token != "" && (s.expires.IsZero() || time.Now().Before(s.expires)) {
+		return s.token, nil
+	}
+
+	jwt, err := os.ReadFile(s.TokenPath)
+	if err != nil {
+		return "", err
+	}
+
+	token, lease, err := s.Client.Login(ctx, s.AuthPath, s.Role, string(jwt))
+	if err != nil {
+		return "", err
+	}
+
+	// Log in again before the token expires rather than when it does.
+	s.token, s.expires = token, time.Time{}
+	if lease > 0 {
+		s.expires = time.Now().Add(lease * 9 / 10)
+	}
+	return token, nil
+}
+
+// ReadKV returns the current version of the secret at path in the KV version 2
+// secrets engine mounted at mount. When the read fails, the token is discarded
+// in case it was revoked.
+func (s *Session) ReadKV(ctx context.Context, mount, path string) (*Secret, error) {
+	token, err := s.login(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := s.Client.ReadKV(ctx, token, mount, path)
+	if err != nil {
+		s.mutex.Lock()
+		if s.token == token {
+			s.token = ""
+		}
+		s.mutex.Unlock()
+	}
+	return secret, err
+}
@@ -0,0 +1,139 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestNewClient(t *testing.T) {
+	t.Setenv("VAULT_ADDR", "https://from-env:8200/")
+	assert.Equal(t, NewClient("").Address, "https://from-env:8200")
+	assert.Equal(t, NewClient("http://given").Address, "http://given")
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/k8s/login":
+			assert.Equal(t, r.Method, http.MethodPost)
+
+			var body map[string]string
+			assert.NilError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.DeepEqual(t, body, map[string]string{"role": "pgo", "jwt": "sa-token"})
+
+			_, _ = w.Write([]byte(`{"auth":{"client_token":"vault-token","lease_duration":3600}}`))
+
+		case "/v1/secret/data/postgres/hippo":
+			assert.Equal(t, r.Method, http.MethodGet)
+			assert.Equal(t, r.Header.Get("X-Vault-Token"), "vault-token")
+
+			_, _ = w.Write([]byte(`{"data":{
+				"data":{"password":"swordfish","number":5},
+				"metadata":{"version":3}
+			}}`))
+
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(server.URL)
+
+	token, lease, err := client.Login(ctx, "/k8s/", "pgo", "sa-token")
+	assert.NilError(t, err)
+	assert.Equal(t, token, "vault-token")
+	assert.Equal(t, lease, time.Hour)
+
+	secret, err := client.ReadKV(ctx, token, "secret", "/postgres/hippo")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, secret, &Secret{
+		Data:    map[string]string{"password": "swordfish"},
+		Version: 3,
+	})
+
+	_, err = client.ReadKV(ctx, token, "secret", "other")
+	assert.ErrorContains(t, err, "403 Forbidden")
+
+	_, _, err = NewClient(" ").Login(ctx, "k8s", "pgo", "")
+	assert.Assert(t, err != nil)
+}
+
+func TestSession(t *testing.T) {
+	ctx := context.Background()
+
+	var logins int
+	readable := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/kubernetes/login":
+			logins++
+			_, _ = w.Write([]byte(`{"auth":{"client_token":"vault-token","lease_duration":3600}}`))
+
+		case "/v1/secret/data/postgres/hippo":
+			if !readable {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":{"data":{"password":"swordfish"},"metadata":{"version":1}}}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	session := NewSession(server.URL, "", "pgo")
+	session.TokenPath = filepath.Join(t.TempDir(), "token")
+	assert.NilError(t, os.WriteFile(session.TokenPath, []byte("sa-token"), 0o600))
+
+	t.Run("CachesToken", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			secret, err := session.ReadKV(ctx, "secret", "postgres/hippo")
+			assert.NilError(t, err)
+			assert.Equal(t, secret.Data["password"], "swordfish")
+		}
+		assert.Equal(t, logins, 1)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		session.expires = time.Now().Add(-time.Second)
+
+		_, err := session.ReadKV(ctx, "secret", "postgres/hippo")
+		assert.NilError(t, err)
+		assert.Equal(t, logins, 2)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		readable = false
+		_, err := session.ReadKV(ctx, "secret", "postgres/hippo")
+		assert.ErrorContains(t, err, "403 Forbidden")
+
+		readable = true
+		_, err = session.ReadKV(ctx, "secret", "postgres/hippo")
+		assert.NilError(t, err)
+		assert.Equal(t, logins, 3)
+	})
+}
@@ -0,0 +1,58 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1beta1
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// CertManagerSpec defines certificates that cert-manager issues and renews
+// for PostgreSQL.
+// More info: https://cert-manager.io/docs/usage/certificate/
+type CertManagerSpec struct {
+	// The issuer of the PostgreSQL server and replication client certificates.
+	// Both are issued by the same issuer so that each can verify the other.
+	// +required
+	IssuerRef CertManagerIssuerReference `json:"issuerRef"`
+
+	// How long issued certificates are valid. When omitted, the value comes
+	// from cert-manager, which defaults to 90 days.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// How long before a certificate expires that cert-manager renews it. When
+	// omitted, the value comes from cert-manager, which defaults to one third
+	// of the duration.
+	// +optional
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+}
+
+// CertManagerIssuerReference identifies a cert-manager issuer.
+// More info: https://cert-manager.io/docs/concepts/issuer/
+type CertManagerIssuerReference struct {
+	// The name of the issuer.
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Name string `json:"name"`
+
+	// The kind of the issuer, e.g. Issuer or ClusterIssuer.
+	// +kubebuilder:default=Issuer
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// The API group of the issuer.
+	// +kubebuilder:default=cert-manager.io
+	// +optional
+	Group string `json:"group,omitempty"`
+}
@@ -0,0 +1,53 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1beta1
+
+import "k8s.io/apimachinery/pkg/api/resource"
+
+// DiskUsageSpec defines how PGO reacts as the PostgreSQL data and WAL volumes
+// of a PostgresCluster fill up.
+type DiskUsageSpec struct {
+	// The percentage of a data or WAL volume in use at which PGO records a
+	// warning event and, when expansion is set, grows the volume. Defaults to 80.
+	// +kubebuilder:default=80
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	WarningPercent *int32 `json:"warningPercent,omitempty"`
+
+	// Grow data and WAL volumes past the warning percentage. The storage class
+	// of the volumes must allow volume expansion.
+	// More info: https://kubernetes.io/docs/concepts/storage/persistent-volumes/#expanding-persistent-volumes-claims
+	// +optional
+	Expansion *DiskExpansionSpec `json:"expansion,omitempty"`
+
+	// The percentage of a data or WAL volume of the primary in use at which PGO
+	// makes PostgreSQL read-only by setting "default_transaction_read_only".
+	// Writes are allowed again once usage drops below this percentage. When
+	// omitted, PostgreSQL is never made read-only.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	ReadOnlyPercent *int32 `json:"readOnlyPercent,omitempty"`
+}
+
+// DiskExpansionSpec defines how far PGO may grow PostgreSQL volumes.
+type DiskExpansionSpec struct {
+	// The largest size to which PGO may grow a volume. Each expansion adds half
+	// of the current size, up to this limit.
+	// +required
+	Limit resource.Quantity `json:"limit"`
+}
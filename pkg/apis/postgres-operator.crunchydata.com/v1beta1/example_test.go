@@ -0,0 +1,81 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1beta1_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// This example demonstrates how a program can take a one-off backup of a
+// PostgresCluster using a controller-runtime client and the kubeconfig of
+// whoever runs it.
+func Example_client() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	scheme := runtime.NewScheme()
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		panic(err)
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		panic(err)
+	}
+	cc, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		panic(err)
+	}
+
+	cluster := &v1beta1.PostgresCluster{}
+	err = cc.Get(ctx, client.ObjectKey{Namespace: "postgres-operator", Name: "hippo"}, cluster)
+	if apierrors.IsNotFound(err) {
+		fmt.Println("no such cluster")
+		return
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	if !meta.IsStatusConditionTrue(cluster.Status.Conditions, "Ready") {
+		fmt.Println("cluster is not ready")
+		return
+	}
+
+	// Ask for a backup by changing the backup annotation. The options of the
+	// backup are in spec.backups.pgbackrest.manual.
+	before := cluster.DeepCopy()
+	annotations := cluster.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations["postgres-operator.crunchydata.com/pgbackrest-backup"] = time.Now().Format(time.RFC3339)
+	cluster.SetAnnotations(annotations)
+
+	if err := cc.Patch(ctx, cluster, client.MergeFrom(before)); err != nil {
+		panic(err)
+	}
+}
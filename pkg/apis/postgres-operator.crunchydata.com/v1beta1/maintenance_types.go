@@ -0,0 +1,55 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1beta1
+
+// MaintenanceWindowSpec defines when PGO may make disruptive changes to a
+// PostgresCluster. Outside of its windows, restarts and rollouts of instances
+// wait until the next window opens.
+type MaintenanceWindowSpec struct {
+	// The time zone of every window, as a name from the IANA Time Zone database
+	// such as "America/New_York". Defaults to UTC.
+	// More info: https://www.iana.org/time-zones
+	// +kubebuilder:default=UTC
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// Periods of time during which disruptive changes may happen. Windows may
+	// overlap.
+	// +kubebuilder:validation:MinItems=1
+	// +listType=atomic
+	// +required
+	Windows []MaintenanceWindow `json:"windows"`
+}
+
+// MaintenanceWindow is a daily period of time during which PGO may make
+// disruptive changes to a PostgresCluster.
+type MaintenanceWindow struct {
+	// The days of the week on which this window starts. Defaults to every day.
+	// +listType=set
+	// +optional
+	Days []Weekday `json:"days,omitempty"`
+
+	// The time of day at which this window starts, in 24-hour "HH:MM" format.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	// +required
+	Start string `json:"start"`
+
+	// The time of day at which this window ends, in 24-hour "HH:MM" format.
+	// When this is not after start, the window ends on the following day.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	// +required
+	End string `json:"end"`
+}
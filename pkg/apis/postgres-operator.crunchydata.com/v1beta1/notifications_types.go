@@ -0,0 +1,38 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NotificationsSpec defines a webhook that PGO posts to about lifecycle events
+// of a PostgresCluster: finished backups, changes of primary, and volumes that
+// are filling up.
+type NotificationsSpec struct {
+	// A key of a Secret in the namespace of the cluster that contains the URL
+	// of the webhook.
+	// +required
+	URL corev1.SecretKeySelector `json:"url"`
+
+	// The shape of each message: "generic" posts a JSON object with the
+	// cluster, namespace, type, reason, message, and time of the event;
+	// "slack" posts a message for a Slack incoming webhook.
+	// +kubebuilder:validation:Enum={generic,slack}
+	// +kubebuilder:default=generic
+	// +optional
+	Format string `json:"format,omitempty"`
+}
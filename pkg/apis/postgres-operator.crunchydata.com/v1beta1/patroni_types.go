@@ -18,7 +18,8 @@ package v1beta1
 type PatroniSpec struct {
 	// Patroni dynamic configuration settings. Changes to this value will be
 	// automatically reloaded without validation. Changes to certain PostgreSQL
-	// parameters cause PostgreSQL to restart.
+	// parameters cause PostgreSQL to restart. PostgreSQL parameters here take
+	// precedence over those in spec.config.parameters.
 	// More info: https://patroni.readthedocs.io/en/latest/SETTINGS.html
 	// +optional
 	// +kubebuilder:pruning:PreserveUnknownFields
@@ -53,12 +54,34 @@ type PatroniSpec struct {
 	// +optional
 	Switchover *PatroniSwitchover `json:"switchover,omitempty"`
 
+	// ReplicaRemediation controls how replicas that cannot recover on their
+	// own are recreated from the primary or a backup.
+	// +optional
+	ReplicaRemediation *PatroniReplicaRemediation `json:"replicaRemediation,omitempty"`
+
 	// TODO(cbandy): Add UseConfigMaps bool, default false.
 	// TODO(cbandy): Allow other DCS: etcd, raft, etc?
 	// N.B. changing this will cause downtime.
 	// - https://patroni.readthedocs.io/en/latest/kubernetes.html
 }
 
+// PatroniReplicaRemediation defines when the data directory of a failed replica
+// is replaced by a fresh copy.
+type PatroniReplicaRemediation struct {
+	// Whether or not to reinitialize replicas that fail to start PostgreSQL or
+	// whose timeline diverged from the primary. Reinitializing a replica
+	// discards its data directory.
+	// +optional
+	// +kubebuilder:default=true
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// The minimum time to wait before reinitializing the same replica again.
+	// +optional
+	// +kubebuilder:default=600
+	// +kubebuilder:validation:Minimum=60
+	CooldownSeconds *int32 `json:"cooldownSeconds,omitempty"`
+}
+
 type PatroniSwitchover struct {
 
 	// Whether or not the operator should allow switchovers in a PostgresCluster
@@ -121,4 +144,9 @@ type PatroniStatus struct {
 	// Tracks the execution of the switchover requests.
 	// +optional
 	Switchover *string `json:"switchover,omitempty"`
+
+	// The name of the instance that Patroni most recently elected leader,
+	// i.e. the PostgreSQL primary.
+	// +optional
+	Leader string `json:"leader,omitempty"`
 }
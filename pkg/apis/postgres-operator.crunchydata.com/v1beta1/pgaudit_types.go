@@ -0,0 +1,88 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1beta1
+
+import corev1 "k8s.io/api/core/v1"
+
+// PGAuditSpec defines the configuration of pgAudit, which is always loaded
+// and installed in every database of a PostgresCluster.
+// More info: https://github.com/pgaudit/pgaudit
+type PGAuditSpec struct {
+	// Classes of statements to log for every role. This sets the "pgaudit.log"
+	// parameter. When omitted, the value comes from spec.patroni.dynamicConfiguration
+	// or the pgAudit default of "none".
+	// More info: https://github.com/pgaudit/pgaudit#pgauditlog
+	// +listType=set
+	// +optional
+	Log []PGAuditLogClass `json:"log,omitempty"`
+
+	// Classes of statements to log for particular roles. These take precedence
+	// over log for those roles. Roles that do not exist are skipped. Removing
+	// a role from this list does not reset its setting.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Roles []PGAuditRoleSpec `json:"roles,omitempty"`
+
+	// Ship audit log entries to another system using a Fluent Bit sidecar in
+	// each PostgreSQL instance Pod. Enabling this writes PostgreSQL logs to
+	// files on the data volume rather than to the container log.
+	// +optional
+	LogShipping *PGAuditLogShippingSpec `json:"logShipping,omitempty"`
+}
+
+// PGAuditLogClass is a class of statements that pgAudit can log.
+// More info: https://github.com/pgaudit/pgaudit#pgauditlog
+// +kubebuilder:validation:Enum={all,ddl,function,misc,misc_set,none,read,role,write}
+type PGAuditLogClass string
+
+// PGAuditRoleSpec defines the classes of statements pgAudit logs for a role.
+type PGAuditRoleSpec struct {
+	// The name of an existing role.
+	// +kubebuilder:validation:Type=string
+	// +required
+	Name PostgresIdentifier `json:"name"`
+
+	// Classes of statements to log for this role.
+	// +kubebuilder:validation:MinItems=1
+	// +listType=set
+	// +required
+	Log []PGAuditLogClass `json:"log"`
+}
+
+// PGAuditLogShippingSpec defines a Fluent Bit sidecar that sends audit log
+// entries to a Fluent Bit output.
+// More info: https://docs.fluentbit.io/manual/pipeline/outputs
+type PGAuditLogShippingSpec struct {
+	// Name of a container image that can run Fluent Bit. The image may also
+	// be set using the RELATED_IMAGE_FLUENTBIT environment variable.
+	// More info: https://kubernetes.io/docs/concepts/containers/images
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// The name of a Fluent Bit output plugin, e.g. "http", "forward", or "es".
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Output string `json:"output"`
+
+	// Properties of the output plugin, e.g. "host", "port", and "uri".
+	// +optional
+	Parameters map[string]string `json:"parameters,omitempty"`
+
+	// Resource requirements for the Fluent Bit container.
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
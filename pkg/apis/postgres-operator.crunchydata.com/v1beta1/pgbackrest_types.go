@@ -55,6 +55,15 @@ type PGBackRestJobStatus struct {
 	// The number of Pods for the manual backup Job that reached the "Failed" phase.
 	// +optional
 	Failed int32 `json:"failed,omitempty"`
+
+	// The current phase of the Job: Pending, Running, Succeeded, or Failed.
+	// +optional
+	// +kubebuilder:validation:Enum={Pending,Running,Succeeded,Failed}
+	Phase string `json:"phase,omitempty"`
+
+	// A human readable message describing why the Job failed.
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 type PGBackRestScheduledBackupStatus struct {
@@ -93,6 +102,15 @@ type PGBackRestScheduledBackupStatus struct {
 	// The number of Pods for the manual backup Job that reached the "Failed" phase.
 	// +optional
 	Failed int32 `json:"failed,omitempty"`
+
+	// The current phase of the Job: Pending, Running, Succeeded, or Failed.
+	// +optional
+	// +kubebuilder:validation:Enum={Pending,Running,Succeeded,Failed}
+	Phase string `json:"phase,omitempty"`
+
+	// A human readable message describing why the Job failed.
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // PGBackRestArchive defines a pgBackRest archive configuration
@@ -140,6 +158,11 @@ type PGBackRestArchive struct {
 	// +optional
 	Manual *PGBackRestManualBackup `json:"manual,omitempty"`
 
+	// Defines details for a full pgBackRest backup taken when the cluster is
+	// deleted. Deletion waits until this backup is complete.
+	// +optional
+	FinalBackup *PGBackRestFinalBackup `json:"finalBackup,omitempty"`
+
 	// Defines details for performing an in-place restore using pgBackRest
 	// +optional
 	Restore *PGBackRestRestore `json:"restore,omitempty"`
@@ -171,6 +194,26 @@ type BackupJobs struct {
 	// More info: https://kubernetes.io/docs/concepts/scheduling-eviction/pod-priority-preemption/
 	// +optional
 	PriorityClassName *string `json:"priorityClassName,omitempty"`
+
+	// The number of successful scheduled backup Jobs to keep for each schedule.
+	// Older Jobs and their Pods are deleted. Defaults to 3.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	SuccessfulJobsHistoryLimit *int32 `json:"successfulJobsHistoryLimit,omitempty"`
+
+	// The number of failed scheduled backup Jobs to keep for each schedule.
+	// Older Jobs and their Pods are deleted. Defaults to 1.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	FailedJobsHistoryLimit *int32 `json:"failedJobsHistoryLimit,omitempty"`
+
+	// The number of seconds a backup Job may run. When a backup takes longer,
+	// Kubernetes stops its Pod and the Job fails. PGO then stops the pgBackRest
+	// processes of the backup so they release their locks. Includes manual,
+	// scheduled and replica create backups.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
 }
 
 // PGBackRestManualBackup contains information that is used for creating a
@@ -187,6 +230,21 @@ type PGBackRestManualBackup struct {
 	Options []string `json:"options,omitempty"`
 }
 
+// PGBackRestFinalBackup defines a full backup that is taken before the instances
+// of a deleted cluster are stopped.
+type PGBackRestFinalBackup struct {
+	// The name of the pgBackRest repo to run the backup command against. This
+	// must be a cloud repo so that the backup outlives the cluster.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=^repo[1-4]
+	RepoName string `json:"repoName"`
+
+	// Command line options to include when running the pgBackRest backup command.
+	// https://pgbackrest.org/command.html#command-backup
+	// +optional
+	Options []string `json:"options,omitempty"`
+}
+
 // PGBackRestRepoHost represents a pgBackRest dedicated repository host
 type PGBackRestRepoHost struct {
 
@@ -262,6 +320,19 @@ type PGBackRestBackupSchedules struct {
 	// +optional
 	// +kubebuilder:validation:MinLength=6
 	Incremental *string `json:"incremental,omitempty"`
+
+	// The time zone of every schedule, as a name from the IANA Time Zone
+	// database such as "America/New_York". When omitted, schedules follow the
+	// time zone of the Kubernetes controller manager, which is usually UTC.
+	// Backups are suspended when Kubernetes does not support CronJob time zones.
+	// More info: https://www.iana.org/time-zones
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// Whether these schedules should stop starting backups, e.g. during a
+	// maintenance freeze. Backups that have already started continue.
+	// +optional
+	Suspend *bool `json:"suspend,omitempty"`
 }
 
 // PGBackRestStatus defines the status of pgBackRest within a PostgresCluster
@@ -421,7 +492,8 @@ type PGBackRestDataSource struct {
 	// +optional
 	Global map[string]string `json:"global,omitempty"`
 
-	// Defines a pgBackRest repository
+	// Defines a pgBackRest repository. A volume repository is read from the existing
+	// PVC defined in spec.dataSource.volumes.pgBackRestVolume.
 	// +kubebuilder:validation:Required
 	Repo PGBackRestRepo `json:"repo"`
 
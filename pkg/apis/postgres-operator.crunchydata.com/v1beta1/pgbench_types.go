@@ -0,0 +1,134 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PGBenchSpec defines a pgbench benchmark run against the primary instance.
+// A benchmark is started by setting the "pgbench" annotation on the
+// PostgresCluster to a unique identifier, e.g. a timestamp.
+// More info: https://www.postgresql.org/docs/current/pgbench.html
+type PGBenchSpec struct {
+	// The user that connects to PostgreSQL. It must be listed in the users of
+	// this cluster with at least one database. The benchmark runs in the first
+	// database of the user, and replaces any "pgbench_" tables there.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:Type=string
+	// +required
+	User PostgresIdentifier `json:"user"`
+
+	// Number of concurrent database clients.
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Clients *int32 `json:"clients,omitempty"`
+
+	// Number of worker threads within pgbench.
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Jobs *int32 `json:"jobs,omitempty"`
+
+	// Number of seconds to run the benchmark.
+	// +kubebuilder:default=60
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	DurationSeconds *int32 `json:"durationSeconds,omitempty"`
+
+	// The scale factor used to initialize the "pgbench_" tables. Each unit of
+	// scale is about 16MiB of data.
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Scale *int32 `json:"scale,omitempty"`
+
+	// Resource requirements for the pgbench container.
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// Default sets the default values for a pgbench benchmark.
+func (s *PGBenchSpec) Default() {
+	if s.Clients == nil {
+		s.Clients = new(int32)
+		*s.Clients = 1
+	}
+	if s.Jobs == nil {
+		s.Jobs = new(int32)
+		*s.Jobs = 1
+	}
+	if s.DurationSeconds == nil {
+		s.DurationSeconds = new(int32)
+		*s.DurationSeconds = 60
+	}
+	if s.Scale == nil {
+		s.Scale = new(int32)
+		*s.Scale = 1
+	}
+}
+
+// PGBenchStatus contains the results of recent benchmarks.
+type PGBenchStatus struct {
+	// Recent benchmarks, newest first.
+	// +kubebuilder:validation:MaxItems=10
+	// +optional
+	Results []PGBenchResult `json:"results,omitempty"`
+}
+
+// PGBenchResult records a single benchmark and its measurements.
+type PGBenchResult struct {
+	// A unique identifier for the benchmark as provided using the "pgbench"
+	// annotation when initiating a benchmark.
+	// +required
+	ID string `json:"id"`
+
+	// Specifies whether or not the Job is finished executing (does not indicate
+	// success or failure).
+	// +required
+	Finished bool `json:"finished"`
+
+	// Represents the time the benchmark Job was acknowledged by the Job controller.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// Represents the time the benchmark Job was determined by the Job controller
+	// to be completed. This field is only set if the benchmark completed successfully.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// The number of concurrent database clients.
+	// +optional
+	Clients int32 `json:"clients,omitempty"`
+
+	// The number of worker threads within pgbench.
+	// +optional
+	Jobs int32 `json:"jobs,omitempty"`
+
+	// The number of seconds the benchmark ran.
+	// +optional
+	DurationSeconds int32 `json:"durationSeconds,omitempty"`
+
+	// Transactions per second, excluding the time to establish connections.
+	// +optional
+	TPS string `json:"tps,omitempty"`
+
+	// Average latency of each transaction in milliseconds.
+	// +optional
+	LatencyAverage string `json:"latencyAverage,omitempty"`
+}
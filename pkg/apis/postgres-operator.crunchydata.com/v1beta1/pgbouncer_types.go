@@ -95,6 +95,17 @@ type PGBouncerPodSpec struct {
 	// +kubebuilder:validation:Minimum=1024
 	Port *int32 `json:"port,omitempty"`
 
+	// A strategic merge patch that PGO applies to the PgBouncer pod template
+	// every time it generates the Deployment. Use this for settings PGO does
+	// not otherwise expose, such as environment variables or security context
+	// fields. Changing this value causes PgBouncer to restart.
+	// More info: https://kubernetes.io/docs/tasks/manage-kubernetes-objects/update-api-object-kubectl-patch/
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	PodTemplateOverlay SchemalessObject `json:"podTemplateOverlay,omitempty"`
+
 	// Priority class name for the pgBouncer pod. Changing this value causes
 	// PostgreSQL to restart.
 	// More info: https://kubernetes.io/docs/concepts/scheduling-eviction/pod-priority-preemption/
@@ -15,6 +15,10 @@
 
 package v1beta1
 
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
 // PostgreSQL identifiers are limited in length but may contain any character.
 // More info: https://www.postgresql.org/docs/current/sql-syntax-lexical.html#SQL-SYNTAX-IDENTIFIERS
 //
@@ -30,6 +34,81 @@ type PostgresPasswordSpec struct {
 	// +kubebuilder:default=ASCII
 	// +kubebuilder:validation:Enum={ASCII,AlphaNumeric}
 	Type string `json:"type"`
+
+	// Read the password from HashiCorp Vault rather than generating one. When
+	// set, the password is not stored in the Secret of this user; only its
+	// verifier and details for connecting without it are. Applications should
+	// read the password from Vault, e.g. using the Vault Agent Injector.
+	// +optional
+	Vault *PostgresPasswordVaultSpec `json:"vault,omitempty"`
+
+	// Read the password from a key of an existing Secret rather than generating
+	// one, e.g. a Secret synced from AWS Secrets Manager by the External Secrets
+	// Operator or the Secrets Store CSI Driver. The Secret must be in the same
+	// namespace as the cluster. When set, the password is not copied into the
+	// Secret of this user; only its verifier and details for connecting without
+	// it are. Cannot be set together with vault.
+	// +optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// PostgresPasswordPolicySpec types.
+const (
+	PostgresPasswordCharactersDigit     = "Digit"
+	PostgresPasswordCharactersLowercase = "Lowercase"
+	PostgresPasswordCharactersSymbol    = "Symbol"
+	PostgresPasswordCharactersUppercase = "Uppercase"
+)
+
+// PostgresPasswordPolicySpec defines rules for the passwords of PostgreSQL
+// users. PGO checks passwords that it reads from Secrets or HashiCorp Vault
+// before changing them in PostgreSQL. A password that breaks a rule is not
+// used, and PGO records a PasswordPolicyViolation event.
+type PostgresPasswordPolicySpec struct {
+	// The minimum number of characters in a password. Generated passwords are
+	// at least 24 characters long, or longer to meet this.
+	// +kubebuilder:validation:Minimum=8
+	// +kubebuilder:validation:Maximum=1024
+	// +optional
+	MinLength *int32 `json:"minLength,omitempty"`
+
+	// Kinds of characters that every password must contain. Valid options are
+	// Lowercase, Uppercase, Digit, and Symbol.
+	// +listType=set
+	// +optional
+	RequiredCharacters []PostgresPasswordCharacters `json:"requiredCharacters,omitempty"`
+
+	// Words that passwords cannot contain, regardless of case. Passwords also
+	// cannot contain the name of their user.
+	// +listType=set
+	// +optional
+	ForbiddenWords []string `json:"forbiddenWords,omitempty"`
+}
+
+// +kubebuilder:validation:Enum={Lowercase,Uppercase,Digit,Symbol}
+type PostgresPasswordCharacters string
+
+// PostgresPasswordVaultSpec defines a password stored in a KV version 2
+// secrets engine of HashiCorp Vault. PGO logs in to the Vault server that it
+// is configured with using the Kubernetes auth method and its own service
+// account, and reads the password every few minutes. A new version of the
+// secret changes the password in PostgreSQL.
+// More info: https://www.vaultproject.io/docs/secrets/kv/kv-v2
+type PostgresPasswordVaultSpec struct {
+	// The mount path of the KV version 2 secrets engine.
+	// +kubebuilder:default=secret
+	// +optional
+	Mount string `json:"mount,omitempty"`
+
+	// The path of the secret within the secrets engine, e.g. "postgres/hippo".
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Path string `json:"path"`
+
+	// The key of the password in the secret.
+	// +kubebuilder:default=password
+	// +optional
+	Key string `json:"key,omitempty"`
 }
 
 // PostgresPasswordSpec types.
@@ -57,6 +136,15 @@ type PostgresUserSpec struct {
 	// +optional
 	Databases []PostgresIdentifier `json:"databases,omitempty"`
 
+	// Roles in which this user is a member, such as "pg_monitor" or
+	// "pg_signal_backend". Roles that do not exist are skipped. Removing a
+	// role from this list does NOT revoke membership. This field is ignored
+	// for the "postgres" user.
+	// More info: https://www.postgresql.org/docs/current/predefined-roles.html
+	// +listType=set
+	// +optional
+	Roles []PostgresIdentifier `json:"roles,omitempty"`
+
 	// ALTER ROLE options except for PASSWORD. This field is ignored for the
 	// "postgres" user.
 	// More info: https://www.postgresql.org/docs/current/role-attributes.html
@@ -68,3 +156,207 @@ type PostgresUserSpec struct {
 	// +optional
 	Password *PostgresPasswordSpec `json:"password,omitempty"`
 }
+
+type PostgresDatabaseSpec struct {
+	// The name of the database.
+	// +required
+	Name PostgresIdentifier `json:"name"`
+
+	// The user that owns this database and its schemas. It must be listed in
+	// the users of this cluster. When omitted, the "postgres" superuser owns
+	// them.
+	// +optional
+	Owner PostgresIdentifier `json:"owner,omitempty"`
+
+	// Schemas to create in this database. Removing a schema from this list
+	// does NOT drop it.
+	// +listType=set
+	// +optional
+	Schemas []PostgresIdentifier `json:"schemas,omitempty"`
+
+	// Users that can read every table in the "public" schema and in schemas
+	// of the owner, including tables and schemas that the owner creates later.
+	// They must be listed in the users of this cluster. Removing a user from
+	// this list does NOT revoke their access.
+	// More info: https://www.postgresql.org/docs/current/sql-alterdefaultprivileges.html
+	// +listType=set
+	// +optional
+	Readers []PostgresIdentifier `json:"readers,omitempty"`
+
+	// Users that can read and change every table, and use every sequence, in
+	// the schemas of this database, including those that the owner creates
+	// later. They must be listed in the users of this cluster. Removing a
+	// user from this list does NOT revoke their access.
+	// More info: https://www.postgresql.org/docs/current/sql-alterdefaultprivileges.html
+	// +listType=set
+	// +optional
+	Writers []PostgresIdentifier `json:"writers,omitempty"`
+}
+
+type PostgresExtensionSpec struct {
+	// The name of the extension, e.g. "pg_partman". The extension must be
+	// available in the PostgreSQL image.
+	// +required
+	Name PostgresIdentifier `json:"name"`
+
+	// Databases in which to create the extension. When empty, the extension
+	// is created in every database, including "template1" so that databases
+	// created later have it as well.
+	// +listType=set
+	// +optional
+	Databases []PostgresIdentifier `json:"databases,omitempty"`
+}
+
+type PostgresLogicalReplicationSpec struct {
+	// Publications to create inside PostgreSQL.
+	// More info: https://www.postgresql.org/docs/current/logical-replication-publication.html
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Publications []PostgresPublicationSpec `json:"publications,omitempty"`
+
+	// Subscriptions to create inside PostgreSQL.
+	// More info: https://www.postgresql.org/docs/current/logical-replication-subscription.html
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Subscriptions []PostgresSubscriptionSpec `json:"subscriptions,omitempty"`
+}
+
+type PostgresPublicationSpec struct {
+	// The name of this publication in PostgreSQL.
+	// +required
+	Name PostgresIdentifier `json:"name"`
+
+	// The database in which to create this publication. The database must
+	// already exist or be listed in the databases of a user.
+	// +required
+	Database PostgresIdentifier `json:"database"`
+
+	// Tables to publish. Each may be qualified by its schema, e.g. "public.abc".
+	// When empty, the publication includes all tables in the database.
+	// Changing between empty and non-empty has no effect after the publication
+	// is created.
+	// +listType=set
+	// +optional
+	Tables []string `json:"tables,omitempty"`
+}
+
+type PostgresSubscriptionSpec struct {
+	// The name of this subscription in PostgreSQL. PostgreSQL also uses this
+	// as the name of the replication slot it creates on the publisher.
+	// +required
+	Name PostgresIdentifier `json:"name"`
+
+	// The database in which to create this subscription. The database must
+	// already exist or be listed in the databases of a user.
+	// +required
+	Database PostgresIdentifier `json:"database"`
+
+	// A key of a Secret in this namespace that contains the connection string
+	// to the publisher. The "uri" key of a PostgresCluster user Secret, e.g.
+	// "rhino-pguser-logic", connects to the database of that user.
+	// More info: https://www.postgresql.org/docs/current/libpq-connect.html#LIBPQ-CONNSTRING
+	// +required
+	Connection corev1.SecretKeySelector `json:"connection"`
+
+	// The publications on the publisher to replicate.
+	// +kubebuilder:validation:MinItems=1
+	// +listType=set
+	// +required
+	Publications []PostgresIdentifier `json:"publications"`
+}
+
+// PostgresAuthenticationSpec defines how clients authenticate to PostgreSQL.
+type PostgresAuthenticationSpec struct {
+	// Whether or not to reject every TCP/IP connection that does not use TLS,
+	// regardless of rules. PGO provisions certificates for PostgreSQL and
+	// PgBouncer, and its default rules already require TLS; this prevents
+	// other rules from allowing connections without it.
+	// +optional
+	TLSOnly *bool `json:"tlsOnly,omitempty"`
+
+	// Rules for pg_hba.conf, checked in order after those required by PGO.
+	// Connections that match none of these rules are checked against
+	// spec.patroni.dynamicConfiguration or the PGO default: TLS with a password.
+	// More info: https://www.postgresql.org/docs/current/auth-pg-hba-conf.html
+	// +listType=atomic
+	// +optional
+	Rules []PostgresHBARuleSpec `json:"rules,omitempty"`
+
+	// Files that PostgreSQL needs for the gss authentication method. Use this
+	// together with rules that have that method.
+	// More info: https://www.postgresql.org/docs/current/gssapi-auth.html
+	// +optional
+	Kerberos *PostgresKerberosSpec `json:"kerberos,omitempty"`
+
+	// Files that PostgreSQL needs for the ldap authentication method. Use this
+	// together with rules that have that method.
+	// More info: https://www.postgresql.org/docs/current/auth-ldap.html
+	// +optional
+	LDAP *PostgresLDAPSpec `json:"ldap,omitempty"`
+}
+
+// PostgresKerberosSpec defines the files that PostgreSQL uses to authenticate
+// clients with Kerberos.
+type PostgresKerberosSpec struct {
+	// A key of a Secret in the namespace of the cluster that contains the
+	// keytab of the PostgreSQL service principal, usually "postgres/<host>".
+	// PGO mounts it at /etc/postgres/krb5.keytab and sets krb_server_keyfile.
+	// +required
+	Keytab corev1.SecretKeySelector `json:"keytab"`
+
+	// A key of a ConfigMap in the namespace of the cluster that contains the
+	// Kerberos configuration, krb5.conf. PGO mounts it at /etc/postgres/krb5.conf.
+	// +optional
+	Config *corev1.ConfigMapKeySelector `json:"config,omitempty"`
+}
+
+// PostgresLDAPSpec defines the files that PostgreSQL uses to authenticate
+// clients with LDAP.
+type PostgresLDAPSpec struct {
+	// A key of a Secret in the namespace of the cluster that contains the
+	// certificate authorities that PostgreSQL trusts when connecting to the
+	// LDAP server with ldaptls or ldaps. PGO mounts it at
+	// /etc/postgres/ldap-ca.crt and sets LDAPTLS_CACERT.
+	// +optional
+	CA *corev1.SecretKeySelector `json:"ca,omitempty"`
+}
+
+// PostgresHBARuleSpec defines a single record of pg_hba.conf.
+type PostgresHBARuleSpec struct {
+	// The kind of connection to match: "hostssl" matches TCP/IP with TLS,
+	// "hostnossl" matches TCP/IP without TLS, and "host" matches both.
+	// "hostgssenc" and "hostnogssenc" match TCP/IP with and without GSSAPI
+	// encryption; they require PostgreSQL 12 or later.
+	// +kubebuilder:default=hostssl
+	// +kubebuilder:validation:Enum={host,hostssl,hostnossl,hostgssenc,hostnogssenc}
+	// +optional
+	Connection string `json:"connection,omitempty"`
+
+	// The databases to match. When omitted, all databases match.
+	// +listType=set
+	// +optional
+	Databases []PostgresIdentifier `json:"databases,omitempty"`
+
+	// The users to match. When omitted, all users match.
+	// +listType=set
+	// +optional
+	Users []PostgresIdentifier `json:"users,omitempty"`
+
+	// The block of client IP addresses to match in CIDR notation, e.g.
+	// 10.0.0.0/8. When omitted, all addresses match.
+	// +kubebuilder:validation:Pattern=`^[0-9A-Fa-f.:]+/[0-9]{1,3}$`
+	// +optional
+	Address string `json:"address,omitempty"`
+
+	// The authentication method to use for matching connections.
+	// More info: https://www.postgresql.org/docs/current/auth-methods.html
+	// +kubebuilder:validation:Enum={cert,gss,ldap,md5,pam,password,radius,reject,scram-sha-256}
+	// +required
+	Method string `json:"method"`
+
+	// Options of the authentication method, e.g. clientcert or ldapserver.
+	// +optional
+	Options map[string]string `json:"options,omitempty"`
+}
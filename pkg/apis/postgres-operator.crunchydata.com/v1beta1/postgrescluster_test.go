@@ -21,12 +21,489 @@ import (
 	"testing"
 
 	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/yaml"
 )
 
 func TestPostgresClusterWebhooks(t *testing.T) {
 	var _ webhook.Defaulter = new(PostgresCluster)
+	var _ webhook.Validator = new(PostgresCluster)
+}
+
+func TestPostgresClusterValidate(t *testing.T) {
+	valid := func() *PostgresCluster {
+		var cluster PostgresCluster
+		cluster.Name = "hippo"
+		cluster.Spec.InstanceSets = []PostgresInstanceSetSpec{{Name: "one"}, {}}
+		cluster.Spec.Backups.PGBackRest.Repos = []PGBackRestRepo{{Name: "repo1"}}
+		return &cluster
+	}
+
+	assert.NilError(t, valid().ValidateCreate())
+	assert.NilError(t, valid().ValidateUpdate(valid()))
+	assert.NilError(t, valid().ValidateDelete())
+
+	t.Run("Update", func(t *testing.T) {
+		// A cluster created before a rule existed has a problem already.
+		old := valid()
+		old.Spec.Databases = []PostgresDatabaseSpec{{Name: "zoo", Owner: "hippo"}}
+
+		// Other changes are allowed.
+		cluster := old.DeepCopy()
+		cluster.Spec.InstanceSets[0].Replicas = new(int32)
+		assert.NilError(t, cluster.ValidateUpdate(old))
+
+		// The problem cannot change to another.
+		cluster.Spec.Databases[0].Owner = "rhino"
+		assert.ErrorContains(t, cluster.ValidateUpdate(old),
+			`spec.databases[0].owner: Not found: "rhino"`)
+
+		// New problems are refused.
+		cluster = old.DeepCopy()
+		cluster.Spec.Backups.PGBackRest.Repos = append(cluster.Spec.Backups.PGBackRest.Repos,
+			PGBackRestRepo{Name: "repo1"})
+		err := cluster.ValidateUpdate(old)
+		assert.Assert(t, apierrors.IsInvalid(err))
+		assert.ErrorContains(t, err, `spec.backups.pgbackrest.repos[1].name: Duplicate value`)
+		assert.Assert(t, !strings.Contains(err.Error(), "spec.databases"), "got %v", err)
+
+		// Nothing is refused during deletion.
+		cluster.DeletionTimestamp = new(metav1.Time)
+		assert.NilError(t, cluster.ValidateUpdate(old))
+	})
+
+	t.Run("DuplicateNames", func(t *testing.T) {
+		cluster := valid()
+		cluster.Spec.InstanceSets = append(cluster.Spec.InstanceSets,
+			PostgresInstanceSetSpec{Name: "one"})
+		cluster.Spec.Backups.PGBackRest.Repos = append(cluster.Spec.Backups.PGBackRest.Repos,
+			PGBackRestRepo{Name: "repo1"})
+
+		err := cluster.ValidateCreate()
+		assert.Assert(t, apierrors.IsInvalid(err))
+		assert.ErrorContains(t, err, `spec.instances[2].name: Duplicate value: "one"`)
+		assert.ErrorContains(t, err, `spec.backups.pgbackrest.repos[1].name: Duplicate value: "repo1"`)
+	})
+
+	t.Run("DefaultedNames", func(t *testing.T) {
+		cluster := valid()
+		cluster.Spec.InstanceSets = append(cluster.Spec.InstanceSets,
+			PostgresInstanceSetSpec{Name: "01"})
+
+		assert.ErrorContains(t, cluster.ValidateCreate(),
+			`spec.instances[2].name: Duplicate value: "01"`)
+	})
+
+	t.Run("MinAvailable", func(t *testing.T) {
+		intOrString := func(v intstr.IntOrString) *intstr.IntOrString { return &v }
+		three := int32(3)
+
+		cluster := valid()
+		cluster.Spec.InstanceSets[0].Replicas = &three
+		cluster.Spec.InstanceSets[0].MinAvailable = intOrString(intstr.FromString("51%"))
+		cluster.Spec.InstanceSets[1].MinAvailable = intOrString(intstr.FromInt(1))
+		assert.NilError(t, cluster.ValidateCreate())
+
+		cluster.Spec.InstanceSets[0].MinAvailable = intOrString(intstr.FromString("150%"))
+		cluster.Spec.InstanceSets[1].MinAvailable = intOrString(intstr.FromInt(2))
+		cluster.Spec.Proxy = &PostgresProxySpec{PGBouncer: &PGBouncerPodSpec{
+			MinAvailable: intOrString(intstr.FromString("two")),
+		}}
+
+		err := cluster.ValidateCreate()
+		assert.ErrorContains(t, err, `spec.instances[0].minAvailable: Invalid value: "150%"`)
+		assert.ErrorContains(t, err, `spec.instances[1].minAvailable: Invalid value: 2: must not exceed replicas (1)`)
+		assert.ErrorContains(t, err, `spec.proxy.pgBouncer.minAvailable: Invalid value: "two"`)
+	})
+
+	t.Run("GuaranteedQoS", func(t *testing.T) {
+		enabled := true
+
+		cluster := valid()
+		cluster.Spec.InstanceSets[0].GuaranteedQoS = &enabled
+		cluster.Spec.InstanceSets[0].Resources.Limits = corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("1Gi"),
+		}
+
+		err := cluster.ValidateCreate()
+		assert.ErrorContains(t, err, `spec.instances[0].resources.limits.cpu: Required value`)
+		assert.Assert(t, !strings.Contains(err.Error(), "limits.memory"))
+
+		cluster.Spec.InstanceSets[0].Resources.Limits[corev1.ResourceCPU] = resource.MustParse("1")
+		assert.NilError(t, cluster.ValidateCreate())
+	})
+
+	t.Run("HugePages", func(t *testing.T) {
+		cluster := valid()
+		cluster.Spec.InstanceSets[0].Resources.Limits = corev1.ResourceList{
+			"hugepages-2Mi": resource.MustParse("100Mi"),
+		}
+		assert.ErrorContains(t, cluster.ValidateCreate(),
+			`spec.instances[0].resources.limits.memory: Required value: huge pages require a CPU or memory`)
+
+		cluster.Spec.InstanceSets[0].Resources.Limits[corev1.ResourceMemory] = resource.MustParse("1Gi")
+		assert.NilError(t, cluster.ValidateCreate())
+
+		cluster.Spec.InstanceSets[0].Resources.Requests = corev1.ResourceList{
+			"hugepages-2Mi": resource.MustParse("50Mi"),
+		}
+		assert.ErrorContains(t, cluster.ValidateCreate(),
+			`spec.instances[0].resources.requests.hugepages-2Mi: Invalid value: "50Mi": must equal the limit`)
+
+		cluster.Spec.InstanceSets[0].Resources.Requests["hugepages-2Mi"] = resource.MustParse("100Mi")
+		assert.NilError(t, cluster.ValidateCreate())
+	})
+
+	t.Run("DedicatedCPUs", func(t *testing.T) {
+		enabled := true
+		cluster := valid()
+		cluster.Spec.DedicatedCPUs = &enabled
+		cluster.Spec.InstanceSets = cluster.Spec.InstanceSets[:1]
+		assert.ErrorContains(t, cluster.ValidateCreate(),
+			`spec.instances[0].resources.limits.cpu: Required value: required when dedicatedCPUs is enabled`)
+		assert.ErrorContains(t, cluster.ValidateCreate(),
+			`spec.instances[0].resources.limits.memory: Required value`)
+
+		cluster.Spec.InstanceSets[0].Resources.Limits = corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("1500m"),
+			corev1.ResourceMemory: resource.MustParse("1Gi"),
+		}
+		assert.ErrorContains(t, cluster.ValidateCreate(),
+			`spec.instances[0].resources.limits.cpu: Invalid value: "1500m": must be a whole number of CPUs`)
+
+		cluster.Spec.InstanceSets[0].Resources.Limits[corev1.ResourceCPU] = resource.MustParse("2")
+		assert.NilError(t, cluster.ValidateCreate())
+	})
+
+	t.Run("PodTemplateOverlay", func(t *testing.T) {
+		cluster := valid()
+		cluster.Spec.InstanceSets[0].PodTemplateOverlay = SchemalessObject{
+			"spec": map[string]interface{}{"hostname": "valid"},
+		}
+		assert.NilError(t, cluster.ValidateCreate())
+
+		cluster.Spec.InstanceSets[1].PodTemplateOverlay = SchemalessObject{
+			"spec": map[string]interface{}{"hostname": int64(5)},
+		}
+		assert.ErrorContains(t, cluster.ValidateCreate(),
+			`spec.instances[1].podTemplateOverlay: Invalid value`)
+	})
+
+	t.Run("ManualBackup", func(t *testing.T) {
+		cluster := valid()
+		cluster.Spec.Backups.PGBackRest.Manual = &PGBackRestManualBackup{
+			RepoName: "repo2",
+			Options:  []string{"--type=full", "--repo=1"},
+		}
+
+		err := cluster.ValidateCreate()
+		assert.ErrorContains(t, err, `spec.backups.pgbackrest.manual.repoName: Not found: "repo2"`)
+		assert.ErrorContains(t, err, `spec.backups.pgbackrest.manual.options[1]: Forbidden`)
+	})
+
+	t.Run("FinalBackup", func(t *testing.T) {
+		cluster := valid()
+		cluster.Spec.Backups.PGBackRest.Repos[0].Volume = &RepoPVC{}
+		cluster.Spec.Backups.PGBackRest.Repos = append(cluster.Spec.Backups.PGBackRest.Repos,
+			PGBackRestRepo{Name: "repo2", S3: &RepoS3{}})
+		cluster.Spec.Backups.PGBackRest.FinalBackup = &PGBackRestFinalBackup{
+			RepoName: "repo2", Options: []string{"--repo=1"},
+		}
+
+		err := cluster.ValidateCreate()
+		assert.ErrorContains(t, err, `spec.backups.pgbackrest.finalBackup.options[0]: Forbidden`)
+
+		cluster.Spec.Backups.PGBackRest.FinalBackup.Options = nil
+		assert.NilError(t, cluster.ValidateCreate())
+
+		cluster.Spec.Backups.PGBackRest.FinalBackup.RepoName = "repo1"
+		assert.ErrorContains(t, cluster.ValidateCreate(),
+			`spec.backups.pgbackrest.finalBackup.repoName: Invalid value: "repo1": must be a cloud repo`)
+
+		cluster.Spec.Backups.PGBackRest.FinalBackup.RepoName = "repo3"
+		assert.ErrorContains(t, cluster.ValidateCreate(),
+			`spec.backups.pgbackrest.finalBackup.repoName: Not found: "repo3"`)
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		cluster := valid()
+		cluster.Spec.Backups.PGBackRest.Restore = &PGBackRestRestore{
+			PostgresClusterDataSource: &PostgresClusterDataSource{
+				RepoName: "repo1",
+				Options:  []string{"--type=time", "--stanza=db"},
+			},
+		}
+
+		err := cluster.ValidateUpdate(valid())
+		assert.ErrorContains(t, err, `spec.backups.pgbackrest.restore.options[1]: Forbidden`)
+		assert.Assert(t, !strings.Contains(err.Error(), "repoName"))
+
+		// Another cluster can have repos that this one does not.
+		cluster.Spec.Backups.PGBackRest.Restore.ClusterName = "other"
+		cluster.Spec.Backups.PGBackRest.Restore.RepoName = "repo4"
+		cluster.Spec.Backups.PGBackRest.Restore.Options = nil
+		assert.NilError(t, cluster.ValidateUpdate(valid()))
+	})
+
+	t.Run("DataSource", func(t *testing.T) {
+		cluster := valid()
+		cluster.Spec.DataSource = &DataSource{
+			PostgresCluster: &PostgresClusterDataSource{
+				ClusterName: "other", RepoName: "repo1",
+				Options: []string{"--pg1-path=/tmp"},
+			},
+			PGBackRest: &PGBackRestDataSource{
+				Stanza:  "db",
+				Options: []string{"--link-map=pg_wal=/tmp"},
+			},
+		}
+
+		err := cluster.ValidateCreate()
+		assert.ErrorContains(t, err, `spec.dataSource.postgresCluster.options[0]: Forbidden`)
+		assert.ErrorContains(t, err, `spec.dataSource.pgbackrest.options[0]: Forbidden`)
+
+		t.Run("VolumeRepo", func(t *testing.T) {
+			cluster := valid()
+			cluster.Spec.DataSource = &DataSource{
+				PGBackRest: &PGBackRestDataSource{
+					Stanza: "db",
+					Repo:   PGBackRestRepo{Name: "repo1", Volume: &RepoPVC{}},
+				},
+			}
+
+			err := cluster.ValidateCreate()
+			assert.ErrorContains(t, err, `spec.dataSource.volumes.pgBackRestVolume: Required`)
+
+			cluster.Spec.DataSource.Volumes = &DataSourceVolumes{
+				PGBackRestVolume: &DataSourceVolume{PVCName: "hippo-repo1"},
+			}
+			assert.NilError(t, cluster.ValidateCreate())
+		})
+	})
+
+	t.Run("DeletionProtection", func(t *testing.T) {
+		cluster := valid()
+		cluster.Spec.DeletionProtection = new(bool)
+		assert.NilError(t, cluster.ValidateDelete())
+
+		*cluster.Spec.DeletionProtection = true
+		err := cluster.ValidateDelete()
+		assert.Assert(t, apierrors.IsForbidden(err))
+		assert.ErrorContains(t, err, "spec.deletionProtection")
+	})
+
+	t.Run("BackupSchedules", func(t *testing.T) {
+		full, zoned := "0 1 * * 0", "CRON_TZ=Asia/Tokyo 0 1 * * *"
+		cluster := valid()
+		cluster.Spec.Backups.PGBackRest.Repos[0].BackupSchedules = &PGBackRestBackupSchedules{
+			Full: &full, Incremental: &zoned,
+		}
+		assert.ErrorContains(t, cluster.ValidateCreate(),
+			`spec.backups.pgbackrest.repos[0].schedules.incremental: Invalid value: "CRON_TZ=Asia/Tokyo 0 1 * * *": cannot name a time zone`)
+
+		cluster.Spec.Backups.PGBackRest.Repos[0].BackupSchedules.Incremental = nil
+		assert.NilError(t, cluster.ValidateCreate())
+
+		cluster.Spec.Backups.PGBackRest.Repos[0].BackupSchedules.TimeZone = "America/New_York"
+		assert.NilError(t, cluster.ValidateCreate())
+
+		for _, zone := range []string{"Local", "Mars/Olympus_Mons"} {
+			cluster.Spec.Backups.PGBackRest.Repos[0].BackupSchedules.TimeZone = zone
+			assert.ErrorContains(t, cluster.ValidateCreate(),
+				`spec.backups.pgbackrest.repos[0].schedules.timeZone: Invalid value`)
+		}
+	})
+
+	t.Run("UptimeSchedule", func(t *testing.T) {
+		cluster := valid()
+		cluster.Spec.UptimeSchedule = &UptimeScheduleSpec{
+			Windows: []UptimeWindow{{Start: "08:00", End: "18:00"}},
+		}
+		assert.NilError(t, cluster.ValidateCreate())
+
+		cluster.Spec.UptimeSchedule.TimeZone = "America/New_York"
+		assert.NilError(t, cluster.ValidateCreate())
+
+		for _, zone := range []string{"Local", "Mars/Olympus_Mons"} {
+			cluster.Spec.UptimeSchedule.TimeZone = zone
+			assert.ErrorContains(t, cluster.ValidateCreate(),
+				`spec.uptimeSchedule.timeZone: Invalid value`)
+		}
+	})
+
+	t.Run("DiskUsage", func(t *testing.T) {
+		cluster := valid()
+		percent := func(v int32) *int32 { return &v }
+
+		cluster.Spec.DiskUsage = &DiskUsageSpec{ReadOnlyPercent: percent(95)}
+		assert.NilError(t, cluster.ValidateCreate())
+
+		cluster.Spec.DiskUsage.WarningPercent = percent(95)
+		assert.ErrorContains(t, cluster.ValidateCreate(),
+			`spec.diskUsage.readOnlyPercent: Invalid value: 95: must be greater than warningPercent`)
+
+		cluster.Spec.DiskUsage.ReadOnlyPercent = nil
+		assert.NilError(t, cluster.ValidateCreate())
+	})
+
+	t.Run("CertManager", func(t *testing.T) {
+		cluster := valid()
+		cluster.Spec.CertManager = &CertManagerSpec{
+			IssuerRef: CertManagerIssuerReference{Name: "some-issuer"},
+		}
+		assert.NilError(t, cluster.ValidateCreate())
+
+		cluster.Spec.CustomTLSSecret = &corev1.SecretProjection{}
+		cluster.Spec.CustomReplicationClientTLSSecret = &corev1.SecretProjection{}
+		err := cluster.ValidateCreate()
+		assert.ErrorContains(t, err,
+			`spec.customTLSSecret: Forbidden: cannot be set together with certManager`)
+		assert.ErrorContains(t, err,
+			`spec.customReplicationTLSSecret: Forbidden: cannot be set together with certManager`)
+	})
+
+	t.Run("Images", func(t *testing.T) {
+		cluster := valid()
+		cluster.Spec.TimescaleDBVersion = "2.7"
+		assert.NilError(t, cluster.ValidateCreate())
+
+		cluster.Spec.PostGISVersion = "3.2"
+		assert.ErrorContains(t, cluster.ValidateCreate(),
+			`spec.timescaleDBVersion: Forbidden: cannot be set together with postGISVersion`)
+	})
+
+	t.Run("TempVolume", func(t *testing.T) {
+		cluster := valid()
+		limit := resource.MustParse("1Gi")
+
+		cluster.Spec.TempVolume = &PostgresTempVolumeSpec{SizeLimit: &limit}
+		assert.NilError(t, cluster.ValidateCreate())
+
+		cluster.Spec.TempVolume.VolumeClaimSpec = &corev1.PersistentVolumeClaimSpec{}
+		assert.ErrorContains(t, cluster.ValidateCreate(),
+			`spec.tempVolume.sizeLimit: Forbidden: cannot be set together with volumeClaimSpec`)
+
+		cluster.Spec.TempVolume.SizeLimit = nil
+		assert.NilError(t, cluster.ValidateCreate())
+	})
+
+	t.Run("Databases", func(t *testing.T) {
+		cluster := valid()
+		cluster.Spec.Users = []PostgresUserSpec{{Name: "rhino"}}
+		cluster.Spec.Databases = []PostgresDatabaseSpec{
+			{Name: "app", Owner: "rhino", Schemas: []PostgresIdentifier{"sales"}},
+			{Name: "zoo", Owner: "postgres"},
+		}
+		assert.NilError(t, cluster.ValidateCreate())
+
+		cluster.Spec.Databases[0].Owner = "hippo"
+		cluster.Spec.Databases[1].Readers = []PostgresIdentifier{"rhino", "bi"}
+		cluster.Spec.Databases[1].Writers = []PostgresIdentifier{"etl"}
+		assert.ErrorContains(t, cluster.ValidateCreate(),
+			`spec.databases[0].owner: Not found: "hippo"`)
+		assert.ErrorContains(t, cluster.ValidateCreate(),
+			`spec.databases[1].readers[1]: Not found: "bi"`)
+		assert.ErrorContains(t, cluster.ValidateCreate(),
+			`spec.databases[1].writers[0]: Not found: "etl"`)
+	})
+
+	t.Run("SQLPolicies", func(t *testing.T) {
+		cluster := valid()
+		cluster.Spec.SQLPolicies = []SQLPolicySpec{
+			{Name: "analyze"},
+			{Name: "vacuum-the-largest-tables-every-night"},
+		}
+		assert.ErrorContains(t, cluster.ValidateCreate(),
+			`spec.sqlPolicies[1].name: Invalid value: "vacuum-the-largest-tables-every-night": together with the name of the cluster`)
+
+		cluster.Spec.SQLPolicies[1].Name = "analyze"
+		assert.ErrorContains(t, cluster.ValidateCreate(),
+			`spec.sqlPolicies[1].name: Duplicate value: "analyze"`)
+
+		cluster.Spec.SQLPolicies[1].Name = "vacuum"
+		assert.NilError(t, cluster.ValidateCreate())
+	})
+
+	t.Run("GSSEncryption", func(t *testing.T) {
+		cluster := valid()
+		cluster.Spec.PostgresVersion = 12
+		cluster.Spec.Authentication = &PostgresAuthenticationSpec{
+			Rules: []PostgresHBARuleSpec{
+				{Connection: "hostssl", Method: "scram-sha-256"},
+				{Connection: "hostgssenc", Method: "gss"},
+			},
+		}
+		assert.NilError(t, cluster.ValidateCreate())
+
+		cluster.Spec.PostgresVersion = 11
+		assert.ErrorContains(t, cluster.ValidateCreate(),
+			`spec.authentication.rules[1].connection: Invalid value: "hostgssenc": requires PostgreSQL 12`)
+	})
+
+	t.Run("PasswordSources", func(t *testing.T) {
+		cluster := valid()
+		cluster.Spec.Users = []PostgresUserSpec{
+			{Name: "rhino", Password: &PostgresPasswordSpec{
+				Vault: &PostgresPasswordVaultSpec{Mount: "secret", Path: "rhino", Key: "password"},
+			}},
+		}
+		assert.NilError(t, cluster.ValidateCreate())
+
+		cluster.Spec.Users[0].Password.SecretKeyRef = &corev1.SecretKeySelector{Key: "password"}
+		assert.ErrorContains(t, cluster.ValidateCreate(),
+			`spec.users[0].password.secretKeyRef: Forbidden: cannot be set together with vault`)
+	})
+
+	t.Run("PasswordPolicy", func(t *testing.T) {
+		cluster := valid()
+		cluster.Spec.Users = []PostgresUserSpec{
+			{Name: "rhino", Password: &PostgresPasswordSpec{Type: "AlphaNumeric"}},
+		}
+		cluster.Spec.PasswordPolicy = &PostgresPasswordPolicySpec{
+			RequiredCharacters: []PostgresPasswordCharacters{"Digit"},
+		}
+		assert.NilError(t, cluster.ValidateCreate())
+
+		cluster.Spec.PasswordPolicy.RequiredCharacters = append(
+			cluster.Spec.PasswordPolicy.RequiredCharacters, "Symbol")
+		assert.ErrorContains(t, cluster.ValidateCreate(),
+			`spec.users[0].password.type: Invalid value: "AlphaNumeric": cannot contain the symbols`)
+	})
+
+	t.Run("MaintenanceWindow", func(t *testing.T) {
+		cluster := valid()
+		cluster.Spec.MaintenanceWindow = &MaintenanceWindowSpec{
+			Windows: []MaintenanceWindow{{Start: "01:00", End: "03:00"}},
+		}
+		assert.NilError(t, cluster.ValidateCreate())
+
+		cluster.Spec.MaintenanceWindow.TimeZone = "Europe/Berlin"
+		assert.NilError(t, cluster.ValidateCreate())
+
+		cluster.Spec.MaintenanceWindow.TimeZone = "Local"
+		assert.ErrorContains(t, cluster.ValidateCreate(),
+			`spec.maintenanceWindow.timeZone: Invalid value`)
+	})
+
+	t.Run("CustomTLS", func(t *testing.T) {
+		cluster := valid()
+		cluster.Spec.CustomTLSSecret = &corev1.SecretProjection{}
+
+		assert.ErrorContains(t, cluster.ValidateCreate(),
+			`spec.customReplicationTLSSecret: Required value`)
+
+		cluster.Spec.CustomReplicationClientTLSSecret = &corev1.SecretProjection{}
+		assert.NilError(t, cluster.ValidateCreate())
+
+		cluster.Spec.CustomTLSSecret = nil
+		assert.ErrorContains(t, cluster.ValidateCreate(),
+			`spec.customTLSSecret: Required value`)
+	})
 }
 
 func TestPostgresClusterDefault(t *testing.T) {
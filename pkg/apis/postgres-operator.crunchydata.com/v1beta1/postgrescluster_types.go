@@ -16,11 +16,20 @@
 package v1beta1
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
 // PostgresClusterSpec defines the desired state of PostgresCluster
@@ -32,6 +41,18 @@ type PostgresClusterSpec struct {
 	// +optional
 	DataSource *DataSource `json:"dataSource,omitempty"`
 
+	// Defines a pgbench benchmark that can be run against the primary instance.
+	// +optional
+	Benchmark *PGBenchSpec `json:"benchmark,omitempty"`
+
+	// The configuration of pgAudit and the shipping of its logs.
+	// +optional
+	Audit *PGAuditSpec `json:"audit,omitempty"`
+
+	// How clients authenticate to PostgreSQL.
+	// +optional
+	Authentication *PostgresAuthenticationSpec `json:"authentication,omitempty"`
+
 	// PostgreSQL backup configuration
 	// +kubebuilder:validation:Required
 	Backups Backups `json:"backups"`
@@ -48,6 +69,14 @@ type PostgresClusterSpec struct {
 	// +optional
 	CustomTLSSecret *corev1.SecretProjection `json:"customTLSSecret,omitempty"`
 
+	// Use cert-manager to issue and renew the PostgreSQL server certificate
+	// and the replication client certificate. This cannot be used together
+	// with customTLSSecret or customReplicationTLSSecret. PostgreSQL reloads
+	// its certificates when cert-manager renews them.
+	// More info: https://cert-manager.io
+	// +optional
+	CertManager *CertManagerSpec `json:"certManager,omitempty"`
+
 	// The secret containing the replication client certificates and keys for
 	// secure connections to the PostgreSQL server. It will need to contain the
 	// client TLS certificate, TLS key and the Certificate Authority certificate
@@ -62,6 +91,21 @@ type PostgresClusterSpec struct {
 	// namespace as the cluster.
 	// +optional
 	DatabaseInitSQL *DatabaseInitSQL `json:"databaseInitSQL,omitempty"`
+
+	// Whether or not the PostgreSQL cluster is protected from deletion. When
+	// this is true, the validating webhook refuses to delete the cluster and
+	// the controller keeps its data until this is set to false.
+	// +optional
+	DeletionProtection *bool `json:"deletionProtection,omitempty"`
+
+	// The number of days to keep the volumes of the PostgreSQL cluster after
+	// it is deleted. During this time the cluster remains in the Kubernetes API
+	// with its instances stopped, and its data and pgBackRest volumes remain in
+	// place. When omitted, volumes are removed as soon as the cluster is deleted.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	DeletionRetentionDays *int32 `json:"deletionRetentionDays,omitempty"`
+
 	// Whether or not the PostgreSQL cluster should use the defined default
 	// scheduling constraints. If the field is unset or false, the default
 	// scheduling constraints will be used in addition to any custom constraints
@@ -69,12 +113,33 @@ type PostgresClusterSpec struct {
 	// +optional
 	DisableDefaultPodScheduling *bool `json:"disableDefaultPodScheduling,omitempty"`
 
+	// Whether or not PGO should derive PostgreSQL memory and parallelism
+	// parameters, such as shared_buffers and max_parallel_workers, from the
+	// resources of the instance sets. When true, those parameters are
+	// calculated from the smallest memory and CPU among the instance sets.
+	// When the field is unset or false, PostgreSQL defaults are used.
+	// Parameters set elsewhere in the spec take precedence.
+	// +optional
+	AutoTuning *bool `json:"autoTuning,omitempty"`
+
+	// Extensions to create inside PostgreSQL. Extensions that must be loaded
+	// when PostgreSQL starts are added to "shared_preload_libraries", and
+	// PostgreSQL restarts to load them. Removing an extension from this list
+	// does NOT drop it from PostgreSQL.
+	// More info: https://www.postgresql.org/docs/current/sql-createextension.html
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Extensions []PostgresExtensionSpec `json:"extensions,omitempty"`
+
 	// The image name to use for PostgreSQL containers. When omitted, the value
 	// comes from an operator environment variable. For standard PostgreSQL images,
 	// the format is RELATED_IMAGE_POSTGRES_{postgresVersion},
 	// e.g. RELATED_IMAGE_POSTGRES_13. For PostGIS enabled PostgreSQL images,
 	// the format is RELATED_IMAGE_POSTGRES_{postgresVersion}_GIS_{postGISVersion},
-	// e.g. RELATED_IMAGE_POSTGRES_13_GIS_3.1.
+	// e.g. RELATED_IMAGE_POSTGRES_13_GIS_3.1. For TimescaleDB enabled PostgreSQL
+	// images, the format is RELATED_IMAGE_POSTGRES_{postgresVersion}_TIMESCALEDB_{timescaleDBVersion},
+	// e.g. RELATED_IMAGE_POSTGRES_14_TIMESCALEDB_2.7.
 	// +optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,order=1
 	Image string `json:"image,omitempty"`
@@ -100,6 +165,12 @@ type PostgresClusterSpec struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,order=2
 	InstanceSets []PostgresInstanceSetSpec `json:"instances"`
 
+	// Publications and subscriptions to manage inside PostgreSQL. Removing
+	// one from this list does NOT drop it from PostgreSQL.
+	// More info: https://www.postgresql.org/docs/current/logical-replication.html
+	// +optional
+	LogicalReplication *PostgresLogicalReplicationSpec `json:"logicalReplication,omitempty"`
+
 	// Whether or not the PostgreSQL cluster is being deployed to an OpenShift
 	// environment. If the field is unset, the operator will automatically
 	// detect the environment.
@@ -127,6 +198,15 @@ type PostgresClusterSpec struct {
 	// +optional
 	PostGISVersion string `json:"postGISVersion,omitempty"`
 
+	// The TimescaleDB extension version installed in the PostgreSQL image.
+	// When image is not set, indicates a TimescaleDB enabled image will be used.
+	// The extension is created in every database and updated whenever this
+	// version changes. Cannot be set together with postGISVersion.
+	// More info: https://docs.timescale.com/
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)*$`
+	// +optional
+	TimescaleDBVersion string `json:"timescaleDBVersion,omitempty"`
+
 	// The specification of a proxy that connects to PostgreSQL.
 	// +optional
 	Proxy *PostgresProxySpec `json:"proxy,omitempty"`
@@ -143,6 +223,23 @@ type PostgresClusterSpec struct {
 	// +optional
 	Service *ServiceSpec `json:"service,omitempty"`
 
+	// Specification of the service that exposes PostgreSQL replica instances.
+	// +optional
+	ReplicaService *ServiceSpec `json:"replicaService,omitempty"`
+
+	// Replicas that are further behind the primary than this amount of WAL
+	// are removed from the replica Service until they catch up. Replicas that
+	// are not streaming are removed, too. When not set, the replica Service
+	// includes every replica.
+	// +optional
+	ReplicaServiceMaxLag *resource.Quantity `json:"replicaServiceMaxLag,omitempty"`
+
+	// SQL to run against the primary instance once or on a schedule.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	SQLPolicies []SQLPolicySpec `json:"sqlPolicies,omitempty"`
+
 	// Whether or not the PostgreSQL cluster should be stopped.
 	// When this is true, workloads are scaled to zero and CronJobs
 	// are suspended.
@@ -154,6 +251,47 @@ type PostgresClusterSpec struct {
 	// +optional
 	Standby *PostgresStandbySpec `json:"standby,omitempty"`
 
+	// When the PostgreSQL cluster should be running. Outside of this schedule,
+	// the cluster is stopped as though shutdown were true. When omitted, the
+	// cluster runs unless shutdown is true.
+	// +optional
+	UptimeSchedule *UptimeScheduleSpec `json:"uptimeSchedule,omitempty"`
+
+	// When PGO may restart PostgreSQL or recreate instance Pods, e.g. to apply
+	// a new image or parameters that need a restart. Outside of this window,
+	// those changes wait and the MaintenancePending condition lists them. When
+	// omitted, changes happen as soon as possible.
+	// +optional
+	MaintenanceWindow *MaintenanceWindowSpec `json:"maintenanceWindow,omitempty"`
+
+	// How PGO reacts as PostgreSQL data volumes fill up. When omitted, PGO
+	// does not check how full the volumes are.
+	// +optional
+	DiskUsage *DiskUsageSpec `json:"diskUsage,omitempty"`
+
+	// A volume for the temporary files of PostgreSQL, so that they cannot fill
+	// the data volume. PGO creates a tablespace on this volume and sets
+	// "temp_tablespaces" to it. Changing this value causes PostgreSQL to
+	// restart.
+	// More info: https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-TEMP-TABLESPACES
+	// +optional
+	TempVolume *PostgresTempVolumeSpec `json:"tempVolume,omitempty"`
+
+	// Whether PostgreSQL should run on CPU cores that no other container
+	// shares. When enabled, every instance set must have a whole number of
+	// CPUs and a memory limit, and its pods get the Guaranteed quality of
+	// service class so a kubelet with the static CPU manager policy assigns
+	// exclusive cores to PostgreSQL. Changing this value causes PostgreSQL to
+	// restart.
+	// More info: https://kubernetes.io/docs/tasks/administer-cluster/cpu-management-policies/
+	// +optional
+	DedicatedCPUs *bool `json:"dedicatedCPUs,omitempty"`
+
+	// A webhook to notify about lifecycle events of this cluster. These
+	// notifications are in addition to any that PGO sends for every cluster.
+	// +optional
+	Notifications *NotificationsSpec `json:"notifications,omitempty"`
+
 	// A list of group IDs applied to the process of a container. These can be
 	// useful when accessing shared file systems with constrained permissions.
 	// More info: https://kubernetes.io/docs/reference/kubernetes-api/workload-resources/pod-v1/#security-context
@@ -169,6 +307,19 @@ type PostgresClusterSpec struct {
 	// +optional
 	Users []PostgresUserSpec `json:"users,omitempty"`
 
+	// Databases to create inside PostgreSQL along with their owners, schemas,
+	// and the users that can read them. Databases listed by users are created
+	// as well. Removing a database from this list does NOT drop it.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Databases []PostgresDatabaseSpec `json:"databases,omitempty"`
+
+	// Rules that the passwords of users must follow. Generated passwords
+	// follow them, too.
+	// +optional
+	PasswordPolicy *PostgresPasswordPolicySpec `json:"passwordPolicy,omitempty"`
+
 	Config PostgresAdditionalConfig `json:"config,omitempty"`
 }
 
@@ -257,6 +408,15 @@ type PostgresClusterDataSource struct {
 	// +kubebuilder:validation:Pattern=^repo[1-4]
 	RepoName string `json:"repoName"`
 
+	// Whether or not to copy the passwords of PostgreSQL users from the Secrets
+	// of the source PostgresCluster. Users keep their passwords when a cluster
+	// is moved to another namespace. A Secret in another namespace is copied
+	// only when its "postgres-operator.crunchydata.com/copy-password-to-namespaces"
+	// annotation lists the namespace of this cluster. This field is ignored by
+	// in-place restores. Defaults to false.
+	// +optional
+	CopyUserPasswords *bool `json:"copyUserPasswords,omitempty"`
+
 	// Command line options to include when running the pgBackRest restore command.
 	// https://pgbackrest.org/command.html#command-restore
 	// +optional
@@ -285,6 +445,10 @@ type PostgresClusterDataSource struct {
 
 // Default defines several key default values for a Postgres cluster.
 func (s *PostgresClusterSpec) Default() {
+	if s.Benchmark != nil {
+		s.Benchmark.Default()
+	}
+
 	for i := range s.InstanceSets {
 		s.InstanceSets[i].Default(i)
 	}
@@ -328,6 +492,11 @@ type PostgresClusterStatus struct {
 	// +optional
 	InstanceSets []PostgresInstanceSetStatus `json:"instances,omitempty"`
 
+	// Identifies the publications and subscriptions that have been written
+	// into PostgreSQL.
+	// +optional
+	LogicalReplicationRevision string `json:"logicalReplicationRevision,omitempty"`
+
 	// +optional
 	Patroni PatroniStatus `json:"patroni,omitempty"`
 
@@ -335,6 +504,10 @@ type PostgresClusterStatus struct {
 	// +optional
 	PGBackRest *PGBackRestStatus `json:"pgbackrest,omitempty"`
 
+	// Results of pgbench benchmarks
+	// +optional
+	Benchmark *PGBenchStatus `json:"benchmark,omitempty"`
+
 	// Stores the current PostgreSQL major version following a successful
 	// major PostgreSQL upgrade.
 	// +optional
@@ -344,6 +517,12 @@ type PostgresClusterStatus struct {
 	// +optional
 	Proxy PostgresProxyStatus `json:"proxy,omitempty"`
 
+	// The most recent run of each SQL policy.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	SQLPolicies []SQLPolicyStatus `json:"sqlPolicies,omitempty"`
+
 	// The instance that should be started first when bootstrapping and/or starting a
 	// PostgresCluster.
 	// +optional
@@ -353,6 +532,16 @@ type PostgresClusterStatus struct {
 	// +optional
 	StartupInstanceSet string `json:"startupInstanceSet,omitempty"`
 
+	// Identifies the temporary tablespace that has been written into
+	// PostgreSQL.
+	// +optional
+	TempTablespaceRevision string `json:"tempTablespaceRevision,omitempty"`
+
+	// The value of the reset-query-statistics annotation when the statistics
+	// of pg_stat_statements were last reset.
+	// +optional
+	QueryStatisticsReset string `json:"queryStatisticsReset,omitempty"`
+
 	// Current state of the PostgreSQL user interface.
 	// +optional
 	UserInterface *PostgresUserInterfaceStatus `json:"userInterface,omitempty"`
@@ -360,6 +549,15 @@ type PostgresClusterStatus struct {
 	// Identifies the users that have been installed into PostgreSQL.
 	UsersRevision string `json:"usersRevision,omitempty"`
 
+	// When the passwords of PostgreSQL users in the spec expire, by user name.
+	// Users whose passwords do not expire are omitted.
+	// +optional
+	PasswordExpirations map[string]metav1.Time `json:"passwordExpirations,omitempty"`
+
+	// Identifies the users whose password expirations have been read.
+	// +optional
+	PasswordExpirationsRevision string `json:"passwordExpirationsRevision,omitempty"`
+
 	// Current state of PostgreSQL cluster monitoring tool configuration
 	// +optional
 	Monitoring MonitoringStatus `json:"monitoring,omitempty"`
@@ -422,11 +620,53 @@ type PostgresInstanceSetSpec struct {
 	// +optional
 	Affinity *corev1.Affinity `json:"affinity,omitempty"`
 
+	// Custom sidecars for PostgreSQL instance pods. These run alongside the
+	// containers managed by PGO, such as log shippers or monitoring agents.
+	// Changing this value causes PostgreSQL to restart.
+	// More info: https://kubernetes.io/docs/concepts/workloads/pods/#how-pods-manage-multiple-containers
+	// +optional
+	Containers []corev1.Container `json:"containers,omitempty"`
+
+	// Labels that a node must have for a PostgreSQL pod to be scheduled on it.
+	// Changing this value causes PostgreSQL to restart.
+	// More info: https://kubernetes.io/docs/concepts/scheduling-eviction/assign-pod-node/#nodeselector
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
 	// Defines a PersistentVolumeClaim for PostgreSQL data.
 	// More info: https://kubernetes.io/docs/concepts/storage/persistent-volumes
 	// +kubebuilder:validation:Required
 	DataVolumeClaimSpec corev1.PersistentVolumeClaimSpec `json:"dataVolumeClaimSpec"`
 
+	// Whether PostgreSQL pods should have the Guaranteed quality of service
+	// class, making them the last pods evicted when a node runs out of
+	// resources. When enabled, the CPU and memory requests of every container
+	// are set to its limits, and the PostgreSQL container must have limits.
+	// Changing this value causes PostgreSQL to restart.
+	// More info: https://kubernetes.io/docs/tasks/configure-pod-container/quality-service-pod/
+	// +optional
+	GuaranteedQoS *bool `json:"guaranteedQoS,omitempty"`
+
+	// Custom init containers for PostgreSQL instance pods. These run after the
+	// init containers managed by PGO. Changing this value causes PostgreSQL
+	// to restart.
+	// More info: https://kubernetes.io/docs/concepts/workloads/pods/init-containers/
+	// +optional
+	InitContainers []corev1.Container `json:"initContainers,omitempty"`
+
+	// A strategic merge patch that PGO applies to the PostgreSQL pod template
+	// every time it generates an instance. Use this for settings PGO does not
+	// otherwise expose, such as environment variables or security context
+	// fields. When guaranteedQoS or dedicatedCPUs is enabled, PGO sets resource
+	// requests to limits after applying this patch, so it cannot change those
+	// requests. Changing this value causes PostgreSQL to restart.
+	// More info: https://kubernetes.io/docs/tasks/manage-kubernetes-objects/update-api-object-kubectl-patch/
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	PodTemplateOverlay SchemalessObject `json:"podTemplateOverlay,omitempty"`
+
 	// Priority class name for the PostgreSQL pod. Changing this value causes
 	// PostgreSQL to restart.
 	// More info: https://kubernetes.io/docs/concepts/scheduling-eviction/pod-priority-preemption/
@@ -463,6 +703,12 @@ type PostgresInstanceSetSpec struct {
 	// +optional
 	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
 
+	// Additional volumes for PostgreSQL instance pods. Custom containers can
+	// mount these by name. Changing this value causes PostgreSQL to restart.
+	// More info: https://kubernetes.io/docs/concepts/storage/volumes
+	// +optional
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+
 	// Defines a separate PersistentVolumeClaim for PostgreSQL's write-ahead log.
 	// More info: https://www.postgresql.org/docs/current/wal.html
 	// +optional
@@ -502,6 +748,22 @@ type PostgresInstanceSetStatus struct {
 	// Total number of pods that have the desired specification.
 	// +optional
 	UpdatedReplicas int32 `json:"updatedReplicas,omitempty"`
+
+	// Total number of pods with parameter changes that take effect only after
+	// PostgreSQL restarts.
+	// +optional
+	PendingRestartReplicas int32 `json:"pendingRestartReplicas,omitempty"`
+
+	// The highest percentage of a data volume in use among the pods of this
+	// set. This is reported when spec.diskUsage is set.
+	// +optional
+	DataVolumeUsedPercent *int32 `json:"dataVolumeUsedPercent,omitempty"`
+
+	// The highest percentage of a WAL volume in use among the pods of this
+	// set. This is reported when spec.diskUsage is set and the set has a WAL
+	// volume.
+	// +optional
+	WALVolumeUsedPercent *int32 `json:"walVolumeUsedPercent,omitempty"`
 }
 
 // PostgresProxySpec is a union of the supported PostgreSQL proxies.
@@ -560,10 +822,25 @@ type PostgresUserInterfaceStatus struct {
 
 type PostgresAdditionalConfig struct {
 	Files []corev1.VolumeProjection `json:"files,omitempty"`
+
+	// PostgreSQL parameters that are applied to every instance through Patroni.
+	// These override the defaults chosen by PGO but not the values PGO requires.
+	// Parameters in spec.patroni.dynamicConfiguration take precedence over these.
+	// PostgreSQL reloads when a parameter changes, and instances restart one at
+	// a time when a parameter requires it.
+	// More info: https://www.postgresql.org/docs/current/runtime-config.html
+	// +optional
+	Parameters map[string]intstr.IntOrString `json:"parameters,omitempty"`
 }
 
 // +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=pgc
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Version",type=integer,JSONPath=`.spec.postgresVersion`
+// +kubebuilder:printcolumn:name="Primary",type=string,JSONPath=`.status.patroni.leader`
+// +kubebuilder:printcolumn:name="Status",type=string,priority=1,JSONPath=`.status.conditions[?(@.type=="Ready")].message`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 // +operator-sdk:csv:customresourcedefinitions:resources={{ConfigMap,v1},{Secret,v1},{Service,v1},{CronJob,v1beta1},{Deployment,v1},{Job,v1},{StatefulSet,v1},{PersistentVolumeClaim,v1}}
 
 // PostgresCluster is the Schema for the postgresclusters API
@@ -595,6 +872,497 @@ func (c *PostgresCluster) Default() {
 	c.Spec.Default()
 }
 
+// ValidateCreate implements "sigs.k8s.io/controller-runtime/pkg/webhook.Validator"
+// so a webhook can be registered for the type.
+func (c *PostgresCluster) ValidateCreate() error { return c.invalid(c.validate()) }
+
+// ValidateUpdate implements "sigs.k8s.io/controller-runtime/pkg/webhook.Validator"
+// so a webhook can be registered for the type. It refuses only the problems
+// that an update introduces so that clusters created before a rule existed,
+// or before the webhook was installed, can still be changed. Nothing is
+// refused once a cluster is being deleted; its finalizer must be removable.
+func (c *PostgresCluster) ValidateUpdate(old runtime.Object) error {
+	if c.DeletionTimestamp != nil {
+		return nil
+	}
+
+	errs := c.validate()
+	if previous, ok := old.(*PostgresCluster); ok && len(errs) > 0 {
+		existing := sets.NewString()
+		for _, err := range previous.validate() {
+			existing.Insert(err.Error())
+		}
+
+		var introduced field.ErrorList
+		for _, err := range errs {
+			if !existing.Has(err.Error()) {
+				introduced = append(introduced, err)
+			}
+		}
+		errs = introduced
+	}
+	return c.invalid(errs)
+}
+
+// ValidateDelete implements "sigs.k8s.io/controller-runtime/pkg/webhook.Validator"
+// so a webhook can be registered for the type.
+func (c *PostgresCluster) ValidateDelete() error {
+	if c.Spec.DeletionProtection != nil && *c.Spec.DeletionProtection {
+		return apierrors.NewForbidden(GroupVersion.WithResource("postgresclusters").GroupResource(),
+			c.Name, errors.New("spec.deletionProtection must be false to delete this cluster"))
+	}
+	return nil
+}
+
+// invalid returns an Invalid API error for errs, or nil when there are none.
+func (c *PostgresCluster) invalid(errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(
+		GroupVersion.WithKind("PostgresCluster").GroupKind(), c.Name, errs)
+}
+
+// validate returns the reasons the spec of c cannot be reconciled that the CRD
+// schema cannot express. These are the same reasons that cause the controller
+// to record a warning event and wait for the spec to change.
+func (c *PostgresCluster) validate() field.ErrorList {
+	// Validate against the same defaults the controller uses.
+	cluster := c.DeepCopy()
+	cluster.Spec.Default()
+
+	var errs field.ErrorList
+	spec := field.NewPath("spec")
+
+	instances := sets.NewString()
+	for i, set := range cluster.Spec.InstanceSets {
+		if instances.Has(set.Name) {
+			errs = append(errs, field.Duplicate(
+				spec.Child("instances").Index(i).Child("name"), set.Name))
+		}
+		instances.Insert(set.Name)
+
+		if set.GuaranteedQoS != nil && *set.GuaranteedQoS {
+			for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+				if _, ok := set.Resources.Limits[name]; !ok {
+					errs = append(errs, field.Required(
+						spec.Child("instances").Index(i).Child("resources", "limits", string(name)),
+						"required when guaranteedQoS is enabled"))
+				}
+			}
+		}
+
+		if cluster.Spec.DedicatedCPUs != nil && *cluster.Spec.DedicatedCPUs {
+			errs = append(errs, validateDedicatedCPUs(
+				spec.Child("instances").Index(i).Child("resources"), set.Resources)...)
+		}
+
+		errs = append(errs, validateHugePages(
+			spec.Child("instances").Index(i).Child("resources"), set.Resources)...)
+
+		errs = append(errs, validatePodTemplateOverlay(
+			spec.Child("instances").Index(i).Child("podTemplateOverlay"),
+			set.PodTemplateOverlay)...)
+
+		if set.Replicas != nil {
+			errs = append(errs, validateMinAvailable(
+				spec.Child("instances").Index(i).Child("minAvailable"),
+				set.MinAvailable, *set.Replicas)...)
+		}
+	}
+
+	if auth := cluster.Spec.Authentication; auth != nil && cluster.Spec.PostgresVersion < 12 {
+		for i, rule := range auth.Rules {
+			if rule.Connection == "hostgssenc" || rule.Connection == "hostnogssenc" {
+				errs = append(errs, field.Invalid(
+					spec.Child("authentication", "rules").Index(i).Child("connection"),
+					rule.Connection, "requires PostgreSQL 12 or later"))
+			}
+		}
+	}
+
+	if proxy := cluster.Spec.Proxy; proxy != nil && proxy.PGBouncer != nil {
+		if proxy.PGBouncer.Replicas != nil {
+			errs = append(errs, validateMinAvailable(
+				spec.Child("proxy", "pgBouncer", "minAvailable"),
+				proxy.PGBouncer.MinAvailable, *proxy.PGBouncer.Replicas)...)
+		}
+		errs = append(errs, validatePodTemplateOverlay(
+			spec.Child("proxy", "pgBouncer", "podTemplateOverlay"),
+			proxy.PGBouncer.PodTemplateOverlay)...)
+	}
+
+	if schedule := cluster.Spec.UptimeSchedule; schedule != nil {
+		if _, err := time.LoadLocation(schedule.TimeZone); err != nil || schedule.TimeZone == "Local" {
+			errs = append(errs, field.Invalid(
+				spec.Child("uptimeSchedule", "timeZone"), schedule.TimeZone,
+				"must be a name from the IANA Time Zone database"))
+		}
+	}
+
+	for i, repo := range cluster.Spec.Backups.PGBackRest.Repos {
+		if repo.BackupSchedules != nil {
+			errs = append(errs, validateBackupSchedules(
+				spec.Child("backups", "pgbackrest", "repos").Index(i).Child("schedules"),
+				repo.BackupSchedules)...)
+		}
+	}
+
+	if usage := cluster.Spec.DiskUsage; usage != nil && usage.ReadOnlyPercent != nil {
+		warning := int32(80)
+		if usage.WarningPercent != nil {
+			warning = *usage.WarningPercent
+		}
+		if *usage.ReadOnlyPercent <= warning {
+			errs = append(errs, field.Invalid(
+				spec.Child("diskUsage", "readOnlyPercent"), *usage.ReadOnlyPercent,
+				"must be greater than warningPercent"))
+		}
+	}
+
+	users := sets.NewString("postgres")
+	for _, user := range cluster.Spec.Users {
+		users.Insert(string(user.Name))
+	}
+	for i, user := range cluster.Spec.Users {
+		if user.Password != nil && user.Password.Vault != nil && user.Password.SecretKeyRef != nil {
+			errs = append(errs, field.Forbidden(
+				spec.Child("users").Index(i).Child("password", "secretKeyRef"),
+				"cannot be set together with vault"))
+		}
+	}
+	if policy := cluster.Spec.PasswordPolicy; policy != nil {
+		symbols := false
+		for _, class := range policy.RequiredCharacters {
+			symbols = symbols || class == PostgresPasswordCharactersSymbol
+		}
+		for i, user := range cluster.Spec.Users {
+			if symbols && user.Password != nil &&
+				user.Password.Type == PostgresPasswordTypeAlphaNumeric {
+				errs = append(errs, field.Invalid(
+					spec.Child("users").Index(i).Child("password", "type"),
+					user.Password.Type, "cannot contain the symbols required by passwordPolicy"))
+			}
+		}
+	}
+
+	for i, database := range cluster.Spec.Databases {
+		path := spec.Child("databases").Index(i)
+		if database.Owner != "" && !users.Has(string(database.Owner)) {
+			errs = append(errs, field.NotFound(path.Child("owner"), database.Owner))
+		}
+		for j, reader := range database.Readers {
+			if !users.Has(string(reader)) {
+				errs = append(errs, field.NotFound(path.Child("readers").Index(j), reader))
+			}
+		}
+		for j, writer := range database.Writers {
+			if !users.Has(string(writer)) {
+				errs = append(errs, field.NotFound(path.Child("writers").Index(j), writer))
+			}
+		}
+	}
+
+	// The Job or CronJob of a policy is named after the cluster and policy.
+	// Kubernetes allows at most 52 characters in the name of a CronJob.
+	policies := sets.NewString()
+	for i, policy := range cluster.Spec.SQLPolicies {
+		path := spec.Child("sqlPolicies").Index(i).Child("name")
+		if policies.Has(policy.Name) {
+			errs = append(errs, field.Duplicate(path, policy.Name))
+		}
+		policies.Insert(policy.Name)
+
+		if len(cluster.Name+"-sqlpolicy-"+policy.Name) > 52 {
+			errs = append(errs, field.Invalid(path, policy.Name,
+				"together with the name of the cluster must be at most 41 characters"))
+		}
+	}
+
+	if cluster.Spec.CertManager != nil {
+		if cluster.Spec.CustomTLSSecret != nil {
+			errs = append(errs, field.Forbidden(
+				spec.Child("customTLSSecret"), "cannot be set together with certManager"))
+		}
+		if cluster.Spec.CustomReplicationClientTLSSecret != nil {
+			errs = append(errs, field.Forbidden(
+				spec.Child("customReplicationTLSSecret"), "cannot be set together with certManager"))
+		}
+	}
+
+	if cluster.Spec.PostGISVersion != "" && cluster.Spec.TimescaleDBVersion != "" {
+		errs = append(errs, field.Forbidden(
+			spec.Child("timescaleDBVersion"),
+			"cannot be set together with postGISVersion"))
+	}
+
+	if temp := cluster.Spec.TempVolume; temp != nil &&
+		temp.SizeLimit != nil && temp.VolumeClaimSpec != nil {
+		errs = append(errs, field.Forbidden(
+			spec.Child("tempVolume", "sizeLimit"),
+			"cannot be set together with volumeClaimSpec"))
+	}
+
+	if window := cluster.Spec.MaintenanceWindow; window != nil {
+		if _, err := time.LoadLocation(window.TimeZone); err != nil || window.TimeZone == "Local" {
+			errs = append(errs, field.Invalid(
+				spec.Child("maintenanceWindow", "timeZone"), window.TimeZone,
+				"must be a name from the IANA Time Zone database"))
+		}
+	}
+
+	pgbackrest := spec.Child("backups", "pgbackrest")
+	repos := sets.NewString()
+	for i, repo := range cluster.Spec.Backups.PGBackRest.Repos {
+		if repos.Has(repo.Name) {
+			errs = append(errs, field.Duplicate(
+				pgbackrest.Child("repos").Index(i).Child("name"), repo.Name))
+		}
+		repos.Insert(repo.Name)
+	}
+
+	if final := cluster.Spec.Backups.PGBackRest.FinalBackup; final != nil {
+		if !repos.Has(final.RepoName) {
+			errs = append(errs, field.NotFound(
+				pgbackrest.Child("finalBackup", "repoName"), final.RepoName))
+		}
+		for _, repo := range cluster.Spec.Backups.PGBackRest.Repos {
+			if repo.Name == final.RepoName && repo.Volume != nil {
+				errs = append(errs, field.Invalid(
+					pgbackrest.Child("finalBackup", "repoName"), final.RepoName,
+					"must be a cloud repo; volume repos are deleted with the cluster"))
+			}
+		}
+		for i, opt := range final.Options {
+			if strings.Contains(opt, "--repo") {
+				errs = append(errs, field.Forbidden(
+					pgbackrest.Child("finalBackup", "options").Index(i),
+					"use repoName instead of --repo"))
+			}
+		}
+	}
+
+	if manual := cluster.Spec.Backups.PGBackRest.Manual; manual != nil {
+		if !repos.Has(manual.RepoName) {
+			errs = append(errs, field.NotFound(
+				pgbackrest.Child("manual", "repoName"), manual.RepoName))
+		}
+		for i, opt := range manual.Options {
+			if strings.Contains(opt, "--repo") {
+				errs = append(errs, field.Forbidden(
+					pgbackrest.Child("manual", "options").Index(i),
+					"use repoName instead of --repo"))
+			}
+		}
+	}
+
+	if restore := cluster.Spec.Backups.PGBackRest.Restore; restore != nil &&
+		restore.PostgresClusterDataSource != nil {
+		// An in-place restore uses the repos of this cluster unless it names another.
+		if restore.ClusterName == "" && !repos.Has(restore.RepoName) {
+			errs = append(errs, field.NotFound(
+				pgbackrest.Child("restore", "repoName"), restore.RepoName))
+		}
+		errs = append(errs, validateRestoreOptions(
+			pgbackrest.Child("restore", "options"), restore.Options)...)
+	}
+
+	if source := cluster.Spec.DataSource; source != nil {
+		if source.PostgresCluster != nil {
+			errs = append(errs, validateRestoreOptions(
+				spec.Child("dataSource", "postgresCluster", "options"),
+				source.PostgresCluster.Options)...)
+		}
+		if source.PGBackRest != nil {
+			errs = append(errs, validateRestoreOptions(
+				spec.Child("dataSource", "pgbackrest", "options"),
+				source.PGBackRest.Options)...)
+
+			// A volume repo can only be read from an existing PVC.
+			if source.PGBackRest.Repo.Volume != nil &&
+				(source.Volumes == nil || source.Volumes.PGBackRestVolume == nil) {
+				errs = append(errs, field.Required(
+					spec.Child("dataSource", "volumes", "pgBackRestVolume"),
+					"required when restoring from a volume repo"))
+			}
+		}
+	}
+
+	// Custom TLS certificates for PostgreSQL and for replication go together.
+	if cluster.Spec.CustomTLSSecret != nil && cluster.Spec.CustomReplicationClientTLSSecret == nil {
+		errs = append(errs, field.Required(spec.Child("customReplicationTLSSecret"),
+			"required when customTLSSecret is set"))
+	}
+	if cluster.Spec.CustomTLSSecret == nil && cluster.Spec.CustomReplicationClientTLSSecret != nil {
+		errs = append(errs, field.Required(spec.Child("customTLSSecret"),
+			"required when customReplicationTLSSecret is set"))
+	}
+
+	return errs
+}
+
+// validateMinAvailable returns an error when minAvailable can never be
+// satisfied by replicas pods. A PodDisruptionBudget like that blocks every
+// voluntary disruption, including node drains.
+func validateMinAvailable(path *field.Path, minAvailable *intstr.IntOrString, replicas int32) field.ErrorList {
+	if minAvailable == nil {
+		return nil
+	}
+
+	var errs field.ErrorList
+	if minAvailable.Type == intstr.String {
+		percent, err := intstr.GetScaledValueFromIntOrPercent(minAvailable, 100, true)
+		switch {
+		case err != nil:
+			errs = append(errs, field.Invalid(path, minAvailable.StrVal,
+				"must be a number or a percentage"))
+		case percent < 0 || percent > 100:
+			errs = append(errs, field.Invalid(path, minAvailable.StrVal,
+				"must be between 0% and 100%"))
+		}
+		return errs
+	}
+
+	if minAvailable.IntVal < 0 {
+		errs = append(errs, field.Invalid(path, minAvailable.IntVal,
+			"must be greater than or equal to 0"))
+	}
+	if minAvailable.IntVal > replicas {
+		errs = append(errs, field.Invalid(path, minAvailable.IntVal,
+			fmt.Sprintf("must not exceed replicas (%d)", replicas)))
+	}
+	return errs
+}
+
+// validateBackupSchedules returns errors when the time zone of schedules is
+// not one that CronJobs can load, or when a schedule names its own time zone.
+// Kubernetes does not support "CRON_TZ=" and "TZ=" in schedules.
+// - https://docs.k8s.io/concepts/workloads/controllers/cron-jobs/#unsupported-timezone-specification
+func validateBackupSchedules(path *field.Path, schedules *PGBackRestBackupSchedules) field.ErrorList {
+	var errs field.ErrorList
+
+	if zone := schedules.TimeZone; zone != "" {
+		if _, err := time.LoadLocation(zone); err != nil || zone == "Local" {
+			errs = append(errs, field.Invalid(
+				path.Child("timeZone"), zone,
+				"must be a name from the IANA Time Zone database"))
+		}
+	}
+
+	for _, schedule := range []struct {
+		name  string
+		value *string
+	}{
+		{"full", schedules.Full},
+		{"differential", schedules.Differential},
+		{"incremental", schedules.Incremental},
+	} {
+		if value := schedule.value; value != nil && (strings.HasPrefix(*value, "CRON_TZ=") || strings.HasPrefix(*value, "TZ=")) {
+			errs = append(errs, field.Invalid(
+				path.Child(schedule.name), *value,
+				"cannot name a time zone; use timeZone instead"))
+		}
+	}
+
+	return errs
+}
+
+// validateHugePages returns the errors Kubernetes would report for the huge
+// pages in resources when creating Pods.
+// - https://docs.k8s.io/tasks/manage-hugepages/scheduling-hugepages/
+func validateHugePages(path *field.Path, resources corev1.ResourceRequirements) field.ErrorList {
+	var errs field.ErrorList
+	var hugePages bool
+	for name, request := range resources.Requests {
+		if strings.HasPrefix(string(name), corev1.ResourceHugePagesPrefix) {
+			hugePages = true
+			if limit := resources.Limits[name]; request.Cmp(limit) != 0 {
+				errs = append(errs, field.Invalid(
+					path.Child("requests", string(name)), request.String(),
+					"must equal the limit"))
+			}
+		}
+	}
+	for name := range resources.Limits {
+		hugePages = hugePages || strings.HasPrefix(string(name), corev1.ResourceHugePagesPrefix)
+	}
+
+	var cpuOrMemory bool
+	for _, list := range []corev1.ResourceList{resources.Limits, resources.Requests} {
+		_, cpu := list[corev1.ResourceCPU]
+		_, memory := list[corev1.ResourceMemory]
+		cpuOrMemory = cpuOrMemory || cpu || memory
+	}
+	if hugePages && !cpuOrMemory {
+		errs = append(errs, field.Required(path.Child("limits", string(corev1.ResourceMemory)),
+			"huge pages require a CPU or memory request or limit"))
+	}
+	return errs
+}
+
+// validateDedicatedCPUs returns errors when resources would not get exclusive
+// cores from the static CPU manager policy of the kubelet: a memory limit and
+// a whole number of CPUs are required.
+// - https://docs.k8s.io/tasks/administer-cluster/cpu-management-policies/
+func validateDedicatedCPUs(path *field.Path, resources corev1.ResourceRequirements) field.ErrorList {
+	var errs field.ErrorList
+
+	if _, ok := resources.Limits[corev1.ResourceMemory]; !ok {
+		errs = append(errs, field.Required(
+			path.Child("limits", string(corev1.ResourceMemory)),
+			"required when dedicatedCPUs is enabled"))
+	}
+
+	if cpu, ok := resources.Limits[corev1.ResourceCPU]; !ok {
+		errs = append(errs, field.Required(
+			path.Child("limits", string(corev1.ResourceCPU)),
+			"required when dedicatedCPUs is enabled"))
+	} else if cpu.MilliValue() <= 0 || cpu.MilliValue()%1000 != 0 {
+		errs = append(errs, field.Invalid(
+			path.Child("limits", string(corev1.ResourceCPU)), cpu.String(),
+			"must be a whole number of CPUs when dedicatedCPUs is enabled"))
+	}
+
+	return errs
+}
+
+// validatePodTemplateOverlay returns an error when overlay cannot be applied
+// to a pod template as a strategic merge patch.
+func validatePodTemplateOverlay(path *field.Path, overlay SchemalessObject) field.ErrorList {
+	if len(overlay) == 0 {
+		return nil
+	}
+
+	merged, err := strategicpatch.StrategicMergeMapPatch(
+		map[string]interface{}{}, map[string]interface{}(overlay), corev1.PodTemplateSpec{})
+	if err == nil {
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(
+			merged, &corev1.PodTemplateSpec{})
+	}
+	if err != nil {
+		return field.ErrorList{field.Invalid(path, "", err.Error())}
+	}
+	return nil
+}
+
+// validateRestoreOptions returns an error for each pgBackRest restore option
+// that would conflict with an option set by the controller.
+func validateRestoreOptions(path *field.Path, options []string) field.ErrorList {
+	var errs field.ErrorList
+	for i, opt := range options {
+		switch {
+		case strings.Contains(opt, "--repo"):
+			errs = append(errs, field.Forbidden(path.Index(i), "use repoName instead of --repo"))
+		case strings.Contains(opt, "--stanza"),
+			strings.Contains(opt, "--pg1-path"),
+			strings.Contains(opt, "--target-action"),
+			strings.Contains(opt, "--link-map"):
+			errs = append(errs, field.Forbidden(path.Index(i), "this option is set by the operator"))
+		}
+	}
+	return errs
+}
+
 // +kubebuilder:object:root=true
 
 // PostgresClusterList contains a list of PostgresCluster
@@ -673,4 +1441,58 @@ type ExporterSpec struct {
 	// More info: https://kubernetes.io/docs/concepts/configuration/manage-resources-containers
 	// +optional
 	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Create a Service and Prometheus Operator ServiceMonitor for the exporters.
+	// The ServiceMonitor is created only when its CustomResourceDefinition is
+	// installed.
+	// More info: https://prometheus-operator.dev/docs/operator/design/#servicemonitor
+	// +optional
+	ServiceMonitor *ExporterServiceMonitorSpec `json:"serviceMonitor,omitempty"`
+}
+
+type ExporterServiceMonitorSpec struct {
+	// How often Prometheus scrapes the exporters, e.g. "30s". Defaults to the
+	// scrape interval of Prometheus.
+	// +kubebuilder:validation:Pattern=`^(0|(([0-9]+)h)?(([0-9]+)m)?(([0-9]+)s)?(([0-9]+)ms)?)$`
+	// +optional
+	Interval string `json:"interval,omitempty"`
+
+	// Metadata contains metadata for the ServiceMonitor, such as the labels
+	// that Prometheus uses to select it.
+	// +optional
+	Metadata *Metadata `json:"metadata,omitempty"`
+
+	// Create a Prometheus Operator PrometheusRule with recommended alerts for
+	// replication lag, backup age, disk usage, and connection saturation of
+	// this cluster. The PrometheusRule is created only when its
+	// CustomResourceDefinition is installed.
+	// More info: https://prometheus-operator.dev/docs/operator/design/#prometheusrule
+	// +optional
+	PrometheusRule *ExporterPrometheusRuleSpec `json:"prometheusRule,omitempty"`
+
+	// Create a ConfigMap containing a Grafana dashboard for this cluster. The
+	// ConfigMap has the "grafana_dashboard" label so that the dashboard sidecar
+	// of Grafana loads it.
+	// More info: https://github.com/grafana/helm-charts/tree/main/charts/grafana#sidecar-for-dashboards
+	// +optional
+	GrafanaDashboard *ExporterGrafanaDashboardSpec `json:"grafanaDashboard,omitempty"`
+}
+
+type ExporterGrafanaDashboardSpec struct {
+	// The name of the Grafana folder in which to put the dashboard. This is
+	// set as the "grafana_folder" annotation of the ConfigMap.
+	// +optional
+	Folder string `json:"folder,omitempty"`
+
+	// Metadata contains metadata for the ConfigMap, such as the labels that
+	// the dashboard sidecar of Grafana uses to select it.
+	// +optional
+	Metadata *Metadata `json:"metadata,omitempty"`
+}
+
+type ExporterPrometheusRuleSpec struct {
+	// Metadata contains metadata for the PrometheusRule, such as the labels
+	// that Prometheus uses to select it.
+	// +optional
+	Metadata *Metadata `json:"metadata,omitempty"`
 }
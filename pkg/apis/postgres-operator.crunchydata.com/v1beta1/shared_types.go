@@ -39,6 +39,23 @@ func (in *SchemalessObject) DeepCopy() *SchemalessObject {
 }
 
 type ServiceSpec struct {
+	// Labels and annotations for the Service, such as those that configure a
+	// cloud provider's load balancer.
+	// +optional
+	Metadata *Metadata `json:"metadata,omitempty"`
+
+	// The port on which this service is exposed on each node when type is
+	// NodePort or LoadBalancer. When not set, Kubernetes allocates one.
+	// More info: https://kubernetes.io/docs/concepts/services-networking/service/#type-nodeport
+	// +optional
+	NodePort *int32 `json:"nodePort,omitempty"`
+
+	// Client IP ranges allowed to reach a LoadBalancer service, when the cloud
+	// provider supports it.
+	// More info: https://kubernetes.io/docs/tasks/access-application-cluster/create-external-load-balancer/
+	// +optional
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+
 	// More info: https://kubernetes.io/docs/concepts/services-networking/service/#publishing-services-service-types
 	//
 	// +kubebuilder:validation:Required
@@ -0,0 +1,103 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SQLPolicySpec defines SQL that runs against the primary instance once or on
+// a schedule.
+type SQLPolicySpec struct {
+	// The name of this policy. The value may contain only lowercase letters,
+	// numbers, and hyphen so that it fits into Kubernetes metadata. Together
+	// with the name of the cluster it can have at most 41 characters.
+	// +kubebuilder:validation:MaxLength=32
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +required
+	Name string `json:"name"`
+
+	// A key of a ConfigMap in this namespace that contains the SQL to run.
+	// +required
+	SQL corev1.ConfigMapKeySelector `json:"sql"`
+
+	// The user that runs the SQL. It must be listed in the users of this
+	// cluster with at least one database. The SQL runs in the first database
+	// of the user.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:Type=string
+	// +required
+	User PostgresIdentifier `json:"user"`
+
+	// A schedule in Cron format on which to run the SQL. When omitted, the SQL
+	// runs once after the cluster is initialized and again whenever it changes.
+	// More info: https://k8s.io/docs/concepts/workloads/controllers/cron-jobs/#cron-schedule-syntax
+	// +kubebuilder:validation:MinLength=6
+	// +optional
+	Schedule *string `json:"schedule,omitempty"`
+
+	// How long each statement waits to acquire a lock before it fails, so
+	// that maintenance such as REINDEX does not hold up the application for
+	// long. When omitted, statements wait indefinitely.
+	// More info: https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-LOCK-TIMEOUT
+	// +optional
+	LockTimeout *metav1.Duration `json:"lockTimeout,omitempty"`
+
+	// Whether or not to wait for spec.maintenanceWindow before running a
+	// policy without a schedule. A policy with a schedule runs on its
+	// schedule regardless.
+	// +optional
+	MaintenanceWindow *bool `json:"maintenanceWindow,omitempty"`
+
+	// Compute resources of the container that runs the SQL.
+	// More info: https://kubernetes.io/docs/concepts/configuration/manage-resources-containers
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// SQLPolicyStatus describes the most recent run of a SQL policy.
+type SQLPolicyStatus struct {
+	// The name of the policy.
+	// +required
+	Name string `json:"name"`
+
+	// The name of the Job of the most recent run.
+	// +optional
+	JobName string `json:"jobName,omitempty"`
+
+	// Represents the time the most recent run was acknowledged by the Job controller.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// Represents the time the most recent run was determined by the Job
+	// controller to be completed. This field is only set if the run completed
+	// successfully.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// The number of actively running Pods of the most recent run.
+	// +optional
+	Active int32 `json:"active,omitempty"`
+
+	// The number of Pods of the most recent run that reached the "Succeeded" phase.
+	// +optional
+	Succeeded int32 `json:"succeeded,omitempty"`
+
+	// The number of Pods of the most recent run that reached the "Failed" phase.
+	// +optional
+	Failed int32 `json:"failed,omitempty"`
+}
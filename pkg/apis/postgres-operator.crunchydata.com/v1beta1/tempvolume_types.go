@@ -0,0 +1,37 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// PostgresTempVolumeSpec defines the volume that holds the temporary files of
+// every PostgreSQL instance, such as those of large sorts and hashes.
+type PostgresTempVolumeSpec struct {
+	// The largest size of the emptyDir volume that each instance gets when
+	// volumeClaimSpec is omitted. Kubernetes evicts a Pod whose volume grows
+	// past this limit.
+	// More info: https://kubernetes.io/docs/concepts/storage/volumes/#emptydir
+	// +optional
+	SizeLimit *resource.Quantity `json:"sizeLimit,omitempty"`
+
+	// Defines a PersistentVolumeClaim for the temporary files of each instance.
+	// More info: https://kubernetes.io/docs/concepts/storage/persistent-volumes#persistentvolumeclaims
+	// +optional
+	VolumeClaimSpec *corev1.PersistentVolumeClaimSpec `json:"volumeClaimSpec,omitempty"`
+}
@@ -0,0 +1,58 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1beta1
+
+// UptimeScheduleSpec defines when a PostgresCluster should be running. Outside
+// of its windows, the cluster is shutdown as though spec.shutdown were true.
+type UptimeScheduleSpec struct {
+	// The time zone of every window, as a name from the IANA Time Zone database
+	// such as "America/New_York". Defaults to UTC.
+	// More info: https://www.iana.org/time-zones
+	// +kubebuilder:default=UTC
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// Periods of time during which the cluster should be running. Windows may
+	// overlap.
+	// +kubebuilder:validation:MinItems=1
+	// +listType=atomic
+	// +required
+	Windows []UptimeWindow `json:"windows"`
+}
+
+// UptimeWindow is a daily period of time during which a PostgresCluster
+// should be running.
+type UptimeWindow struct {
+	// The days of the week on which this window starts. Defaults to every day.
+	// +listType=set
+	// +optional
+	Days []Weekday `json:"days,omitempty"`
+
+	// The time of day at which this window starts, in 24-hour "HH:MM" format.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	// +required
+	Start string `json:"start"`
+
+	// The time of day at which this window ends, in 24-hour "HH:MM" format.
+	// When this is not after start, the window ends on the following day.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	// +required
+	End string `json:"end"`
+}
+
+// Weekday is a day of the week.
+// +kubebuilder:validation:Enum={Sunday,Monday,Tuesday,Wednesday,Thursday,Friday,Saturday}
+type Weekday string
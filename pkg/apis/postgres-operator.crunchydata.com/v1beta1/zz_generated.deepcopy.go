@@ -21,8 +21,8 @@
 package v1beta1
 
 import (
-	"k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -36,6 +36,21 @@ func (in *BackupJobs) DeepCopyInto(out *BackupJobs) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.SuccessfulJobsHistoryLimit != nil {
+		in, out := &in.SuccessfulJobsHistoryLimit, &out.SuccessfulJobsHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailedJobsHistoryLimit != nil {
+		in, out := &in.FailedJobsHistoryLimit, &out.FailedJobsHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ActiveDeadlineSeconds != nil {
+		in, out := &in.ActiveDeadlineSeconds, &out.ActiveDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupJobs.
@@ -64,6 +79,47 @@ func (in *Backups) DeepCopy() *Backups {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertManagerIssuerReference) DeepCopyInto(out *CertManagerIssuerReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertManagerIssuerReference.
+func (in *CertManagerIssuerReference) DeepCopy() *CertManagerIssuerReference {
+	if in == nil {
+		return nil
+	}
+	out := new(CertManagerIssuerReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertManagerSpec) DeepCopyInto(out *CertManagerSpec) {
+	*out = *in
+	out.IssuerRef = in.IssuerRef
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertManagerSpec.
+func (in *CertManagerSpec) DeepCopy() *CertManagerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CertManagerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DataSource) DeepCopyInto(out *DataSource) {
 	*out = *in
@@ -154,17 +210,138 @@ func (in *DatabaseInitSQL) DeepCopy() *DatabaseInitSQL {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskExpansionSpec) DeepCopyInto(out *DiskExpansionSpec) {
+	*out = *in
+	in.Limit.DeepCopyInto(&out.Limit)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskExpansionSpec.
+func (in *DiskExpansionSpec) DeepCopy() *DiskExpansionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskExpansionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskUsageSpec) DeepCopyInto(out *DiskUsageSpec) {
+	*out = *in
+	if in.WarningPercent != nil {
+		in, out := &in.WarningPercent, &out.WarningPercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Expansion != nil {
+		in, out := &in.Expansion, &out.Expansion
+		*out = new(DiskExpansionSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadOnlyPercent != nil {
+		in, out := &in.ReadOnlyPercent, &out.ReadOnlyPercent
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskUsageSpec.
+func (in *DiskUsageSpec) DeepCopy() *DiskUsageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskUsageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExporterGrafanaDashboardSpec) DeepCopyInto(out *ExporterGrafanaDashboardSpec) {
+	*out = *in
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(Metadata)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExporterGrafanaDashboardSpec.
+func (in *ExporterGrafanaDashboardSpec) DeepCopy() *ExporterGrafanaDashboardSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExporterGrafanaDashboardSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExporterPrometheusRuleSpec) DeepCopyInto(out *ExporterPrometheusRuleSpec) {
+	*out = *in
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(Metadata)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExporterPrometheusRuleSpec.
+func (in *ExporterPrometheusRuleSpec) DeepCopy() *ExporterPrometheusRuleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExporterPrometheusRuleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExporterServiceMonitorSpec) DeepCopyInto(out *ExporterServiceMonitorSpec) {
+	*out = *in
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(Metadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PrometheusRule != nil {
+		in, out := &in.PrometheusRule, &out.PrometheusRule
+		*out = new(ExporterPrometheusRuleSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GrafanaDashboard != nil {
+		in, out := &in.GrafanaDashboard, &out.GrafanaDashboard
+		*out = new(ExporterGrafanaDashboardSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExporterServiceMonitorSpec.
+func (in *ExporterServiceMonitorSpec) DeepCopy() *ExporterServiceMonitorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExporterServiceMonitorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExporterSpec) DeepCopyInto(out *ExporterSpec) {
 	*out = *in
 	if in.Configuration != nil {
 		in, out := &in.Configuration, &out.Configuration
-		*out = make([]v1.VolumeProjection, len(*in))
+		*out = make([]corev1.VolumeProjection, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	in.Resources.DeepCopyInto(&out.Resources)
+	if in.ServiceMonitor != nil {
+		in, out := &in.ServiceMonitor, &out.ServiceMonitor
+		*out = new(ExporterServiceMonitorSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExporterSpec.
@@ -197,6 +374,48 @@ func (in *InstanceSidecars) DeepCopy() *InstanceSidecars {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]Weekday, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindowSpec) DeepCopyInto(out *MaintenanceWindowSpec) {
+	*out = *in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]MaintenanceWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindowSpec.
+func (in *MaintenanceWindowSpec) DeepCopy() *MaintenanceWindowSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindowSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Metadata) DeepCopyInto(out *Metadata) {
 	*out = *in
@@ -261,19 +480,35 @@ func (in *MonitoringStatus) DeepCopy() *MonitoringStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationsSpec) DeepCopyInto(out *NotificationsSpec) {
+	*out = *in
+	in.URL.DeepCopyInto(&out.URL)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationsSpec.
+func (in *NotificationsSpec) DeepCopy() *NotificationsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PGAdminConfiguration) DeepCopyInto(out *PGAdminConfiguration) {
 	*out = *in
 	if in.Files != nil {
 		in, out := &in.Files, &out.Files
-		*out = make([]v1.VolumeProjection, len(*in))
+		*out = make([]corev1.VolumeProjection, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	if in.LDAPBindPassword != nil {
 		in, out := &in.LDAPBindPassword, &out.LDAPBindPassword
-		*out = new(v1.SecretKeySelector)
+		*out = new(corev1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
 	in.Settings.DeepCopyInto(&out.Settings)
@@ -299,7 +534,7 @@ func (in *PGAdminPodSpec) DeepCopyInto(out *PGAdminPodSpec) {
 	}
 	if in.Affinity != nil {
 		in, out := &in.Affinity, &out.Affinity
-		*out = new(v1.Affinity)
+		*out = new(corev1.Affinity)
 		(*in).DeepCopyInto(*out)
 	}
 	in.Config.DeepCopyInto(&out.Config)
@@ -318,18 +553,18 @@ func (in *PGAdminPodSpec) DeepCopyInto(out *PGAdminPodSpec) {
 	if in.Service != nil {
 		in, out := &in.Service, &out.Service
 		*out = new(ServiceSpec)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
+		*out = make([]corev1.Toleration, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	if in.TopologySpreadConstraints != nil {
 		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
-		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		*out = make([]corev1.TopologySpreadConstraint, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -361,6 +596,81 @@ func (in *PGAdminPodStatus) DeepCopy() *PGAdminPodStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGAuditLogShippingSpec) DeepCopyInto(out *PGAuditLogShippingSpec) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGAuditLogShippingSpec.
+func (in *PGAuditLogShippingSpec) DeepCopy() *PGAuditLogShippingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PGAuditLogShippingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGAuditRoleSpec) DeepCopyInto(out *PGAuditRoleSpec) {
+	*out = *in
+	if in.Log != nil {
+		in, out := &in.Log, &out.Log
+		*out = make([]PGAuditLogClass, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGAuditRoleSpec.
+func (in *PGAuditRoleSpec) DeepCopy() *PGAuditRoleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PGAuditRoleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGAuditSpec) DeepCopyInto(out *PGAuditSpec) {
+	*out = *in
+	if in.Log != nil {
+		in, out := &in.Log, &out.Log
+		*out = make([]PGAuditLogClass, len(*in))
+		copy(*out, *in)
+	}
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]PGAuditRoleSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LogShipping != nil {
+		in, out := &in.LogShipping, &out.LogShipping
+		*out = new(PGAuditLogShippingSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGAuditSpec.
+func (in *PGAuditSpec) DeepCopy() *PGAuditSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PGAuditSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PGBackRestArchive) DeepCopyInto(out *PGBackRestArchive) {
 	*out = *in
@@ -371,7 +681,7 @@ func (in *PGBackRestArchive) DeepCopyInto(out *PGBackRestArchive) {
 	}
 	if in.Configuration != nil {
 		in, out := &in.Configuration, &out.Configuration
-		*out = make([]v1.VolumeProjection, len(*in))
+		*out = make([]corev1.VolumeProjection, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -405,6 +715,11 @@ func (in *PGBackRestArchive) DeepCopyInto(out *PGBackRestArchive) {
 		*out = new(PGBackRestManualBackup)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.FinalBackup != nil {
+		in, out := &in.FinalBackup, &out.FinalBackup
+		*out = new(PGBackRestFinalBackup)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Restore != nil {
 		in, out := &in.Restore, &out.Restore
 		*out = new(PGBackRestRestore)
@@ -445,6 +760,11 @@ func (in *PGBackRestBackupSchedules) DeepCopyInto(out *PGBackRestBackupSchedules
 		*out = new(string)
 		**out = **in
 	}
+	if in.Suspend != nil {
+		in, out := &in.Suspend, &out.Suspend
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestBackupSchedules.
@@ -462,7 +782,7 @@ func (in *PGBackRestDataSource) DeepCopyInto(out *PGBackRestDataSource) {
 	*out = *in
 	if in.Configuration != nil {
 		in, out := &in.Configuration, &out.Configuration
-		*out = make([]v1.VolumeProjection, len(*in))
+		*out = make([]corev1.VolumeProjection, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -483,7 +803,7 @@ func (in *PGBackRestDataSource) DeepCopyInto(out *PGBackRestDataSource) {
 	in.Resources.DeepCopyInto(&out.Resources)
 	if in.Affinity != nil {
 		in, out := &in.Affinity, &out.Affinity
-		*out = new(v1.Affinity)
+		*out = new(corev1.Affinity)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.PriorityClassName != nil {
@@ -493,7 +813,7 @@ func (in *PGBackRestDataSource) DeepCopyInto(out *PGBackRestDataSource) {
 	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
+		*out = make([]corev1.Toleration, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -510,6 +830,26 @@ func (in *PGBackRestDataSource) DeepCopy() *PGBackRestDataSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGBackRestFinalBackup) DeepCopyInto(out *PGBackRestFinalBackup) {
+	*out = *in
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestFinalBackup.
+func (in *PGBackRestFinalBackup) DeepCopy() *PGBackRestFinalBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(PGBackRestFinalBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PGBackRestJobStatus) DeepCopyInto(out *PGBackRestJobStatus) {
 	*out = *in
@@ -598,7 +938,7 @@ func (in *PGBackRestRepoHost) DeepCopyInto(out *PGBackRestRepoHost) {
 	*out = *in
 	if in.Affinity != nil {
 		in, out := &in.Affinity, &out.Affinity
-		*out = new(v1.Affinity)
+		*out = new(corev1.Affinity)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.PriorityClassName != nil {
@@ -609,26 +949,26 @@ func (in *PGBackRestRepoHost) DeepCopyInto(out *PGBackRestRepoHost) {
 	in.Resources.DeepCopyInto(&out.Resources)
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
+		*out = make([]corev1.Toleration, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	if in.TopologySpreadConstraints != nil {
 		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
-		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		*out = make([]corev1.TopologySpreadConstraint, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	if in.SSHConfiguration != nil {
 		in, out := &in.SSHConfiguration, &out.SSHConfiguration
-		*out = new(v1.ConfigMapProjection)
+		*out = new(corev1.ConfigMapProjection)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.SSHSecret != nil {
 		in, out := &in.SSHSecret, &out.SSHSecret
-		*out = new(v1.SecretProjection)
+		*out = new(corev1.SecretProjection)
 		(*in).DeepCopyInto(*out)
 	}
 }
@@ -759,31 +1099,112 @@ func (in *PGBackRestStatus) DeepCopy() *PGBackRestStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGBouncerConfiguration) DeepCopyInto(out *PGBouncerConfiguration) {
+func (in *PGBenchResult) DeepCopyInto(out *PGBenchResult) {
 	*out = *in
-	if in.Files != nil {
-		in, out := &in.Files, &out.Files
-		*out = make([]v1.VolumeProjection, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.Global != nil {
-		in, out := &in.Global, &out.Global
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
 	}
-	if in.Databases != nil {
-		in, out := &in.Databases, &out.Databases
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
 	}
-	if in.Users != nil {
-		in, out := &in.Users, &out.Users
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBenchResult.
+func (in *PGBenchResult) DeepCopy() *PGBenchResult {
+	if in == nil {
+		return nil
+	}
+	out := new(PGBenchResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGBenchSpec) DeepCopyInto(out *PGBenchSpec) {
+	*out = *in
+	if in.Clients != nil {
+		in, out := &in.Clients, &out.Clients
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Jobs != nil {
+		in, out := &in.Jobs, &out.Jobs
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DurationSeconds != nil {
+		in, out := &in.DurationSeconds, &out.DurationSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Scale != nil {
+		in, out := &in.Scale, &out.Scale
+		*out = new(int32)
+		**out = **in
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBenchSpec.
+func (in *PGBenchSpec) DeepCopy() *PGBenchSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PGBenchSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGBenchStatus) DeepCopyInto(out *PGBenchStatus) {
+	*out = *in
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = make([]PGBenchResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBenchStatus.
+func (in *PGBenchStatus) DeepCopy() *PGBenchStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PGBenchStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGBouncerConfiguration) DeepCopyInto(out *PGBouncerConfiguration) {
+	*out = *in
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]corev1.VolumeProjection, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Global != nil {
+		in, out := &in.Global, &out.Global
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
 		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
@@ -811,13 +1232,13 @@ func (in *PGBouncerPodSpec) DeepCopyInto(out *PGBouncerPodSpec) {
 	}
 	if in.Affinity != nil {
 		in, out := &in.Affinity, &out.Affinity
-		*out = new(v1.Affinity)
+		*out = new(corev1.Affinity)
 		(*in).DeepCopyInto(*out)
 	}
 	in.Config.DeepCopyInto(&out.Config)
 	if in.CustomTLSSecret != nil {
 		in, out := &in.CustomTLSSecret, &out.CustomTLSSecret
-		*out = new(v1.SecretProjection)
+		*out = new(corev1.SecretProjection)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.Port != nil {
@@ -825,6 +1246,7 @@ func (in *PGBouncerPodSpec) DeepCopyInto(out *PGBouncerPodSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	in.PodTemplateOverlay.DeepCopyInto(&out.PodTemplateOverlay)
 	if in.PriorityClassName != nil {
 		in, out := &in.PriorityClassName, &out.PriorityClassName
 		*out = new(string)
@@ -844,7 +1266,7 @@ func (in *PGBouncerPodSpec) DeepCopyInto(out *PGBouncerPodSpec) {
 	if in.Service != nil {
 		in, out := &in.Service, &out.Service
 		*out = new(ServiceSpec)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Sidecars != nil {
 		in, out := &in.Sidecars, &out.Sidecars
@@ -853,14 +1275,14 @@ func (in *PGBouncerPodSpec) DeepCopyInto(out *PGBouncerPodSpec) {
 	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
+		*out = make([]corev1.Toleration, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	if in.TopologySpreadConstraints != nil {
 		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
-		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		*out = make([]corev1.TopologySpreadConstraint, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -932,6 +1354,31 @@ func (in *PGMonitorSpec) DeepCopy() *PGMonitorSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PatroniReplicaRemediation) DeepCopyInto(out *PatroniReplicaRemediation) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CooldownSeconds != nil {
+		in, out := &in.CooldownSeconds, &out.CooldownSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniReplicaRemediation.
+func (in *PatroniReplicaRemediation) DeepCopy() *PatroniReplicaRemediation {
+	if in == nil {
+		return nil
+	}
+	out := new(PatroniReplicaRemediation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PatroniSpec) DeepCopyInto(out *PatroniSpec) {
 	*out = *in
@@ -956,6 +1403,11 @@ func (in *PatroniSpec) DeepCopyInto(out *PatroniSpec) {
 		*out = new(PatroniSwitchover)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ReplicaRemediation != nil {
+		in, out := &in.ReplicaRemediation, &out.ReplicaRemediation
+		*out = new(PatroniReplicaRemediation)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniSpec.
@@ -1013,11 +1465,18 @@ func (in *PostgresAdditionalConfig) DeepCopyInto(out *PostgresAdditionalConfig)
 	*out = *in
 	if in.Files != nil {
 		in, out := &in.Files, &out.Files
-		*out = make([]v1.VolumeProjection, len(*in))
+		*out = make([]corev1.VolumeProjection, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]intstr.IntOrString, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresAdditionalConfig.
@@ -1030,6 +1489,43 @@ func (in *PostgresAdditionalConfig) DeepCopy() *PostgresAdditionalConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresAuthenticationSpec) DeepCopyInto(out *PostgresAuthenticationSpec) {
+	*out = *in
+	if in.TLSOnly != nil {
+		in, out := &in.TLSOnly, &out.TLSOnly
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]PostgresHBARuleSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Kerberos != nil {
+		in, out := &in.Kerberos, &out.Kerberos
+		*out = new(PostgresKerberosSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LDAP != nil {
+		in, out := &in.LDAP, &out.LDAP
+		*out = new(PostgresLDAPSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresAuthenticationSpec.
+func (in *PostgresAuthenticationSpec) DeepCopy() *PostgresAuthenticationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresAuthenticationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresCluster) DeepCopyInto(out *PostgresCluster) {
 	*out = *in
@@ -1060,6 +1556,11 @@ func (in *PostgresCluster) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresClusterDataSource) DeepCopyInto(out *PostgresClusterDataSource) {
 	*out = *in
+	if in.CopyUserPasswords != nil {
+		in, out := &in.CopyUserPasswords, &out.CopyUserPasswords
+		*out = new(bool)
+		**out = **in
+	}
 	if in.Options != nil {
 		in, out := &in.Options, &out.Options
 		*out = make([]string, len(*in))
@@ -1068,7 +1569,7 @@ func (in *PostgresClusterDataSource) DeepCopyInto(out *PostgresClusterDataSource
 	in.Resources.DeepCopyInto(&out.Resources)
 	if in.Affinity != nil {
 		in, out := &in.Affinity, &out.Affinity
-		*out = new(v1.Affinity)
+		*out = new(corev1.Affinity)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.PriorityClassName != nil {
@@ -1078,7 +1579,7 @@ func (in *PostgresClusterDataSource) DeepCopyInto(out *PostgresClusterDataSource
 	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
+		*out = make([]corev1.Toleration, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -1140,15 +1641,35 @@ func (in *PostgresClusterSpec) DeepCopyInto(out *PostgresClusterSpec) {
 		*out = new(DataSource)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Benchmark != nil {
+		in, out := &in.Benchmark, &out.Benchmark
+		*out = new(PGBenchSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Audit != nil {
+		in, out := &in.Audit, &out.Audit
+		*out = new(PGAuditSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Authentication != nil {
+		in, out := &in.Authentication, &out.Authentication
+		*out = new(PostgresAuthenticationSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	in.Backups.DeepCopyInto(&out.Backups)
 	if in.CustomTLSSecret != nil {
 		in, out := &in.CustomTLSSecret, &out.CustomTLSSecret
-		*out = new(v1.SecretProjection)
+		*out = new(corev1.SecretProjection)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CertManager != nil {
+		in, out := &in.CertManager, &out.CertManager
+		*out = new(CertManagerSpec)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.CustomReplicationClientTLSSecret != nil {
 		in, out := &in.CustomReplicationClientTLSSecret, &out.CustomReplicationClientTLSSecret
-		*out = new(v1.SecretProjection)
+		*out = new(corev1.SecretProjection)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.DatabaseInitSQL != nil {
@@ -1156,14 +1677,36 @@ func (in *PostgresClusterSpec) DeepCopyInto(out *PostgresClusterSpec) {
 		*out = new(DatabaseInitSQL)
 		**out = **in
 	}
+	if in.DeletionProtection != nil {
+		in, out := &in.DeletionProtection, &out.DeletionProtection
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DeletionRetentionDays != nil {
+		in, out := &in.DeletionRetentionDays, &out.DeletionRetentionDays
+		*out = new(int32)
+		**out = **in
+	}
 	if in.DisableDefaultPodScheduling != nil {
 		in, out := &in.DisableDefaultPodScheduling, &out.DisableDefaultPodScheduling
 		*out = new(bool)
 		**out = **in
 	}
+	if in.AutoTuning != nil {
+		in, out := &in.AutoTuning, &out.AutoTuning
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Extensions != nil {
+		in, out := &in.Extensions, &out.Extensions
+		*out = make([]PostgresExtensionSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.ImagePullSecrets != nil {
 		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
-		*out = make([]v1.LocalObjectReference, len(*in))
+		*out = make([]corev1.LocalObjectReference, len(*in))
 		copy(*out, *in)
 	}
 	if in.InstanceSets != nil {
@@ -1173,6 +1716,11 @@ func (in *PostgresClusterSpec) DeepCopyInto(out *PostgresClusterSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.LogicalReplication != nil {
+		in, out := &in.LogicalReplication, &out.LogicalReplication
+		*out = new(PostgresLogicalReplicationSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.OpenShift != nil {
 		in, out := &in.OpenShift, &out.OpenShift
 		*out = new(bool)
@@ -1206,7 +1754,24 @@ func (in *PostgresClusterSpec) DeepCopyInto(out *PostgresClusterSpec) {
 	if in.Service != nil {
 		in, out := &in.Service, &out.Service
 		*out = new(ServiceSpec)
-		**out = **in
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReplicaService != nil {
+		in, out := &in.ReplicaService, &out.ReplicaService
+		*out = new(ServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReplicaServiceMaxLag != nil {
+		in, out := &in.ReplicaServiceMaxLag, &out.ReplicaServiceMaxLag
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.SQLPolicies != nil {
+		in, out := &in.SQLPolicies, &out.SQLPolicies
+		*out = make([]SQLPolicySpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Shutdown != nil {
 		in, out := &in.Shutdown, &out.Shutdown
@@ -1218,6 +1783,36 @@ func (in *PostgresClusterSpec) DeepCopyInto(out *PostgresClusterSpec) {
 		*out = new(PostgresStandbySpec)
 		**out = **in
 	}
+	if in.UptimeSchedule != nil {
+		in, out := &in.UptimeSchedule, &out.UptimeSchedule
+		*out = new(UptimeScheduleSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaintenanceWindow != nil {
+		in, out := &in.MaintenanceWindow, &out.MaintenanceWindow
+		*out = new(MaintenanceWindowSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DiskUsage != nil {
+		in, out := &in.DiskUsage, &out.DiskUsage
+		*out = new(DiskUsageSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TempVolume != nil {
+		in, out := &in.TempVolume, &out.TempVolume
+		*out = new(PostgresTempVolumeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DedicatedCPUs != nil {
+		in, out := &in.DedicatedCPUs, &out.DedicatedCPUs
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = new(NotificationsSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.SupplementalGroups != nil {
 		in, out := &in.SupplementalGroups, &out.SupplementalGroups
 		*out = make([]int64, len(*in))
@@ -1230,6 +1825,18 @@ func (in *PostgresClusterSpec) DeepCopyInto(out *PostgresClusterSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]PostgresDatabaseSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PasswordPolicy != nil {
+		in, out := &in.PasswordPolicy, &out.PasswordPolicy
+		*out = new(PostgresPasswordPolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
 	in.Config.DeepCopyInto(&out.Config)
 }
 
@@ -1249,7 +1856,9 @@ func (in *PostgresClusterStatus) DeepCopyInto(out *PostgresClusterStatus) {
 	if in.InstanceSets != nil {
 		in, out := &in.InstanceSets, &out.InstanceSets
 		*out = make([]PostgresInstanceSetStatus, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	in.Patroni.DeepCopyInto(&out.Patroni)
 	if in.PGBackRest != nil {
@@ -1257,12 +1866,31 @@ func (in *PostgresClusterStatus) DeepCopyInto(out *PostgresClusterStatus) {
 		*out = new(PGBackRestStatus)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Benchmark != nil {
+		in, out := &in.Benchmark, &out.Benchmark
+		*out = new(PGBenchStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	out.Proxy = in.Proxy
+	if in.SQLPolicies != nil {
+		in, out := &in.SQLPolicies, &out.SQLPolicies
+		*out = make([]SQLPolicyStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.UserInterface != nil {
 		in, out := &in.UserInterface, &out.UserInterface
 		*out = new(PostgresUserInterfaceStatus)
 		**out = **in
 	}
+	if in.PasswordExpirations != nil {
+		in, out := &in.PasswordExpirations, &out.PasswordExpirations
+		*out = make(map[string]v1.Time, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 	out.Monitoring = in.Monitoring
 	if in.DatabaseInitSQL != nil {
 		in, out := &in.DatabaseInitSQL, &out.DatabaseInitSQL
@@ -1271,7 +1899,7 @@ func (in *PostgresClusterStatus) DeepCopyInto(out *PostgresClusterStatus) {
 	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
+		*out = make([]v1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -1288,6 +1916,88 @@ func (in *PostgresClusterStatus) DeepCopy() *PostgresClusterStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresDatabaseSpec) DeepCopyInto(out *PostgresDatabaseSpec) {
+	*out = *in
+	if in.Schemas != nil {
+		in, out := &in.Schemas, &out.Schemas
+		*out = make([]PostgresIdentifier, len(*in))
+		copy(*out, *in)
+	}
+	if in.Readers != nil {
+		in, out := &in.Readers, &out.Readers
+		*out = make([]PostgresIdentifier, len(*in))
+		copy(*out, *in)
+	}
+	if in.Writers != nil {
+		in, out := &in.Writers, &out.Writers
+		*out = make([]PostgresIdentifier, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresDatabaseSpec.
+func (in *PostgresDatabaseSpec) DeepCopy() *PostgresDatabaseSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresDatabaseSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresExtensionSpec) DeepCopyInto(out *PostgresExtensionSpec) {
+	*out = *in
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]PostgresIdentifier, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresExtensionSpec.
+func (in *PostgresExtensionSpec) DeepCopy() *PostgresExtensionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresExtensionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresHBARuleSpec) DeepCopyInto(out *PostgresHBARuleSpec) {
+	*out = *in
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]PostgresIdentifier, len(*in))
+		copy(*out, *in)
+	}
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]PostgresIdentifier, len(*in))
+		copy(*out, *in)
+	}
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresHBARuleSpec.
+func (in *PostgresHBARuleSpec) DeepCopy() *PostgresHBARuleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresHBARuleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresInstanceSetSpec) DeepCopyInto(out *PostgresInstanceSetSpec) {
 	*out = *in
@@ -1298,10 +2008,37 @@ func (in *PostgresInstanceSetSpec) DeepCopyInto(out *PostgresInstanceSetSpec) {
 	}
 	if in.Affinity != nil {
 		in, out := &in.Affinity, &out.Affinity
-		*out = new(v1.Affinity)
+		*out = new(corev1.Affinity)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]corev1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	in.DataVolumeClaimSpec.DeepCopyInto(&out.DataVolumeClaimSpec)
+	if in.GuaranteedQoS != nil {
+		in, out := &in.GuaranteedQoS, &out.GuaranteedQoS
+		*out = new(bool)
+		**out = **in
+	}
+	if in.InitContainers != nil {
+		in, out := &in.InitContainers, &out.InitContainers
+		*out = make([]corev1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.PodTemplateOverlay.DeepCopyInto(&out.PodTemplateOverlay)
 	if in.PriorityClassName != nil {
 		in, out := &in.PriorityClassName, &out.PriorityClassName
 		*out = new(string)
@@ -1325,21 +2062,28 @@ func (in *PostgresInstanceSetSpec) DeepCopyInto(out *PostgresInstanceSetSpec) {
 	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
+		*out = make([]corev1.Toleration, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	if in.TopologySpreadConstraints != nil {
 		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
-		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		*out = make([]corev1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]corev1.Volume, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	if in.WALVolumeClaimSpec != nil {
 		in, out := &in.WALVolumeClaimSpec, &out.WALVolumeClaimSpec
-		*out = new(v1.PersistentVolumeClaimSpec)
+		*out = new(corev1.PersistentVolumeClaimSpec)
 		(*in).DeepCopyInto(*out)
 	}
 }
@@ -1357,6 +2101,16 @@ func (in *PostgresInstanceSetSpec) DeepCopy() *PostgresInstanceSetSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresInstanceSetStatus) DeepCopyInto(out *PostgresInstanceSetStatus) {
 	*out = *in
+	if in.DataVolumeUsedPercent != nil {
+		in, out := &in.DataVolumeUsedPercent, &out.DataVolumeUsedPercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.WALVolumeUsedPercent != nil {
+		in, out := &in.WALVolumeUsedPercent, &out.WALVolumeUsedPercent
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresInstanceSetStatus.
@@ -1369,9 +2123,119 @@ func (in *PostgresInstanceSetStatus) DeepCopy() *PostgresInstanceSetStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresKerberosSpec) DeepCopyInto(out *PostgresKerberosSpec) {
+	*out = *in
+	in.Keytab.DeepCopyInto(&out.Keytab)
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresKerberosSpec.
+func (in *PostgresKerberosSpec) DeepCopy() *PostgresKerberosSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresKerberosSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresLDAPSpec) DeepCopyInto(out *PostgresLDAPSpec) {
+	*out = *in
+	if in.CA != nil {
+		in, out := &in.CA, &out.CA
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresLDAPSpec.
+func (in *PostgresLDAPSpec) DeepCopy() *PostgresLDAPSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresLDAPSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresLogicalReplicationSpec) DeepCopyInto(out *PostgresLogicalReplicationSpec) {
+	*out = *in
+	if in.Publications != nil {
+		in, out := &in.Publications, &out.Publications
+		*out = make([]PostgresPublicationSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Subscriptions != nil {
+		in, out := &in.Subscriptions, &out.Subscriptions
+		*out = make([]PostgresSubscriptionSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresLogicalReplicationSpec.
+func (in *PostgresLogicalReplicationSpec) DeepCopy() *PostgresLogicalReplicationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresLogicalReplicationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresPasswordPolicySpec) DeepCopyInto(out *PostgresPasswordPolicySpec) {
+	*out = *in
+	if in.MinLength != nil {
+		in, out := &in.MinLength, &out.MinLength
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RequiredCharacters != nil {
+		in, out := &in.RequiredCharacters, &out.RequiredCharacters
+		*out = make([]PostgresPasswordCharacters, len(*in))
+		copy(*out, *in)
+	}
+	if in.ForbiddenWords != nil {
+		in, out := &in.ForbiddenWords, &out.ForbiddenWords
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresPasswordPolicySpec.
+func (in *PostgresPasswordPolicySpec) DeepCopy() *PostgresPasswordPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresPasswordPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresPasswordSpec) DeepCopyInto(out *PostgresPasswordSpec) {
 	*out = *in
+	if in.Vault != nil {
+		in, out := &in.Vault, &out.Vault
+		*out = new(PostgresPasswordVaultSpec)
+		**out = **in
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresPasswordSpec.
@@ -1384,6 +2248,21 @@ func (in *PostgresPasswordSpec) DeepCopy() *PostgresPasswordSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresPasswordVaultSpec) DeepCopyInto(out *PostgresPasswordVaultSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresPasswordVaultSpec.
+func (in *PostgresPasswordVaultSpec) DeepCopy() *PostgresPasswordVaultSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresPasswordVaultSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresProxySpec) DeepCopyInto(out *PostgresProxySpec) {
 	*out = *in
@@ -1420,6 +2299,26 @@ func (in *PostgresProxyStatus) DeepCopy() *PostgresProxyStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresPublicationSpec) DeepCopyInto(out *PostgresPublicationSpec) {
+	*out = *in
+	if in.Tables != nil {
+		in, out := &in.Tables, &out.Tables
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresPublicationSpec.
+func (in *PostgresPublicationSpec) DeepCopy() *PostgresPublicationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresPublicationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresStandbySpec) DeepCopyInto(out *PostgresStandbySpec) {
 	*out = *in
@@ -1435,6 +2334,52 @@ func (in *PostgresStandbySpec) DeepCopy() *PostgresStandbySpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresSubscriptionSpec) DeepCopyInto(out *PostgresSubscriptionSpec) {
+	*out = *in
+	in.Connection.DeepCopyInto(&out.Connection)
+	if in.Publications != nil {
+		in, out := &in.Publications, &out.Publications
+		*out = make([]PostgresIdentifier, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresSubscriptionSpec.
+func (in *PostgresSubscriptionSpec) DeepCopy() *PostgresSubscriptionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresSubscriptionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresTempVolumeSpec) DeepCopyInto(out *PostgresTempVolumeSpec) {
+	*out = *in
+	if in.SizeLimit != nil {
+		in, out := &in.SizeLimit, &out.SizeLimit
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.VolumeClaimSpec != nil {
+		in, out := &in.VolumeClaimSpec, &out.VolumeClaimSpec
+		*out = new(corev1.PersistentVolumeClaimSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresTempVolumeSpec.
+func (in *PostgresTempVolumeSpec) DeepCopy() *PostgresTempVolumeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresTempVolumeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresUserInterfaceStatus) DeepCopyInto(out *PostgresUserInterfaceStatus) {
 	*out = *in
@@ -1459,10 +2404,15 @@ func (in *PostgresUserSpec) DeepCopyInto(out *PostgresUserSpec) {
 		*out = make([]PostgresIdentifier, len(*in))
 		copy(*out, *in)
 	}
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]PostgresIdentifier, len(*in))
+		copy(*out, *in)
+	}
 	if in.Password != nil {
 		in, out := &in.Password, &out.Password
 		*out = new(PostgresPasswordSpec)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -1568,6 +2518,61 @@ func (in *RepoStatus) DeepCopy() *RepoStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SQLPolicySpec) DeepCopyInto(out *SQLPolicySpec) {
+	*out = *in
+	in.SQL.DeepCopyInto(&out.SQL)
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(string)
+		**out = **in
+	}
+	if in.LockTimeout != nil {
+		in, out := &in.LockTimeout, &out.LockTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaintenanceWindow != nil {
+		in, out := &in.MaintenanceWindow, &out.MaintenanceWindow
+		*out = new(bool)
+		**out = **in
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SQLPolicySpec.
+func (in *SQLPolicySpec) DeepCopy() *SQLPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SQLPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SQLPolicyStatus) DeepCopyInto(out *SQLPolicyStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SQLPolicyStatus.
+func (in *SQLPolicyStatus) DeepCopy() *SQLPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SQLPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in SchemalessObject) DeepCopyInto(out *SchemalessObject) {
 	{
@@ -1580,6 +2585,21 @@ func (in SchemalessObject) DeepCopyInto(out *SchemalessObject) {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceSpec) DeepCopyInto(out *ServiceSpec) {
 	*out = *in
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(Metadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodePort != nil {
+		in, out := &in.NodePort, &out.NodePort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LoadBalancerSourceRanges != nil {
+		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceSpec.
@@ -1597,7 +2617,7 @@ func (in *Sidecar) DeepCopyInto(out *Sidecar) {
 	*out = *in
 	if in.Resources != nil {
 		in, out := &in.Resources, &out.Resources
-		*out = new(v1.ResourceRequirements)
+		*out = new(corev1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
 }
@@ -1612,6 +2632,48 @@ func (in *Sidecar) DeepCopy() *Sidecar {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UptimeScheduleSpec) DeepCopyInto(out *UptimeScheduleSpec) {
+	*out = *in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]UptimeWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UptimeScheduleSpec.
+func (in *UptimeScheduleSpec) DeepCopy() *UptimeScheduleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(UptimeScheduleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UptimeWindow) DeepCopyInto(out *UptimeWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]Weekday, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UptimeWindow.
+func (in *UptimeWindow) DeepCopy() *UptimeWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(UptimeWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UserInterfaceSpec) DeepCopyInto(out *UserInterfaceSpec) {
 	*out = *in